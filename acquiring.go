@@ -0,0 +1,212 @@
+package iso8583
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// mappedField maps ISO 8583 field number to a field of canonical struct
+type mappedField struct {
+	index int
+	value *string
+}
+
+// storeMappedFields sets non-empty values of fields to m
+func storeMappedFields(m *Message, fields []mappedField) error {
+	for _, f := range fields {
+		if *f.value == "" {
+			continue
+		}
+		if err := m.SetString(f.index, *f.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadMappedFields reads values of fields from m without space padding,
+// fields which m doesn't define are empty
+func loadMappedFields(m *Message, fields []mappedField) error {
+	for _, f := range fields {
+		*f.value = ""
+		if !hasField(m.Data, f.index) {
+			continue
+		}
+		v, err := m.GetString(f.index)
+		if err != nil {
+			return err
+		}
+		*f.value = strings.TrimSpace(v)
+	}
+	return nil
+}
+
+// storeAmount sets amount to field 4 if it is not zero
+func storeAmount(m *Message, amount int64) error {
+	if amount == 0 {
+		return nil
+	}
+	return m.SetAmount(4, amount)
+}
+
+// loadAmount reads amount from field 4
+func loadAmount(m *Message) (int64, error) {
+	if !hasField(m.Data, 4) {
+		return 0, nil
+	}
+	v, err := m.GetString(4)
+	if err != nil || v == "" {
+		return 0, err
+	}
+	amount, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrBadAmount, v)
+	}
+	return amount, nil
+}
+
+// AuthorizationRequest is authorization or financial request (0100, 0200)
+// with meaningful names instead of field numbers. It is converted to and
+// from Message with any tagged struct defining used fields, so the same
+// code works with different specs.
+type AuthorizationRequest struct {
+	PAN              string // field 2
+	ProcessingCode   string // field 3
+	Amount           int64  // field 4, in minor units
+	TransmissionTime string // field 7, MMDDhhmmss
+	Stan             string // field 11
+	LocalTime        string // field 12, hhmmss
+	LocalDate        string // field 13, MMDD
+	Expiry           string // field 14, YYMM
+	MerchantType     string // field 18
+	EntryMode        string // field 22
+	Track2           string // field 35
+	Rrn              string // field 37
+	Terminal         string // field 41
+	Merchant         string // field 42
+	MerchantName     string // field 43
+	Currency         string // field 49, numeric code
+}
+
+func (r *AuthorizationRequest) fields() []mappedField {
+	return []mappedField{
+		{2, &r.PAN}, {3, &r.ProcessingCode}, {7, &r.TransmissionTime},
+		{11, &r.Stan}, {12, &r.LocalTime}, {13, &r.LocalDate}, {14, &r.Expiry},
+		{18, &r.MerchantType}, {22, &r.EntryMode}, {35, &r.Track2}, {37, &r.Rrn},
+		{41, &r.Terminal}, {42, &r.Merchant}, {43, &r.MerchantName}, {49, &r.Currency},
+	}
+}
+
+// ToMessage creates Message with mti and data, a pointer to tagged struct,
+// and sets its fields. Empty values are not set.
+func (r *AuthorizationRequest) ToMessage(mti string, data interface{}) (*Message, error) {
+	m := NewMessage(mti, data)
+	if err := storeAmount(m, r.Amount); err != nil {
+		return nil, err
+	}
+	return m, storeMappedFields(m, r.fields())
+}
+
+// FromMessage reads fields of m
+func (r *AuthorizationRequest) FromMessage(m *Message) (err error) {
+	if r.Amount, err = loadAmount(m); err != nil {
+		return err
+	}
+	return loadMappedFields(m, r.fields())
+}
+
+// AuthorizationResponse is response to AuthorizationRequest (0110, 0210)
+type AuthorizationResponse struct {
+	ProcessingCode   string // field 3
+	Amount           int64  // field 4, in minor units
+	TransmissionTime string // field 7, MMDDhhmmss
+	Stan             string // field 11
+	Rrn              string // field 37
+	AuthCode         string // field 38
+	ResponseCode     string // field 39
+	Terminal         string // field 41
+	Currency         string // field 49, numeric code
+}
+
+func (r *AuthorizationResponse) fields() []mappedField {
+	return []mappedField{
+		{3, &r.ProcessingCode}, {7, &r.TransmissionTime}, {11, &r.Stan},
+		{37, &r.Rrn}, {38, &r.AuthCode}, {39, &r.ResponseCode},
+		{41, &r.Terminal}, {49, &r.Currency},
+	}
+}
+
+// Approved checks if response code is "00"
+func (r *AuthorizationResponse) Approved() bool {
+	return r.ResponseCode == "00"
+}
+
+// ToMessage creates Message with mti and data, a pointer to tagged struct,
+// and sets its fields. Empty values are not set.
+func (r *AuthorizationResponse) ToMessage(mti string, data interface{}) (*Message, error) {
+	m := NewMessage(mti, data)
+	if err := storeAmount(m, r.Amount); err != nil {
+		return nil, err
+	}
+	return m, storeMappedFields(m, r.fields())
+}
+
+// FromMessage reads fields of m
+func (r *AuthorizationResponse) FromMessage(m *Message) (err error) {
+	if r.Amount, err = loadAmount(m); err != nil {
+		return err
+	}
+	return loadMappedFields(m, r.fields())
+}
+
+// Reversal is reversal request (0400, 0420) of original authorization
+type Reversal struct {
+	AuthorizationRequest
+	ResponseCode string // field 39, reason of reversal
+}
+
+// ToMessage creates Message with mti and data, a pointer to tagged struct,
+// and sets its fields. Empty values are not set.
+func (r *Reversal) ToMessage(mti string, data interface{}) (*Message, error) {
+	m, err := r.AuthorizationRequest.ToMessage(mti, data)
+	if err != nil {
+		return nil, err
+	}
+	return m, storeMappedFields(m, []mappedField{{39, &r.ResponseCode}})
+}
+
+// FromMessage reads fields of m
+func (r *Reversal) FromMessage(m *Message) error {
+	if err := r.AuthorizationRequest.FromMessage(m); err != nil {
+		return err
+	}
+	return loadMappedFields(m, []mappedField{{39, &r.ResponseCode}})
+}
+
+// NetworkManagementInfo is network management message (0800, 0810), see
+// NetworkManagement for its default spec
+type NetworkManagementInfo struct {
+	TransmissionTime string // field 7, MMDDhhmmss
+	Stan             string // field 11
+	ResponseCode     string // field 39
+	Code             string // field 70, for ex. NMI_ECHO
+}
+
+func (r *NetworkManagementInfo) fields() []mappedField {
+	return []mappedField{
+		{7, &r.TransmissionTime}, {11, &r.Stan}, {39, &r.ResponseCode}, {70, &r.Code},
+	}
+}
+
+// ToMessage creates Message with mti and data, a pointer to tagged struct,
+// and sets its fields. Empty values are not set.
+func (r *NetworkManagementInfo) ToMessage(mti string, data interface{}) (*Message, error) {
+	m := NewMessage(mti, data)
+	return m, storeMappedFields(m, r.fields())
+}
+
+// FromMessage reads fields of m
+func (r *NetworkManagementInfo) FromMessage(m *Message) error {
+	return loadMappedFields(m, r.fields())
+}
@@ -0,0 +1,86 @@
+package iso8583
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAuthorizationRequest(t *testing.T) {
+	type spec struct {
+		F2  *Llnumeric    `field:"2" length:"19"`
+		F3  *Numeric      `field:"3" length:"6"`
+		F4  *Numeric      `field:"4" length:"12"`
+		F11 *Numeric      `field:"11" length:"6"`
+		F38 *Alphanumeric `field:"38" length:"6"`
+		F39 *Alphanumeric `field:"39" length:"2"`
+		F41 *Alphanumeric `field:"41" length:"8"`
+		F49 *Numeric      `field:"49" length:"3"`
+	}
+
+	req := &AuthorizationRequest{
+		PAN:            "4276555555555558",
+		ProcessingCode: "000000",
+		Amount:         1250,
+		Stan:           "42",
+		Terminal:       "TERM01",
+		Currency:       "643",
+	}
+	msg, err := req.ToMessage("0100", &spec{})
+	assert.Nil(t, err)
+	data := msg.Data.(*spec)
+	assert.Equal(t, "000000001250", data.F4.Value)
+	assert.Equal(t, "000042", data.F11.Value)
+	assert.Nil(t, data.F38)
+
+	raw, err := msg.Bytes()
+	assert.Nil(t, err)
+	p := &Parser{}
+	p.Register("0100", &spec{})
+	msg, err = p.Parse(raw)
+	assert.Nil(t, err)
+
+	var req2 AuthorizationRequest
+	assert.Nil(t, req2.FromMessage(msg))
+	req.Stan = "000042"
+	assert.Equal(t, *req, req2)
+
+	// field not defined by spec
+	req.Merchant = "MERCHANT1"
+	_, err = req.ToMessage("0100", &spec{})
+	assert.EqualError(t, err, "field 42 not defined")
+
+	resp := msg.Clone()
+	resp.Mti = "0110"
+	assert.Nil(t, resp.SetString(38, "A1B2C3"))
+	assert.Nil(t, resp.SetString(39, "00"))
+	var r AuthorizationResponse
+	assert.Nil(t, r.FromMessage(resp))
+	assert.True(t, r.Approved())
+	assert.Equal(t, AuthorizationResponse{
+		ProcessingCode: "000000",
+		Amount:         1250,
+		Stan:           "000042",
+		AuthCode:       "A1B2C3",
+		ResponseCode:   "00",
+		Terminal:       "TERM01",
+		Currency:       "643",
+	}, r)
+
+	rev := &Reversal{req2, "68"}
+	revMsg, err := rev.ToMessage("0400", &spec{})
+	assert.Nil(t, err)
+	var rev2 Reversal
+	assert.Nil(t, rev2.FromMessage(revMsg))
+	assert.Equal(t, *rev, rev2)
+}
+
+func TestNetworkManagementInfo(t *testing.T) {
+	info := &NetworkManagementInfo{Stan: "000001", Code: NMI_ECHO}
+	msg, err := info.ToMessage("0800", &NetworkManagement{})
+	assert.Nil(t, err)
+	assert.Equal(t, NMI_ECHO, msg.Data.(*NetworkManagement).F70.Value)
+
+	var info2 NetworkManagementInfo
+	assert.Nil(t, info2.FromMessage(msg))
+	assert.Equal(t, *info, info2)
+}
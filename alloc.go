@@ -0,0 +1,47 @@
+package iso8583
+
+import (
+	"runtime"
+)
+
+// allocsPerRun returns average number of heap allocations of f, measured
+// like testing.AllocsPerRun: f is run once to warm up, then runs times
+// with GOMAXPROCS set to 1
+func allocsPerRun(runs int, f func() error) (float64, error) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(1))
+
+	if err := f(); err != nil {
+		return 0, err
+	}
+	if runs <= 0 {
+		return 0, nil
+	}
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	for i := 0; i < runs; i++ {
+		if err := f(); err != nil {
+			return 0, err
+		}
+	}
+	runtime.ReadMemStats(&after)
+	return float64(after.Mallocs-before.Mallocs) / float64(runs), nil
+}
+
+// AllocsPerPack returns average number of heap allocations of msg.Bytes
+// over runs runs, so applications can guard allocation budget of their
+// messages in tests
+func AllocsPerPack(msg *Message, runs int) (float64, error) {
+	return allocsPerRun(runs, func() error {
+		_, err := msg.Bytes()
+		return err
+	})
+}
+
+// AllocsPerParse returns average number of heap allocations of p.Parse(raw)
+// over runs runs
+func AllocsPerParse(p *Parser, raw []byte, runs int) (float64, error) {
+	return allocsPerRun(runs, func() error {
+		_, err := p.Parse(raw)
+		return err
+	})
+}
@@ -0,0 +1,43 @@
+package iso8583
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAllocsPerPack(t *testing.T) {
+	msg := newTestBatch(1)[0]
+	allocs, err := AllocsPerPack(msg, 10)
+	assert.Nil(t, err)
+	assert.True(t, allocs > 0)
+
+	raw, _ := msg.Bytes()
+	p := &Parser{}
+	p.Register("0200", &testTransaction{})
+	allocs, err = AllocsPerParse(p, raw, 10)
+	assert.Nil(t, err)
+	assert.True(t, allocs > 0)
+
+	_, err = AllocsPerParse(p, raw[:2], 10)
+	assert.NotNil(t, err)
+}
+
+// Baseline benchmarks of single message, run with -benchmem
+func BenchmarkBytes(b *testing.B) {
+	msg := newTestBatch(1)[0]
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		msg.Bytes()
+	}
+}
+
+func BenchmarkLoad(b *testing.B) {
+	raw, _ := newTestBatch(1)[0].Bytes()
+	p := &Parser{}
+	p.Register("0200", &testTransaction{})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.Parse(raw)
+	}
+}
@@ -0,0 +1,161 @@
+package iso8583
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// Balancing strategies of Balancer
+const (
+	BALANCE_ROUND_ROBIN       = iota // default
+	BALANCE_LEAST_OUTSTANDING        // endpoint with the least requests in flight
+)
+
+const (
+	ERR_NO_ENDPOINT string = "no healthy endpoint"
+)
+
+var ErrNoEndpoint = errors.New(ERR_NO_ENDPOINT)
+
+// Endpoint is a connection to host used by Balancer
+type Endpoint struct {
+	Name string
+	// Send sends request and waits for response
+	Send func(ctx context.Context, req *Message) (*Message, error)
+	// Link supervises connection, optional. Endpoint is healthy while its
+	// Link is connected.
+	Link *Link
+	// Secondary endpoints are used only if there is no healthy primary one
+	Secondary bool
+
+	outstanding atomic.Int64
+	sent        atomic.Uint64
+	failed      atomic.Uint64
+}
+
+// EndpointStats contains counters of Endpoint
+type EndpointStats struct {
+	Name        string
+	State       LinkState
+	Outstanding int64
+	Sent        uint64
+	Failed      uint64
+}
+
+func (e *Endpoint) healthy() bool {
+	return e.Link == nil || e.Link.State() == LinkConnected
+}
+
+// Stats returns counters of endpoint
+func (e *Endpoint) Stats() EndpointStats {
+	state := LinkConnected
+	if e.Link != nil {
+		state = e.Link.State()
+	}
+	return EndpointStats{
+		Name:        e.Name,
+		State:       state,
+		Outstanding: e.outstanding.Load(),
+		Sent:        e.sent.Load(),
+		Failed:      e.failed.Load(),
+	}
+}
+
+// Balancer spreads requests over endpoints, preferring healthy primary
+// ones. Request which send fails is resent to the next endpoint if it is
+// safe to resend (see Resendable).
+type Balancer struct {
+	Endpoints []*Endpoint
+	Strategy  int
+	// Resendable decides if request can be resent to another endpoint after
+	// failure, default allows network management messages, advices and
+	// reversals only, as they don't cause duplicate transactions
+	Resendable func(req *Message) bool
+
+	mu   sync.Mutex
+	next int
+}
+
+// Send sends req through chosen endpoint and returns response. Sending is
+// traced, see SetTracer.
+func (b *Balancer) Send(ctx context.Context, req *Message) (resp *Message, err error) {
+	ctx, span := startSpan(ctx, OpSend)
+	defer func() { endExchangeSpan(span, req, resp, err) }()
+
+	tried := make(map[*Endpoint]bool)
+	err = ErrNoEndpoint
+	for {
+		e := b.choose(tried)
+		if e == nil {
+			return nil, err
+		}
+		tried[e] = true
+
+		resp, err = b.sendTo(ctx, e, req)
+		if err == nil || ctx.Err() != nil || !b.resendable(req) {
+			return resp, err
+		}
+	}
+}
+
+func (b *Balancer) sendTo(ctx context.Context, e *Endpoint, req *Message) (*Message, error) {
+	e.outstanding.Add(1)
+	defer e.outstanding.Add(-1)
+	e.sent.Add(1)
+	resp, err := e.Send(ctx, req)
+	if err != nil {
+		e.failed.Add(1)
+	}
+	return resp, err
+}
+
+func (b *Balancer) resendable(req *Message) bool {
+	if b.Resendable != nil {
+		return b.Resendable(req)
+	}
+	return isReversalOrAdvice(req.Mti) || (len(req.Mti) == 4 && req.Mti[1] == '8')
+}
+
+// choose returns healthy endpoint not tried yet, primary ones first
+func (b *Balancer) choose(tried map[*Endpoint]bool) *Endpoint {
+	for _, secondary := range []bool{false, true} {
+		var candidates []*Endpoint
+		for _, e := range b.Endpoints {
+			if e.Secondary == secondary && !tried[e] && e.healthy() {
+				candidates = append(candidates, e)
+			}
+		}
+		if len(candidates) > 0 {
+			return b.pick(candidates)
+		}
+	}
+	return nil
+}
+
+func (b *Balancer) pick(candidates []*Endpoint) *Endpoint {
+	if b.Strategy == BALANCE_LEAST_OUTSTANDING {
+		best := candidates[0]
+		for _, e := range candidates[1:] {
+			if e.outstanding.Load() < best.outstanding.Load() {
+				best = e
+			}
+		}
+		return best
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e := candidates[b.next%len(candidates)]
+	b.next++
+	return e
+}
+
+// Stats returns counters of all endpoints
+func (b *Balancer) Stats() []EndpointStats {
+	ret := make([]EndpointStats, len(b.Endpoints))
+	for i, e := range b.Endpoints {
+		ret[i] = e.Stats()
+	}
+	return ret
+}
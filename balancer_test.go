@@ -0,0 +1,64 @@
+package iso8583
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBalancer(t *testing.T) {
+	var calls []string
+	fail := errors.New("connection reset")
+	endpoint := func(name string, err error) *Endpoint {
+		return &Endpoint{
+			Name: name,
+			Send: func(ctx context.Context, req *Message) (*Message, error) {
+				calls = append(calls, name)
+				return req, err
+			},
+		}
+	}
+	a, b, c := endpoint("a", nil), endpoint("b", nil), endpoint("c", nil)
+	c.Secondary = true
+	bal := &Balancer{Endpoints: []*Endpoint{a, b, c}}
+	ctx := context.Background()
+	req := NewMessage("0200", &testTransaction{})
+
+	for i := 0; i < 3; i++ {
+		_, err := bal.Send(ctx, req)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, []string{"a", "b", "a"}, calls)
+
+	// failover to secondary for resendable messages only
+	a.Link = &Link{}
+	b.Send = endpoint("b", fail).Send
+	calls = nil
+	_, err := bal.Send(ctx, req)
+	assert.Equal(t, fail, err)
+	_, err = bal.Send(ctx, NewMessage("0800", &testTransaction{}))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"b", "b", "c"}, calls)
+
+	stats := bal.Stats()
+	assert.Equal(t, EndpointStats{Name: "a", State: LinkDown, Sent: 2}, stats[0])
+	assert.Equal(t, EndpointStats{Name: "b", State: LinkConnected, Sent: 3, Failed: 2}, stats[1])
+
+	c.Link = &Link{}
+	_, err = bal.Send(ctx, NewMessage("0800", &testTransaction{}))
+	assert.Equal(t, fail, err)
+	b.Link = &Link{}
+	_, err = bal.Send(ctx, req)
+	assert.Equal(t, ErrNoEndpoint, err)
+}
+
+func TestBalancerLeastOutstanding(t *testing.T) {
+	a := &Endpoint{Name: "a"}
+	b := &Endpoint{Name: "b"}
+	a.outstanding.Store(2)
+	b.outstanding.Store(1)
+	bal := &Balancer{Endpoints: []*Endpoint{a, b}, Strategy: BALANCE_LEAST_OUTSTANDING}
+	assert.Equal(t, b, bal.choose(map[*Endpoint]bool{}))
+	assert.Equal(t, a, bal.choose(map[*Endpoint]bool{b: true}))
+}
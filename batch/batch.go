@@ -0,0 +1,490 @@
+// Package batch reads and writes files of consecutive ISO 8583 messages,
+// such as clearing and settlement files. Reader and Writer work with any
+// stream (for ex. net.Conn), length header style is chosen by Framer.
+// Messages concatenated without framing are read by NewDecoder.
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/ideazxy/iso8583"
+)
+
+const (
+	// HeaderBinary is 2 bytes big-endian length header
+	HeaderBinary = iota
+	// HeaderASCII is 4 digits ASCII length header
+	HeaderASCII
+	// HeaderNone means records are delimited by separator
+	HeaderNone
+	// HeaderBinaryLE is 2 bytes little-endian length header
+	HeaderBinaryLE
+	// HeaderBCD is 2 bytes BCD length header
+	HeaderBCD
+)
+
+const (
+	// TrailerNone means there is no check value after record
+	TrailerNone = iota
+	// TrailerLRC is 1 byte XOR of checked bytes
+	TrailerLRC
+	// TrailerCRC16 is 2 bytes big-endian CRC-16/CCITT-FALSE of checked bytes
+	TrailerCRC16
+)
+
+const (
+	stx = 0x02
+	etx = 0x03
+)
+
+const (
+	ERR_INVALID_HEADER    string = "invalid length header type"
+	ERR_MISSING_SEPARATOR string = "missing record separator"
+	ERR_BAD_HEADER        string = "bad length header"
+	ERR_RECORD_TOO_LONG   string = "record is too long for length header; len=%d"
+	ERR_TRUNCATED_RECORD  string = "truncated record"
+	ERR_INVALID_TRAILER   string = "invalid trailer type"
+	ERR_TRAILER_NEEDS_STX string = "trailer without length header requires STX"
+	ERR_MISSING_STX       string = "missing STX"
+	ERR_MISSING_ETX       string = "missing ETX"
+	ERR_BAD_CHECK_VALUE   string = "bad record check value"
+	ERR_INVALID_SIZE      string = "invalid record size"
+	ERR_BAD_RECORD_SIZE   string = "record size doesn't match; len=%d"
+)
+
+// maxRecordLen is maximum length of a single record
+const maxRecordLen = 1 << 20
+
+// Framer splits stream to records and wraps records for writing. Framing,
+// Fixed and Unframed are Framers.
+type Framer interface {
+	// Split is bufio.SplitFunc returning records without framing
+	Split(data []byte, atEOF bool) (advance int, record []byte, err error)
+	// Frame returns record with framing
+	Frame(record []byte) ([]byte, error)
+}
+
+// Fixed is Framer for records of fixed size without any framing
+type Fixed struct {
+	Size int
+}
+
+// Split implements Framer
+func (f Fixed) Split(data []byte, atEOF bool) (int, []byte, error) {
+	if f.Size <= 0 {
+		return 0, nil, errors.New(ERR_INVALID_SIZE)
+	}
+	if len(data) < f.Size {
+		if atEOF && len(data) > 0 {
+			return 0, nil, errors.New(ERR_TRUNCATED_RECORD)
+		}
+		return 0, nil, nil
+	}
+	return f.Size, data[:f.Size], nil
+}
+
+// Frame implements Framer
+func (f Fixed) Frame(record []byte) ([]byte, error) {
+	if len(record) != f.Size {
+		return nil, fmt.Errorf(ERR_BAD_RECORD_SIZE, len(record))
+	}
+	return record, nil
+}
+
+// Unframed is Framer for messages concatenated without any framing, as in
+// some legacy file dumps. Boundaries of messages are computed from their
+// bitmaps and field lengths by Parser.
+type Unframed struct {
+	Parser *iso8583.Parser
+}
+
+// Split implements Framer. Incomplete message (ErrBadRaw of truncated
+// data) is read further until EOF, then parsing error is returned. Other
+// errors are returned right away.
+func (f Unframed) Split(data []byte, atEOF bool) (int, []byte, error) {
+	if len(data) == 0 {
+		return 0, nil, nil
+	}
+	n, err := f.Parser.MessageLen(data)
+	if err != nil {
+		if atEOF || !errors.Is(err, iso8583.ErrBadRaw) {
+			return 0, nil, err
+		}
+		return 0, nil, nil
+	}
+	return n, data[:n], nil
+}
+
+// Frame implements Framer
+func (f Unframed) Frame(record []byte) ([]byte, error) {
+	return record, nil
+}
+
+// Framing describes how records are delimited in a file. Record on wire
+// is [STX] [length header] data [ETX] [trailer] [separator].
+type Framing struct {
+	Header    int
+	Separator []byte // used with HeaderNone without STX only, records must not contain it
+	// STX wraps records (with length header) in STX and ETX. With
+	// HeaderNone records are delimited by ETX and must not contain it.
+	STX bool
+	// Trailer is check value of bytes after STX up to ETX inclusive (or of
+	// length header and data without STX)
+	Trailer int
+	// MaxSize limits size of records written by Frame, 0 means no limit
+	MaxSize int
+}
+
+func (f Framing) check() error {
+	switch f.Trailer {
+	case TrailerNone, TrailerLRC, TrailerCRC16:
+	default:
+		return errors.New(ERR_INVALID_TRAILER)
+	}
+	switch f.Header {
+	case HeaderBinary, HeaderASCII, HeaderBinaryLE, HeaderBCD:
+		return nil
+	case HeaderNone:
+		if f.STX {
+			return nil
+		}
+		if f.Trailer != TrailerNone {
+			return errors.New(ERR_TRAILER_NEEDS_STX)
+		}
+		if len(f.Separator) == 0 {
+			return errors.New(ERR_MISSING_SEPARATOR)
+		}
+		return nil
+	default:
+		return errors.New(ERR_INVALID_HEADER)
+	}
+}
+
+// headLen returns length of length header
+func (f Framing) headLen() int {
+	switch f.Header {
+	case HeaderASCII:
+		return 4
+	case HeaderNone:
+		return 0
+	}
+	return 2
+}
+
+// encodeHead returns length header for record of length l
+func (f Framing) encodeHead(l int) ([]byte, error) {
+	max := 0xffff
+	if f.Header == HeaderASCII || f.Header == HeaderBCD {
+		max = 9999
+	}
+	if l > max {
+		return nil, fmt.Errorf(ERR_RECORD_TOO_LONG, l)
+	}
+	switch f.Header {
+	case HeaderBinary:
+		return []byte{byte(l >> 8), byte(l)}, nil
+	case HeaderBinaryLE:
+		return []byte{byte(l), byte(l >> 8)}, nil
+	case HeaderASCII:
+		return []byte(fmt.Sprintf("%04d", l)), nil
+	case HeaderBCD:
+		h := fmt.Sprintf("%04d", l)
+		return []byte{(h[0]-'0')<<4 | (h[1] - '0'), (h[2]-'0')<<4 | (h[3] - '0')}, nil
+	}
+	return nil, nil
+}
+
+// decodeHead returns record length from length header
+func (f Framing) decodeHead(head []byte) (int, error) {
+	switch f.Header {
+	case HeaderBinary:
+		return int(binary.BigEndian.Uint16(head)), nil
+	case HeaderBinaryLE:
+		return int(binary.LittleEndian.Uint16(head)), nil
+	case HeaderASCII:
+		n, err := strconv.Atoi(string(head))
+		if err != nil || n < 0 {
+			return 0, errors.New(ERR_BAD_HEADER + ": " + string(head))
+		}
+		return n, nil
+	case HeaderBCD:
+		n := 0
+		for _, b := range head {
+			if b>>4 > 9 || b&0x0f > 9 {
+				return 0, fmt.Errorf("%s: %x", ERR_BAD_HEADER, head)
+			}
+			n = n*100 + int(b>>4)*10 + int(b&0x0f)
+		}
+		return n, nil
+	}
+	return 0, errors.New(ERR_INVALID_HEADER)
+}
+
+// trailerLen returns length of trailer
+func (f Framing) trailerLen() int {
+	switch f.Trailer {
+	case TrailerLRC:
+		return 1
+	case TrailerCRC16:
+		return 2
+	}
+	return 0
+}
+
+// trailer returns check value of data
+func (f Framing) trailer(data []byte) []byte {
+	switch f.Trailer {
+	case TrailerLRC:
+		return []byte{LRC(data)}
+	case TrailerCRC16:
+		crc := CRC16(data)
+		return []byte{byte(crc >> 8), byte(crc)}
+	}
+	return nil
+}
+
+// LRC returns XOR of all bytes of data
+func LRC(data []byte) byte {
+	var lrc byte
+	for _, b := range data {
+		lrc ^= b
+	}
+	return lrc
+}
+
+// CRC16 returns CRC-16/CCITT-FALSE (polynomial 0x1021, initial value
+// 0xffff) of data
+func CRC16(data []byte) uint16 {
+	crc := uint16(0xffff)
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// Reader reads messages from a file one by one
+type Reader struct {
+	scanner *bufio.Scanner
+	parser  *iso8583.Parser
+	err     error
+}
+
+// NewReader creates new Reader. Messages are parsed with parser.
+func NewReader(r io.Reader, parser *iso8583.Parser, framing Framing) *Reader {
+	rd := NewFramedReader(r, parser, framing)
+	rd.err = framing.check()
+	return rd
+}
+
+// NewFramedReader creates new Reader with custom Framer. Messages are
+// parsed with parser.
+func NewFramedReader(r io.Reader, parser *iso8583.Parser, framer Framer) *Reader {
+	rd := &Reader{
+		scanner: bufio.NewScanner(r),
+		parser:  parser,
+	}
+	rd.scanner.Buffer(make([]byte, 0, 4096), maxRecordLen+8)
+	rd.scanner.Split(framer.Split)
+	return rd
+}
+
+// NewDecoder creates new Reader of messages concatenated without framing,
+// see Unframed. Use bytes.NewReader to decode []byte.
+func NewDecoder(r io.Reader, parser *iso8583.Parser) *Reader {
+	return NewFramedReader(r, parser, Unframed{parser})
+}
+
+// NextRaw returns bytes of next record. It returns io.EOF if there are no
+// more records.
+func (r *Reader) NextRaw() ([]byte, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if !r.scanner.Scan() {
+		r.err = r.scanner.Err()
+		if r.err == nil {
+			r.err = io.EOF
+		}
+		return nil, r.err
+	}
+	return r.scanner.Bytes(), nil
+}
+
+// Next returns next parsed message. It returns io.EOF if there are no more
+// records.
+func (r *Reader) Next() (*iso8583.Message, error) {
+	raw, err := r.NextRaw()
+	if err != nil {
+		return nil, err
+	}
+	// scanner reuses its buffer, so parse a copy
+	return r.parser.Parse(append([]byte(nil), raw...))
+}
+
+// NextInto loads next record into msg, which Data is used as a template.
+// It returns io.EOF if there are no more records.
+func (r *Reader) NextInto(msg *iso8583.Message) error {
+	raw, err := r.NextRaw()
+	if err != nil {
+		return err
+	}
+	return msg.Load(append([]byte(nil), raw...))
+}
+
+// Split implements Framer
+func (f Framing) Split(data []byte, atEOF bool) (int, []byte, error) {
+	if err := f.check(); err != nil {
+		return 0, nil, err
+	}
+	if !f.STX && f.Header == HeaderNone {
+		if i := bytes.Index(data, f.Separator); i >= 0 {
+			return i + len(f.Separator), data[:i], nil
+		}
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
+
+	// more returns result when frame is not complete yet
+	more := func() (int, []byte, error) {
+		if atEOF && len(data) > 0 {
+			return 0, nil, errors.New(ERR_TRUNCATED_RECORD)
+		}
+		return 0, nil, nil
+	}
+
+	start := 0
+	if f.STX {
+		if len(data) < 1 {
+			return more()
+		}
+		if data[0] != stx {
+			return 0, nil, errors.New(ERR_MISSING_STX)
+		}
+		start = 1
+	}
+
+	var rec []byte
+	end := start
+	switch f.Header {
+	case HeaderNone:
+		i := bytes.IndexByte(data[start:], etx)
+		if i < 0 {
+			return more()
+		}
+		rec = data[start : start+i]
+		end = start + i
+	default:
+		headLen := f.headLen()
+		if len(data) < start+headLen {
+			return more()
+		}
+		l, err := f.decodeHead(data[start : start+headLen])
+		if err != nil {
+			return 0, nil, err
+		}
+		end = start + headLen + l
+		if len(data) < end {
+			return more()
+		}
+		rec = data[start+headLen : end]
+	}
+
+	if f.STX {
+		if len(data) < end+1 {
+			return more()
+		}
+		if data[end] != etx {
+			return 0, nil, errors.New(ERR_MISSING_ETX)
+		}
+		end++
+	}
+	tl := f.trailerLen()
+	if len(data) < end+tl {
+		return more()
+	}
+	if !bytes.Equal(f.trailer(data[start:end]), data[end:end+tl]) {
+		return 0, nil, errors.New(ERR_BAD_CHECK_VALUE)
+	}
+	return end + tl, rec, nil
+}
+
+// Writer writes messages to a file with buffering. Flush must be called
+// after the last message.
+type Writer struct {
+	w      *bufio.Writer
+	framer Framer
+}
+
+// NewWriter creates new Writer
+func NewWriter(w io.Writer, framing Framing) *Writer {
+	return NewFramedWriter(w, framing)
+}
+
+// NewFramedWriter creates new Writer with custom Framer
+func NewFramedWriter(w io.Writer, framer Framer) *Writer {
+	return &Writer{bufio.NewWriter(w), framer}
+}
+
+// Write marshalls message and writes it as a record
+func (w *Writer) Write(msg *iso8583.Message) error {
+	b, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+	return w.WriteRaw(b)
+}
+
+// WriteRaw writes bytes as a record
+func (w *Writer) WriteRaw(b []byte) error {
+	frame, err := w.framer.Frame(b)
+	if err != nil {
+		return err
+	}
+	_, err = w.w.Write(frame)
+	return err
+}
+
+// Frame implements Framer
+func (f Framing) Frame(b []byte) ([]byte, error) {
+	if err := f.check(); err != nil {
+		return nil, err
+	}
+	if f.MaxSize > 0 && len(b) > f.MaxSize {
+		return nil, fmt.Errorf("%w: %d bytes, limit is %d", iso8583.ErrMessageTooLarge, len(b), f.MaxSize)
+	}
+	frame, err := f.encodeHead(len(b))
+	if err != nil {
+		return nil, err
+	}
+	frame = append(frame, b...)
+	if f.STX {
+		frame = append(frame, etx)
+		frame = append(frame, f.trailer(frame)...)
+		return append([]byte{stx}, frame...), nil
+	}
+	frame = append(frame, f.trailer(frame)...)
+	if f.Header == HeaderNone {
+		frame = append(frame, f.Separator...)
+	}
+	return frame, nil
+}
+
+// Flush writes buffered data to the underlying writer
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
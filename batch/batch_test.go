@@ -0,0 +1,276 @@
+package batch
+
+import (
+	"bytes"
+	"errors"
+	"github.com/ideazxy/iso8583"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+type testRecord struct {
+	F2  *iso8583.Llnumeric `field:"2" length:"19"`
+	F4  *iso8583.Numeric   `field:"4" length:"12"`
+	F11 *iso8583.Numeric   `field:"11" length:"6"`
+}
+
+func newRecord(stan string) *iso8583.Message {
+	return iso8583.NewMessage("0220", &testRecord{
+		F2:  iso8583.NewLlnumeric("4276555555555555"),
+		F4:  iso8583.NewNumeric("77700"),
+		F11: iso8583.NewNumeric(stan),
+	})
+}
+
+func TestReadWrite(t *testing.T) {
+	parser := &iso8583.Parser{}
+	parser.Register("0220", &testRecord{})
+
+	framings := []Framing{
+		{Header: HeaderBinary},
+		{Header: HeaderASCII},
+		{Header: HeaderNone, Separator: []byte("\r\n")},
+	}
+	for _, framing := range framings {
+		buf := &bytes.Buffer{}
+		w := NewWriter(buf, framing)
+		assert.Nil(t, w.Write(newRecord("000001")))
+		assert.Nil(t, w.Write(newRecord("000002")))
+		assert.Equal(t, 0, buf.Len())
+		assert.Nil(t, w.Flush())
+
+		r := NewReader(buf, parser, framing)
+		msg, err := r.Next()
+		assert.Nil(t, err)
+		assert.Equal(t, "000001", msg.Data.(*testRecord).F11.Value)
+		msg, err = r.Next()
+		assert.Nil(t, err)
+		assert.Equal(t, "000002", msg.Data.(*testRecord).F11.Value)
+		assert.Equal(t, "4276555555555555", msg.Data.(*testRecord).F2.Value)
+		_, err = r.Next()
+		assert.Equal(t, io.EOF, err)
+	}
+
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf, framings[0])
+	assert.Nil(t, w.Write(newRecord("000003")))
+	assert.Nil(t, w.Flush())
+
+	msg := iso8583.NewMessage("", &testRecord{&iso8583.Llnumeric{}, &iso8583.Numeric{}, &iso8583.Numeric{}})
+	r := NewReader(buf, parser, framings[0])
+	assert.Nil(t, r.NextInto(msg))
+	assert.Equal(t, "000003", msg.Data.(*testRecord).F11.Value)
+	assert.Equal(t, io.EOF, r.NextInto(msg))
+}
+
+func TestStreamMessages(t *testing.T) {
+	framings := []Framing{
+		{Header: HeaderBinary},
+		{Header: HeaderASCII, STX: true, Trailer: TrailerLRC},
+		{Header: HeaderNone, STX: true, Trailer: TrailerCRC16},
+		{Header: HeaderNone, Separator: []byte("\r\n")},
+	}
+	for _, framing := range framings {
+		buf := &bytes.Buffer{}
+		n, err := framing.WriteMessage(buf, newRecord("000001"))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(buf.Len()), n)
+		size := buf.Len()
+		_, err = framing.WriteMessage(buf, newRecord("000002"))
+		assert.Nil(t, err)
+
+		// nothing beyond the record is read
+		msg := iso8583.NewMessage("", &testRecord{&iso8583.Llnumeric{}, &iso8583.Numeric{}, &iso8583.Numeric{}})
+		n, err = framing.ReadMessage(buf, msg)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(size), n)
+		assert.Equal(t, size, buf.Len())
+		assert.Equal(t, "000001", msg.Data.(*testRecord).F11.Value)
+		_, err = framing.ReadMessage(buf, msg)
+		assert.Nil(t, err)
+		assert.Equal(t, "000002", msg.Data.(*testRecord).F11.Value)
+		_, err = framing.ReadMessage(buf, msg)
+		assert.Equal(t, io.EOF, err)
+	}
+
+	framing := Framing{Header: HeaderBinary}
+	_, err := framing.ReadMessage(bytes.NewReader([]byte{0, 10, '0', '2'}), iso8583.NewMessage("", &testRecord{}))
+	assert.EqualError(t, err, ERR_TRUNCATED_RECORD)
+	// length header is checked before the record is allocated
+	framing.Header = HeaderASCII
+	_, err = framing.ReadMessage(bytes.NewReader([]byte("12x4")), iso8583.NewMessage("", &testRecord{}))
+	assert.EqualError(t, err, ERR_BAD_HEADER+": 12x4")
+}
+
+func TestReaderErrors(t *testing.T) {
+	parser := &iso8583.Parser{}
+
+	r := NewReader(bytes.NewReader([]byte{0, 5, 1, 2}), parser, Framing{Header: HeaderBinary})
+	_, err := r.NextRaw()
+	assert.EqualError(t, err, "truncated record")
+
+	r = NewReader(bytes.NewReader([]byte("00a1")), parser, Framing{Header: HeaderASCII})
+	_, err = r.NextRaw()
+	assert.EqualError(t, err, "bad length header: 00a1")
+
+	r = NewReader(bytes.NewReader(nil), parser, Framing{Header: HeaderNone})
+	_, err = r.NextRaw()
+	assert.EqualError(t, err, "missing record separator")
+
+	r = NewReader(bytes.NewReader(nil), parser, Framing{Header: 10})
+	_, err = r.NextRaw()
+	assert.EqualError(t, err, "invalid length header type")
+
+	w := NewWriter(&bytes.Buffer{}, Framing{Header: HeaderASCII})
+	err = w.WriteRaw(make([]byte, 10000))
+	assert.EqualError(t, err, "record is too long for length header; len=10000")
+
+	w = NewWriter(&bytes.Buffer{}, Framing{Header: HeaderBinary, MaxSize: 4096})
+	assert.Nil(t, w.WriteRaw(make([]byte, 4096)))
+	err = w.WriteRaw(make([]byte, 4097))
+	assert.True(t, errors.Is(err, iso8583.ErrMessageTooLarge))
+	assert.EqualError(t, err, "message is too large: 4097 bytes, limit is 4096")
+}
+
+func TestWrappedFraming(t *testing.T) {
+	assert.Equal(t, byte(0x03), LRC([]byte{0x01, 0x02}))
+	assert.Equal(t, uint16(0x29b1), CRC16([]byte("123456789")))
+
+	framings := []Framing{
+		{Header: HeaderBinary, STX: true, Trailer: TrailerLRC},
+		{Header: HeaderASCII, Trailer: TrailerCRC16},
+		{Header: HeaderNone, STX: true, Trailer: TrailerCRC16},
+		{Header: HeaderNone, STX: true},
+	}
+	for _, framing := range framings {
+		buf := &bytes.Buffer{}
+		w := NewWriter(buf, framing)
+		assert.Nil(t, w.WriteRaw([]byte("abc")))
+		assert.Nil(t, w.WriteRaw([]byte("de")))
+		assert.Nil(t, w.Flush())
+
+		r := NewReader(bytes.NewReader(buf.Bytes()), nil, framing)
+		raw, err := r.NextRaw()
+		assert.Nil(t, err)
+		assert.Equal(t, "abc", string(raw))
+		raw, err = r.NextRaw()
+		assert.Nil(t, err)
+		assert.Equal(t, "de", string(raw))
+		_, err = r.NextRaw()
+		assert.Equal(t, io.EOF, err)
+	}
+
+	framing := Framing{Header: HeaderBinary, STX: true, Trailer: TrailerLRC}
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf, framing)
+	assert.Nil(t, w.WriteRaw([]byte("abc")))
+	assert.Nil(t, w.Flush())
+	assert.Equal(t, []byte{0x02, 0x00, 0x03, 'a', 'b', 'c', 0x03, 0x03 ^ 'a' ^ 'b' ^ 'c' ^ 0x03}, buf.Bytes())
+
+	bad := buf.Bytes()
+	bad[len(bad)-1] ^= 0xff
+	_, err := NewReader(bytes.NewReader(bad), nil, framing).NextRaw()
+	assert.EqualError(t, err, "bad record check value")
+
+	_, err = NewReader(bytes.NewReader([]byte{0x00, 0x00, 0x03}), nil, framing).NextRaw()
+	assert.EqualError(t, err, "missing STX")
+
+	_, err = NewReader(bytes.NewReader([]byte{0x02, 0x00, 0x01, 'a', 'b'}), nil, framing).NextRaw()
+	assert.EqualError(t, err, "missing ETX")
+
+	_, err = NewReader(bytes.NewReader([]byte{0x02, 0x00, 0x01, 'a', 0x03}), nil, framing).NextRaw()
+	assert.EqualError(t, err, "truncated record")
+
+	_, err = NewReader(bytes.NewReader(nil), nil, Framing{Header: HeaderNone, Separator: []byte("\n"), Trailer: TrailerLRC}).NextRaw()
+	assert.EqualError(t, err, "trailer without length header requires STX")
+
+	_, err = NewReader(bytes.NewReader(nil), nil, Framing{Trailer: 5}).NextRaw()
+	assert.EqualError(t, err, "invalid trailer type")
+}
+
+func TestFramers(t *testing.T) {
+	framers := []Framer{
+		Framing{Header: HeaderBinaryLE},
+		Framing{Header: HeaderBCD},
+		Fixed{Size: 3},
+	}
+	for _, framer := range framers {
+		buf := &bytes.Buffer{}
+		w := NewFramedWriter(buf, framer)
+		assert.Nil(t, w.WriteRaw([]byte("abc")))
+		assert.Nil(t, w.WriteRaw([]byte("def")))
+		assert.Nil(t, w.Flush())
+
+		r := NewFramedReader(bytes.NewReader(buf.Bytes()), nil, framer)
+		raw, err := r.NextRaw()
+		assert.Nil(t, err)
+		assert.Equal(t, "abc", string(raw))
+		raw, err = r.NextRaw()
+		assert.Nil(t, err)
+		assert.Equal(t, "def", string(raw))
+		_, err = r.NextRaw()
+		assert.Equal(t, io.EOF, err)
+	}
+
+	b, err := Framing{Header: HeaderBinaryLE}.Frame(make([]byte, 0x102))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x02, 0x01}, b[:2])
+	b, err = Framing{Header: HeaderBCD}.Frame(make([]byte, 1234))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x12, 0x34}, b[:2])
+	_, err = Framing{Header: HeaderBCD}.Frame(make([]byte, 10000))
+	assert.EqualError(t, err, "record is too long for length header; len=10000")
+	_, err = NewFramedReader(bytes.NewReader([]byte{0x1a, 0x00}), nil, Framing{Header: HeaderBCD}).NextRaw()
+	assert.EqualError(t, err, "bad length header: 1a00")
+
+	_, err = Fixed{Size: 3}.Frame([]byte("ab"))
+	assert.EqualError(t, err, "record size doesn't match; len=2")
+	r := NewFramedReader(bytes.NewReader([]byte("abcd")), nil, Fixed{Size: 3})
+	r.NextRaw()
+	_, err = r.NextRaw()
+	assert.EqualError(t, err, "truncated record")
+}
+
+func TestDecoder(t *testing.T) {
+	parser := &iso8583.Parser{}
+	parser.Register("0220", &testRecord{})
+
+	buf := &bytes.Buffer{}
+	w := NewFramedWriter(buf, Unframed{parser})
+	for _, stan := range []string{"000001", "000002", "000003"} {
+		assert.Nil(t, w.Write(newRecord(stan)))
+	}
+	assert.Nil(t, w.Flush())
+	raw := buf.Bytes()
+
+	// stream is read in small chunks
+	r := NewDecoder(iotest.OneByteReader(bytes.NewReader(raw)), parser)
+	for _, stan := range []string{"000001", "000002", "000003"} {
+		msg, err := r.Next()
+		assert.Nil(t, err)
+		assert.Equal(t, stan, msg.Data.(*testRecord).F11.Value)
+	}
+	_, err := r.Next()
+	assert.Equal(t, io.EOF, err)
+
+	r = NewDecoder(bytes.NewReader(raw[:len(raw)-2]), parser)
+	_, err = r.Next()
+	assert.Nil(t, err)
+	_, err = r.Next()
+	assert.Nil(t, err)
+	_, err = r.Next()
+	assert.EqualError(t, err, "field 11: bad raw data")
+
+	// truncated message is read further, other errors are returned
+	f := Unframed{parser}
+	for _, data := range [][]byte{raw[:2], raw[:20]} {
+		n, record, err := f.Split(data, false)
+		assert.Equal(t, 0, n)
+		assert.Nil(t, record)
+		assert.Nil(t, err)
+	}
+	_, _, err = f.Split(append([]byte("0100"), raw[4:]...), false)
+	assert.EqualError(t, err, "no template registered for MTI: 0100")
+}
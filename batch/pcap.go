@@ -0,0 +1,205 @@
+package batch
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+)
+
+const (
+	ERR_BAD_PCAP          string = "bad pcap data"
+	ERR_PCAP_LINK_TYPE    string = "unsupported pcap link type %d"
+	ERR_PCAP_MISSING_DATA string = "missing TCP segment in stream %s"
+)
+
+// pcap link types
+const (
+	linkTypeEthernet = 1
+	linkTypeRaw      = 101
+	linkTypeLinuxSLL = 113
+)
+
+// TCP flags
+const (
+	tcpFin = 0x01
+	tcpSyn = 0x02
+	tcpRst = 0x04
+)
+
+// pcapStream is reassembled payload of one direction of TCP connection
+type pcapStream struct {
+	next    uint32 // expected sequence number
+	started bool
+	buf     []byte
+}
+
+// ReadPcap reads records from TCP streams of libpcap capture file (for
+// ex. written by tcpdump -w), so captured traffic can be replayed with
+// Pair. Payload of each direction of each connection is reassembled and
+// split to records by framer. Records are in order of capture of their
+// last segment, so responses follow their requests. Ethernet, raw IP and
+// Linux cooked captures of IPv4 and IPv6 are supported, pcapng is not.
+func ReadPcap(r io.Reader, framer Framer) ([][]byte, error) {
+	head := make([]byte, 24)
+	if _, err := io.ReadFull(r, head); err != nil {
+		return nil, errors.New(ERR_BAD_PCAP)
+	}
+	var order binary.ByteOrder
+	switch binary.LittleEndian.Uint32(head) {
+	case 0xa1b2c3d4, 0xa1b23c4d:
+		order = binary.LittleEndian
+	case 0xd4c3b2a1, 0x4d3cb2a1:
+		order = binary.BigEndian
+	default:
+		return nil, errors.New(ERR_BAD_PCAP)
+	}
+	linkType := order.Uint32(head[20:]) & 0xffff
+	switch linkType {
+	case linkTypeEthernet, linkTypeRaw, linkTypeLinuxSLL:
+	default:
+		return nil, fmt.Errorf(ERR_PCAP_LINK_TYPE, linkType)
+	}
+
+	var ret [][]byte
+	streams := make(map[string]*pcapStream)
+	rec := make([]byte, 16)
+	for {
+		if _, err := io.ReadFull(r, rec); err == io.EOF {
+			break
+		} else if err != nil {
+			return ret, errors.New(ERR_BAD_PCAP)
+		}
+		n := order.Uint32(rec[8:])
+		if n > maxRecordLen {
+			return ret, errors.New(ERR_BAD_PCAP)
+		}
+		pkt := make([]byte, n)
+		if _, err := io.ReadFull(r, pkt); err != nil {
+			return ret, errors.New(ERR_BAD_PCAP)
+		}
+
+		key, seq, flags, payload, ok := tcpSegment(pkt, linkType)
+		if !ok {
+			continue
+		}
+		s := streams[key]
+		if s == nil {
+			s = &pcapStream{}
+			streams[key] = s
+		}
+		if flags&tcpSyn != 0 {
+			s.next, s.started, s.buf = seq+1, true, nil
+			continue
+		}
+		if len(payload) > 0 {
+			if !s.started {
+				s.next, s.started = seq, true
+			}
+			// skip retransmitted bytes
+			if d := int32(s.next - seq); d > 0 {
+				if int(d) >= len(payload) {
+					continue
+				}
+				payload = payload[d:]
+				seq = s.next
+			}
+			if seq != s.next {
+				return ret, fmt.Errorf(ERR_PCAP_MISSING_DATA, key)
+			}
+			s.next += uint32(len(payload))
+			s.buf = append(s.buf, payload...)
+		}
+		atEOF := flags&(tcpFin|tcpRst) != 0
+		for len(s.buf) > 0 {
+			adv, record, err := framer.Split(s.buf, atEOF)
+			if err != nil {
+				return ret, fmt.Errorf("stream %s: %s", key, err)
+			}
+			if adv == 0 {
+				break
+			}
+			if record != nil {
+				ret = append(ret, append([]byte(nil), record...))
+			}
+			s.buf = s.buf[adv:]
+		}
+		if atEOF {
+			delete(streams, key)
+		}
+	}
+
+	for key, s := range streams {
+		if len(s.buf) > 0 {
+			return ret, fmt.Errorf("stream %s: %s", key, ERR_TRUNCATED_RECORD)
+		}
+	}
+	return ret, nil
+}
+
+// tcpSegment returns stream key ("src -> dst"), sequence number, flags and
+// payload of TCP segment in packet of link type
+func tcpSegment(pkt []byte, linkType uint32) (key string, seq uint32, flags byte, payload []byte, ok bool) {
+	var etherType uint16
+	switch linkType {
+	case linkTypeEthernet:
+		if len(pkt) < 14 {
+			return
+		}
+		etherType, pkt = binary.BigEndian.Uint16(pkt[12:]), pkt[14:]
+		for etherType == 0x8100 && len(pkt) >= 4 {
+			etherType, pkt = binary.BigEndian.Uint16(pkt[2:]), pkt[4:]
+		}
+	case linkTypeLinuxSLL:
+		if len(pkt) < 16 {
+			return
+		}
+		etherType, pkt = binary.BigEndian.Uint16(pkt[14:]), pkt[16:]
+	case linkTypeRaw:
+		if len(pkt) < 1 {
+			return
+		}
+		switch pkt[0] >> 4 {
+		case 4:
+			etherType = 0x0800
+		case 6:
+			etherType = 0x86dd
+		}
+	}
+
+	var src, dst net.IP
+	switch etherType {
+	case 0x0800:
+		if len(pkt) < 20 || pkt[9] != 6 {
+			return
+		}
+		ihl, total := int(pkt[0]&0x0f)*4, int(binary.BigEndian.Uint16(pkt[2:]))
+		if ihl < 20 || total < ihl || total > len(pkt) {
+			return
+		}
+		src, dst, pkt = net.IP(pkt[12:16]), net.IP(pkt[16:20]), pkt[ihl:total]
+	case 0x86dd:
+		if len(pkt) < 40 || pkt[6] != 6 {
+			return
+		}
+		total := 40 + int(binary.BigEndian.Uint16(pkt[4:]))
+		if total > len(pkt) {
+			return
+		}
+		src, dst, pkt = net.IP(pkt[8:24]), net.IP(pkt[24:40]), pkt[40:total]
+	default:
+		return
+	}
+
+	if len(pkt) < 20 {
+		return
+	}
+	off := int(pkt[12]>>4) * 4
+	if off < 20 || off > len(pkt) {
+		return
+	}
+	srcPort, dstPort := binary.BigEndian.Uint16(pkt), binary.BigEndian.Uint16(pkt[2:])
+	key = fmt.Sprintf("%s -> %s", net.JoinHostPort(src.String(), fmt.Sprint(srcPort)), net.JoinHostPort(dst.String(), fmt.Sprint(dstPort)))
+	return key, binary.BigEndian.Uint32(pkt[4:]), pkt[13], pkt[off:], true
+}
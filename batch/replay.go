@@ -0,0 +1,75 @@
+package batch
+
+import (
+	"io"
+
+	"github.com/ideazxy/iso8583"
+)
+
+// Exchange is a request paired with its response. Request or Response is
+// nil if its counterpart is not found. Repeats are repeated requests (for
+// ex. 0201) sent before the response.
+type Exchange struct {
+	Request  *iso8583.Message
+	Response *iso8583.Message
+	Repeats  []*iso8583.Message
+}
+
+// ReadAll reads and parses all messages of a capture or dump until EOF
+func (r *Reader) ReadAll() ([]*iso8583.Message, error) {
+	var ret []*iso8583.Message
+	for {
+		msg, err := r.Next()
+		if err == io.EOF {
+			return ret, nil
+		}
+		if err != nil {
+			return ret, err
+		}
+		ret = append(ret, msg)
+	}
+}
+
+// pairKey identifies request and its response
+type pairKey struct {
+	mti string // MTI of request
+	key iso8583.TransactionKey
+}
+
+// Pair pairs requests with responses in order of messages: response (MTI
+// with odd message function digit, for ex. 0210) matches the earliest
+// unanswered request with MTI of request (0200) and the same STAN, date
+// and terminal. Repeat (for ex. 0201) of unanswered request is added to its
+// exchange, other repeats are requests of their own. Exchanges are in order
+// of requests, unmatched responses are in place of their own.
+func Pair(msgs []*iso8583.Message) []*Exchange {
+	var ret []*Exchange
+	pending := make(map[pairKey][]*Exchange)
+	for _, msg := range msgs {
+		key, err := iso8583.KeyOf(msg)
+		if err != nil || len(msg.Mti) != 4 {
+			ret = append(ret, &Exchange{Request: msg})
+			continue
+		}
+		mti := iso8583.MtiOriginal(msg.Mti)
+		if f := mti[2]; f >= '0' && f <= '9' && (f-'0')%2 == 1 {
+			k := pairKey{mti[:2] + string(f-1) + mti[3:], key}
+			if q := pending[k]; len(q) > 0 {
+				q[0].Response = msg
+				pending[k] = q[1:]
+				continue
+			}
+			ret = append(ret, &Exchange{Response: msg})
+			continue
+		}
+		k := pairKey{mti, key}
+		if q := pending[k]; mti != msg.Mti && len(q) > 0 {
+			q[len(q)-1].Repeats = append(q[len(q)-1].Repeats, msg)
+			continue
+		}
+		e := &Exchange{Request: msg}
+		pending[k] = append(pending[k], e)
+		ret = append(ret, e)
+	}
+	return ret
+}
@@ -0,0 +1,142 @@
+package batch
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/ideazxy/iso8583"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestReplay(t *testing.T) {
+	parser := &iso8583.Parser{}
+	for _, mti := range []string{"0200", "0201", "0210", "0800", "0810"} {
+		parser.Register(mti, &testRecord{})
+	}
+	newMsg := func(mti, stan string) *iso8583.Message {
+		return iso8583.NewMessage(mti, &testRecord{F11: iso8583.NewNumeric(stan)})
+	}
+
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf, Framing{Header: HeaderBinary})
+	for _, msg := range []*iso8583.Message{
+		newMsg("0200", "000001"),
+		newMsg("0200", "000002"),
+		newMsg("0800", "000001"),
+		newMsg("0201", "000002"),
+		newMsg("0210", "000002"),
+		newMsg("0810", "000001"),
+		newMsg("0210", "000009"),
+	} {
+		assert.Nil(t, w.Write(msg))
+	}
+	assert.Nil(t, w.Flush())
+
+	msgs, err := NewReader(buf, parser, Framing{Header: HeaderBinary}).ReadAll()
+	assert.Nil(t, err)
+	assert.Equal(t, 7, len(msgs))
+
+	exchanges := Pair(msgs)
+	assert.Equal(t, 4, len(exchanges))
+	assert.Equal(t, msgs[0], exchanges[0].Request)
+	assert.Nil(t, exchanges[0].Response)
+	assert.Equal(t, msgs[1], exchanges[1].Request)
+	assert.Equal(t, []*iso8583.Message{msgs[3]}, exchanges[1].Repeats)
+	assert.Equal(t, msgs[4], exchanges[1].Response)
+	assert.Equal(t, msgs[2], exchanges[2].Request)
+	assert.Equal(t, msgs[5], exchanges[2].Response)
+	assert.Nil(t, exchanges[3].Request)
+	assert.Equal(t, msgs[6], exchanges[3].Response)
+
+	_, err = NewReader(bytes.NewReader([]byte{0, 9, 1}), parser, Framing{Header: HeaderBinary}).ReadAll()
+	assert.EqualError(t, err, "truncated record")
+}
+
+// pcapWriter writes libpcap capture of Ethernet frames with IPv4 TCP
+// segments
+type pcapWriter struct {
+	bytes.Buffer
+}
+
+func newPcapWriter() *pcapWriter {
+	w := &pcapWriter{}
+	head := make([]byte, 24)
+	binary.LittleEndian.PutUint32(head, 0xa1b2c3d4)
+	binary.LittleEndian.PutUint16(head[4:], 2)
+	binary.LittleEndian.PutUint16(head[6:], 4)
+	binary.LittleEndian.PutUint32(head[16:], 65535)
+	binary.LittleEndian.PutUint32(head[20:], linkTypeEthernet)
+	w.Write(head)
+	return w
+}
+
+func (w *pcapWriter) segment(srcPort, dstPort uint16, seq uint32, flags byte, payload []byte) {
+	tcp := make([]byte, 20)
+	binary.BigEndian.PutUint16(tcp, srcPort)
+	binary.BigEndian.PutUint16(tcp[2:], dstPort)
+	binary.BigEndian.PutUint32(tcp[4:], seq)
+	tcp[12], tcp[13] = 5<<4, flags
+	ip := []byte{0x45, 0, 0, 0, 0, 0, 0, 0, 64, 6, 0, 0, 10, 0, 0, 1, 10, 0, 0, 2}
+	binary.BigEndian.PutUint16(ip[2:], uint16(20+len(tcp)+len(payload)))
+	eth := make([]byte, 14)
+	binary.BigEndian.PutUint16(eth[12:], 0x0800)
+	pkt := append(append(append(eth, ip...), tcp...), payload...)
+
+	rec := make([]byte, 16)
+	binary.LittleEndian.PutUint32(rec[8:], uint32(len(pkt)))
+	binary.LittleEndian.PutUint32(rec[12:], uint32(len(pkt)))
+	w.Write(rec)
+	w.Write(pkt)
+}
+
+func TestReadPcap(t *testing.T) {
+	parser := &iso8583.Parser{}
+	for _, mti := range []string{"0800", "0810"} {
+		parser.Register(mti, &testRecord{})
+	}
+	framing := Framing{Header: HeaderBinary}
+	frame := func(mti string) []byte {
+		raw, err := iso8583.NewMessage(mti, &testRecord{F11: iso8583.NewNumeric("000001")}).Bytes()
+		assert.Nil(t, err)
+		ret, err := framing.Frame(raw)
+		assert.Nil(t, err)
+		return ret
+	}
+	req, resp := frame("0800"), frame("0810")
+
+	w := newPcapWriter()
+	w.segment(40000, 5000, 99, tcpSyn, nil)
+	w.segment(40000, 5000, 100, 0, req[:5])
+	w.segment(40000, 5000, 100, 0, req[:5]) // retransmission
+	w.segment(40000, 5000, 105, 0, req[5:])
+	w.segment(5000, 40000, 7000, 0, resp)
+	w.segment(40000, 5000, uint32(100+len(req)), tcpFin, nil)
+
+	records, err := ReadPcap(bytes.NewReader(w.Bytes()), framing)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(records))
+	var msgs []*iso8583.Message
+	for _, raw := range records {
+		msg, err := parser.Parse(raw)
+		assert.Nil(t, err)
+		msgs = append(msgs, msg)
+	}
+	exchanges := Pair(msgs)
+	assert.Equal(t, 1, len(exchanges))
+	assert.Equal(t, "0800", exchanges[0].Request.Mti)
+	assert.Equal(t, "0810", exchanges[0].Response.Mti)
+
+	w = newPcapWriter()
+	w.segment(40000, 5000, 100, 0, req[:5])
+	w.segment(40000, 5000, 110, 0, req[10:])
+	_, err = ReadPcap(bytes.NewReader(w.Bytes()), framing)
+	assert.EqualError(t, err, "missing TCP segment in stream 10.0.0.1:40000 -> 10.0.0.2:5000")
+
+	w = newPcapWriter()
+	w.segment(40000, 5000, 100, 0, req[:5])
+	_, err = ReadPcap(bytes.NewReader(w.Bytes()), framing)
+	assert.EqualError(t, err, "stream 10.0.0.1:40000 -> 10.0.0.2:5000: truncated record")
+
+	_, err = ReadPcap(bytes.NewReader([]byte("not a capture file")), framing)
+	assert.EqualError(t, err, "bad pcap data")
+}
@@ -0,0 +1,136 @@
+package batch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ideazxy/iso8583"
+)
+
+// errTruncated is returned by readRecord when stream ends in a record
+var errTruncated = errors.New(ERR_TRUNCATED_RECORD)
+
+// WriteMessage packs msg and writes it to w as one record, for ex. to
+// net.Conn. It returns number of bytes written.
+func (f Framing) WriteMessage(w io.Writer, msg *iso8583.Message) (int64, error) {
+	b, err := msg.Bytes()
+	if err != nil {
+		return 0, err
+	}
+	frame, err := f.Frame(b)
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(frame)
+	return int64(n), err
+}
+
+// ReadMessage reads one record from r and loads it into msg, which Data is
+// used as a template. It returns number of bytes read. Unlike Reader it
+// doesn't read beyond the record, so r can be net.Conn. Length header is
+// read first and the record is allocated for its length, records without
+// length header are read byte by byte up to ETX or separator. It returns
+// io.EOF if r ends before the record.
+func (f Framing) ReadMessage(r io.Reader, msg *iso8583.Message) (int64, error) {
+	rec, n, err := f.readRecord(r)
+	if err != nil {
+		return n, err
+	}
+	return n, msg.Load(rec)
+}
+
+// readRecord reads one record from r, it returns the record and number of
+// bytes read
+func (f Framing) readRecord(r io.Reader) ([]byte, int64, error) {
+	if err := f.check(); err != nil {
+		return nil, 0, err
+	}
+	var frame []byte
+	read := func(n int) error {
+		start := len(frame)
+		frame = append(frame, make([]byte, n)...)
+		got, err := io.ReadFull(r, frame[start:])
+		frame = frame[:start+got]
+		if err == io.ErrUnexpectedEOF || (err == io.EOF && start > 0) {
+			return errTruncated
+		}
+		return err
+	}
+	// readTo reads bytes until frame ends with delim
+	readTo := func(delim []byte) error {
+		for len(frame) < len(delim) || !bytes.HasSuffix(frame, delim) {
+			if len(frame) > maxRecordLen {
+				return fmt.Errorf(ERR_RECORD_TOO_LONG, len(frame))
+			}
+			if err := read(1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if !f.STX && f.Header == HeaderNone {
+		err := readTo(f.Separator)
+		if err == errTruncated {
+			// last record of stream may miss separator
+			return frame, int64(len(frame)), nil
+		}
+		if err != nil {
+			return nil, int64(len(frame)), err
+		}
+		return frame[:len(frame)-len(f.Separator)], int64(len(frame)), nil
+	}
+
+	start := 0
+	if f.STX {
+		if err := read(1); err != nil {
+			return nil, int64(len(frame)), err
+		}
+		if frame[0] != stx {
+			return nil, int64(len(frame)), errors.New(ERR_MISSING_STX)
+		}
+		start = 1
+	}
+	var recStart, recEnd int
+	switch f.Header {
+	case HeaderNone:
+		if err := readTo([]byte{etx}); err != nil {
+			return nil, int64(len(frame)), err
+		}
+		recStart, recEnd = start, len(frame)-1
+	default:
+		if err := read(f.headLen()); err != nil {
+			return nil, int64(len(frame)), err
+		}
+		l, err := f.decodeHead(frame[start:])
+		if err != nil {
+			return nil, int64(len(frame)), err
+		}
+		if l > maxRecordLen {
+			return nil, int64(len(frame)), fmt.Errorf(ERR_RECORD_TOO_LONG, l)
+		}
+		recStart = len(frame)
+		if err := read(l); err != nil {
+			return nil, int64(len(frame)), err
+		}
+		recEnd = len(frame)
+		if f.STX {
+			if err := read(1); err != nil {
+				return nil, int64(len(frame)), err
+			}
+			if frame[recEnd] != etx {
+				return nil, int64(len(frame)), errors.New(ERR_MISSING_ETX)
+			}
+		}
+	}
+	end := len(frame)
+	if err := read(f.trailerLen()); err != nil {
+		return nil, int64(len(frame)), err
+	}
+	if !bytes.Equal(f.trailer(frame[start:end]), frame[end:]) {
+		return nil, int64(len(frame)), errors.New(ERR_BAD_CHECK_VALUE)
+	}
+	return frame[recStart:recEnd], int64(len(frame)), nil
+}
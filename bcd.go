@@ -1,44 +1,97 @@
 package iso8583
 
 import (
-	"encoding/hex"
+	bcdenc "github.com/ideazxy/iso8583/encoding/bcd"
 )
 
+// BCD helpers of fields are wrappers of package encoding/bcd which panic on
+// error, callers recover
+
 func lbcd(data []byte) []byte {
-	if len(data)%2 != 0 {
-		return bcd(append(data, "0"...))
-	}
-	return bcd(data)
+	return mustBCD(bcdenc.EncodeLeft(data))
 }
 
 func rbcd(data []byte) []byte {
-	if len(data)%2 != 0 {
-		return bcd(append([]byte("0"), data...))
-	}
-	return bcd(data)
+	return mustBCD(bcdenc.EncodeRight(data))
 }
 
 // Encode numeric in ascii into bsd (be sure len(data) % 2 == 0)
 func bcd(data []byte) []byte {
-	out := make([]byte, len(data)/2+1)
-	n, err := hex.Decode(out, data)
-	if err != nil {
-		panic(err.Error())
-	}
-	return out[:n]
+	return mustBCD(bcdenc.Encode(data))
 }
 
 func bcdl2Ascii(data []byte, length int) []byte {
-	return bcd2Ascii(data)[:length]
+	return mustBCD(bcdenc.DecodeLeft(data, length))
 }
 
 func bcdr2Ascii(data []byte, length int) []byte {
-	out := bcd2Ascii(data)
-	return out[len(out)-length:]
+	return mustBCD(bcdenc.DecodeRight(data, length))
 }
 
 func bcd2Ascii(data []byte) []byte {
-	out := make([]byte, len(data)*2)
-	n := hex.Encode(out, data)
-	return out[:n]
+	return bcdenc.Decode(data)
+}
+
+func mustBCD(out []byte, err error) []byte {
+	if err != nil {
+		panic(err.Error())
+	}
+	return out
+}
+
+func parseFillerStr(str string) byte {
+	switch str {
+	case "", "0":
+		return 0
+	case "f", "F":
+		return 0xf
+	}
+	panic("unknown filler: " + str)
+}
+
+// fillNibble sets filler nibble of odd length BCD value encoded by Numeric,
+// Llnumeric or Lllnumeric field: the last nibble for bcd and the first one
+// for rbcd. Value is the tail of d.
+func fillNibble(d []byte, f *fieldInfo) []byte {
+	var n int
+	switch v := f.Field.(type) {
+	case *Numeric:
+		n = f.Length
+	case *Llnumeric:
+		n = len(v.Value)
+	case *Lllnumeric:
+		n = len(v.Value)
+	default:
+		return d
+	}
+	if n%2 == 0 || len(d) < (n+1)/2 {
+		return d
+	}
+	body := d[len(d)-(n+1)/2:]
+	switch f.Encode {
+	case BCD:
+		body[len(body)-1] = body[len(body)-1]&0xf0 | f.Filler
+	case rBCD:
+		body[0] = body[0]&0x0f | f.Filler<<4
+	}
+	return d
+}
+
+// trimFillerNibble removes filler nibble "f" of value decoded from whole
+// bytes: the last digit for bcd and the first one for rbcd
+func trimFillerNibble(value string, encode int) string {
+	if value == "" {
+		return value
+	}
+	switch encode {
+	case BCD:
+		if c := value[len(value)-1]; c == 'f' || c == 'F' {
+			return value[:len(value)-1]
+		}
+	case rBCD:
+		if c := value[0]; c == 'f' || c == 'F' {
+			return value[1:]
+		}
+	}
+	return value
 }
@@ -40,3 +40,37 @@ func TestBCDEncode(t *testing.T) {
 
 	assert.Equal(t, []byte("12345"), bcdr2Ascii([]byte("\x01\x23\x45"), 5))
 }
+
+func TestBCDFiller(t *testing.T) {
+	type test struct {
+		F2  *Llnumeric  `field:"2" length:"19" encode:"bcd,bcd" filler:"f"`
+		F14 *Numeric    `field:"14" length:"3" encode:"rbcd" filler:"F"`
+		F35 *Lllnumeric `field:"35" length:"37" encode:"ascii,rbcd" filler:"f"`
+		F49 *Numeric    `field:"49" length:"3" encode:"rbcd" filler:"0"`
+	}
+
+	data := &test{
+		F2:  NewLlnumeric("427655555555555"),
+		F14: NewNumeric("643"),
+		F35: NewLllnumeric("123"),
+		F49: NewNumeric("643"),
+	}
+
+	iso := NewMessage("0100", data)
+	res, err := iso.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "15427655555555555f"+"f643"+"303033f123"+"0643", fmt.Sprintf("%x", res[12:]))
+
+	iso2 := NewMessage("", &test{&Llnumeric{}, &Numeric{}, &Lllnumeric{}, &Numeric{}})
+	err = iso2.Load(res)
+	assert.Nil(t, err)
+	assert.Equal(t, data, iso2.Data)
+
+	type test2 struct {
+		F2 *Llnumeric `field:"2" length:"19" encode:"bcd,bcd" filler:"x"`
+	}
+
+	_, err = NewMessage("0100", &test2{NewLlnumeric("1")}).Bytes()
+	assert.ErrorIs(t, err, ErrInvalidTag)
+	assert.EqualError(t, err, "field 2: invalid tag: unknown filler: x")
+}
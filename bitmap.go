@@ -0,0 +1,109 @@
+package iso8583
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+const ERR_INVALID_BITMAP_ENCODE string = "invalid bitmap encoding"
+
+// ErrInvalidBitmapEncode is returned for unknown BitmapEncode
+var ErrInvalidBitmapEncode = errors.New(ERR_INVALID_BITMAP_ENCODE)
+
+// Encodings of bitmaps, set by BitmapEncode of Message or Parser
+const (
+	BITMAP_BINARY string = "binary" // 8 bytes per bitmap (default)
+	BITMAP_HEX    string = "hex"    // 16 ascii hex digits per bitmap
+	BITMAP_BITS   string = "bits"   // 64 ascii '0' and '1' characters per bitmap, sent by some legacy POS terminals
+)
+
+// bitmapUnit returns wire length of 8 byte bitmap encoded with enc. It
+// panics if enc is unknown.
+func bitmapUnit(enc string) int {
+	switch enc {
+	case "", BITMAP_BINARY:
+		return 8
+	case BITMAP_HEX:
+		return 16
+	case BITMAP_BITS:
+		return 64
+	}
+	panic("unknown bitmap encoding: " + enc)
+}
+
+// checkBitmapEncode returns ErrInvalidBitmapEncode if enc is unknown
+func checkBitmapEncode(enc string) error {
+	switch enc {
+	case "", BITMAP_BINARY, BITMAP_HEX, BITMAP_BITS:
+		return nil
+	}
+	return ErrInvalidBitmapEncode
+}
+
+// encodeBitmap encodes bitmap with enc
+func encodeBitmap(bitmap []byte, enc string) []byte {
+	switch enc {
+	case BITMAP_HEX:
+		return []byte(strings.ToUpper(hex.EncodeToString(bitmap)))
+	case BITMAP_BITS:
+		ret := make([]byte, 0, len(bitmap)*8)
+		for _, b := range bitmap {
+			for step := 7; step >= 0; step-- {
+				ret = append(ret, '0'+b>>uint(step)&1)
+			}
+		}
+		return ret
+	}
+	bitmapUnit(enc)
+	return bitmap
+}
+
+// decodeBitmap decodes primary bitmap, and secondary one if it is marked,
+// at the start of raw. It returns the bitmap and its wire length.
+func decodeBitmap(raw []byte, enc string) ([]byte, int, error) {
+	if err := checkBitmapEncode(enc); err != nil {
+		return nil, 0, err
+	}
+	unit := bitmapUnit(enc)
+	n := unit
+	if len(raw) >= unit {
+		first, err := decodeBitmapUnit(raw[:unit], enc)
+		if err != nil {
+			return nil, 0, err
+		}
+		if first[0]&0x80 == 0x80 {
+			n = 2 * unit
+		}
+	}
+	if len(raw) < n {
+		return nil, 0, ErrBadRaw
+	}
+	bitmap, err := decodeBitmapUnit(raw[:n], enc)
+	if err != nil {
+		return nil, 0, err
+	}
+	return bitmap, n, nil
+}
+
+// decodeBitmapUnit decodes bitmaps encoded with enc in raw
+func decodeBitmapUnit(raw []byte, enc string) ([]byte, error) {
+	switch enc {
+	case BITMAP_HEX:
+		ret, err := hex.DecodeString(string(raw))
+		if err != nil {
+			return nil, ErrBadRaw
+		}
+		return ret, nil
+	case BITMAP_BITS:
+		ret := make([]byte, len(raw)/8)
+		for i, c := range raw {
+			if c != '0' && c != '1' {
+				return nil, ErrBadRaw
+			}
+			ret[i/8] |= (c - '0') << uint(7-i%8)
+		}
+		return ret, nil
+	}
+	return raw, nil
+}
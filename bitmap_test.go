@@ -0,0 +1,62 @@
+package iso8583
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestBitmapEncode(t *testing.T) {
+	type test struct {
+		F2  *Llnumeric `field:"2" length:"19"`
+		F4  *Numeric   `field:"4" length:"12"`
+		F70 *Numeric   `field:"70" length:"3"`
+	}
+	fields := "164276555555555558" + "000000000100"
+
+	msg := NewMessage("0200", &test{NewLlnumeric("4276555555555558"), NewNumeric("100"), nil})
+	msg.BitmapEncode = BITMAP_HEX
+	res, err := msg.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "0200"+"5000000000000000"+fields, string(res))
+
+	msg.BitmapEncode = BITMAP_BITS
+	res, err = msg.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "0200"+"0101"+strings.Repeat("0", 60)+fields, string(res))
+
+	p := &Parser{BitmapEncode: BITMAP_BITS}
+	p.Register("0200", &test{})
+	parsed, err := p.Parse(res)
+	assert.Nil(t, err)
+	assert.Equal(t, "000000000100", parsed.Data.(*test).F4.Value)
+
+	// secondary bitmap
+	msg.Data.(*test).F70 = NewNumeric("301")
+	res, err = msg.Bytes()
+	assert.Nil(t, err)
+	bits := "1101" + strings.Repeat("0", 60) + "00000" + "1" + strings.Repeat("0", 58)
+	assert.Equal(t, "0200"+bits+fields+"301", string(res))
+	parsed, err = p.Parse(res)
+	assert.Nil(t, err)
+	assert.True(t, parsed.SecondBitmap)
+	assert.Equal(t, "301", parsed.Data.(*test).F70.Value)
+	n, err := p.MessageLen(append(res, "0200"...))
+	assert.Nil(t, err)
+	assert.Equal(t, len(res), n)
+
+	_, err = p.Parse([]byte("0200" + "0102" + strings.Repeat("0", 60)))
+	assert.Equal(t, ErrBadRaw, err)
+	_, err = p.Parse([]byte("0200" + "1101"))
+	assert.Equal(t, ErrBadRaw, err)
+	p.BitmapEncode = BITMAP_HEX
+	_, err = p.Parse([]byte("0200" + "500000000000000G" + fields))
+	assert.Equal(t, ErrBadRaw, err)
+
+	msg.BitmapEncode = "octal"
+	_, err = msg.Bytes()
+	assert.Equal(t, ErrInvalidBitmapEncode, err)
+	p.BitmapEncode = "octal"
+	_, err = p.Parse([]byte("0200" + "1101"))
+	assert.Equal(t, ErrInvalidBitmapEncode, err)
+}
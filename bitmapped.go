@@ -0,0 +1,29 @@
+package iso8583
+
+import (
+	"reflect"
+)
+
+// PackBitmapped marshall data like Message without MTI: primary bitmap
+// followed by present fields. It is used by fields with their own bitmap
+// (for ex. Postilion field 127).
+func PackBitmapped(data interface{}) ([]byte, error) {
+	m := &Message{Mti: "0000", MtiEncode: ASCII, DisableSecondBitmap: true, Data: data}
+	ret, err := m.pack()
+	if err != nil {
+		return nil, err
+	}
+	return ret[mtiLen(ASCII):], nil
+}
+
+// LoadBitmapped unmarshall data packed by PackBitmapped. Data must be a
+// pointer to struct, its nil fields are allocated.
+func LoadBitmapped(raw []byte, data interface{}) error {
+	v := reflect.ValueOf(data)
+	if !isStructPtr(v) {
+		return ErrNotStructPointer
+	}
+	initStruct(v.Type().Elem(), v)
+	_, err := (&Message{Data: data}).loadFields(raw)
+	return err
+}
@@ -0,0 +1,75 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+const (
+	ERR_NOT_CANONICAL string = "message is not canonical"
+)
+
+var ErrNotCanonical = errors.New(ERR_NOT_CANONICAL)
+
+// VerifyCanonical checks that raw is exactly what Bytes emits for the
+// message parsed from raw by p. Bytes guarantees that fields are emitted in
+// ascending order of their numbers, bitmap has bits of emitted fields only
+// and secondary bitmap is emitted if any of fields 65-128 is present or
+// SecondBitmap is set (Load sets it if raw has secondary bitmap). Returned
+// error matches ErrNotCanonical and names the first non-canonical part:
+// MTI or bitmap, field or trailing bytes.
+func VerifyCanonical(raw []byte, p *Parser) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("Critical error:" + fmt.Sprint(r))
+		}
+	}()
+
+	mti, err := decodeMti(raw, p.Quirks.mtiEncode(p.MtiEncode), p.CodePage)
+	if err != nil {
+		return err
+	}
+	msg, err := p.newMessage(mti)
+	if err != nil {
+		return err
+	}
+	msg.CaptureRaw = true
+	if err := msg.Load(raw); err != nil {
+		return err
+	}
+	res, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+
+	d := 0
+	for d < len(raw) && d < len(res) && raw[d] == res[d] {
+		d++
+	}
+	if d == len(raw) && d == len(res) {
+		return nil
+	}
+
+	// locate first difference
+	unit := bitmapUnit(msg.BitmapEncode)
+	offset := msg.mtiLen() + unit
+	if msg.SecondBitmap {
+		offset += unit
+	}
+	if d < offset {
+		return fmt.Errorf("%w: MTI or bitmap", ErrNotCanonical)
+	}
+	indexes := make([]int, 0, len(msg.raw))
+	for i := range msg.raw {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	for _, i := range indexes {
+		offset += len(msg.raw[i])
+		if d < offset {
+			return fmt.Errorf("%w: field %d", ErrNotCanonical, i)
+		}
+	}
+	return fmt.Errorf("%w: %d trailing bytes", ErrNotCanonical, len(raw)-offset)
+}
@@ -0,0 +1,53 @@
+package iso8583
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestVerifyCanonical(t *testing.T) {
+	// struct order doesn't matter, fields are emitted in ascending order
+	type test struct {
+		F41 *Alphanumeric `field:"41" length:"8"`
+		F2  *Llvar        `field:"2" length:"19"`
+		F11 *Numeric      `field:"11" length:"5" encode:"bcd"`
+	}
+	iso := NewMessage("0200", &test{NewAlphanumeric("TERM0001"), NewLlvar([]byte("4276")), NewNumeric("00001")})
+	raw, err := iso.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "0200"+"\x40\x20\x00\x00\x00\x80\x00\x00"+"044276"+"\x00\x00\x10"+"TERM0001", string(raw))
+
+	p := &Parser{}
+	p.Register("0200", &test{})
+	assert.Nil(t, VerifyCanonical(raw, p))
+
+	// secondary bitmap without fields 65-128 is emitted as set
+	iso.SecondBitmap = true
+	raw2, err := iso.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "\xc0\x20\x00\x00\x00\x80\x00\x00"+"\x00\x00\x00\x00\x00\x00\x00\x00", string(raw2[4:20]))
+	assert.Nil(t, VerifyCanonical(raw2, p))
+
+	bad := append([]byte(nil), raw...)
+	bad[20] = 0x1f // filler nibble is not emitted by Bytes
+	err = VerifyCanonical(bad, p)
+	assert.True(t, errors.Is(err, ErrNotCanonical))
+	assert.EqualError(t, err, "message is not canonical: field 11")
+
+	err = VerifyCanonical(append(raw, "xx"...), p)
+	assert.EqualError(t, err, "message is not canonical: 2 trailing bytes")
+
+	// fields are located after hex bitmap
+	iso = NewMessage("0200", &test{NewAlphanumeric("TERM0001"), NewLlvar([]byte("4276")), NewNumeric("00001")})
+	iso.BitmapEncode = BITMAP_HEX
+	raw, err = iso.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "0200"+"4020000000800000"+"044276", string(raw[:26]))
+	p.BitmapEncode = BITMAP_HEX
+	assert.Nil(t, VerifyCanonical(raw, p))
+
+	bad = append([]byte(nil), raw...)
+	bad[28] = 0x1f
+	assert.EqualError(t, VerifyCanonical(bad, p), "message is not canonical: field 11")
+}
@@ -0,0 +1,71 @@
+package iso8583
+
+import (
+	"fmt"
+	"sort"
+)
+
+// CatchAllField describes how to decode fields from First to Last which
+// are present in bitmap but not defined in Data, for ex. private use
+// fields 61-63 or 120-127 sent by chatty hosts
+type CatchAllField struct {
+	First int
+	Last  int
+	// Encode and LenEncode are encoders of the field, default is ascii
+	Encode    int
+	LenEncode int
+	// Length is maximal length, default is 999
+	Length int
+	// New creates empty field, default is Lllvar
+	New func() Iso8583Type
+}
+
+// catchAllInfo returns fieldInfo for field i according to the first
+// matching CatchAll, or false if there is none
+func (m *Message) catchAllInfo(i int, field Iso8583Type) (*fieldInfo, bool) {
+	for _, c := range m.CatchAll {
+		if i < c.First || i > c.Last {
+			continue
+		}
+		if field == nil {
+			if c.New != nil {
+				field = c.New()
+			} else {
+				field = &Lllvar{}
+			}
+		}
+		length := c.Length
+		if length == 0 {
+			length = 999
+		}
+		return &fieldInfo{
+			Index:     i,
+			Encode:    c.Encode,
+			LenEncode: c.LenEncode,
+			Length:    length,
+			Field:     field,
+		}, true
+	}
+	return nil, false
+}
+
+// addExtraFields adds fields of Extra which Data doesn't define to fields
+func (m *Message) addExtraFields(fields map[int]*fieldInfo) error {
+	indexes := make([]int, 0, len(m.Extra))
+	for i := range m.Extra {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	for _, i := range indexes {
+		f := m.Extra[i]
+		if _, ok := fields[i]; ok || f == nil {
+			continue
+		}
+		info, ok := m.catchAllInfo(i, f)
+		if !ok {
+			return fmt.Errorf("field %d not defined", i)
+		}
+		fields[i] = info
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package iso8583
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCatchAll(t *testing.T) {
+	type full struct {
+		F11 *Numeric `field:"11" length:"6"`
+		F62 *Lllvar  `field:"62" length:"999"`
+		F63 *Llvar   `field:"63" length:"99" encode:"bcd,ascii"`
+	}
+	type known struct {
+		F11 *Numeric `field:"11" length:"6"`
+	}
+	raw, err := NewMessage("0200", &full{
+		NewNumeric("000001"),
+		NewLllvar([]byte("private")),
+		NewLlvar([]byte("data")),
+	}).Bytes()
+	assert.Nil(t, err)
+
+	p := &Parser{}
+	p.Register("0200", &known{})
+	_, err = p.Parse(raw)
+	assert.EqualError(t, err, "field 62 not defined")
+
+	p.CatchAll = []CatchAllField{
+		{First: 61, Last: 62},
+		{First: 63, Last: 63, LenEncode: BCD, Length: 99, New: func() Iso8583Type { return &Llvar{} }},
+	}
+	msg, err := p.Parse(raw)
+	assert.Nil(t, err)
+	assert.Equal(t, map[int]Iso8583Type{
+		62: NewLllvar([]byte("private")),
+		63: NewLlvar([]byte("data")),
+	}, msg.Extra)
+
+	// unknown data is preserved
+	res, err := msg.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, raw, res)
+
+	msg.CatchAll = nil
+	_, err = msg.Bytes()
+	assert.EqualError(t, err, "field 62 not defined")
+}
@@ -0,0 +1,103 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ISO 8583 character classes of field values, set by charset tag (for ex.
+// `charset:"ans"`) or Message.Charsets. Values are checked on packing and
+// loading. Case of letters is restricted with upper or lower option, for
+// ex. `charset:"an,upper"`.
+const (
+	CHARSET_A   string = "a"   // letters
+	CHARSET_N   string = "n"   // digits
+	CHARSET_S   string = "s"   // printable special characters, including space
+	CHARSET_AN  string = "an"  // letters and digits
+	CHARSET_AS  string = "as"  // letters and special characters
+	CHARSET_NS  string = "ns"  // digits and special characters
+	CHARSET_ANS string = "ans" // printable characters
+	CHARSET_B   string = "b"   // binary data, not checked
+	CHARSET_Z   string = "z"   // track data: digits, separators '=' and 'D', sentinels ';' and '?' and filler 'F'
+
+	CHARSET_UPPER string = "upper" // only upper case letters
+	CHARSET_LOWER string = "lower" // only lower case letters
+)
+
+const TAG_CHARSET string = "charset"
+
+const (
+	ERR_BAD_CHARSET string = "value has characters outside of charset"
+)
+
+var ErrBadCharset = errors.New(ERR_BAD_CHARSET)
+
+// parseCharsetStr returns class and case option of charset str. It panics
+// if any of them is unknown.
+func parseCharsetStr(str string) (class, letterCase string) {
+	opts := strings.Split(str, ",")
+	class = opts[0]
+	switch class {
+	case CHARSET_A, CHARSET_N, CHARSET_S, CHARSET_AN, CHARSET_AS, CHARSET_NS, CHARSET_ANS, CHARSET_B, CHARSET_Z:
+	default:
+		panic("unknown charset: " + str)
+	}
+	for _, opt := range opts[1:] {
+		switch opt {
+		case CHARSET_UPPER, CHARSET_LOWER:
+			letterCase = opt
+		default:
+			panic("unknown charset option: " + opt)
+		}
+	}
+	return class, letterCase
+}
+
+// inCharset checks if c belongs to class with letter case restriction
+func inCharset(c byte, class, letterCase string) bool {
+	switch class {
+	case CHARSET_B:
+		return true
+	case CHARSET_Z:
+		return (c >= '0' && c <= '9') || c == '=' || c == 'D' || c == ';' || c == '?' || c == 'F'
+	}
+	upper, lower := c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z'
+	switch {
+	case upper || lower:
+		if letterCase == CHARSET_UPPER && lower || letterCase == CHARSET_LOWER && upper {
+			return false
+		}
+		return strings.Contains(class, CHARSET_A)
+	case c >= '0' && c <= '9':
+		return strings.Contains(class, CHARSET_N)
+	case c >= 0x20 && c <= 0x7e:
+		return strings.Contains(class, CHARSET_S)
+	}
+	return false
+}
+
+// checkCharset checks value of f against its charset. Padding of
+// Alphanumeric values is not checked, binary and composite fields are
+// never checked.
+func (f *fieldInfo) checkCharset() error {
+	if f.Charset == "" {
+		return nil
+	}
+	class, letterCase := parseCharsetStr(f.Charset)
+	var v string
+	switch t := f.Field.(type) {
+	case *Binary, *composite:
+		return nil
+	case *Alphanumeric:
+		v = strings.Trim(t.Value, " ")
+	default:
+		v = fieldValue(t)
+	}
+	for i := 0; i < len(v); i++ {
+		if !inCharset(v[i], class, letterCase) {
+			return fmt.Errorf("%w %s: %q", ErrBadCharset, f.Charset, v[i])
+		}
+	}
+	return nil
+}
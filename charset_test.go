@@ -0,0 +1,76 @@
+package iso8583
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCharset(t *testing.T) {
+	type test struct {
+		F2  *Llnumeric    `field:"2" length:"19" charset:"n"`
+		F35 *Llvar        `field:"35" length:"37" charset:"z"`
+		F41 *Alphanumeric `field:"41" length:"8" charset:"an,upper"`
+		F43 *Alphanumeric `field:"43" length:"40" charset:"ans"`
+		F52 *Binary       `field:"52" length:"2" charset:"b"`
+	}
+	data := &test{
+		F2:  NewLlnumeric("4276555555555558"),
+		F35: NewLlvar([]byte(";4276555555555558=2612101?")),
+		F41: NewAlphanumeric("TERM01"),
+		F43: NewAlphanumeric("Shop #1, Main st."),
+		F52: NewBinary([]byte{0, 0xff}),
+	}
+	msg := NewMessage("0100", data)
+	raw, err := msg.Bytes()
+	assert.Nil(t, err)
+
+	p := &Parser{}
+	p.Register("0100", &test{})
+	parsed, err := p.Parse(raw)
+	assert.Nil(t, err)
+	assert.Equal(t, "  TERM01", parsed.Data.(*test).F41.Value)
+
+	data.F41 = NewAlphanumeric("term01")
+	_, err = msg.Bytes()
+	assert.EqualError(t, err, "field 41: value has characters outside of charset an,upper: 't'")
+	assert.True(t, errors.Is(err, ErrBadCharset))
+	data.F41 = NewAlphanumeric("TERM01")
+	data.F43 = NewAlphanumeric("Shop\n")
+	_, err = msg.Bytes()
+	assert.EqualError(t, err, "field 43: value has characters outside of charset ans: '\\n'")
+
+	// Charsets override tags
+	msg.Charsets = map[int]string{43: CHARSET_B, 41: CHARSET_A}
+	_, err = msg.Bytes()
+	assert.EqualError(t, err, "field 41: value has characters outside of charset a: '0'")
+	msg.Charsets = map[int]string{43: CHARSET_B}
+	raw, err = msg.Bytes()
+	assert.Nil(t, err)
+
+	// loading checks charsets too
+	_, err = p.Parse(raw)
+	assert.EqualError(t, err, "field 43: value has characters outside of charset ans: '\\n'")
+	p.Charsets = msg.Charsets
+	_, err = p.Parse(raw)
+	assert.Nil(t, err)
+	p.Charsets = nil
+	p.CollectErrors = true
+	_, err = p.Parse(raw)
+	assert.EqualError(t, err, "field 43: value has characters outside of charset ans: '\\n'")
+
+	for c, class := range map[byte]string{'a': "as", '1': "ns", ' ': "s", 'D': "z", 0: "b"} {
+		assert.True(t, inCharset(c, class, ""))
+	}
+	for c, class := range map[byte]string{'a': "ns", '1': "as", ' ': "an", 'A': "z", 0xc1: "ans"} {
+		assert.False(t, inCharset(c, class, ""))
+	}
+	assert.False(t, inCharset('A', "a", CHARSET_LOWER))
+
+	type bad struct {
+		F2 *Llnumeric `field:"2" length:"19" charset:"x"`
+	}
+	_, err = NewMessage("0100", &bad{NewLlnumeric("1")}).Bytes()
+	assert.ErrorIs(t, err, ErrInvalidTag)
+	assert.EqualError(t, err, "field 2: invalid tag: unknown charset: x")
+}
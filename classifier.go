@@ -0,0 +1,131 @@
+package iso8583
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Message classes by second digit of MTI, returned by MtiClass
+const (
+	CLASS_AUTHORIZATION      string = "authorization"      // x1xx
+	CLASS_FINANCIAL          string = "financial"          // x2xx
+	CLASS_FILE_ACTION        string = "file-action"        // x3xx
+	CLASS_REVERSAL           string = "reversal"           // x4xx
+	CLASS_RECONCILIATION     string = "reconciliation"     // x5xx
+	CLASS_ADMINISTRATIVE     string = "administrative"     // x6xx
+	CLASS_FEE_COLLECTION     string = "fee-collection"     // x7xx
+	CLASS_NETWORK_MANAGEMENT string = "network-management" // x8xx
+)
+
+const (
+	ERR_UNSUPPORTED_MESSAGE string = "unsupported message"
+)
+
+var ErrUnsupportedMessage = errors.New(ERR_UNSUPPORTED_MESSAGE)
+
+var mtiClasses = map[byte]string{
+	'1': CLASS_AUTHORIZATION,
+	'2': CLASS_FINANCIAL,
+	'3': CLASS_FILE_ACTION,
+	'4': CLASS_REVERSAL,
+	'5': CLASS_RECONCILIATION,
+	'6': CLASS_ADMINISTRATIVE,
+	'7': CLASS_FEE_COLLECTION,
+	'8': CLASS_NETWORK_MANAGEMENT,
+}
+
+// MtiClass returns message class of MTI, or "" if it is unknown
+func MtiClass(mti string) string {
+	if len(mti) != 4 {
+		return ""
+	}
+	return mtiClasses[mti[1]]
+}
+
+// MtiOriginal returns MTI of original message of repeat (for ex. 0200 for
+// 0201 and 0422 for 0423), other MTIs are returned as is
+func MtiOriginal(mti string) string {
+	if len(mti) != 4 || (mti[3] != '1' && mti[3] != '3') {
+		return mti
+	}
+	return mti[:3] + string(mti[3]-1)
+}
+
+// Classification is a parser and handler selected for a class of messages
+type Classification struct {
+	Class   string
+	Parser  *Parser
+	Handler HandlerFunc
+}
+
+// Classifier triages raw messages cheaply: it reads only the header and
+// the MTI, selects Classification registered for message class and
+// rejects unsupported messages before full unpack.
+type Classifier struct {
+	// HeaderLen is length of header preceding MTI
+	HeaderLen int
+	// MtiEncode, CodePage and Quirks are used to read MTI
+	MtiEncode int
+	CodePage  *CodePage
+	Quirks    Quirks
+	// Class returns class of message by header and MTI, default is
+	// MtiClass of MTI. Empty class means unsupported message.
+	Class func(header []byte, mti string) string
+
+	classes map[string]*Classification
+}
+
+// Register sets parser and handler of messages of class
+func (c *Classifier) Register(class string, p *Parser, h HandlerFunc) {
+	if c.classes == nil {
+		c.classes = make(map[string]*Classification)
+	}
+	c.classes[class] = &Classification{class, p, h}
+}
+
+// Peek returns header and MTI of raw message without unpacking it
+func (c *Classifier) Peek(raw []byte) (header []byte, mti string, err error) {
+	if len(raw) < c.HeaderLen {
+		return nil, "", ErrBadRaw
+	}
+	mti, err = decodeMti(raw[c.HeaderLen:], c.Quirks.mtiEncode(c.MtiEncode), c.CodePage)
+	if err != nil {
+		return nil, "", err
+	}
+	return raw[:c.HeaderLen], mti, nil
+}
+
+// Classify returns Classification of raw message. It returns
+// ErrUnsupportedMessage if there is no Classification for its class.
+func (c *Classifier) Classify(raw []byte) (*Classification, error) {
+	header, mti, err := c.Peek(raw)
+	if err != nil {
+		return nil, err
+	}
+	var class string
+	if c.Class != nil {
+		class = c.Class(header, mti)
+	} else {
+		class = MtiClass(mti)
+	}
+	cl, ok := c.classes[class]
+	if !ok || class == "" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedMessage, mti)
+	}
+	return cl, nil
+}
+
+// Dispatch classifies raw message, parses it without header by selected
+// Parser and passes it to selected handler
+func (c *Classifier) Dispatch(ctx context.Context, raw []byte) (*Message, error) {
+	cl, err := c.Classify(raw)
+	if err != nil {
+		return nil, err
+	}
+	req, err := cl.Parser.Parse(raw[c.HeaderLen:])
+	if err != nil {
+		return nil, err
+	}
+	return cl.Handler(ctx, req)
+}
@@ -0,0 +1,55 @@
+package iso8583
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestClassifier(t *testing.T) {
+	assert.Equal(t, CLASS_NETWORK_MANAGEMENT, MtiClass("0800"))
+	assert.Equal(t, CLASS_FINANCIAL, MtiClass("1200"))
+	assert.Equal(t, "", MtiClass("0900"))
+	assert.Equal(t, "", MtiClass("08"))
+
+	p := &Parser{}
+	p.Register("0800", &NetworkManagement{})
+	c := &Classifier{HeaderLen: 2}
+	c.Register(CLASS_NETWORK_MANAGEMENT, p, func(ctx context.Context, req *Message) (*Message, error) {
+		return NewNetworkManagementResponse(req, "00")
+	})
+
+	msg := NewEcho("000001", time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	b, err := msg.Bytes()
+	assert.Nil(t, err)
+	raw := append([]byte("H1"), b...)
+
+	header, mti, err := c.Peek(raw)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("H1"), header)
+	assert.Equal(t, "0800", mti)
+
+	resp, err := c.Dispatch(context.Background(), raw)
+	assert.Nil(t, err)
+	assert.Equal(t, "0810", resp.Mti)
+	assert.Equal(t, "00", resp.Data.(*NetworkManagement).F39.Value)
+
+	// rejected before unpack, even though the rest is garbage
+	_, err = c.Classify([]byte("H10200garbage"))
+	assert.True(t, errors.Is(err, ErrUnsupportedMessage))
+	assert.Equal(t, "unsupported message: 0200", err.Error())
+
+	c.Class = func(header []byte, mti string) string {
+		if string(header) == "H2" {
+			return ""
+		}
+		return MtiClass(mti)
+	}
+	_, err = c.Classify(append([]byte("H2"), b...))
+	assert.True(t, errors.Is(err, ErrUnsupportedMessage))
+
+	_, _, err = c.Peek([]byte("H"))
+	assert.Equal(t, ErrBadRaw, err)
+}
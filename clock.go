@@ -0,0 +1,53 @@
+package iso8583
+
+import (
+	"reflect"
+	"time"
+)
+
+// Clock returns current time. It is replaced by fixed time in tests.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc is Clock implemented by function, for ex. ClockFunc(time.Now)
+type ClockFunc func() time.Time
+
+// Now calls f
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock is Clock of local system time
+var SystemClock Clock = ClockFunc(time.Now)
+
+// stampTime sets empty transmission date and time (field 7), local time
+// (field 12) and local date (field 13) which are defined in Data to
+// current time of m.Clock by SetTransmissionTime with m.Location
+func (m *Message) stampTime() error {
+	if m.Clock == nil {
+		return nil
+	}
+	set := make(map[int]interface{})
+	for _, i := range []int{7, 12, 13} {
+		_, fv, ok := findField(m.Data, i)
+		if !ok {
+			continue
+		}
+		if f, ok := fv.Interface().(Iso8583Type); ok && !fv.IsNil() && !f.IsEmpty() {
+			set[i] = f
+		}
+	}
+	if len(set) == 3 {
+		return nil
+	}
+	if err := m.SetTransmissionTime(m.Clock.Now(), m.Location); err != nil {
+		return err
+	}
+	// fields which were set are kept
+	for i, f := range set {
+		_, fv, _ := findField(m.Data, i)
+		fv.Set(reflect.ValueOf(f))
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package iso8583
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestStampTime(t *testing.T) {
+	type test struct {
+		F7  *Numeric `field:"7" length:"10"`
+		F11 *Numeric `field:"11" length:"6"`
+		F12 *Numeric `field:"12" length:"6"`
+		F13 *Numeric `field:"13" length:"4"`
+	}
+	zone := time.FixedZone("UTC+3", 3*60*60)
+	clock := ClockFunc(func() time.Time {
+		return time.Date(2026, 10, 15, 1, 4, 5, 0, zone)
+	})
+	data := &test{F11: NewNumeric("000001"), F13: NewNumeric("1231")}
+	iso := NewMessage("0200", data)
+	_, err := iso.Bytes()
+	assert.Nil(t, err)
+	assert.Nil(t, data.F7)
+
+	iso.Clock = clock
+	_, err = iso.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "1014220405", data.F7.Value)
+	assert.Equal(t, "010405", data.F12.Value)
+	assert.Equal(t, "1231", data.F13.Value)
+
+	// local time in Location, set fields are kept
+	data = &test{F12: NewNumeric("120000")}
+	iso = NewMessage("0200", data)
+	iso.Clock = clock
+	iso.Location = time.UTC
+	_, err = iso.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "1014220405", data.F7.Value)
+	assert.Equal(t, "120000", data.F12.Value)
+	assert.Equal(t, "1014", data.F13.Value)
+
+	p := &Parser{Location: zone}
+	assert.Nil(t, p.Register("0200", &test{}))
+	msg, err := p.newMessage("0200")
+	assert.Nil(t, err)
+	assert.Equal(t, zone, msg.Location)
+
+	type short struct {
+		F11 *Numeric `field:"11" length:"6"`
+	}
+	iso = NewMessage("0200", &short{NewNumeric("000001")})
+	iso.Clock = SystemClock
+	_, err = iso.Bytes()
+	assert.Nil(t, err)
+}
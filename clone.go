@@ -0,0 +1,92 @@
+package iso8583
+
+import (
+	"reflect"
+)
+
+// Clone returns deep copy of Message. Fields of Data and Extra, including
+// []byte values, don't share memory with the original, so the copy can be
+// changed (for ex. to build a response from a request) without aliasing.
+func (m *Message) Clone() *Message {
+	ret := *m
+	if m.raw != nil {
+		ret.raw = make(map[int][]byte, len(m.raw))
+		for i, b := range m.raw {
+			ret.raw[i] = append([]byte(nil), b...)
+		}
+	}
+	if m.snap != nil {
+		ret.snap = make(map[int]interface{}, len(m.snap))
+		for i, v := range m.snap {
+			ret.snap[i] = deepCopy(reflect.ValueOf(v)).Interface()
+		}
+	}
+	if m.present != nil {
+		ret.present = make(map[int]bool, len(m.present))
+		for i := range m.present {
+			ret.present[i] = true
+		}
+	}
+	if m.Extra != nil {
+		ret.Extra = make(map[int]Iso8583Type, len(m.Extra))
+		for i, f := range m.Extra {
+			ret.Extra[i] = deepCopy(reflect.ValueOf(f)).Interface().(Iso8583Type)
+		}
+	}
+	if m.Data != nil {
+		ret.Data = deepCopy(reflect.ValueOf(m.Data)).Interface()
+	}
+	return &ret
+}
+
+// deepCopy copies pointers, structs, slices, maps and interfaces
+// recursively. Unexported struct fields are copied shallowly.
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		ret := reflect.New(v.Type().Elem())
+		ret.Elem().Set(deepCopy(v.Elem()))
+		return ret
+	case reflect.Interface:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		ret := reflect.New(v.Type()).Elem()
+		ret.Set(deepCopy(v.Elem()))
+		return ret
+	case reflect.Struct:
+		ret := reflect.New(v.Type()).Elem()
+		ret.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath != "" {
+				// unexported field
+				continue
+			}
+			ret.Field(i).Set(deepCopy(v.Field(i)))
+		}
+		return ret
+	case reflect.Slice:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		ret := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			ret.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return ret
+	case reflect.Map:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		ret := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for _, k := range v.MapKeys() {
+			ret.SetMapIndex(k, deepCopy(v.MapIndex(k)))
+		}
+		return ret
+	default:
+		return v
+	}
+}
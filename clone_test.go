@@ -0,0 +1,63 @@
+package iso8583
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestClone(t *testing.T) {
+	parser := Parser{}
+	parser.Register("0100", newDataIso())
+
+	input, err := (&Message{Mti: "0100", MtiEncode: ASCII, Data: &TestISO{
+		F2:  NewLlnumeric("4276555555555555"),
+		F37: NewAlphanumeric("987654321001"),
+		F52: NewBinary([]byte{1, 2, 3, 4, 5, 6, 7, 8}),
+	}}).Bytes()
+	assert.Nil(t, err)
+
+	req, err := parser.Parse(input)
+	assert.Nil(t, err)
+
+	resp := req.Clone()
+	resp.Mti = "0110"
+	data := resp.Data.(*TestISO)
+	data.F52.Value[0] = 0xff
+	data.F37.Value = "000000000000"
+	data.F39 = NewAlphanumeric("00")
+
+	reqData := req.Data.(*TestISO)
+	assert.Equal(t, "0100", req.Mti)
+	assert.Equal(t, byte(1), reqData.F52.Value[0])
+	assert.Equal(t, "987654321001", reqData.F37.Value)
+	assert.Equal(t, "", reqData.F39.Value)
+	assert.Equal(t, reqData.F2, data.F2)
+	assert.False(t, reqData.F2 == data.F2)
+
+	// Data as struct value
+	iso := NewMessage("0100", testTransaction{F11: NewNumeric("1")})
+	clone := iso.Clone()
+	clone.Data.(testTransaction).F11.Value = "2"
+	assert.Equal(t, "1", iso.Data.(testTransaction).F11.Value)
+
+	assert.Nil(t, NewMessage("0100", nil).Clone().Data)
+
+	// fields decoded by CatchAll
+	type known struct {
+		F11 *Numeric `field:"11" length:"6"`
+	}
+	raw, err := NewMessage("0200", &struct {
+		F11 *Numeric `field:"11" length:"6"`
+		F62 *Lllvar  `field:"62" length:"999"`
+	}{NewNumeric("000001"), NewLllvar([]byte("private"))}).Bytes()
+	assert.Nil(t, err)
+	p := &Parser{CatchAll: []CatchAllField{{First: 62, Last: 62}}}
+	p.Register("0200", &known{})
+	msg, err := p.Parse(raw)
+	assert.Nil(t, err)
+	clone = msg.Clone()
+	clone.Extra[62].(*Lllvar).Value[0] = 'X'
+	clone.Extra[63] = NewLlvar([]byte("data"))
+	assert.Equal(t, NewLllvar([]byte("private")), msg.Extra[62])
+	assert.Len(t, msg.Extra, 1)
+}
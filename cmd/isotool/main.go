@@ -0,0 +1,414 @@
+// Command isotool decodes, encodes and inspects ISO 8583 messages.
+//
+// Usage:
+//
+//	isotool decode  -spec SPEC [-in hex|bin] [FILE]
+//	isotool inspect -spec SPEC [-in hex|bin] [FILE]
+//	isotool describe -spec SPEC [-in hex|bin] [-format csv|json] [FILE]
+//	isotool encode  -spec SPEC [-out hex|bin] [FILE]
+//	isotool replay  -spec SPEC [-framing binary|ascii] [-pcap] [FILE]
+//
+// SPEC is a name of built-in preset (visa, mastercard, amex) or a path to
+// JSON spec file:
+//
+//	{
+//	  "mti_encode": "ascii",
+//	  "fields": {
+//	    "2": {"type": "llnumeric", "length": 19, "encode": "bcd,bcd"},
+//	    "4": {"type": "numeric", "length": 12}
+//	  }
+//	}
+//
+// Field types are numeric, alphanumeric, binary, llvar, lllvar, llnumeric
+// and lllnumeric, encode is value of encode tag. Decode prints message as
+// JSON of Message.MarshalJSON, which encode accepts: {"mti": "0200",
+// "fields": {"4": "100"}}. Fields are ordered by number, values of binary
+// fields (binary, llvar and lllvar) are hex strings, numeric values may be
+// JSON numbers for encode. Input is read from FILE or
+// stdin. Replay reads binary dump of framed messages (for ex. payload of
+// captured TCP stream) or pcap capture file with -pcap and prints requests
+// paired with responses. Describe
+// prints offset, wire length, encoding and value of each field as CSV or
+// JSON table for certification documents.
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ideazxy/iso8583"
+	"github.com/ideazxy/iso8583/batch"
+	"github.com/ideazxy/iso8583/networks/amex"
+	"github.com/ideazxy/iso8583/networks/mastercard"
+	"github.com/ideazxy/iso8583/networks/visa"
+)
+
+// Spec is JSON spec file
+type Spec struct {
+	MtiEncode string               `json:"mti_encode"`
+	Fields    map[string]FieldSpec `json:"fields"`
+}
+
+// FieldSpec describes a field in spec file
+type FieldSpec struct {
+	Type   string `json:"type"`
+	Length int    `json:"length"`
+	Encode string `json:"encode"`
+}
+
+var fieldTypes = map[string]reflect.Type{
+	"numeric":      reflect.TypeOf(&iso8583.Numeric{}),
+	"alphanumeric": reflect.TypeOf(&iso8583.Alphanumeric{}),
+	"binary":       reflect.TypeOf(&iso8583.Binary{}),
+	"llvar":        reflect.TypeOf(&iso8583.Llvar{}),
+	"lllvar":       reflect.TypeOf(&iso8583.Lllvar{}),
+	"llnumeric":    reflect.TypeOf(&iso8583.Llnumeric{}),
+	"lllnumeric":   reflect.TypeOf(&iso8583.Lllnumeric{}),
+}
+
+var mtiEncodes = map[string]int{
+	"":       iso8583.ASCII,
+	"ascii":  iso8583.ASCII,
+	"bcd":    iso8583.BCD,
+	"ebcdic": iso8583.EBCDIC,
+	"binary": iso8583.BINARY,
+}
+
+type preset struct {
+	tpl       reflect.Type
+	mtiEncode int
+}
+
+var presets = map[string]preset{
+	"visa":       {reflect.TypeOf(visa.Fields{}), visa.MtiEncode},
+	"mastercard": {reflect.TypeOf(mastercard.Fields{}), mastercard.MtiEncode},
+	"amex":       {reflect.TypeOf(amex.Fields{}), amex.MtiEncode},
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "isotool:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) < 1 {
+		return errors.New("command is required: decode, inspect, describe, encode or replay")
+	}
+	cmd := args[0]
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	specName := fs.String("spec", "", "preset name or JSON spec file")
+	in := fs.String("in", "hex", "input format of decode and inspect: hex or bin")
+	out := fs.String("out", "hex", "output format of encode: hex or bin")
+	framing := fs.String("framing", "binary", "length header of replay: binary or ascii")
+	pcap := fs.Bool("pcap", false, "input of replay is pcap capture file")
+	format := fs.String("format", "csv", "output format of describe: csv or json")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *specName == "" {
+		return errors.New("-spec is required")
+	}
+	tpl, mtiEncode, err := loadSpec(*specName)
+	if err != nil {
+		return err
+	}
+
+	r := stdin
+	if fs.NArg() > 0 {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	input, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	switch cmd {
+	case "decode", "inspect":
+		raw, err := decodeInput(input, *in)
+		if err != nil {
+			return err
+		}
+		msg := iso8583.NewMessage("", newData(tpl))
+		msg.MtiEncode = mtiEncode
+		msg.CaptureRaw = true
+		if err := msg.Load(raw); err != nil {
+			return err
+		}
+		if cmd == "decode" {
+			return printJSON(stdout, msg)
+		}
+		return inspect(stdout, msg, raw)
+	case "describe":
+		raw, err := decodeInput(input, *in)
+		if err != nil {
+			return err
+		}
+		return printReport(stdout, raw, tpl, mtiEncode, *format)
+	case "encode":
+		msg := iso8583.NewMessage("", reflect.New(tpl).Interface())
+		msg.MtiEncode = mtiEncode
+		if err := json.Unmarshal(input, msg); err != nil {
+			return err
+		}
+		b, err := msg.Bytes()
+		if err != nil {
+			return err
+		}
+		switch *out {
+		case "bin":
+			_, err = stdout.Write(b)
+		case "hex":
+			_, err = fmt.Fprintln(stdout, hex.EncodeToString(b))
+		default:
+			err = fmt.Errorf("unknown output format: %s", *out)
+		}
+		return err
+	case "replay":
+		return replay(stdout, input, *framing, *pcap, tpl, mtiEncode)
+	default:
+		return fmt.Errorf("unknown command: %s", cmd)
+	}
+}
+
+// loadSpec returns struct type of message data and MTI encoding
+func loadSpec(name string) (reflect.Type, int, error) {
+	if p, ok := presets[name]; ok {
+		return p.tpl, p.mtiEncode, nil
+	}
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return nil, 0, err
+	}
+	var spec Spec
+	if err := json.Unmarshal(b, &spec); err != nil {
+		return nil, 0, fmt.Errorf("spec %s: %s", name, err)
+	}
+	return specType(spec)
+}
+
+// specType builds struct type with field tags from spec
+func specType(spec Spec) (reflect.Type, int, error) {
+	mtiEncode, ok := mtiEncodes[spec.MtiEncode]
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown MTI encode: %s", spec.MtiEncode)
+	}
+	indexes := make([]int, 0, len(spec.Fields))
+	for k := range spec.Fields {
+		i, err := strconv.Atoi(k)
+		if err != nil || i < 2 || i > 128 {
+			return nil, 0, fmt.Errorf("bad field index: %s", k)
+		}
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	fields := make([]reflect.StructField, 0, len(indexes))
+	for _, i := range indexes {
+		fs := spec.Fields[strconv.Itoa(i)]
+		tp, ok := fieldTypes[fs.Type]
+		if !ok {
+			return nil, 0, fmt.Errorf("field %d: unknown type: %s", i, fs.Type)
+		}
+		tag := fmt.Sprintf(`field:"%d"`, i)
+		if fs.Length > 0 {
+			tag += fmt.Sprintf(` length:"%d"`, fs.Length)
+		}
+		if fs.Encode != "" {
+			tag += fmt.Sprintf(` encode:"%s"`, fs.Encode)
+		}
+		fields = append(fields, reflect.StructField{
+			Name: fmt.Sprintf("F%d", i),
+			Type: tp,
+			Tag:  reflect.StructTag(tag),
+		})
+	}
+	return reflect.StructOf(fields), mtiEncode, nil
+}
+
+// newData allocates data struct with all fields allocated
+func newData(tpl reflect.Type) interface{} {
+	v := reflect.New(tpl)
+	for i := 0; i < tpl.NumField(); i++ {
+		f := v.Elem().Field(i)
+		if f.Kind() == reflect.Ptr && f.CanSet() {
+			f.Set(reflect.New(f.Type().Elem()))
+		}
+	}
+	return v.Interface()
+}
+
+// dataFields returns fields of data by index
+func dataFields(data interface{}) map[int]reflect.Value {
+	ret := make(map[int]reflect.Value)
+	v := reflect.ValueOf(data).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		tag := v.Type().Field(i).Tag.Get("field")
+		if tag == "" {
+			continue
+		}
+		index, err := strconv.Atoi(strings.Split(tag, ",")[0])
+		if err != nil {
+			continue
+		}
+		ret[index] = v.Field(i)
+	}
+	return ret
+}
+
+// fieldString returns value of field, hex for Binary
+func fieldString(f reflect.Value) string {
+	if f.IsNil() {
+		return ""
+	}
+	val := f.Elem().FieldByName("Value")
+	switch {
+	case !val.IsValid():
+		return fmt.Sprintf("%+v", f.Elem().Interface())
+	case val.Kind() == reflect.String:
+		return val.String()
+	case f.Type() == fieldTypes["binary"]:
+		return hex.EncodeToString(val.Bytes())
+	default:
+		return string(val.Bytes())
+	}
+}
+
+// printJSON prints msg as indented JSON of Message.MarshalJSON
+func printJSON(w io.Writer, msg *iso8583.Message) error {
+	b, err := json.MarshalIndent(msg, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// printReport prints field report of raw message in format
+func printReport(w io.Writer, raw []byte, tpl reflect.Type, mtiEncode int, format string) error {
+	msg := iso8583.NewMessage("", newData(tpl))
+	msg.MtiEncode = mtiEncode
+	if err := msg.Load(raw); err != nil {
+		return err
+	}
+	p := &iso8583.Parser{MtiEncode: mtiEncode}
+	if err := p.Register(msg.Mti, newData(tpl)); err != nil {
+		return err
+	}
+	r, err := iso8583.Describe(raw, p)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case "csv":
+		return r.WriteCSV(w)
+	case "json":
+		return r.WriteJSON(w)
+	}
+	return fmt.Errorf("unknown output format: %s", format)
+}
+
+func inspect(w io.Writer, msg *iso8583.Message, raw []byte) error {
+	fields := dataFields(msg.Data)
+	indexes := make([]int, 0, len(fields))
+	for i := range fields {
+		if msg.RawField(i) != nil {
+			indexes = append(indexes, i)
+		}
+	}
+	sort.Ints(indexes)
+
+	fmt.Fprintf(w, "MTI     %s\n", msg.Mti)
+	fmt.Fprintf(w, "Length  %d\n", len(raw))
+	for _, i := range indexes {
+		f := fields[i]
+		fmt.Fprintf(w, "F%03d  %-22s %q\n      raw %s\n",
+			i, f.Type().Elem().Name(), fieldString(f), hex.EncodeToString(msg.RawField(i)))
+	}
+	return nil
+}
+
+func decodeInput(input []byte, format string) ([]byte, error) {
+	switch format {
+	case "bin":
+		return input, nil
+	case "hex":
+		s := strings.Join(strings.Fields(string(input)), "")
+		return hex.DecodeString(s)
+	}
+	return nil, fmt.Errorf("unknown input format: %s", format)
+}
+
+func replay(w io.Writer, input []byte, framing string, pcap bool, tpl reflect.Type, mtiEncode int) error {
+	var f batch.Framing
+	switch framing {
+	case "binary":
+		f.Header = batch.HeaderBinary
+	case "ascii":
+		f.Header = batch.HeaderASCII
+	default:
+		return fmt.Errorf("unknown framing: %s", framing)
+	}
+
+	var records [][]byte
+	if pcap {
+		var err error
+		if records, err = batch.ReadPcap(bytes.NewReader(input), f); err != nil {
+			return err
+		}
+	} else {
+		r := batch.NewReader(bytes.NewReader(input), nil, f)
+		for {
+			raw, err := r.NextRaw()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			records = append(records, append([]byte(nil), raw...))
+		}
+	}
+
+	var msgs []*iso8583.Message
+	for n, raw := range records {
+		msg := iso8583.NewMessage("", newData(tpl))
+		msg.MtiEncode = mtiEncode
+		if err := msg.Load(raw); err != nil {
+			return fmt.Errorf("message %d: %s", n, err)
+		}
+		msgs = append(msgs, msg)
+	}
+
+	for _, e := range batch.Pair(msgs) {
+		fmt.Fprintf(w, "%s -> %s\n", describe(e.Request), describe(e.Response))
+	}
+	return nil
+}
+
+// describe returns MTI and STAN of msg
+func describe(msg *iso8583.Message) string {
+	if msg == nil {
+		return "(none)"
+	}
+	if f, ok := dataFields(msg.Data)[11]; ok && !f.IsNil() {
+		return msg.Mti + " STAN " + fieldString(f)
+	}
+	return msg.Mti
+}
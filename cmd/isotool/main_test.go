@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSpec = `{
+	"mti_encode": "ascii",
+	"fields": {
+		"2": {"type": "llnumeric", "length": 19},
+		"4": {"type": "numeric", "length": 12},
+		"48": {"type": "lllvar", "length": 999},
+		"52": {"type": "binary", "length": 8}
+	}
+}`
+
+func TestEncodeDecode(t *testing.T) {
+	spec := filepath.Join(t.TempDir(), "spec.json")
+	assert.Nil(t, os.WriteFile(spec, []byte(testSpec), 0644))
+
+	out := &bytes.Buffer{}
+	doc := `{"mti": "0200", "fields": {"2": "4276555555555558", "4": "100", "52": "0102030405060708"}}`
+	err := run([]string{"encode", "-spec", spec}, strings.NewReader(doc), out)
+	assert.Nil(t, err)
+	assert.Equal(t, "30323030"+"5000000000001000"+"3136"+"34323736353535353535353535353538"+"303030303030303030313030"+"0102030405060708\n", out.String())
+
+	hexMsg := out.String()
+	out.Reset()
+	err = run([]string{"decode", "-spec", spec}, strings.NewReader(hexMsg), out)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"mti": "0200", "fields": {"2": "4276555555555558", "4": "000000000100", "52": "0102030405060708"}}`, out.String())
+	assert.Less(t, strings.Index(out.String(), `"4"`), strings.Index(out.String(), `"52"`))
+
+	out.Reset()
+	err = run([]string{"encode", "-spec", spec}, strings.NewReader(`{"mti": "0200", "fields": {"2": 4276555555555558, "4": 100}}`), out)
+	assert.Nil(t, err)
+	assert.Equal(t, "30323030"+"5000000000000000"+"3136"+"34323736353535353535353535353538"+"303030303030303030313030\n", out.String())
+
+	out.Reset()
+	err = run([]string{"inspect", "-spec", spec}, strings.NewReader(hexMsg), out)
+	assert.Nil(t, err)
+	assert.Contains(t, out.String(), "MTI     0200\n")
+	assert.Contains(t, out.String(), "F004  Numeric                \"000000000100\"\n      raw 303030303030303030313030\n")
+
+	out.Reset()
+	err = run([]string{"describe", "-spec", spec}, strings.NewReader(hexMsg), out)
+	assert.Nil(t, err)
+	assert.Contains(t, out.String(), "4,F4,Numeric,30,12,ascii,ascii,000000000100,303030303030303030313030\n")
+	out.Reset()
+	err = run([]string{"describe", "-spec", spec, "-format", "json"}, strings.NewReader(hexMsg), out)
+	assert.Nil(t, err)
+	assert.Contains(t, out.String(), `"offset": 42`)
+	err = run([]string{"describe", "-spec", spec, "-format", "xml"}, strings.NewReader(hexMsg), out)
+	assert.EqualError(t, err, "unknown output format: xml")
+
+	// llvar and lllvar values are hex, so binary data is kept
+	out.Reset()
+	err = run([]string{"encode", "-spec", spec}, strings.NewReader(`{"mti": "0200", "fields": {"48": "FF00"}}`), out)
+	assert.Nil(t, err)
+	hexMsg = out.String()
+	out.Reset()
+	err = run([]string{"decode", "-spec", spec}, strings.NewReader(hexMsg), out)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"mti": "0200", "fields": {"48": "FF00"}}`, out.String())
+
+	err = run([]string{"encode", "-spec", spec}, strings.NewReader(`{"mti": "0200", "fields": {"3": "0"}}`), out)
+	assert.EqualError(t, err, "field 3 not defined")
+	err = run([]string{"decode", "-spec", "unknown.json"}, strings.NewReader(""), out)
+	assert.NotNil(t, err)
+	err = run([]string{"decode"}, strings.NewReader(""), out)
+	assert.EqualError(t, err, "-spec is required")
+}
+
+func TestPreset(t *testing.T) {
+	out := &bytes.Buffer{}
+	doc := `{"mti": "0800", "fields": {"11": "000001", "70": "301"}}`
+	err := run([]string{"encode", "-spec", "visa", "-out", "bin"}, strings.NewReader(doc), out)
+	assert.Nil(t, err)
+
+	raw := out.Bytes()
+	out = &bytes.Buffer{}
+	err = run([]string{"decode", "-spec", "visa", "-in", "bin"}, bytes.NewReader(raw), out)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"mti": "0800", "fields": {"11": "000001", "70": "301"}}`, out.String())
+}
+
+func TestReplay(t *testing.T) {
+	dump := &bytes.Buffer{}
+	for _, doc := range []string{
+		`{"mti": "0800", "fields": {"11": "000001"}}`,
+		`{"mti": "0800", "fields": {"11": "000002"}}`,
+		`{"mti": "0810", "fields": {"11": "000001", "39": "00"}}`,
+	} {
+		out := &bytes.Buffer{}
+		assert.Nil(t, run([]string{"encode", "-spec", "amex", "-out", "bin"}, strings.NewReader(doc), out))
+		dump.Write([]byte{0, byte(out.Len())})
+		dump.Write(out.Bytes())
+	}
+
+	out := &bytes.Buffer{}
+	err := run([]string{"replay", "-spec", "amex"}, dump, out)
+	assert.Nil(t, err)
+	assert.Equal(t, "0800 STAN 000001 -> 0810 STAN 000001\n0800 STAN 000002 -> (none)\n", out.String())
+}
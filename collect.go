@@ -0,0 +1,29 @@
+package iso8583
+
+// collectError returns err, or errs with err appended if CollectErrors is
+// set
+func (m *Message) collectError(errs FieldErrors, err error) error {
+	if !m.CollectErrors {
+		return err
+	}
+	return append(errs, err)
+}
+
+// checkLoaded returns problems of loaded field which don't prevent parsing
+// of the rest of message
+func (m *Message) checkLoaded(f *fieldInfo) []error {
+	var errs []error
+	// zoned values may be signed
+	if f.Encode != ZONED && !isNumericValue(f.Field) {
+		errs = append(errs, &FieldError{f.Index, ErrNotNumeric})
+	}
+	if err := f.checkCharset(); err != nil {
+		errs = append(errs, &FieldError{f.Index, err})
+	}
+	if max, ok := m.MaxLengths[f.Index]; ok {
+		if err := checkMaxLength(f.Index, f, max); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
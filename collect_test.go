@@ -0,0 +1,54 @@
+package iso8583
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCollectErrors(t *testing.T) {
+	type test struct {
+		F3  *Numeric      `field:"3" length:"6" pad:"space"`
+		F4  *Numeric      `field:"4" length:"12"`
+		F11 *Numeric      `field:"11,required" length:"6"`
+		F32 *Llnumeric    `field:"32" length:"19"`
+		F41 *Alphanumeric `field:"41,required" length:"8"`
+		F43 *Alphanumeric `field:"43" length:"40"`
+	}
+	raw, err := NewMessage("0200", &test{
+		F4:  &Numeric{Value: "00000000012x"},
+		F11: NewNumeric("000001"),
+		F32: NewLlnumeric("123456789012"),
+		F43: NewAlphanumeric("SHOP"),
+	}).Bytes()
+	assert.Nil(t, err)
+
+	p := &Parser{CollectErrors: true, MaxLengths: map[int]int{32: 11}}
+	p.Register("0200", &test{})
+	msg, err := p.Parse(raw)
+	assert.NotNil(t, msg)
+	assert.EqualError(t, err, "field 4: value is not numeric; "+
+		"field 32: length of value is longer than definition; type=Llnumeric, def_len=11, len=12")
+	assert.True(t, errors.Is(err, ErrNotNumeric))
+	assert.True(t, errors.Is(err, ErrValueTooLong))
+	// parsing went on after bad fields
+	assert.Equal(t, "SHOP", msg.Data.(*test).F43.Value[36:])
+
+	// parsing stops at a field which can't be decoded
+	_, err = p.Parse(raw[:len(raw)-10])
+	assert.EqualError(t, err, "field 4: value is not numeric; "+
+		"field 32: length of value is longer than definition; type=Llnumeric, def_len=11, len=12; "+
+		"field 43: bad raw data")
+
+	// without CollectErrors bad values are loaded as is
+	p.CollectErrors = false
+	msg, err = p.Parse(raw)
+	assert.Nil(t, err)
+	assert.Equal(t, "00000000012x", msg.Data.(*test).F4.Value)
+
+	iso := NewMessage("0200", &test{})
+	assert.EqualError(t, iso.Validate(), "field 11 is required")
+	iso.CollectErrors = true
+	assert.EqualError(t, iso.Validate(), "field 11 is required; field 41 is required")
+	assert.Nil(t, NewMessage("0200", &test{F11: NewNumeric("1"), F41: NewAlphanumeric("T")}).Validate())
+}
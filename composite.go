@@ -0,0 +1,286 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	TAG_COMPOSITE string = "composite"
+)
+
+// Kinds of composite field, set by composite tag (for ex. `composite:"llvar"`)
+const (
+	COMPOSITE_LLLVAR string = "lllvar" // default
+	COMPOSITE_LLVAR  string = "llvar"
+	COMPOSITE_FIXED  string = "fixed"
+	// COMPOSITE_BITMAP option (for ex. `composite:"llvar,bitmap"`) makes
+	// body start with 8 byte bitmap of present subfields. Bitmap of other
+	// length is set by number of bytes after the option, for ex. bitmap3.
+	COMPOSITE_BITMAP string = "bitmap"
+	// COMPOSITE_BITMAP8 option is COMPOSITE_BITMAP with explicit 8 byte
+	// bitmap, for ex. Visa field 62
+	COMPOSITE_BITMAP8 string = "bitmap8"
+)
+
+const (
+	ERR_INVALID_COMPOSITE string = "invalid composite kind"
+)
+
+var ErrInvalidComposite = errors.New(ERR_INVALID_COMPOSITE)
+
+// composite is Iso8583Type for a pointer to nested struct, which members
+// are subfields with their own field tags. All subfields (nil and empty
+// too) are packed one after another in order of their indexes, without
+// bitmap, and the result is packed as Lllvar, Llvar or fixed length Binary
+// according to composite tag. Encoder of composite field itself must be
+// ascii.
+//
+// Bitmapped composite (with COMPOSITE_BITMAP option) packs only present
+// subfields after bitmap, in which bit i (from the most significant bit of
+// the first byte) marks subfield i. Bitmap has 8 bytes, or number of bytes
+// set by the option, for ex. 3 bytes with bitmap3.
+type composite struct {
+	value reflect.Value
+	kind  string
+}
+
+// options returns kind of composite and number of bytes of its bitmap, 0
+// if it is not bitmapped. It panics if option is unknown.
+func (c *composite) options() (string, int) {
+	opts := strings.Split(c.kind, ",")
+	bitmap := 0
+	for _, opt := range opts[1:] {
+		bitmap = parseBitmapOption(opt)
+	}
+	return opts[0], bitmap
+}
+
+// parseBitmapOption returns number of bytes of bitmap set by composite
+// option opt, bitmap or bitmapN with N from 1 to 16
+func parseBitmapOption(opt string) int {
+	if opt == COMPOSITE_BITMAP {
+		return 8
+	}
+	if strings.HasPrefix(opt, COMPOSITE_BITMAP) {
+		if n, err := strconv.Atoi(opt[len(COMPOSITE_BITMAP):]); err == nil && n >= 1 && n <= 16 {
+			return n
+		}
+	}
+	panic("unknown composite option: " + opt)
+}
+
+// isStructPtr checks that v is a pointer to struct
+func isStructPtr(v reflect.Value) bool {
+	return v.Kind() == reflect.Ptr && v.Type().Elem().Kind() == reflect.Struct
+}
+
+// subfields returns subfields and their sorted indexes
+func (c *composite) subfields() (map[int]*fieldInfo, []int, error) {
+	fields, err := parseFields(c.value.Interface())
+	if err != nil {
+		return nil, nil, err
+	}
+	indexes := make([]int, 0, len(fields))
+	for i := range fields {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	return fields, indexes, nil
+}
+
+// IsEmpty check all subfields for empty value
+func (c *composite) IsEmpty() bool {
+	fields, _, err := c.subfields()
+	if err != nil {
+		// not empty, so the error is returned by Bytes
+		return false
+	}
+	for _, info := range fields {
+		if !info.Field.IsEmpty() {
+			return false
+		}
+	}
+	return true
+}
+
+// Bytes encode composite field to bytes
+func (c *composite) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
+	kind, bitmap := c.options()
+	var body []byte
+	var err error
+	if bitmap > 0 {
+		body, err = c.bitmappedBody(bitmap)
+	} else {
+		body, err = c.body()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return packComposite(kind, body, encoder, lenEncoder, length)
+}
+
+// packComposite packs body of composite field of kind
+func packComposite(kind string, body []byte, encoder, lenEncoder, length int) ([]byte, error) {
+	switch kind {
+	case "", COMPOSITE_LLLVAR:
+		return NewLllvar(body).Bytes(encoder, lenEncoder, length)
+	case COMPOSITE_LLVAR:
+		return NewLlvar(body).Bytes(encoder, lenEncoder, length)
+	case COMPOSITE_FIXED:
+		return NewBinary(body).Bytes(encoder, lenEncoder, length)
+	default:
+		return nil, ErrInvalidComposite
+	}
+}
+
+// body packs all subfields
+func (c *composite) body() ([]byte, error) {
+	// nil subfields are packed as empty ones, so allocate them in a copy
+	tmp := reflect.New(c.value.Type().Elem())
+	tmp.Elem().Set(c.value.Elem())
+	initStruct(tmp.Type().Elem(), tmp)
+	fields, indexes, err := (&composite{tmp, c.kind}).subfields()
+	if err != nil {
+		return nil, err
+	}
+	body := make([]byte, 0, 64)
+	for _, i := range indexes {
+		d, err := fields[i].bytes()
+		if err != nil {
+			return nil, fmt.Errorf("subfield %d: %w", i, err)
+		}
+		body = append(body, d...)
+	}
+	return body, nil
+}
+
+// bitmappedBody packs bitmap of n bytes and present subfields
+func (c *composite) bitmappedBody(n int) ([]byte, error) {
+	bitmap := make([]byte, n)
+	body := make([]byte, 0, 64)
+	fields, indexes, err := c.subfields()
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range indexes {
+		info := fields[i]
+		if info.Field.IsEmpty() && !info.Present {
+			continue
+		}
+		if i < 1 || i > n*8 {
+			return nil, fmt.Errorf("subfield %d: index of bitmapped subfield must be from 1 to %d", i, n*8)
+		}
+		bitmap[(i-1)/8] |= 0x80 >> uint((i-1)%8)
+		d, err := info.bytes()
+		if err != nil {
+			return nil, fmt.Errorf("subfield %d: %w", i, err)
+		}
+		body = append(body, d...)
+	}
+	return append(bitmap, body...), nil
+}
+
+// Load decode composite field from bytes. Nil subfields are allocated. If
+// data ends before the last subfield, the rest of subfields are left empty.
+func (c *composite) Load(raw []byte, encoder, lenEncoder, length int) (int, error) {
+	kind, bitmap := c.options()
+	body, read, err := loadComposite(kind, raw, encoder, lenEncoder, length)
+	if err != nil {
+		return 0, err
+	}
+
+	initStruct(c.value.Type().Elem(), c.value)
+	if bitmap > 0 {
+		return read, c.loadBitmapped(body, bitmap)
+	}
+	if _, err := c.loadBody(body, false); err != nil {
+		return 0, err
+	}
+	return read, nil
+}
+
+// loadComposite extracts body of composite field of kind from raw
+func loadComposite(kind string, raw []byte, encoder, lenEncoder, length int) ([]byte, int, error) {
+	var body []byte
+	var read int
+	var err error
+	switch kind {
+	case "", COMPOSITE_LLLVAR:
+		l := &Lllvar{}
+		read, err = l.Load(raw, encoder, lenEncoder, length)
+		body = l.Value
+	case COMPOSITE_LLVAR:
+		l := &Llvar{}
+		read, err = l.Load(raw, encoder, lenEncoder, length)
+		body = l.Value
+	case COMPOSITE_FIXED:
+		b := NewBinary(nil)
+		read, err = b.Load(raw, encoder, lenEncoder, length)
+		body = b.Value
+	default:
+		return nil, 0, ErrInvalidComposite
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return body, read, nil
+}
+
+// loadBody decodes subfields one after another from body and returns the
+// number of bytes read. Unless full, the rest of subfields is left empty
+// when body ends.
+func (c *composite) loadBody(body []byte, full bool) (int, error) {
+	fields, indexes, err := c.subfields()
+	if err != nil {
+		return 0, err
+	}
+	start := 0
+	for _, i := range indexes {
+		if start >= len(body) {
+			if full {
+				return 0, fmt.Errorf("subfield %d: %w", i, ErrBadRaw)
+			}
+			break
+		}
+		info := fields[i]
+		l, err := info.load(body[start:])
+		if err != nil {
+			return 0, fmt.Errorf("subfield %d: %w", i, err)
+		}
+		start += l
+	}
+	return start, nil
+}
+
+// loadBitmapped decodes subfields marked in bitmap of n bytes at the start
+// of body
+func (c *composite) loadBitmapped(body []byte, n int) error {
+	if len(body) < n {
+		return ErrBadRaw
+	}
+	fields, _, err := c.subfields()
+	if err != nil {
+		return err
+	}
+	start := n
+	for i := 1; i <= n*8; i++ {
+		if body[(i-1)/8]&(0x80>>uint((i-1)%8)) == 0 {
+			continue
+		}
+		info, ok := fields[i]
+		if !ok {
+			return fmt.Errorf("subfield %d not defined", i)
+		}
+		l, err := info.load(body[start:])
+		if err != nil {
+			return fmt.Errorf("subfield %d: %w", i, err)
+		}
+		start += l
+	}
+	return nil
+}
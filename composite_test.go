@@ -0,0 +1,121 @@
+package iso8583
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type testAdditionalData struct {
+	S1 *Numeric      `field:"1" length:"2"`
+	S2 *Alphanumeric `field:"2" length:"4"`
+	S3 *Llvar        `field:"3" length:"20"`
+}
+
+func TestComposite(t *testing.T) {
+	type test struct {
+		F3  *Numeric            `field:"3" length:"6"`
+		F48 *testAdditionalData `field:"48" length:"999"`
+		F62 *testAdditionalData `field:"62" length:"99" encode:"bcd,ascii" composite:"llvar"`
+		F63 *testAdditionalData `field:"63" length:"8" composite:"fixed"`
+	}
+
+	data := &test{
+		F3: NewNumeric("000000"),
+		F48: &testAdditionalData{
+			S1: NewNumeric("7"),
+			S2: NewAlphanumeric("ab"),
+			S3: NewLlvar([]byte("hello")),
+		},
+		F62: &testAdditionalData{
+			S1: NewNumeric("12"),
+			S3: NewLlvar([]byte("x")),
+		},
+		F63: &testAdditionalData{
+			S1: NewNumeric("1"),
+			S2: NewAlphanumeric("abcd"),
+		},
+	}
+
+	iso := NewMessage("0100", data)
+	res, err := iso.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "013"+"07"+"  ab"+"05hello", string(res[4+8+6:4+8+6+16]))
+
+	parser := Parser{}
+	parser.Register("0100", &test{})
+	iso2, err := parser.Parse(res)
+	assert.Nil(t, err)
+	assert.Equal(t, "07", iso2.Data.(*test).F48.S1.Value)
+	assert.Equal(t, "  ab", iso2.Data.(*test).F48.S2.Value)
+	assert.Equal(t, data.F48.S3, iso2.Data.(*test).F48.S3)
+	assert.Equal(t, "12", iso2.Data.(*test).F62.S1.Value)
+	assert.Equal(t, "    ", iso2.Data.(*test).F62.S2.Value)
+	assert.Equal(t, []byte("x"), iso2.Data.(*test).F62.S3.Value)
+	assert.Equal(t, data.F63.S2, iso2.Data.(*test).F63.S2)
+	assert.Equal(t, 0, len(iso2.Data.(*test).F63.S3.Value))
+
+	// empty composite is not packed
+	iso = NewMessage("0100", &test{F3: NewNumeric("1"), F48: &testAdditionalData{}})
+	res, err = iso.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, 4+8+6, len(res))
+
+	type test2 struct {
+		F48 *testAdditionalData `field:"48" length:"999" composite:"bitmap"`
+	}
+
+	iso = NewMessage("0100", &test2{data.F48})
+	_, err = iso.Bytes()
+	assert.EqualError(t, err, "invalid composite kind")
+
+	data.F48.S1.Value = "123"
+	iso = NewMessage("0100", data)
+	_, err = iso.Bytes()
+	assert.EqualError(t, err, "subfield 1: length of value is longer than definition; type=Numeric, def_len=2, len=3")
+}
+
+func TestBitmappedComposite(t *testing.T) {
+	type private struct {
+		S1  *Numeric      `field:"1" length:"2"`
+		S2  *Alphanumeric `field:"2" length:"4"`
+		S10 *Llvar        `field:"10" length:"20"`
+	}
+	type test struct {
+		F47 *private `field:"47" length:"999" composite:"lllvar,bitmap2"`
+	}
+	data := &test{&private{S2: NewAlphanumeric("ab"), S10: NewLlvar([]byte("hi"))}}
+	res, err := NewMessage("0100", data).Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "010"+"\x40\x40"+"  ab"+"02hi", string(res[12:]))
+
+	// bitmap has 8 bytes by default
+	type test8 struct {
+		F47 *private `field:"47" length:"999" composite:"lllvar,bitmap"`
+	}
+	res8, err := NewMessage("0100", &test8{data.F47}).Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "016"+"\x40\x40\x00\x00\x00\x00\x00\x00"+"  ab"+"02hi", string(res8[12:]))
+
+	type test1 struct {
+		F47 *private `field:"47" length:"999" composite:"lllvar,bitmap1"`
+	}
+	_, err = NewMessage("0100", &test1{data.F47}).Bytes()
+	assert.EqualError(t, err, "subfield 10: index of bitmapped subfield must be from 1 to 8")
+
+	iso := NewMessage("", &test{&private{}})
+	assert.Nil(t, iso.Load(res))
+	loaded := iso.Data.(*test).F47
+	assert.True(t, loaded.S1.IsEmpty())
+	assert.Equal(t, "  ab", loaded.S2.Value)
+	assert.Equal(t, []byte("hi"), loaded.S10.Value)
+
+	res[15] |= 0x20 // subfield 3
+	assert.EqualError(t, iso.Load(res), "field 47: subfield 3 not defined")
+
+	type bad struct {
+		F47 *private `field:"47" length:"999" composite:"lllvar,bits"`
+	}
+	_, err = NewMessage("0100", &bad{data.F47}).Bytes()
+	assert.ErrorIs(t, err, ErrInvalidTag)
+	assert.EqualError(t, err, "field 47: invalid tag: unknown composite option: bits")
+}
@@ -0,0 +1,128 @@
+package iso8583
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+)
+
+// Compression of values of Llvar and Lllvar fields, set by compress tag
+// (for ex. `compress:"zlib"`) or Message.Compression. Value is compressed
+// before its length is calculated on packing and decompressed after it is
+// extracted on loading, for hosts which compress big private data fields.
+const (
+	COMPRESS_ZLIB string = "zlib"
+	COMPRESS_GZIP string = "gzip"
+)
+
+const TAG_COMPRESS string = "compress"
+
+const (
+	ERR_COMPRESSED_TYPE        string = "compression requires Llvar or Lllvar field"
+	ERR_DECOMPRESSED_TOO_LARGE string = "decompressed value is too large"
+)
+
+var (
+	ErrCompressedType       = errors.New(ERR_COMPRESSED_TYPE)
+	ErrDecompressedTooLarge = errors.New(ERR_DECOMPRESSED_TOO_LARGE)
+)
+
+// DefaultMaxDecompressed limits size of decompressed value of message
+// without MaxSize
+const DefaultMaxDecompressed = 1 << 20
+
+// parseCompressStr checks compression str. It panics if it is unknown.
+func parseCompressStr(str string) string {
+	switch str {
+	case "", COMPRESS_ZLIB, COMPRESS_GZIP:
+		return str
+	}
+	panic("unknown compression: " + str)
+}
+
+// compressed returns copy of f which value is compressed. Value of the
+// original field is not changed.
+func (f *fieldInfo) compressed() (*fieldInfo, error) {
+	if f.Compress == "" {
+		return f, nil
+	}
+	var v []byte
+	switch t := f.Field.(type) {
+	case *Llvar:
+		v = t.Value
+	case *Lllvar:
+		v = t.Value
+	default:
+		return nil, ErrCompressedType
+	}
+
+	buf := &bytes.Buffer{}
+	var w io.WriteCloser
+	if f.Compress == COMPRESS_GZIP {
+		w = gzip.NewWriter(buf)
+	} else {
+		w = zlib.NewWriter(buf)
+	}
+	if _, err := w.Write(v); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	ret := *f
+	if _, ok := f.Field.(*Llvar); ok {
+		ret.Field = &Llvar{buf.Bytes()}
+	} else {
+		ret.Field = &Lllvar{buf.Bytes()}
+	}
+	return &ret, nil
+}
+
+// decompress decompresses loaded value of f, which must not be longer than
+// max bytes
+func (f *fieldInfo) decompress(max int) error {
+	if f.Compress == "" {
+		return nil
+	}
+	var v *[]byte
+	switch t := f.Field.(type) {
+	case *Llvar:
+		v = &t.Value
+	case *Lllvar:
+		v = &t.Value
+	default:
+		return ErrCompressedType
+	}
+
+	var r io.ReadCloser
+	var err error
+	if f.Compress == COMPRESS_GZIP {
+		r, err = gzip.NewReader(bytes.NewReader(*v))
+	} else {
+		r, err = zlib.NewReader(bytes.NewReader(*v))
+	}
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	d, err := io.ReadAll(io.LimitReader(r, int64(max)+1))
+	if err != nil {
+		return err
+	}
+	if len(d) > max {
+		return ErrDecompressedTooLarge
+	}
+	*v = d
+	return nil
+}
+
+// maxDecompressed returns limit of decompressed values of m
+func (m *Message) maxDecompressed() int {
+	if m.MaxSize > 0 {
+		return m.MaxSize
+	}
+	return DefaultMaxDecompressed
+}
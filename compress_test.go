@@ -0,0 +1,86 @@
+package iso8583
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+func TestCompress(t *testing.T) {
+	type test struct {
+		F11  *Numeric `field:"11" length:"6"`
+		F126 *Lllvar  `field:"126" length:"999" compress:"gzip"`
+		F127 *Lllvar  `field:"127" length:"999" compress:"zlib"`
+	}
+	blob := bytes.Repeat([]byte("private data "), 200)
+	data := &test{
+		F11:  NewNumeric("000001"),
+		F126: NewLllvar([]byte("small")),
+		F127: NewLllvar(blob),
+	}
+	raw, err := NewMessage("0100", data).Bytes()
+	assert.Nil(t, err)
+	assert.True(t, len(raw) < 999)
+	// original value is not changed
+	assert.Equal(t, blob, data.F127.Value)
+
+	p := &Parser{}
+	p.Register("0100", &test{})
+	parsed, err := p.Parse(raw)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("small"), parsed.Data.(*test).F126.Value)
+	assert.Equal(t, blob, parsed.Data.(*test).F127.Value)
+
+	// wire value is zlib stream
+	plain := &Parser{Compression: map[int]string{127: ""}}
+	type wire struct {
+		F11  *Numeric `field:"11" length:"6"`
+		F126 *Lllvar  `field:"126" length:"999"`
+		F127 *Lllvar  `field:"127" length:"999"`
+	}
+	plain.Register("0100", &wire{})
+	parsed, err = plain.Parse(raw)
+	assert.Nil(t, err)
+	r, err := zlib.NewReader(bytes.NewReader(parsed.Data.(*wire).F127.Value))
+	assert.Nil(t, err)
+	d, err := io.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Equal(t, blob, d)
+
+	// Compression overrides tags
+	msg := NewMessage("0100", &wire{F127: NewLllvar(blob)})
+	msg.Compression = map[int]string{127: COMPRESS_ZLIB}
+	raw2, err := msg.Bytes()
+	assert.Nil(t, err)
+	assert.True(t, len(raw2) < 999)
+
+	// uncompressed value is rejected, field 127 is the last one
+	head := len(raw) - len(parsed.Data.(*wire).F127.Value) - 3
+	_, err = p.Parse(append(raw[:head:head], "004abcd"...))
+	assert.EqualError(t, err, "field 127: zlib: invalid header")
+
+	type bad struct {
+		F48 *Alphanumeric `field:"48" length:"10" compress:"zlib"`
+	}
+	_, err = NewMessage("0100", &bad{NewAlphanumeric("x")}).Bytes()
+	assert.True(t, errors.Is(err, ErrCompressedType))
+
+	// MaxLengths limits compressed value as it is sent
+	msg = NewMessage("0100", &test{F127: NewLllvar(blob)})
+	msg.MaxLengths = map[int]int{127: 100}
+	_, err = msg.Bytes()
+	assert.Nil(t, err)
+	msg.MaxLengths = map[int]int{127: 10}
+	_, err = msg.Bytes()
+	assert.True(t, errors.Is(err, ErrValueTooLong))
+
+	// decompressed value is limited by MaxSize
+	limited := &Parser{MaxSize: 1000}
+	limited.Register("0100", &test{})
+	_, err = limited.Parse(raw)
+	assert.EqualError(t, err, "field 127: "+ERR_DECOMPRESSED_TOO_LARGE)
+	assert.True(t, errors.Is(err, ErrDecompressedTooLarge))
+}
@@ -0,0 +1,218 @@
+// Package conformance runs scenarios of a specification (mandatory fields
+// of responses, echo test, reversal matching) against server
+// implementation and reports which of them pass, so the server can be
+// self-tested before certification. Requests are sent by any client, for
+// ex. Correlator.Send over a connection to the server.
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/ideazxy/iso8583"
+)
+
+// Runner runs scenarios one after another
+type Runner struct {
+	// Send sends request to the server and returns its response
+	Send func(ctx context.Context, req *iso8583.Message) (*iso8583.Message, error)
+	// Stan generates STANs of requests, sequence starts from 000001 if nil
+	Stan *iso8583.StanGenerator
+	// Timeout limits each scenario, 0 means no limit
+	Timeout time.Duration
+}
+
+// Scenario is a named check, Run returns error if the server fails it
+type Scenario struct {
+	Name string
+	Run  func(ctx context.Context, r *Runner) error
+}
+
+// Result is result of a scenario, Err is nil if it passed
+type Result struct {
+	Name     string
+	Err      error
+	Duration time.Duration
+}
+
+// Report contains results of scenarios in order they were run
+type Report struct {
+	Results []Result
+}
+
+// Passed checks that all scenarios passed
+func (r *Report) Passed() bool {
+	return len(r.Failed()) == 0
+}
+
+// Failed returns results of scenarios which failed
+func (r *Report) Failed() []Result {
+	var failed []Result
+	for _, res := range r.Results {
+		if res.Err != nil {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// String returns report with a line per scenario and a summary line
+func (r *Report) String() string {
+	var b strings.Builder
+	for _, res := range r.Results {
+		if res.Err != nil {
+			fmt.Fprintf(&b, "FAIL %s (%s): %v\n", res.Name, res.Duration, res.Err)
+		} else {
+			fmt.Fprintf(&b, "PASS %s (%s)\n", res.Name, res.Duration)
+		}
+	}
+	fmt.Fprintf(&b, "%d passed, %d failed\n", len(r.Results)-len(r.Failed()), len(r.Failed()))
+	return b.String()
+}
+
+// Run runs scenarios and returns their report. Panic of a scenario fails
+// it, following scenarios are still run.
+func (r *Runner) Run(ctx context.Context, scenarios ...Scenario) *Report {
+	if r.Stan == nil {
+		r.Stan = &iso8583.StanGenerator{}
+	}
+	report := &Report{}
+	for _, s := range scenarios {
+		start := time.Now()
+		err := r.run(ctx, s)
+		report.Results = append(report.Results, Result{s.Name, err, time.Since(start)})
+	}
+	return report
+}
+
+// run runs scenario s
+func (r *Runner) run(ctx context.Context, s Scenario) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = errors.New("Critical error:" + fmt.Sprint(rec))
+		}
+	}()
+
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+	return s.Run(ctx, r)
+}
+
+// Exchange sends a copy of req with next STAN and returns the sent request
+// and its response. It checks that the response has MTI of response to req
+// and the same STAN.
+func (r *Runner) Exchange(ctx context.Context, req *iso8583.Message) (sent, resp *iso8583.Message, err error) {
+	sent = req.Clone()
+	stan, err := r.Stan.Stamp(sent, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err = r.Send(ctx, sent)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp == nil {
+		return nil, nil, fmt.Errorf("no response to %s", sent.Mti)
+	}
+	if mti := responseMti(sent.Mti); resp.Mti != mti {
+		return nil, nil, fmt.Errorf("response MTI is %s, expected %s", resp.Mti, mti)
+	}
+	if s, err := resp.GetString(11); err != nil || s != stan {
+		return nil, nil, fmt.Errorf("response STAN is %q, expected %s", s, stan)
+	}
+	return sent, resp, nil
+}
+
+// responseMti returns MTI of response to request mti
+func responseMti(mti string) string {
+	if len(mti) != 4 {
+		return ""
+	}
+	return mti[:2] + string(mti[2]+1) + mti[3:]
+}
+
+// approved checks that response code (field 39) of resp is 00
+func approved(resp *iso8583.Message) error {
+	code, err := resp.GetString(39)
+	if err != nil {
+		return err
+	}
+	if code != "00" {
+		return fmt.Errorf("response code is %q, expected 00", code)
+	}
+	return nil
+}
+
+// Echo is scenario which sends echo test (0800 with network management
+// code 301) and expects approved response
+func Echo() Scenario {
+	return Scenario{"echo test", func(ctx context.Context, r *Runner) error {
+		_, resp, err := r.Exchange(ctx, iso8583.NewEcho("", time.Now()))
+		if err != nil {
+			return err
+		}
+		return approved(resp)
+	}}
+}
+
+// MandatoryFields is scenario which sends req and checks its response
+// against mti and required tags of response type (see
+// Message.ValidateFor)
+func MandatoryFields(req *iso8583.Message) Scenario {
+	return Scenario{"mandatory fields of " + responseMti(req.Mti), func(ctx context.Context, r *Runner) error {
+		_, resp, err := r.Exchange(ctx, req)
+		if err != nil {
+			return err
+		}
+		return resp.ValidateFor(resp.Mti)
+	}}
+}
+
+// Reversal is scenario which sends authorization or financial request
+// req, then its reversal built by NewReversal, and expects approved
+// response to the reversal. Original data elements (field 90) of the
+// response must match the reversal if the response has them.
+func Reversal(req *iso8583.Message) Scenario {
+	return Scenario{"reversal of " + req.Mti, func(ctx context.Context, r *Runner) error {
+		sent, _, err := r.Exchange(ctx, req)
+		if err != nil {
+			return err
+		}
+		reversal, err := iso8583.NewReversal(sent)
+		if err != nil {
+			return err
+		}
+		reversal, resp, err := r.Exchange(ctx, reversal)
+		if err != nil {
+			return fmt.Errorf("reversal: %w", err)
+		}
+		if err := approved(resp); err != nil {
+			return fmt.Errorf("reversal: %w", err)
+		}
+		want, err := reversal.GetField(90)
+		if err != nil || want == nil {
+			return nil
+		}
+		if got, err := resp.GetField(90); err == nil && got != nil && !reflect.DeepEqual(got, want) {
+			return fmt.Errorf("reversal: field 90 doesn't match: %v, expected %v", got, want)
+		}
+		return nil
+	}}
+}
+
+// Suite returns echo test, and mandatory fields and reversal scenarios for
+// each of reversible requests
+func Suite(reversible ...*iso8583.Message) []Scenario {
+	scenarios := []Scenario{Echo()}
+	for _, req := range reversible {
+		scenarios = append(scenarios, MandatoryFields(req), Reversal(req))
+	}
+	return scenarios
+}
@@ -0,0 +1,80 @@
+package conformance
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ideazxy/iso8583"
+	"github.com/stretchr/testify/assert"
+)
+
+type auth struct {
+	F2  *iso8583.Llnumeric            `field:"2" length:"19"`
+	F4  *iso8583.Numeric              `field:"4" length:"12"`
+	F7  *iso8583.Numeric              `field:"7" length:"10"`
+	F11 *iso8583.Numeric              `field:"11" length:"6"`
+	F38 *iso8583.Alphanumeric         `field:"38" length:"6" mti:"0110:C"`
+	F39 *iso8583.Alphanumeric         `field:"39" length:"2" mti:"0110:M,0410:M"`
+	F90 *iso8583.OriginalDataElements `field:"90" mti:"0400:M,0410:M"`
+}
+
+// server answers requests, broken drops authorization code of 0110
+func server(broken bool) func(ctx context.Context, req *iso8583.Message) (*iso8583.Message, error) {
+	return func(ctx context.Context, req *iso8583.Message) (*iso8583.Message, error) {
+		if req.Mti == "0800" {
+			return iso8583.NewNetworkManagementResponse(req, "00")
+		}
+		resp := req.Clone()
+		resp.Mti = responseMti(req.Mti)
+		data := resp.Data.(*auth)
+		if !broken {
+			data.F39 = iso8583.NewAlphanumeric("00")
+		}
+		if req.Mti == "0100" {
+			data.F38 = iso8583.NewAlphanumeric("A1B2C3")
+		}
+		return resp, nil
+	}
+}
+
+func TestRunner(t *testing.T) {
+	req := iso8583.NewMessage("0100", &auth{
+		F2: iso8583.NewLlnumeric("4276555555555558"),
+		F4: iso8583.NewNumeric("000000001000"),
+		F7: iso8583.NewNumeric(time.Now().UTC().Format("0102150405")),
+	})
+
+	r := &Runner{Send: server(false), Timeout: time.Second}
+	report := r.Run(context.Background(), Suite(req)...)
+	assert.True(t, report.Passed(), report.String())
+	assert.Equal(t, 3, len(report.Results))
+	assert.Equal(t, "echo test", report.Results[0].Name)
+	assert.Equal(t, "mandatory fields of 0110", report.Results[1].Name)
+	assert.Equal(t, "reversal of 0100", report.Results[2].Name)
+	assert.Equal(t, "000004", r.Stan.Last())
+	assert.Nil(t, req.Data.(*auth).F11)
+
+	r = &Runner{Send: server(true)}
+	report = r.Run(context.Background(), Suite(req)...)
+	assert.False(t, report.Passed())
+	failed := report.Failed()
+	assert.Equal(t, 2, len(failed))
+	assert.EqualError(t, failed[0].Err, "field 39 is mandatory for MTI 0110")
+	assert.EqualError(t, failed[1].Err, `reversal: response code is "", expected 00`)
+	assert.True(t, strings.HasPrefix(report.String(), "PASS echo test ("))
+	assert.True(t, strings.HasSuffix(report.String(), "1 passed, 2 failed\n"))
+
+	// wrong STAN and panic
+	r = &Runner{Send: func(ctx context.Context, req *iso8583.Message) (*iso8583.Message, error) {
+		resp, err := iso8583.NewNetworkManagementResponse(req, "00")
+		resp.Data.(*iso8583.NetworkManagement).F11 = iso8583.NewNumeric("999999")
+		return resp, err
+	}}
+	report = r.Run(context.Background(), Echo(), Scenario{"panic", func(ctx context.Context, r *Runner) error {
+		panic("boom")
+	}})
+	assert.EqualError(t, report.Results[0].Err, `response STAN is "999999", expected 000001`)
+	assert.EqualError(t, report.Results[1].Err, "Critical error:boom")
+}
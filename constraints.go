@@ -0,0 +1,118 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	TAG_MTI string = "mti"
+)
+
+// Field requirements per MTI, set by mti tag (for ex.
+// `mti:"0200:M,0210:C,0400:O"`). Field with mti tag is unexpected in
+// messages with MTIs which are not listed. Field without mti tag is
+// optional for all MTIs.
+const (
+	REQ_MANDATORY   string = "M"
+	REQ_CONDITIONAL string = "C" // presence depends on other data, it is not checked
+	REQ_OPTIONAL    string = "O"
+)
+
+// FieldErrors contains errors of several fields
+type FieldErrors []error
+
+func (e FieldErrors) Error() string {
+	s := make([]string, len(e))
+	for i, err := range e {
+		s[i] = err.Error()
+	}
+	return strings.Join(s, "; ")
+}
+
+// Unwrap returns the errors, so errors.Is and errors.As match any of them
+func (e FieldErrors) Unwrap() []error {
+	return e
+}
+
+// parseMtiTag parses mti tag into map of MTI to requirement
+func parseMtiTag(tag string) map[string]string {
+	ret := make(map[string]string)
+	for _, item := range strings.Split(tag, ",") {
+		parts := strings.Split(item, ":")
+		if len(parts) != 2 || len(parts[0]) != 4 {
+			panic("bad mti tag: " + tag)
+		}
+		switch parts[1] {
+		case REQ_MANDATORY, REQ_CONDITIONAL, REQ_OPTIONAL:
+			ret[parts[0]] = parts[1]
+		default:
+			panic("bad mti tag: " + tag)
+		}
+	}
+	return ret
+}
+
+// ValidateFor checks fields of Message against mti and required tags for
+// MTI mti. It reports all missing mandatory and unexpected fields at once
+// as FieldErrors.
+func (m *Message) ValidateFor(mti string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("Critical error:" + fmt.Sprint(r))
+		}
+	}()
+
+	v := reflect.Indirect(reflect.ValueOf(m.Data))
+	if v.Kind() != reflect.Struct {
+		panic("data must be a struct")
+	}
+
+	fields, err := m.fields()
+	if err != nil {
+		return err
+	}
+	found := make(map[int]error)
+	walkFields(v, func(sf reflect.StructField, fv reflect.Value) {
+		index, _, required := parseFieldTag(sf.Tag.Get(TAG_FIELD))
+		info, isSet := fields[index]
+		isSet = isSet && (!info.Field.IsEmpty() || info.Present)
+
+		req := REQ_OPTIONAL
+		if required {
+			req = REQ_MANDATORY
+		}
+		if tag := sf.Tag.Get(TAG_MTI); tag != "" {
+			r, ok := parseMtiTag(tag)[mti]
+			if !ok {
+				if isSet {
+					found[index] = fmt.Errorf("field %d is unexpected for MTI %s", index, mti)
+				}
+				return
+			}
+			if !required {
+				req = r
+			}
+		}
+		if req == REQ_MANDATORY && !isSet {
+			found[index] = fmt.Errorf("field %d is mandatory for MTI %s", index, mti)
+		}
+	})
+
+	if len(found) == 0 {
+		return nil
+	}
+	indexes := make([]int, 0, len(found))
+	for i := range found {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	errs := make(FieldErrors, len(indexes))
+	for j, i := range indexes {
+		errs[j] = found[i]
+	}
+	return errs
+}
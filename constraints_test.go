@@ -0,0 +1,43 @@
+package iso8583
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestValidateFor(t *testing.T) {
+	type test struct {
+		F3  *Numeric      `field:"3" length:"6" mti:"0200:M,0210:M"`
+		F4  *Numeric      `field:"4" length:"12" mti:"0200:M,0210:C"`
+		F11 *Numeric      `field:"11,required" length:"6"`
+		F38 *Alphanumeric `field:"38" length:"6" mti:"0210:C"`
+		F39 *Alphanumeric `field:"39" length:"2" mti:"0210:M"`
+		F41 *Alphanumeric `field:"41" length:"8"`
+	}
+
+	data := &test{
+		F3:  NewNumeric("000000"),
+		F4:  NewNumeric("100"),
+		F11: NewNumeric("1"),
+	}
+	iso := NewMessage("0200", data)
+
+	assert.Nil(t, iso.ValidateFor("0200"))
+	assert.EqualError(t, iso.ValidateFor("0210"), "field 39 is mandatory for MTI 0210")
+
+	data.F3 = NewNumeric("")
+	data.F11 = nil
+	data.F38 = NewAlphanumeric("123456")
+	data.F41 = NewAlphanumeric("TERM0001")
+
+	err := iso.ValidateFor("0200")
+	assert.EqualError(t, err, "field 3 is mandatory for MTI 0200; field 11 is mandatory for MTI 0200; field 38 is unexpected for MTI 0200")
+	assert.Equal(t, 3, len(err.(FieldErrors)))
+
+	type test2 struct {
+		F3 *Numeric `field:"3" length:"6" mti:"0200:X"`
+	}
+
+	iso = NewMessage("0200", &test2{})
+	assert.EqualError(t, iso.ValidateFor("0200"), "Critical error:bad mti tag: 0200:X")
+}
@@ -0,0 +1,70 @@
+package iso8583
+
+import (
+	"context"
+	"fmt"
+)
+
+// BytesContext marshall Message to bytes if ctx is not done yet. The
+// operation is traced as child of span in ctx, see SetTracer.
+func (m *Message) BytesContext(ctx context.Context) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	_, span := startSpan(ctx, OpPack)
+	ret, err := m.Bytes()
+	endSpan(span, m, len(ret), err)
+	return ret, err
+}
+
+// LoadContext unmarshall Message from bytes if ctx is not done yet. The
+// operation is traced as child of span in ctx, see SetTracer.
+func (m *Message) LoadContext(ctx context.Context, raw []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	_, span := startSpan(ctx, OpUnpack)
+	err := m.Load(raw)
+	endSpan(span, m, len(raw), err)
+	return err
+}
+
+// BytesBatch marshall messages one by one, checking ctx before each one.
+// Returned slice holds bytes of messages completed before cancellation or
+// first error, so its length is the number of completed messages.
+func BytesBatch(ctx context.Context, msgs []*Message) ([][]byte, error) {
+	ret := make([][]byte, 0, len(msgs))
+	for i, msg := range msgs {
+		b, err := msg.BytesContext(ctx)
+		if err != nil {
+			return ret, batchError(i, err)
+		}
+		ret = append(ret, b)
+	}
+	return ret, nil
+}
+
+// ParseBatch parses messages one by one, checking ctx before each one.
+// Returned slice holds messages completed before cancellation or first
+// error, so its length is the number of completed messages.
+func (p *Parser) ParseBatch(ctx context.Context, raws [][]byte) ([]*Message, error) {
+	ret := make([]*Message, 0, len(raws))
+	for i, raw := range raws {
+		if err := ctx.Err(); err != nil {
+			return ret, batchError(i, err)
+		}
+		msg, err := p.Parse(raw)
+		if err != nil {
+			return ret, batchError(i, err)
+		}
+		ret = append(ret, msg)
+	}
+	return ret, nil
+}
+
+func batchError(i int, err error) error {
+	if err == context.Canceled || err == context.DeadlineExceeded {
+		return err
+	}
+	return fmt.Errorf("message %d: %w", i, err)
+}
@@ -0,0 +1,44 @@
+package iso8583
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBatchContext(t *testing.T) {
+	msgs := []*Message{
+		NewMessage("0200", &testTransaction{F11: NewNumeric("000001")}),
+		NewMessage("0200", &testTransaction{F11: NewNumeric("000002")}),
+		NewMessage("0200", &testTransaction{F11: NewNumeric("1234567")}),
+	}
+
+	res, err := BytesBatch(context.Background(), msgs)
+	assert.EqualError(t, err, "message 2: length of value is longer than definition; type=Numeric, def_len=6, len=7")
+	assert.Equal(t, 2, len(res))
+
+	parser := Parser{}
+	parser.Register("0200", &testTransaction{})
+
+	parsed, err := parser.ParseBatch(context.Background(), res)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(parsed))
+	assert.Equal(t, "000002", parsed[1].Data.(*testTransaction).F11.Value)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	res, err = BytesBatch(ctx, msgs)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 0, len(res))
+
+	parsed, err = parser.ParseBatch(ctx, [][]byte{nil})
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 0, len(parsed))
+
+	_, err = msgs[0].BytesContext(ctx)
+	assert.Equal(t, context.Canceled, err)
+
+	err = msgs[0].LoadContext(ctx, nil)
+	assert.Equal(t, context.Canceled, err)
+}
@@ -0,0 +1,248 @@
+package iso8583
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	ERR_NOT_REQUEST       string = "message is not a request"
+	ERR_DUPLICATE_STAN    string = "request with the same STAN is outstanding"
+	ERR_TOO_MANY_INFLIGHT string = "too many requests in flight"
+)
+
+var (
+	ErrNotRequest      = errors.New(ERR_NOT_REQUEST)
+	ErrDuplicateStan   = errors.New(ERR_DUPLICATE_STAN)
+	ErrTooManyInflight = errors.New(ERR_TOO_MANY_INFLIGHT)
+)
+
+// responseMti returns MTI of response to request mti (xx0x, xx2x, xx4x),
+// or false if mti is not a request
+func responseMti(mti string) (string, bool) {
+	if len(mti) != 4 || (mti[2] != '0' && mti[2] != '2' && mti[2] != '4') {
+		return "", false
+	}
+	return mti[:2] + string(mti[2]+1) + mti[3:], true
+}
+
+// Correlator pairs responses read from a connection with outstanding
+// requests by MTI and STAN (field 11). Messages which don't match any
+// outstanding request, like network management requests and advices from
+// the host or responses which came after timeout, are passed to
+// Unsolicited, so they are neither lost nor mis-correlated. It is safe for
+// concurrent use.
+type Correlator struct {
+	// Unsolicited is called by Deliver with unmatched messages, optional
+	Unsolicited func(msg *Message)
+	// Timeout limits waiting for response, 0 means waiting until ctx of
+	// Send is done
+	Timeout time.Duration
+	// Timeouts override Timeout by MTI (for ex. "0100") or its class (for
+	// ex. CLASS_NETWORK_MANAGEMENT). Negative timeout makes Send return
+	// nil response right after writing, for ex. for advices and reversals
+	// which are resent from SAF anyway.
+	Timeouts map[string]time.Duration
+	// Late is called by Deliver instead of Unsolicited with response which
+	// came after Send of its request gave up, optional
+	Late func(req, resp *Message)
+	// LateTTL limits keeping of abandoned requests for Late, default is
+	// DefaultLateTTL. Responses to older requests are unsolicited.
+	LateTTL time.Duration
+	// MaxInflight limits number of requests waiting for response, Send
+	// and SendAsync fail with ErrTooManyInflight when the window is full.
+	// 0 means no limit.
+	MaxInflight int
+
+	mu      sync.Mutex
+	pending map[string]chan *Message
+	late    map[string]lateRequest // abandoned requests until STAN is reused or TTL
+}
+
+// DefaultLateTTL is default LateTTL of Correlator
+const DefaultLateTTL = 5 * time.Minute
+
+// lateRequest is abandoned request waiting for late response
+type lateRequest struct {
+	req     *Message
+	expires time.Time
+}
+
+// addLate keeps abandoned req with key and evicts expired requests. It is
+// called with c.mu held.
+func (c *Correlator) addLate(key string, req *Message) {
+	now := time.Now()
+	for k, l := range c.late {
+		if now.After(l.expires) {
+			delete(c.late, k)
+		}
+	}
+	ttl := c.LateTTL
+	if ttl <= 0 {
+		ttl = DefaultLateTTL
+	}
+	if c.late == nil {
+		c.late = make(map[string]lateRequest)
+	}
+	c.late[key] = lateRequest{req, now.Add(ttl)}
+}
+
+// timeout returns response timeout of mti
+func (c *Correlator) timeout(mti string) time.Duration {
+	if t, ok := c.Timeouts[mti]; ok {
+		return t
+	}
+	if t, ok := c.Timeouts[MtiClass(mti)]; ok {
+		return t
+	}
+	return c.Timeout
+}
+
+// correlationKey returns key of message with MTI mti
+func correlationKey(mti string, msg *Message) (string, error) {
+	key, err := KeyOf(msg)
+	if err != nil {
+		return "", err
+	}
+	return mti + "/" + key.Stan, nil
+}
+
+// Response is result of request sent by SendAsync
+type Response struct {
+	Message *Message
+	Err     error
+}
+
+// Send writes req with write and waits until its response is delivered by
+// Deliver, timeout of req MTI expires or ctx is done
+func (c *Correlator) Send(ctx context.Context, req *Message, write func(ctx context.Context, req *Message) error) (*Message, error) {
+	key, ch, err := c.register(req)
+	if err != nil {
+		return nil, err
+	}
+	return c.wait(ctx, req, key, ch, write)
+}
+
+// SendAsync is Send which doesn't wait for response. Request is registered
+// before return, so ErrTooManyInflight and other errors of registration
+// are returned here; result of write and response are sent to the
+// returned channel.
+func (c *Correlator) SendAsync(ctx context.Context, req *Message, write func(ctx context.Context, req *Message) error) (<-chan Response, error) {
+	key, ch, err := c.register(req)
+	if err != nil {
+		return nil, err
+	}
+	res := make(chan Response, 1)
+	go func() {
+		resp, err := c.wait(ctx, req, key, ch, write)
+		res <- Response{resp, err}
+	}()
+	return res, nil
+}
+
+// register adds req to pending requests and returns its key and channel
+// of response
+func (c *Correlator) register(req *Message) (string, chan *Message, error) {
+	mti, ok := responseMti(req.Mti)
+	if !ok {
+		return "", nil, ErrNotRequest
+	}
+	key, err := correlationKey(mti, req)
+	if err != nil {
+		return "", nil, err
+	}
+	ch := make(chan *Message, 1)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.pending[key]; ok {
+		return "", nil, ErrDuplicateStan
+	}
+	if c.MaxInflight > 0 && len(c.pending) >= c.MaxInflight {
+		return "", nil, ErrTooManyInflight
+	}
+	if c.pending == nil {
+		c.pending = make(map[string]chan *Message)
+	}
+	c.pending[key] = ch
+	delete(c.late, key)
+	return key, ch, nil
+}
+
+// wait writes registered req and waits for its response
+func (c *Correlator) wait(ctx context.Context, req *Message, key string, ch chan *Message, write func(ctx context.Context, req *Message) error) (*Message, error) {
+	abandoned := false
+	defer func() {
+		c.mu.Lock()
+		if c.pending[key] == ch {
+			delete(c.pending, key)
+			if abandoned && c.Late != nil {
+				c.addLate(key, req)
+			}
+		}
+		c.mu.Unlock()
+	}()
+
+	timeout := c.timeout(req.Mti)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if err := write(ctx, req); err != nil {
+		return nil, err
+	}
+	if timeout < 0 {
+		abandoned = true
+		return nil, nil
+	}
+	select {
+	case resp := <-ch:
+		if resp.CorrelationID == "" {
+			resp.CorrelationID = req.CorrelationID
+		}
+		return resp, nil
+	case <-ctx.Done():
+		abandoned = true
+		return nil, ctx.Err()
+	}
+}
+
+// Deliver passes message read from connection to the request waiting for
+// it, to Late if the request is abandoned, or to Unsolicited if there is
+// no such request. It reports whether msg matched a waiting request.
+func (c *Correlator) Deliver(msg *Message) bool {
+	if key, err := correlationKey(msg.Mti, msg); err == nil {
+		c.mu.Lock()
+		ch, ok := c.pending[key]
+		if ok {
+			delete(c.pending, key)
+		}
+		l, late := c.late[key]
+		if late {
+			delete(c.late, key)
+			late = !time.Now().After(l.expires)
+		}
+		c.mu.Unlock()
+		if ok {
+			ch <- msg
+			return true
+		}
+		if late {
+			c.Late(l.req, msg)
+			return false
+		}
+	}
+	if c.Unsolicited != nil {
+		c.Unsolicited(msg)
+	}
+	return false
+}
+
+// Outstanding returns number of requests waiting for response
+func (c *Correlator) Outstanding() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.pending)
+}
@@ -0,0 +1,156 @@
+package iso8583
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCorrelator(t *testing.T) {
+	var unsolicited []*Message
+	c := &Correlator{Unsolicited: func(msg *Message) {
+		unsolicited = append(unsolicited, msg)
+	}}
+	ctx := context.Background()
+	req := NewMessage("0200", &testTransaction{F11: NewNumeric("000042")})
+
+	resp, err := c.Send(ctx, req, func(ctx context.Context, req *Message) error {
+		// host sends sign-on request before the response
+		assert.False(t, c.Deliver(NewSignOn("000042", time.Now())))
+		assert.True(t, c.Deliver(NewMessage("0210", &testTransaction{F11: NewNumeric("000042")})))
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "0210", resp.Mti)
+	assert.Equal(t, 1, len(unsolicited))
+	assert.Equal(t, "0800", unsolicited[0].Mti)
+	assert.Equal(t, 0, c.Outstanding())
+
+	// late response is unsolicited
+	timeout, cancel := context.WithTimeout(ctx, time.Millisecond)
+	defer cancel()
+	_, err = c.Send(timeout, req, func(ctx context.Context, req *Message) error {
+		_, err := c.Send(ctx, req, nil)
+		assert.Equal(t, ErrDuplicateStan, err)
+		return nil
+	})
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.False(t, c.Deliver(NewMessage("0210", &testTransaction{F11: NewNumeric("000042")})))
+	assert.Equal(t, 2, len(unsolicited))
+
+	_, err = c.Send(ctx, NewMessage("0210", &testTransaction{F11: NewNumeric("1")}), nil)
+	assert.Equal(t, ErrNotRequest, err)
+	_, err = c.Send(ctx, NewMessage("0200", &testTransaction{}), nil)
+	assert.Equal(t, ErrMissingStan, err)
+}
+
+func TestCorrelatorTimeouts(t *testing.T) {
+	var late [][2]*Message
+	c := &Correlator{
+		Timeout:  time.Hour,
+		Timeouts: map[string]time.Duration{"0200": time.Millisecond, CLASS_REVERSAL: -1},
+		Late: func(req, resp *Message) {
+			late = append(late, [2]*Message{req, resp})
+		},
+	}
+	assert.Equal(t, time.Millisecond, c.timeout("0200"))
+	assert.Equal(t, time.Duration(-1), c.timeout("0420"))
+	assert.Equal(t, time.Hour, c.timeout("0800"))
+
+	ctx := context.Background()
+	write := func(ctx context.Context, req *Message) error { return nil }
+	req := NewMessage("0200", &testTransaction{F11: NewNumeric("000001")})
+	_, err := c.Send(ctx, req, write)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.False(t, c.Deliver(NewMessage("0210", &testTransaction{F11: NewNumeric("000001")})))
+
+	reversal := NewMessage("0400", &testTransaction{F11: NewNumeric("000002")})
+	resp, err := c.Send(ctx, reversal, write)
+	assert.Nil(t, err)
+	assert.Nil(t, resp)
+	assert.False(t, c.Deliver(NewMessage("0410", &testTransaction{F11: NewNumeric("000002")})))
+
+	assert.Equal(t, 2, len(late))
+	assert.Equal(t, req, late[0][0])
+	assert.Equal(t, "0210", late[0][1].Mti)
+	assert.Equal(t, reversal, late[1][0])
+	assert.Equal(t, 0, len(c.late))
+}
+
+func TestCorrelatorSendAsync(t *testing.T) {
+	c := &Correlator{MaxInflight: 2}
+	ctx := context.Background()
+	written := make(chan *Message, 2)
+	write := func(ctx context.Context, req *Message) error {
+		written <- req
+		return nil
+	}
+	send := func(stan string) (<-chan Response, error) {
+		return c.SendAsync(ctx, NewMessage("0200", &testTransaction{F11: NewNumeric(stan)}), write)
+	}
+
+	r1, err := send("000001")
+	assert.Nil(t, err)
+	r2, err := send("000002")
+	assert.Nil(t, err)
+	_, err = send("000003")
+	assert.Equal(t, ErrTooManyInflight, err)
+	_, err = c.Send(ctx, NewMessage("0200", &testTransaction{F11: NewNumeric("000003")}), write)
+	assert.Equal(t, ErrTooManyInflight, err)
+	assert.Equal(t, 2, c.Outstanding())
+
+	<-written
+	<-written
+	assert.True(t, c.Deliver(NewMessage("0210", &testTransaction{F11: NewNumeric("000002")})))
+	res := <-r2
+	assert.Nil(t, res.Err)
+	assert.Equal(t, "000002", res.Message.Data.(*testTransaction).F11.Value)
+
+	// window has room again
+	r3, err := send("000003")
+	assert.Nil(t, err)
+	<-written
+	assert.True(t, c.Deliver(NewMessage("0210", &testTransaction{F11: NewNumeric("000001")})))
+	assert.Equal(t, "0210", (<-r1).Message.Mti)
+	assert.True(t, c.Deliver(NewMessage("0210", &testTransaction{F11: NewNumeric("000003")})))
+	assert.Nil(t, (<-r3).Err)
+	assert.Equal(t, 0, c.Outstanding())
+
+	_, err = c.SendAsync(ctx, NewMessage("0210", &testTransaction{F11: NewNumeric("1")}), write)
+	assert.Equal(t, ErrNotRequest, err)
+}
+
+func TestCorrelatorLateTTL(t *testing.T) {
+	var late, unsolicited int
+	c := &Correlator{
+		Timeouts:    map[string]time.Duration{CLASS_REVERSAL: -1},
+		Late:        func(req, resp *Message) { late++ },
+		Unsolicited: func(msg *Message) { unsolicited++ },
+		LateTTL:     time.Millisecond,
+	}
+	ctx := context.Background()
+	write := func(ctx context.Context, req *Message) error { return nil }
+	for _, stan := range []string{"000001", "000002"} {
+		_, err := c.Send(ctx, NewMessage("0400", &testTransaction{F11: NewNumeric(stan)}), write)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, 2, len(c.late))
+
+	time.Sleep(5 * time.Millisecond)
+	// expired requests are evicted when another one is abandoned
+	_, err := c.Send(ctx, NewMessage("0400", &testTransaction{F11: NewNumeric("000003")}), write)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(c.late))
+
+	c.Deliver(NewMessage("0410", &testTransaction{F11: NewNumeric("000001")}))
+	assert.Equal(t, 0, late)
+	assert.Equal(t, 1, unsolicited)
+
+	time.Sleep(5 * time.Millisecond)
+	// expired request is not matched
+	c.Deliver(NewMessage("0410", &testTransaction{F11: NewNumeric("000003")}))
+	assert.Equal(t, 0, late)
+	assert.Equal(t, 2, unsolicited)
+	assert.Equal(t, 0, len(c.late))
+}
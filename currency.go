@@ -0,0 +1,204 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	ERR_UNKNOWN_CURRENCY string = "unknown currency"
+	ERR_BAD_AMOUNT       string = "bad amount"
+)
+
+var (
+	ErrUnknownCurrency = errors.New(ERR_UNKNOWN_CURRENCY)
+	ErrBadAmount       = errors.New(ERR_BAD_AMOUNT)
+)
+
+// CurrencyInfo describes ISO 4217 currency
+type CurrencyInfo struct {
+	Numeric  string // numeric code, for ex. "840"
+	Alpha    string // alphabetic code, for ex. "USD"
+	Exponent int    // number of minor unit digits
+}
+
+// currencies is a table of ISO 4217 currencies
+var currencies = []CurrencyInfo{
+	{"784", "AED", 2},
+	{"051", "AMD", 2},
+	{"032", "ARS", 2},
+	{"036", "AUD", 2},
+	{"944", "AZN", 2},
+	{"975", "BGN", 2},
+	{"048", "BHD", 3},
+	{"986", "BRL", 2},
+	{"933", "BYN", 2},
+	{"124", "CAD", 2},
+	{"756", "CHF", 2},
+	{"152", "CLP", 0},
+	{"156", "CNY", 2},
+	{"170", "COP", 2},
+	{"203", "CZK", 2},
+	{"208", "DKK", 2},
+	{"818", "EGP", 2},
+	{"978", "EUR", 2},
+	{"826", "GBP", 2},
+	{"981", "GEL", 2},
+	{"344", "HKD", 2},
+	{"348", "HUF", 2},
+	{"360", "IDR", 2},
+	{"376", "ILS", 2},
+	{"356", "INR", 2},
+	{"368", "IQD", 3},
+	{"352", "ISK", 0},
+	{"400", "JOD", 3},
+	{"392", "JPY", 0},
+	{"404", "KES", 2},
+	{"410", "KRW", 0},
+	{"414", "KWD", 3},
+	{"398", "KZT", 2},
+	{"434", "LYD", 3},
+	{"484", "MXN", 2},
+	{"458", "MYR", 2},
+	{"566", "NGN", 2},
+	{"578", "NOK", 2},
+	{"554", "NZD", 2},
+	{"512", "OMR", 3},
+	{"608", "PHP", 2},
+	{"586", "PKR", 2},
+	{"985", "PLN", 2},
+	{"634", "QAR", 2},
+	{"946", "RON", 2},
+	{"643", "RUB", 2},
+	{"682", "SAR", 2},
+	{"752", "SEK", 2},
+	{"702", "SGD", 2},
+	{"764", "THB", 2},
+	{"788", "TND", 3},
+	{"949", "TRY", 2},
+	{"901", "TWD", 2},
+	{"980", "UAH", 2},
+	{"840", "USD", 2},
+	{"860", "UZS", 2},
+	{"704", "VND", 0},
+	{"950", "XAF", 0},
+	{"952", "XOF", 0},
+	{"710", "ZAR", 2},
+}
+
+var (
+	currenciesByAlpha   = make(map[string]CurrencyInfo)
+	currenciesByNumeric = make(map[string]CurrencyInfo)
+)
+
+func init() {
+	for _, c := range currencies {
+		currenciesByAlpha[c.Alpha] = c
+		currenciesByNumeric[c.Numeric] = c
+	}
+}
+
+// CurrencyByAlpha returns currency by alphabetic code, for ex. "USD"
+func CurrencyByAlpha(code string) (CurrencyInfo, bool) {
+	c, ok := currenciesByAlpha[strings.ToUpper(code)]
+	return c, ok
+}
+
+// CurrencyByNumeric returns currency by numeric code, for ex. "840"
+func CurrencyByNumeric(code string) (CurrencyInfo, bool) {
+	c, ok := currenciesByNumeric[code]
+	return c, ok
+}
+
+// lookupCurrency returns currency by alphabetic or numeric code
+func lookupCurrency(code string) (CurrencyInfo, error) {
+	if c, ok := CurrencyByNumeric(code); ok {
+		return c, nil
+	}
+	if c, ok := CurrencyByAlpha(code); ok {
+		return c, nil
+	}
+	return CurrencyInfo{}, fmt.Errorf("%w: %s", ErrUnknownCurrency, code)
+}
+
+// A Currency contains ISO 4217 numeric currency code (fields 49, 50 and
+// 51). It is packed like Numeric with length 3, supported encoder are
+// ascii, bcd and rbcd.
+type Currency struct {
+	Value string
+}
+
+// NewCurrency create new Currency field from alphabetic ("USD") or numeric
+// ("840") code
+func NewCurrency(code string) (*Currency, error) {
+	c, err := lookupCurrency(code)
+	if err != nil {
+		return nil, err
+	}
+	return &Currency{c.Numeric}, nil
+}
+
+// Info returns currency description
+func (c *Currency) Info() (CurrencyInfo, error) {
+	return lookupCurrency(c.Value)
+}
+
+// IsEmpty check Currency field for empty value
+func (c *Currency) IsEmpty() bool {
+	return len(c.Value) == 0
+}
+
+// Bytes encode Currency field to bytes
+func (c *Currency) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
+	return NewNumeric(c.Value).Bytes(encoder, lenEncoder, 3)
+}
+
+// Load decode Currency field from bytes
+func (c *Currency) Load(raw []byte, encoder, lenEncoder, length int) (int, error) {
+	n := &Numeric{}
+	read, err := n.Load(raw, encoder, lenEncoder, 3)
+	if err != nil {
+		return 0, err
+	}
+	c.Value = n.Value
+	return read, nil
+}
+
+// FormatAmount formats amount in minor units (for ex. field 4 value
+// "000000077700") with currency exponent ("777.00" for USD)
+func FormatAmount(amount string, cur CurrencyInfo) (string, error) {
+	if amount == "" || !isDigits(amount) {
+		return "", fmt.Errorf("%w: %s", ErrBadAmount, amount)
+	}
+	if len(amount) <= cur.Exponent {
+		amount = strings.Repeat("0", cur.Exponent-len(amount)+1) + amount
+	}
+	units := strings.TrimLeft(amount[:len(amount)-cur.Exponent], "0")
+	if units == "" {
+		units = "0"
+	}
+	if cur.Exponent == 0 {
+		return units, nil
+	}
+	return units + "." + amount[len(amount)-cur.Exponent:], nil
+}
+
+// ParseAmount parses amount with currency exponent ("777.00" or "777" for
+// USD) into minor units ("77700")
+func ParseAmount(s string, cur CurrencyInfo) (string, error) {
+	parts := strings.SplitN(s, ".", 2)
+	units := parts[0]
+	fraction := ""
+	if len(parts) == 2 {
+		fraction = parts[1]
+	}
+	if units == "" || !isDigits(units) || !isDigits(fraction) || len(fraction) > cur.Exponent {
+		return "", fmt.Errorf("%w: %s", ErrBadAmount, s)
+	}
+	amount := strings.TrimLeft(units+fraction+strings.Repeat("0", cur.Exponent-len(fraction)), "0")
+	if amount == "" {
+		amount = "0"
+	}
+	return amount, nil
+}
@@ -0,0 +1,79 @@
+package iso8583
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCurrency(t *testing.T) {
+	usd, ok := CurrencyByAlpha("usd")
+	assert.True(t, ok)
+	assert.Equal(t, CurrencyInfo{"840", "USD", 2}, usd)
+
+	jpy, ok := CurrencyByNumeric("392")
+	assert.True(t, ok)
+	assert.Equal(t, "JPY", jpy.Alpha)
+
+	_, ok = CurrencyByAlpha("XXX")
+	assert.False(t, ok)
+
+	type test struct {
+		F4  *Numeric  `field:"4" length:"12"`
+		F49 *Currency `field:"49" length:"3" encode:"bcd"`
+	}
+
+	f49, err := NewCurrency("USD")
+	assert.Nil(t, err)
+	amount, err := ParseAmount("777.5", usd)
+	assert.Nil(t, err)
+	assert.Equal(t, "77750", amount)
+
+	iso := NewMessage("0100", &test{NewNumeric(amount), f49})
+	res, err := iso.Bytes()
+	assert.Nil(t, err)
+
+	iso2 := NewMessage("", &test{&Numeric{}, &Currency{}})
+	err = iso2.Load(res)
+	assert.Nil(t, err)
+	data := iso2.Data.(*test)
+	assert.Equal(t, "840", data.F49.Value)
+
+	info, err := data.F49.Info()
+	assert.Nil(t, err)
+	res2, err := FormatAmount(data.F4.Value, info)
+	assert.Nil(t, err)
+	assert.Equal(t, "777.50", res2)
+
+	_, err = NewCurrency("ABC")
+	assert.EqualError(t, err, "unknown currency: ABC")
+}
+
+func TestFormatParseAmount(t *testing.T) {
+	kwd, _ := CurrencyByAlpha("KWD")
+	jpy, _ := CurrencyByAlpha("JPY")
+
+	s, err := FormatAmount("5", kwd)
+	assert.Nil(t, err)
+	assert.Equal(t, "0.005", s)
+
+	s, err = FormatAmount("000000001500", jpy)
+	assert.Nil(t, err)
+	assert.Equal(t, "1500", s)
+
+	_, err = FormatAmount("12a", jpy)
+	assert.EqualError(t, err, "bad amount: 12a")
+
+	s, err = ParseAmount("0.00", kwd)
+	assert.Nil(t, err)
+	assert.Equal(t, "0", s)
+
+	s, err = ParseAmount("1500", jpy)
+	assert.Nil(t, err)
+	assert.Equal(t, "1500", s)
+
+	_, err = ParseAmount("15.5", jpy)
+	assert.EqualError(t, err, "bad amount: 15.5")
+
+	_, err = ParseAmount(".5", kwd)
+	assert.EqualError(t, err, "bad amount: .5")
+}
@@ -0,0 +1,236 @@
+package iso8583
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// FieldDescription describes a field of tagged struct, see DescribeStruct
+type FieldDescription struct {
+	Field     int    // ISO 8583 field number
+	Name      string // name of struct member
+	Kind      string // field type name, for ex. "Numeric"
+	Length    int    // -1 if not defined
+	Encode    string
+	LenEncode string
+	Present   bool
+	Required  bool
+	// Subfields of composite field or of repeating group
+	Subfields []FieldDescription
+}
+
+// DescribeStruct returns fields of tagged struct v (or pointer to it) sorted
+// by field number. Nothing is packed, so members may be nil.
+func DescribeStruct(v interface{}) (ret []FieldDescription, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("Critical error:" + fmt.Sprint(r))
+			ret = nil
+		}
+	}()
+
+	return describeType(reflect.TypeOf(v)), nil
+}
+
+func describeType(tp reflect.Type) []FieldDescription {
+	if tp == nil {
+		panic("data must be a struct")
+	}
+	if tp.Kind() == reflect.Ptr {
+		tp = tp.Elem()
+	}
+	if tp.Kind() != reflect.Struct {
+		panic("data must be a struct")
+	}
+	// zero value with initialized members, so embedded structs are walked
+	v := reflect.New(tp)
+	initStruct(tp, v)
+
+	var ret []FieldDescription
+	walkFields(v.Elem(), func(sf reflect.StructField, fv reflect.Value) {
+		info := newFieldInfo(sf, fv)
+		d := FieldDescription{
+			Field:     info.Index,
+			Name:      sf.Name,
+			Kind:      sf.Type.Elem().Name(),
+			Length:    info.Length,
+			Encode:    encodeName(info.Encode),
+			LenEncode: encodeName(info.LenEncode),
+			Present:   info.Present,
+			Required:  info.Required,
+		}
+		switch info.Field.(type) {
+		case *composite:
+			d.Kind = "Composite"
+			d.Subfields = describeType(sf.Type)
+		case *repeated:
+			d.Kind = "Repeated"
+			d.Subfields = describeType(sf.Type.Elem())
+		}
+		ret = append(ret, d)
+	})
+	sort.SliceStable(ret, func(i, j int) bool { return ret[i].Field < ret[j].Field })
+	return ret
+}
+
+// encodeName returns name of encode in tags
+func encodeName(encode int) string {
+	switch encode {
+	case ASCII:
+		return "ascii"
+	case BCD:
+		return "bcd"
+	case rBCD:
+		return "rbcd"
+	case ZONED:
+		return "zoned"
+	case EBCDIC:
+		return "ebcdic"
+	case BINARY:
+		return "binary"
+	}
+	return fmt.Sprintf("unknown(%d)", encode)
+}
+
+// Report describes layout of a packed message field by field, for
+// certification documents, see Describe
+type Report struct {
+	Mti    string `json:"mti"`
+	Length int    `json:"length"`
+	// MtiLength and BitmapLength are wire lengths of MTI and bitmaps,
+	// fields start after them
+	MtiLength    int           `json:"mti_length"`
+	BitmapLength int           `json:"bitmap_length"`
+	Fields       []FieldReport `json:"fields"`
+}
+
+// FieldReport describes a field of packed message
+type FieldReport struct {
+	Field     int    `json:"field"`
+	Name      string `json:"name"` // name of struct member, empty for Extra fields
+	Kind      string `json:"kind"` // field type name, for ex. "Numeric"
+	Offset    int    `json:"offset"`
+	Length    int    `json:"length"` // wire length including length head
+	Encode    string `json:"encode"`
+	LenEncode string `json:"len_encode"`
+	// Value is decoded value of text, numeric and binary (in hex) fields,
+	// it is empty for composite fields
+	Value string `json:"value"`
+	// Raw is wire form of field in hex
+	Raw string `json:"raw"`
+}
+
+// Describe parses raw with p and reports offset, wire length, encoding,
+// decoded value and name of each field
+func Describe(raw []byte, p *Parser) (ret *Report, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("Critical error:" + fmt.Sprint(r))
+			ret = nil
+		}
+	}()
+
+	mti, err := decodeMti(raw, p.Quirks.mtiEncode(p.MtiEncode), p.CodePage)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := p.newMessage(mti)
+	if err != nil {
+		return nil, err
+	}
+	msg.CaptureRaw = true
+	n, err := msg.load(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	ret = &Report{
+		Mti:          mti,
+		Length:       n,
+		MtiLength:    mtiLen(msg.Quirks.mtiEncode(msg.MtiEncode)),
+		BitmapLength: bitmapUnit(msg.BitmapEncode),
+	}
+	if msg.SecondBitmap {
+		ret.BitmapLength *= 2
+	}
+	names := make(map[int]string)
+	walkFields(reflect.Indirect(reflect.ValueOf(msg.Data)), func(sf reflect.StructField, fv reflect.Value) {
+		index, _, _ := parseFieldTag(sf.Tag.Get(TAG_FIELD))
+		names[index] = sf.Name
+	})
+	fields, err := parseFields(msg.Data)
+	if err != nil {
+		return nil, err
+	}
+	if err := msg.addExtraFields(fields); err != nil {
+		return nil, err
+	}
+	indexes := make([]int, 0, len(msg.raw))
+	for i := range msg.raw {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	offset := ret.MtiLength + ret.BitmapLength
+	for _, i := range indexes {
+		info := fields[i]
+		f := FieldReport{
+			Field:     i,
+			Name:      names[i],
+			Kind:      reflect.TypeOf(info.Field).Elem().Name(),
+			Offset:    offset,
+			Length:    len(msg.raw[i]),
+			Encode:    encodeName(info.Encode),
+			LenEncode: encodeName(info.LenEncode),
+			Raw:       hex.EncodeToString(msg.raw[i]),
+		}
+		switch v := info.Field.(type) {
+		case *composite:
+			f.Kind = "Composite"
+		case *repeated:
+			f.Kind = "Repeated"
+		case *Binary:
+			f.Value = hex.EncodeToString(v.Value)
+		default:
+			f.Value = fieldValue(v)
+		}
+		ret.Fields = append(ret.Fields, f)
+		offset += f.Length
+	}
+	return ret, nil
+}
+
+// WriteJSON writes report as JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteCSV writes report as CSV table with header, MTI and bitmap are the
+// first rows
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	rows := [][]string{
+		{"field", "name", "kind", "offset", "length", "encode", "len_encode", "value", "raw"},
+		{"MTI", "", "", "0", strconv.Itoa(r.MtiLength), "", "", r.Mti, ""},
+		{"bitmap", "", "", strconv.Itoa(r.MtiLength), strconv.Itoa(r.BitmapLength), "", "", "", ""},
+	}
+	for _, f := range r.Fields {
+		rows = append(rows, []string{
+			strconv.Itoa(f.Field), f.Name, f.Kind, strconv.Itoa(f.Offset), strconv.Itoa(f.Length),
+			f.Encode, f.LenEncode, f.Value, f.Raw,
+		})
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
@@ -0,0 +1,113 @@
+package iso8583
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestDescribeStruct(t *testing.T) {
+	type additional struct {
+		F1 *Alphanumeric `field:"1" length:"2"`
+	}
+	type common struct {
+		F11 *Numeric `field:"11" length:"6" encode:"bcd"`
+	}
+	type test struct {
+		common
+		F2  *Llnumeric  `field:"2" length:"19" encode:"bcd,rbcd" filler:"f"`
+		F48 *additional `field:"48,required"`
+		F4  *Numeric    `field:"4" length:"12"`
+		Ign string
+	}
+
+	fields, err := DescribeStruct(&test{})
+	assert.Nil(t, err)
+	assert.Equal(t, []FieldDescription{
+		{Field: 2, Name: "F2", Kind: "Llnumeric", Length: 19, Encode: "rbcd", LenEncode: "bcd"},
+		{Field: 4, Name: "F4", Kind: "Numeric", Length: 12, Encode: "ascii", LenEncode: "ascii"},
+		{Field: 11, Name: "F11", Kind: "Numeric", Length: 6, Encode: "bcd", LenEncode: "ascii"},
+		{Field: 48, Name: "F48", Kind: "Composite", Length: -1, Encode: "ascii", LenEncode: "ascii",
+			Present: true, Required: true, Subfields: []FieldDescription{
+				{Field: 1, Name: "F1", Kind: "Alphanumeric", Length: 2, Encode: "ascii", LenEncode: "ascii"},
+			}},
+	}, fields)
+
+	typed, err := DescribeStruct((*test)(nil))
+	assert.Nil(t, err)
+	assert.Equal(t, fields, typed)
+
+	_, err = DescribeStruct(1)
+	assert.EqualError(t, err, "Critical error:data must be a struct")
+}
+
+func TestDuplicateField(t *testing.T) {
+	type common struct {
+		Stan *Numeric `field:"11" length:"6"`
+	}
+	type test struct {
+		common
+		F11 *Numeric `field:"11" length:"6"`
+	}
+
+	_, err := DescribeStruct(&test{})
+	assert.EqualError(t, err, "Critical error:duplicate field 11 in iso8583.test: Stan and F11")
+
+	// reported on first use even if members are nil
+	_, err = NewMessage("0100", &test{}).Bytes()
+	assert.EqualError(t, err, "Critical error:duplicate field 11 in iso8583.test: Stan and F11")
+	err = NewMessage("0100", &test{}).Load([]byte("0100\x00\x20\x00\x00\x00\x00\x00\x00123456"))
+	assert.EqualError(t, err, "Critical error:duplicate field 11 in iso8583.test: Stan and F11")
+}
+
+func TestDescribe(t *testing.T) {
+	type test struct {
+		F2  *Llnumeric          `field:"2" length:"19" encode:"bcd,ascii"`
+		F11 *Numeric            `field:"11" length:"6" encode:"bcd"`
+		F48 *testAdditionalData `field:"48" length:"999"`
+		F52 *Binary             `field:"52" length:"2"`
+	}
+	raw, err := NewMessage("0100", &test{
+		F2:  NewLlnumeric("4276555555555558"),
+		F11: NewNumeric("000042"),
+		F48: &testAdditionalData{S1: NewNumeric("7")},
+		F52: NewBinary([]byte{0xab, 0xcd}),
+	}).Bytes()
+	assert.Nil(t, err)
+
+	p := &Parser{}
+	p.Register("0100", &test{})
+	r, err := Describe(raw, p)
+	assert.Nil(t, err)
+	assert.Equal(t, "0100", r.Mti)
+	assert.Equal(t, len(raw), r.Length)
+	assert.Equal(t, []FieldReport{
+		{Field: 2, Name: "F2", Kind: "Llnumeric", Offset: 12, Length: 17, Encode: "ascii", LenEncode: "bcd",
+			Value: "4276555555555558", Raw: "1634323736353535353535353535353538"},
+		{Field: 11, Name: "F11", Kind: "Numeric", Offset: 29, Length: 3, Encode: "bcd", LenEncode: "ascii",
+			Value: "000042", Raw: "000042"},
+		{Field: 48, Name: "F48", Kind: "Composite", Offset: 32, Length: 11, Encode: "ascii", LenEncode: "ascii",
+			Raw: "3030383037202020203030"},
+		{Field: 52, Name: "F52", Kind: "Binary", Offset: 43, Length: 2, Encode: "ascii", LenEncode: "ascii",
+			Value: "abcd", Raw: "abcd"},
+	}, r.Fields)
+
+	buf := &bytes.Buffer{}
+	assert.Nil(t, r.WriteCSV(buf))
+	lines := strings.Split(buf.String(), "\n")
+	assert.Equal(t, "field,name,kind,offset,length,encode,len_encode,value,raw", lines[0])
+	assert.Equal(t, "MTI,,,0,4,,,0100,", lines[1])
+	assert.Equal(t, "bitmap,,,4,8,,,,", lines[2])
+	assert.Equal(t, "11,F11,Numeric,29,3,bcd,ascii,000042,000042", lines[4])
+
+	buf.Reset()
+	assert.Nil(t, r.WriteJSON(buf))
+	var decoded Report
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, *r, decoded)
+
+	_, err = Describe(raw[:20], p)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,135 @@
+package iso8583
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DuplicateKey identifies repeated request by MTI, STAN (field 11),
+// transmission date and time (field 7) and terminal ID (field 41). Mti is
+// the original MTI, so repeats (xxx1, xxx3) match the first request.
+type DuplicateKey struct {
+	Mti              string
+	Stan             string
+	TransmissionTime string
+	Terminal         string
+}
+
+// duplicateKeyOf builds DuplicateKey of req, or false if req has no STAN
+func duplicateKeyOf(req *Message) (DuplicateKey, bool) {
+	key, err := KeyOf(req)
+	if err != nil {
+		return DuplicateKey{}, false
+	}
+	ret := DuplicateKey{Mti: MtiOriginal(req.Mti), Stan: key.Stan, Terminal: key.Terminal}
+	if hasField(req.Data, 7) {
+		ret.TransmissionTime, _ = req.GetString(7)
+	}
+	return ret, true
+}
+
+// ResponseCache keeps responses to requests for duplicate detection
+type ResponseCache interface {
+	// Get returns response saved under the key, or false if there is none
+	Get(key DuplicateKey) (*Message, bool)
+	// Put saves response under the key
+	Put(key DuplicateKey, resp *Message)
+}
+
+// MemoryResponseCache is in-memory ResponseCache which keeps responses for
+// Window, safe for concurrent use
+type MemoryResponseCache struct {
+	Window time.Duration
+
+	mu      sync.Mutex
+	entries map[DuplicateKey]cachedResponse
+}
+
+type cachedResponse struct {
+	resp    *Message
+	expires time.Time
+}
+
+// NewMemoryResponseCache creates new MemoryResponseCache with window
+func NewMemoryResponseCache(window time.Duration) *MemoryResponseCache {
+	return &MemoryResponseCache{Window: window}
+}
+
+// Get returns response saved less than Window ago
+func (c *MemoryResponseCache) Get(key DuplicateKey) (*Message, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || time.Now().After(e.expires) {
+		return nil, false
+	}
+	return e.resp, true
+}
+
+// Put saves response and drops expired ones
+func (c *MemoryResponseCache) Put(key DuplicateKey, resp *Message) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if c.entries == nil {
+		c.entries = make(map[DuplicateKey]cachedResponse)
+	}
+	for k, e := range c.entries {
+		if now.After(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cachedResponse{resp, now.Add(c.Window)}
+}
+
+// DetectDuplicates returns Middleware which answers repeated requests with
+// copy of response cached for the first one instead of processing them
+// again. Repeats which come while the first request is processed wait for
+// its response. Requests without STAN and failed requests are not cached.
+func DetectDuplicates(cache ResponseCache) Middleware {
+	var mu sync.Mutex
+	inflight := make(map[DuplicateKey]chan struct{})
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *Message) (*Message, error) {
+			key, ok := duplicateKeyOf(req)
+			if !ok {
+				return next(ctx, req)
+			}
+			for {
+				if resp, ok := cache.Get(key); ok {
+					resp = resp.Clone()
+					resp.CorrelationID = req.CorrelationID
+					return resp, nil
+				}
+				mu.Lock()
+				done, busy := inflight[key]
+				if !busy {
+					done = make(chan struct{})
+					inflight[key] = done
+				}
+				mu.Unlock()
+				if !busy {
+					break
+				}
+				select {
+				case <-done:
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+			}
+			defer func() {
+				mu.Lock()
+				close(inflight[key])
+				delete(inflight, key)
+				mu.Unlock()
+			}()
+
+			resp, err := next(ctx, req)
+			if err == nil && resp != nil {
+				cache.Put(key, resp.Clone())
+			}
+			return resp, err
+		}
+	}
+}
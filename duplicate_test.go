@@ -0,0 +1,107 @@
+package iso8583
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDetectDuplicates(t *testing.T) {
+	calls := 0
+	fail := errors.New("host unavailable")
+	r := &Router{}
+	r.Handle("0200", "", "", func(ctx context.Context, req *Message) (*Message, error) {
+		calls++
+		if req.Data.(*testTransaction).F3.Value == "999999" {
+			return nil, fail
+		}
+		return NewMessage("0210", &testTransaction{F11: req.Data.(*testTransaction).F11}), nil
+	})
+	r.Use(DetectDuplicates(NewMemoryResponseCache(time.Minute)))
+
+	request := func(stan, terminal string) *Message {
+		return NewMessage("0200", &testTransaction{
+			F3:  NewNumeric("000000"),
+			F11: NewNumeric(stan),
+			F41: NewAlphanumeric(terminal),
+		})
+	}
+	ctx := context.Background()
+	resp1, err := r.Serve(ctx, request("000001", "TERM01"))
+	assert.Nil(t, err)
+	resp2, err := r.Serve(ctx, request("000001", "TERM01"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, resp1, resp2)
+	assert.False(t, resp1 == resp2)
+
+	_, err = r.Serve(ctx, request("000001", "TERM02"))
+	assert.Nil(t, err)
+	_, err = r.Serve(ctx, NewMessage("0200", &testTransaction{F3: NewNumeric("000000")}))
+	assert.Nil(t, err)
+	_, err = r.Serve(ctx, NewMessage("0200", &testTransaction{F3: NewNumeric("000000")}))
+	assert.Nil(t, err)
+	assert.Equal(t, 4, calls)
+
+	failed := request("000002", "TERM01")
+	failed.Data.(*testTransaction).F3 = NewNumeric("999999")
+	_, err = r.Serve(ctx, failed)
+	assert.Equal(t, fail, err)
+	_, err = r.Serve(ctx, failed)
+	assert.Equal(t, fail, err)
+	assert.Equal(t, 6, calls)
+
+	// repeat hits response cached for the original request
+	h := DetectDuplicates(NewMemoryResponseCache(time.Minute))(func(ctx context.Context, req *Message) (*Message, error) {
+		calls++
+		return NewMessage("0210", &testTransaction{F11: req.Data.(*testTransaction).F11}), nil
+	})
+	resp1, err = h(ctx, request("000003", "TERM01"))
+	assert.Nil(t, err)
+	repeat := request("000003", "TERM01")
+	repeat.Mti = "0201"
+	resp2, err = h(ctx, repeat)
+	assert.Nil(t, err)
+	assert.Equal(t, 7, calls)
+	assert.Equal(t, resp1, resp2)
+}
+
+func TestMemoryResponseCache(t *testing.T) {
+	c := NewMemoryResponseCache(time.Millisecond)
+	key := DuplicateKey{Mti: "0200", Stan: "000001"}
+	c.Put(key, NewMessage("0210", nil))
+	resp, ok := c.Get(key)
+	assert.True(t, ok)
+	assert.Equal(t, "0210", resp.Mti)
+
+	time.Sleep(2 * time.Millisecond)
+	_, ok = c.Get(key)
+	assert.False(t, ok)
+	c.Put(DuplicateKey{Stan: "000002"}, NewMessage("0210", nil))
+	assert.Equal(t, 1, len(c.entries))
+}
+
+func TestDetectDuplicatesInflight(t *testing.T) {
+	release := make(chan struct{})
+	calls := 0
+	h := DetectDuplicates(NewMemoryResponseCache(time.Minute))(func(ctx context.Context, req *Message) (*Message, error) {
+		calls++
+		<-release
+		return NewMessage("0210", nil), nil
+	})
+	req := NewMessage("0200", &testTransaction{F11: NewNumeric("000001")})
+	done := make(chan *Message)
+	for i := 0; i < 2; i++ {
+		go func() {
+			resp, _ := h(context.Background(), req)
+			done <- resp
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	assert.Equal(t, "0210", (<-done).Mti)
+	assert.Equal(t, "0210", (<-done).Mti)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,45 @@
+package iso8583
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodePage(t *testing.T) {
+	assert.Equal(t, []byte{0xf1, 0xba, 0xc1}, CodePage037.Encode([]byte("1[A")))
+	assert.Equal(t, []byte{0xf1, 0xad, 0xc1}, CodePage1047.Encode([]byte("1[A")))
+	assert.Equal(t, "1[A", string(CodePage1047.Decode([]byte{0xf1, 0xad, 0xc1})))
+
+	var table [256]byte
+	_, err := NewCodePage(table)
+	assert.EqualError(t, err, "code page is not reversible: 0x00 is used twice")
+
+	// custom code page with digits shifted by one
+	table = CodePage037.toEbcdic
+	for c := '0'; c <= '9'; c++ {
+		table[c] = CodePage037.toEbcdic[(c-'0'+1)%10+'0']
+	}
+	cp, err := NewCodePage(table)
+	assert.Nil(t, err)
+
+	data := &testTransaction{F11: NewNumeric("000123")}
+	iso := NewMessage("0200", data)
+	iso.MtiEncode = EBCDIC
+	iso.CodePage = cp
+	res, err := iso.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0xf1, 0xf3, 0xf1, 0xf1}, res[:4])
+
+	p := &Parser{MtiEncode: EBCDIC, CodePage: cp}
+	assert.Nil(t, p.Register("0200", &testTransaction{}))
+	msg, err := p.Parse(res)
+	assert.Nil(t, err)
+	assert.Equal(t, "0200", msg.Mti)
+	assert.Equal(t, "000123", msg.Data.(*testTransaction).F11.Value)
+
+	iso2 := NewMessage("", &testTransaction{F11: &Numeric{}})
+	iso2.MtiEncode = EBCDIC
+	assert.Nil(t, iso2.Load(res))
+	assert.Equal(t, "1311", iso2.Mti)
+}
@@ -0,0 +1,89 @@
+package emv
+
+import (
+	"crypto/subtle"
+	"fmt"
+
+	"github.com/ideazxy/iso8583/hsm"
+)
+
+// Cryptogram is application cryptogram of card with data it covers
+type Cryptogram struct {
+	ARQC   []byte // TAG_CRYPTOGRAM
+	CID    byte   // TAG_CID
+	ATC    []byte // TAG_ATC
+	IAD    []byte // TAG_IAD
+	PanSeq string // TAG_PAN_SEQUENCE, 2 digits, or field 23 set by caller
+	Data   []byte // assembled by dol
+}
+
+// ParseCryptogram extracts cryptogram from field 55 data and assembles
+// data it covers by dol, for ex. DefaultARQCData
+func ParseCryptogram(de55 []byte, dol []DOLEntry) (*Cryptogram, error) {
+	list, err := ParseTLV(de55)
+	if err != nil {
+		return nil, err
+	}
+	c := &Cryptogram{}
+	var ok bool
+	if c.ARQC, ok = Find(list, TAG_CRYPTOGRAM); !ok || len(c.ARQC) != 8 {
+		return nil, fmt.Errorf("%w: %s", ErrMissingTag, TAG_CRYPTOGRAM)
+	}
+	if c.ATC, ok = Find(list, TAG_ATC); !ok || len(c.ATC) != 2 {
+		return nil, fmt.Errorf("%w: %s", ErrMissingTag, TAG_ATC)
+	}
+	if cid, ok := Find(list, TAG_CID); ok && len(cid) == 1 {
+		c.CID = cid[0]
+	}
+	c.IAD, _ = Find(list, TAG_IAD)
+	if psn, ok := Find(list, TAG_PAN_SEQUENCE); ok && len(psn) == 1 {
+		c.PanSeq = fmt.Sprintf("%02x", psn[0])
+	}
+	if c.Data, err = AssembleDOL(dol, list, false); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Verify checks ARQC via HSM with issuer master key
+func (c *Cryptogram) Verify(h hsm.HSM, key hsm.Key, pan string) error {
+	return h.VerifyARQC(key, pan, c.PanSeq, c.ATC, c.Data, c.ARQC)
+}
+
+// VerifyWithKey checks ARQC with session key sk
+func (c *Cryptogram) VerifyWithKey(sk []byte) error {
+	expected, err := hsm.ApplicationCryptogram(sk, c.Data)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(expected, c.ARQC) != 1 {
+		return hsm.ErrVerificationFailed
+	}
+	return nil
+}
+
+// issuerAuthentication packs ARPC and ARC as data object for field 55 of
+// response
+func issuerAuthentication(arpc, arc []byte) ([]byte, error) {
+	return PackTLV([]TLV{{TAG_ISSUER_AUTHENTICATION, append(append([]byte(nil), arpc...), arc...)}})
+}
+
+// Respond returns issuer authentication data object (TAG_ISSUER_AUTHENTICATION)
+// with ARPC of authorisation response code arc (for ex. []byte("00"))
+// generated via HSM, for field 55 of response
+func (c *Cryptogram) Respond(h hsm.ARPCGenerator, key hsm.Key, pan string, arc []byte) ([]byte, error) {
+	arpc, err := h.GenerateARPC(key, pan, c.PanSeq, c.ATC, c.ARQC, arc)
+	if err != nil {
+		return nil, err
+	}
+	return issuerAuthentication(arpc, arc)
+}
+
+// RespondWithKey is Respond with session key sk
+func (c *Cryptogram) RespondWithKey(sk, arc []byte) ([]byte, error) {
+	arpc, err := hsm.ARPC(sk, c.ARQC, arc)
+	if err != nil {
+		return nil, err
+	}
+	return issuerAuthentication(arpc, arc)
+}
@@ -0,0 +1,60 @@
+package emv
+
+import (
+	"encoding/hex"
+	"github.com/ideazxy/iso8583/hsm"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCryptogram(t *testing.T) {
+	h, err := hsm.NewSoftware(map[hsm.Key]string{"imk": "0123456789ABCDEFFEDCBA9876543210"})
+	assert.Nil(t, err)
+	pan := "5413330089020011"
+
+	list := []TLV{
+		{TAG_AMOUNT_AUTHORISED, []byte{0, 0, 0, 0, 0x10, 0}},
+		{TAG_AMOUNT_OTHER, make([]byte, 6)},
+		{TAG_TERMINAL_COUNTRY, []byte{0x08, 0x40}},
+		{TAG_TVR, make([]byte, 5)},
+		{TAG_TRANSACTION_CURRENCY, []byte{0x08, 0x40}},
+		{TAG_TRANSACTION_DATE, []byte{0x26, 0x10, 0x15}},
+		{TAG_TRANSACTION_TYPE, []byte{0}},
+		{TAG_UNPREDICTABLE_NUMBER, []byte{0x7b, 0xa9, 0xab, 0x3c}},
+		{TAG_AIP, []byte{0x19, 0x80}},
+		{TAG_ATC, []byte{0x00, 0x1c}},
+		{TAG_CID, []byte{0x80}},
+		{TAG_PAN_SEQUENCE, []byte{0x01}},
+	}
+	data, err := AssembleDOL(DefaultARQCData, list, false)
+	assert.Nil(t, err)
+	arqc, err := h.GenerateARQC("imk", pan, "01", []byte{0x00, 0x1c}, data)
+	assert.Nil(t, err)
+	de55, err := PackTLV(append(list, TLV{TAG_CRYPTOGRAM, arqc}))
+	assert.Nil(t, err)
+
+	c, err := ParseCryptogram(de55, DefaultARQCData)
+	assert.Nil(t, err)
+	assert.Equal(t, "01", c.PanSeq)
+	assert.Equal(t, byte(0x80), c.CID)
+	assert.Equal(t, data, c.Data)
+	assert.Nil(t, c.Verify(h, "imk", pan))
+	assert.Equal(t, hsm.ErrVerificationFailed, c.Verify(h, "imk", "5413330089020029"))
+
+	mk, err := hsm.CardMasterKey(h.Keys["imk"], pan, "01")
+	assert.Nil(t, err)
+	sk, err := hsm.SessionKey(mk, c.ATC)
+	assert.Nil(t, err)
+	assert.Nil(t, c.VerifyWithKey(sk))
+
+	resp, err := c.Respond(h, "imk", pan, []byte("00"))
+	assert.Nil(t, err)
+	resp2, err := c.RespondWithKey(sk, []byte("00"))
+	assert.Nil(t, err)
+	assert.Equal(t, resp, resp2)
+	assert.Equal(t, "910a", hex.EncodeToString(resp[:2]))
+	assert.Equal(t, "3030", hex.EncodeToString(resp[10:]))
+
+	_, err = ParseCryptogram(de55[:len(de55)-11], DefaultARQCData)
+	assert.EqualError(t, err, "missing tag: 9F26")
+}
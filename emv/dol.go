@@ -0,0 +1,63 @@
+package emv
+
+import (
+	"fmt"
+)
+
+// DOLEntry is tag and length of data object in data object list
+type DOLEntry struct {
+	Tag    string
+	Length int
+}
+
+// DefaultARQCData is data recommended by EMV for application cryptogram.
+// Some cryptogram versions append issuer application data (TAG_IAD) or
+// its part.
+var DefaultARQCData = []DOLEntry{
+	{TAG_AMOUNT_AUTHORISED, 6},
+	{TAG_AMOUNT_OTHER, 6},
+	{TAG_TERMINAL_COUNTRY, 2},
+	{TAG_TVR, 5},
+	{TAG_TRANSACTION_CURRENCY, 2},
+	{TAG_TRANSACTION_DATE, 3},
+	{TAG_TRANSACTION_TYPE, 1},
+	{TAG_UNPREDICTABLE_NUMBER, 4},
+	{TAG_AIP, 2},
+	{TAG_ATC, 2},
+}
+
+// ParseDOL parses data object list, for ex. CDOL1 (tag 8C) of card
+func ParseDOL(dol []byte) ([]DOLEntry, error) {
+	var ret []DOLEntry
+	for pos := 0; pos < len(dol); {
+		tag, tl, err := readTag(dol[pos:])
+		if err != nil {
+			return nil, fmt.Errorf("%w at %d", err, pos)
+		}
+		l, ll, err := readLength(dol[pos+tl:])
+		if err != nil {
+			return nil, fmt.Errorf("%w at %d", err, pos)
+		}
+		ret = append(ret, DOLEntry{tag, l})
+		pos += tl + ll
+	}
+	return ret, nil
+}
+
+// AssembleDOL concatenates values of dol entries found in list. Longer
+// values are truncated and shorter ones are padded with zeros on the right,
+// entries missing in list are sent as zeros if missing is true, otherwise
+// ErrMissingTag is returned.
+func AssembleDOL(dol []DOLEntry, list []TLV, missing bool) ([]byte, error) {
+	ret := make([]byte, 0, 64)
+	for _, e := range dol {
+		v, ok := Find(list, e.Tag)
+		if !ok && !missing {
+			return nil, fmt.Errorf("%w: %s", ErrMissingTag, e.Tag)
+		}
+		field := make([]byte, e.Length)
+		copy(field, v)
+		ret = append(ret, field...)
+	}
+	return ret, nil
+}
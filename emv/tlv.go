@@ -0,0 +1,155 @@
+// Package emv contains helpers for EMV chip data of field 55: BER-TLV
+// parsing, data object list (DOL) assembly and verification of application
+// cryptograms (ARQC) and generation of response cryptograms (ARPC) with
+// session keys or via HSM.
+package emv
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	ERR_BAD_TLV     string = "bad TLV data"
+	ERR_MISSING_TAG string = "missing tag"
+)
+
+var (
+	ErrBadTLV     = errors.New(ERR_BAD_TLV)
+	ErrMissingTag = errors.New(ERR_MISSING_TAG)
+)
+
+// Tags of cryptogram related data objects
+const (
+	TAG_AMOUNT_AUTHORISED     string = "9F02"
+	TAG_AMOUNT_OTHER          string = "9F03"
+	TAG_TERMINAL_COUNTRY      string = "9F1A"
+	TAG_TVR                   string = "95"
+	TAG_TRANSACTION_CURRENCY  string = "5F2A"
+	TAG_TRANSACTION_DATE      string = "9A"
+	TAG_TRANSACTION_TYPE      string = "9C"
+	TAG_UNPREDICTABLE_NUMBER  string = "9F37"
+	TAG_AIP                   string = "82"
+	TAG_ATC                   string = "9F36"
+	TAG_IAD                   string = "9F10" // issuer application data
+	TAG_CRYPTOGRAM            string = "9F26"
+	TAG_CID                   string = "9F27" // cryptogram information data
+	TAG_PAN_SEQUENCE          string = "5F34"
+	TAG_ISSUER_AUTHENTICATION string = "91" // ARPC and response code
+)
+
+// TLV is BER-TLV data object. Tag is upper case hex.
+type TLV struct {
+	Tag   string
+	Value []byte
+}
+
+// readTag returns tag at the start of data and its length in bytes
+func readTag(data []byte) (string, int, error) {
+	if len(data) == 0 {
+		return "", 0, ErrBadTLV
+	}
+	n := 1
+	if data[0]&0x1f == 0x1f {
+		// subsequent bytes follow while bit 8 is set
+		for {
+			if n >= len(data) {
+				return "", 0, ErrBadTLV
+			}
+			n++
+			if data[n-1]&0x80 == 0 {
+				break
+			}
+		}
+	}
+	return strings.ToUpper(hex.EncodeToString(data[:n])), n, nil
+}
+
+// readLength returns BER length at the start of data and number of its
+// bytes
+func readLength(data []byte) (int, int, error) {
+	if len(data) == 0 {
+		return 0, 0, ErrBadTLV
+	}
+	if data[0]&0x80 == 0 {
+		return int(data[0]), 1, nil
+	}
+	n := int(data[0] & 0x7f)
+	if n == 0 || n > 3 || len(data) < 1+n {
+		return 0, 0, ErrBadTLV
+	}
+	l := 0
+	for _, b := range data[1 : 1+n] {
+		l = l<<8 | int(b)
+	}
+	return l, 1 + n, nil
+}
+
+// ParseTLV parses sequence of data objects, for ex. value of field 55.
+// Values of constructed objects are not parsed.
+func ParseTLV(data []byte) ([]TLV, error) {
+	var ret []TLV
+	for pos := 0; pos < len(data); {
+		// padding between objects
+		if data[pos] == 0x00 || data[pos] == 0xff {
+			pos++
+			continue
+		}
+		tag, tl, err := readTag(data[pos:])
+		if err != nil {
+			return nil, fmt.Errorf("%w at %d", err, pos)
+		}
+		l, ll, err := readLength(data[pos+tl:])
+		if err != nil || len(data)-pos-tl-ll < l {
+			return nil, fmt.Errorf("%w at %d", ErrBadTLV, pos)
+		}
+		start := pos + tl + ll
+		ret = append(ret, TLV{tag, append([]byte(nil), data[start:start+l]...)})
+		pos = start + l
+	}
+	return ret, nil
+}
+
+// encodeLength returns BER length of l
+func encodeLength(l int) []byte {
+	switch {
+	case l < 0x80:
+		return []byte{byte(l)}
+	case l <= 0xff:
+		return []byte{0x81, byte(l)}
+	default:
+		return []byte{0x82, byte(l >> 8), byte(l)}
+	}
+}
+
+// PackTLV packs data objects in order
+func PackTLV(list []TLV) ([]byte, error) {
+	ret := make([]byte, 0, 128)
+	for _, t := range list {
+		tag, err := hex.DecodeString(t.Tag)
+		if err != nil || len(tag) == 0 {
+			return nil, fmt.Errorf("%w: tag %q", ErrBadTLV, t.Tag)
+		}
+		if len(t.Value) > 0xffff {
+			return nil, fmt.Errorf("%w: tag %s is too long", ErrBadTLV, t.Tag)
+		}
+		ret = append(ret, tag...)
+		ret = append(ret, encodeLength(len(t.Value))...)
+		ret = append(ret, t.Value...)
+	}
+	return ret, nil
+}
+
+// Find returns value of the first data object with tag, or false if there
+// is no such object
+func Find(list []TLV, tag string) ([]byte, bool) {
+	tag = strings.ToUpper(tag)
+	for _, t := range list {
+		if t.Tag == tag {
+			return t.Value, true
+		}
+	}
+	return nil, false
+}
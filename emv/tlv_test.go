@@ -0,0 +1,51 @@
+package emv
+
+import (
+	"encoding/hex"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTLV(t *testing.T) {
+	data, _ := hex.DecodeString("9F2608A1B2C3D4E5F60718" + "9F270180" + "00" + "5F340101" + "DF8101820100")
+	tvr, _ := hex.DecodeString("95050000000000")
+	data = append(append(data, make([]byte, 0x100)...), tvr...)
+	list, err := ParseTLV(data)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(list))
+	assert.Equal(t, "9F26", list[0].Tag)
+	assert.Equal(t, "DF8101", list[3].Tag)
+	assert.Equal(t, 0x100, len(list[3].Value))
+	v, ok := Find(list, "9f27")
+	assert.True(t, ok)
+	assert.Equal(t, []byte{0x80}, v)
+	_, ok = Find(list, "9F10")
+	assert.False(t, ok)
+
+	packed, err := PackTLV(list)
+	assert.Nil(t, err)
+	again, err := ParseTLV(packed)
+	assert.Nil(t, err)
+	assert.Equal(t, list, again)
+
+	_, err = ParseTLV([]byte{0x9f, 0x26, 0x08, 0x01})
+	assert.True(t, errors.Is(err, ErrBadTLV))
+	assert.EqualError(t, err, "bad TLV data at 0")
+	_, err = PackTLV([]TLV{{"9G", nil}})
+	assert.EqualError(t, err, `bad TLV data: tag "9G"`)
+}
+
+func TestDOL(t *testing.T) {
+	dol, _ := hex.DecodeString("9F02069F03069F1A0295055F2A029A039C019F3704")
+	entries, err := ParseDOL(dol)
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultARQCData[:8], entries)
+
+	list := []TLV{{TAG_AMOUNT_AUTHORISED, []byte{0, 0, 0, 0, 0x10, 0}}, {TAG_TRANSACTION_TYPE, []byte{0}}}
+	data, err := AssembleDOL([]DOLEntry{{TAG_AMOUNT_AUTHORISED, 6}, {TAG_AMOUNT_OTHER, 6}, {TAG_TRANSACTION_TYPE, 1}}, list, true)
+	assert.Nil(t, err)
+	assert.Equal(t, "000000001000"+"000000000000"+"00", hex.EncodeToString(data))
+	_, err = AssembleDOL(DefaultARQCData, list, false)
+	assert.EqualError(t, err, "missing tag: 9F03")
+}
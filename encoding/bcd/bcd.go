@@ -0,0 +1,100 @@
+// Package bcd converts between ASCII digits and packed BCD, two digits per
+// byte, as ISO 8583 fields with bcd and rbcd encodings do. Besides 0-9,
+// nibbles a-f (A-F) are accepted, for ex. separator D of track 2 data and
+// filler F, so proprietary fields can use the same conversions.
+package bcd
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	ERR_INVALID_DIGIT string = "invalid BCD digit"
+	ERR_SHORT_DATA    string = "BCD data is shorter than length"
+)
+
+var (
+	ErrInvalidDigit = errors.New(ERR_INVALID_DIGIT)
+	ErrShortData    = errors.New(ERR_SHORT_DATA)
+)
+
+// Encode packs even number of digits, two per byte
+func Encode(digits []byte) ([]byte, error) {
+	if len(digits)%2 != 0 {
+		return nil, fmt.Errorf("odd number of BCD digits: %d", len(digits))
+	}
+	out := make([]byte, len(digits)/2)
+	for i := 0; i < len(digits); i += 2 {
+		hi, err := nibble(digits, i)
+		if err != nil {
+			return nil, err
+		}
+		lo, err := nibble(digits, i+1)
+		if err != nil {
+			return nil, err
+		}
+		out[i/2] = hi<<4 | lo
+	}
+	return out, nil
+}
+
+// EncodeLeft packs digits left justified: odd number of digits is padded
+// with 0 on the right, for ex. "123" is 0x12 0x30
+func EncodeLeft(digits []byte) ([]byte, error) {
+	if len(digits)%2 != 0 {
+		return Encode(append(append(make([]byte, 0, len(digits)+1), digits...), '0'))
+	}
+	return Encode(digits)
+}
+
+// EncodeRight packs digits right justified: odd number of digits is padded
+// with 0 on the left, for ex. "123" is 0x01 0x23
+func EncodeRight(digits []byte) ([]byte, error) {
+	if len(digits)%2 != 0 {
+		return Encode(append([]byte{'0'}, digits...))
+	}
+	return Encode(digits)
+}
+
+// Decode unpacks all nibbles of data into lower case digits
+func Decode(data []byte) []byte {
+	const digits = "0123456789abcdef"
+	out := make([]byte, len(data)*2)
+	for i, b := range data {
+		out[i*2] = digits[b>>4]
+		out[i*2+1] = digits[b&0x0f]
+	}
+	return out
+}
+
+// DecodeLeft unpacks length digits packed by EncodeLeft
+func DecodeLeft(data []byte, length int) ([]byte, error) {
+	if length < 0 || length > len(data)*2 {
+		return nil, fmt.Errorf("%w: %d bytes, length %d", ErrShortData, len(data), length)
+	}
+	return Decode(data)[:length], nil
+}
+
+// DecodeRight unpacks length digits packed by EncodeRight
+func DecodeRight(data []byte, length int) ([]byte, error) {
+	if length < 0 || length > len(data)*2 {
+		return nil, fmt.Errorf("%w: %d bytes, length %d", ErrShortData, len(data), length)
+	}
+	out := Decode(data)
+	return out[len(out)-length:], nil
+}
+
+// nibble returns value of digit i
+func nibble(digits []byte, i int) (byte, error) {
+	c := digits[i]
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', nil
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, nil
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, nil
+	}
+	return 0, fmt.Errorf("%w: %q at %d", ErrInvalidDigit, c, i)
+}
@@ -0,0 +1,51 @@
+package bcd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncode(t *testing.T) {
+	b, err := EncodeLeft([]byte("954"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x95, 0x40}, b)
+	b, err = EncodeRight([]byte("954"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x09, 0x54}, b)
+	_, err = Encode([]byte("4276D2512"))
+	assert.EqualError(t, err, "odd number of BCD digits: 9")
+	b, err = EncodeRight([]byte("4276D2512"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x04, 0x27, 0x6d, 0x25, 0x12}, b)
+	b, err = Encode(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{}, b)
+
+	_, err = EncodeLeft([]byte("12x"))
+	assert.True(t, errors.Is(err, ErrInvalidDigit))
+	assert.EqualError(t, err, `invalid BCD digit: 'x' at 2`)
+
+	// input is not modified
+	digits := make([]byte, 3, 4)
+	copy(digits, "123")
+	_, err = EncodeLeft(digits)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("123\x00"), digits[:4])
+}
+
+func TestDecode(t *testing.T) {
+	assert.Equal(t, []byte("12a34f"), Decode([]byte{0x12, 0xa3, 0x4f}))
+
+	d, err := DecodeLeft([]byte{0x12, 0x34, 0x50}, 5)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("12345"), d)
+	d, err = DecodeRight([]byte{0x01, 0x23, 0x45}, 5)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("12345"), d)
+
+	_, err = DecodeRight([]byte{0x01}, 3)
+	assert.True(t, errors.Is(err, ErrShortData))
+	assert.EqualError(t, err, "BCD data is shorter than length: 1 bytes, length 3")
+}
@@ -0,0 +1,123 @@
+package iso8583
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+const (
+	ERR_BAD_ENVELOPE string = "bad envelope data"
+)
+
+var ErrBadEnvelope = errors.New(ERR_BAD_ENVELOPE)
+
+// envelopeVersion is version of binary form of Envelope
+const envelopeVersion = 1
+
+// Envelope bundles packed message with metadata, for piping ISO traffic
+// through message brokers. It is encoded with MarshalBinary or as JSON
+// (Message is base64 encoded).
+type Envelope struct {
+	// Received is time when message was received
+	Received time.Time `json:"received"`
+	// Source is endpoint which message was received from, for ex. address
+	// of the host
+	Source string `json:"source,omitempty"`
+	// SpecVersion identifies spec which message is packed with, for ex.
+	// "visa/2024.1"
+	SpecVersion string `json:"spec_version,omitempty"`
+	// CorrelationID ties message to upstream request
+	CorrelationID string `json:"correlation_id,omitempty"`
+	// Message is packed message
+	Message []byte `json:"message"`
+}
+
+// NewEnvelope packs msg into Envelope received now from source, with
+// CorrelationID of msg
+func NewEnvelope(msg *Message, source string) (*Envelope, error) {
+	raw, err := msg.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return &Envelope{
+		Received:      time.Now(),
+		Source:        source,
+		CorrelationID: msg.CorrelationID,
+		Message:       raw,
+	}, nil
+}
+
+// Parse parses message of envelope with p, CorrelationID of envelope is
+// set to the message
+func (e *Envelope) Parse(p *Parser) (*Message, error) {
+	msg, err := p.Parse(e.Message)
+	if err != nil {
+		return nil, err
+	}
+	msg.CorrelationID = e.CorrelationID
+	return msg, nil
+}
+
+// MarshalBinary encodes envelope as version byte, received time in Unix
+// nanoseconds (8 bytes big-endian, 0 for zero time), source, spec version
+// and correlation ID with 2 bytes length each, and message with 4 bytes
+// length
+func (e *Envelope) MarshalBinary() ([]byte, error) {
+	strs := []string{e.Source, e.SpecVersion, e.CorrelationID}
+	n := 1 + 8 + 4 + len(e.Message)
+	for _, s := range strs {
+		if len(s) > 0xffff {
+			return nil, ErrBadEnvelope
+		}
+		n += 2 + len(s)
+	}
+	if uint64(len(e.Message)) > 0xffffffff {
+		return nil, ErrBadEnvelope
+	}
+
+	b := make([]byte, n)
+	b[0] = envelopeVersion
+	var ts int64
+	if !e.Received.IsZero() {
+		ts = e.Received.UnixNano()
+	}
+	binary.BigEndian.PutUint64(b[1:], uint64(ts))
+	off := 9
+	for _, s := range strs {
+		binary.BigEndian.PutUint16(b[off:], uint16(len(s)))
+		off += 2 + copy(b[off+2:], s)
+	}
+	binary.BigEndian.PutUint32(b[off:], uint32(len(e.Message)))
+	copy(b[off+4:], e.Message)
+	return b, nil
+}
+
+// UnmarshalBinary decodes envelope encoded by MarshalBinary
+func (e *Envelope) UnmarshalBinary(b []byte) error {
+	if len(b) < 9 || b[0] != envelopeVersion {
+		return ErrBadEnvelope
+	}
+	var res Envelope
+	if ts := int64(binary.BigEndian.Uint64(b[1:9])); ts != 0 {
+		res.Received = time.Unix(0, ts)
+	}
+	b = b[9:]
+	for _, s := range []*string{&res.Source, &res.SpecVersion, &res.CorrelationID} {
+		if len(b) < 2 {
+			return ErrBadEnvelope
+		}
+		l := int(binary.BigEndian.Uint16(b))
+		if len(b) < 2+l {
+			return ErrBadEnvelope
+		}
+		*s = string(b[2 : 2+l])
+		b = b[2+l:]
+	}
+	if len(b) < 4 || uint64(len(b)-4) != uint64(binary.BigEndian.Uint32(b)) {
+		return ErrBadEnvelope
+	}
+	res.Message = append([]byte(nil), b[4:]...)
+	*e = res
+	return nil
+}
@@ -0,0 +1,60 @@
+package iso8583
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestEnvelope(t *testing.T) {
+	type test struct {
+		F11 *Numeric `field:"11" length:"6"`
+		F70 *Numeric `field:"70" length:"3"`
+	}
+
+	e, err := NewEnvelope(NewMessage("0800", &test{F11: NewNumeric("000001"), F70: NewNumeric("301")}), "10.0.0.1:5000")
+	assert.Nil(t, err)
+	e.Received = time.Date(2026, 10, 15, 13, 4, 5, 6, time.UTC)
+	e.SpecVersion = "visa/2026.1"
+	e.CorrelationID = "req-42"
+
+	b, err := e.MarshalBinary()
+	assert.Nil(t, err)
+	assert.Equal(t, byte(1), b[0])
+	var res Envelope
+	assert.Nil(t, res.UnmarshalBinary(b))
+	assert.True(t, e.Received.Equal(res.Received))
+	assert.Equal(t, e.Source, res.Source)
+	assert.Equal(t, e.SpecVersion, res.SpecVersion)
+	assert.Equal(t, e.CorrelationID, res.CorrelationID)
+	assert.Equal(t, e.Message, res.Message)
+
+	j, err := json.Marshal(e)
+	assert.Nil(t, err)
+	res = Envelope{}
+	assert.Nil(t, json.Unmarshal(j, &res))
+	assert.True(t, e.Received.Equal(res.Received))
+	assert.Equal(t, "req-42", res.CorrelationID)
+	assert.Equal(t, e.Message, res.Message)
+
+	p := &Parser{}
+	p.Register("0800", &test{})
+	msg, err := res.Parse(p)
+	assert.Nil(t, err)
+	assert.Equal(t, "301", msg.Data.(*test).F70.Value)
+
+	// zero time round-trips
+	b, err = (&Envelope{Message: []byte("x")}).MarshalBinary()
+	assert.Nil(t, err)
+	assert.Nil(t, res.UnmarshalBinary(b))
+	assert.True(t, res.Received.IsZero())
+	assert.Equal(t, "", res.Source)
+
+	assert.Equal(t, ErrBadEnvelope, res.UnmarshalBinary(b[:len(b)-1]))
+	assert.Equal(t, ErrBadEnvelope, res.UnmarshalBinary(append([]byte{2}, b[1:]...)))
+	assert.Equal(t, []byte("x"), res.Message)
+
+	_, err = NewEnvelope(NewMessage("08", &test{}), "")
+	assert.EqualError(t, err, "MTI is invalid")
+}
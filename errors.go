@@ -0,0 +1,97 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Sentinel errors for use with errors.Is. Their messages are the ERR_*
+// constants.
+var (
+	ErrInvalidEncoder       = errors.New(ERR_INVALID_ENCODER)
+	ErrInvalidLengthEncoder = errors.New(ERR_INVALID_LENGTH_ENCODER)
+	ErrInvalidLengthHead    = errors.New(ERR_INVALID_LENGTH_HEAD)
+	ErrMissingLength        = errors.New(ERR_MISSING_LENGTH)
+	ErrValueTooLong         = errors.New("length of value is longer than definition")
+	ErrBadRaw               = errors.New(ERR_BAD_RAW)
+	ErrParseLengthFailed    = errors.New(ERR_PARSE_LENGTH_FAILED)
+	ErrMessageTooLarge      = errors.New(ERR_MESSAGE_TOO_LARGE)
+)
+
+// ValueTooLongError is returned when field value is longer than its
+// definition. It matches ErrValueTooLong.
+type ValueTooLongError struct {
+	Type   string // field type name, for ex. "Numeric"
+	Length int    // defined length
+	Actual int    // length of value
+}
+
+func valueTooLong(typ string, length, actual int) error {
+	return &ValueTooLongError{typ, length, actual}
+}
+
+func (e *ValueTooLongError) Error() string {
+	return fmt.Sprintf(ERR_VALUE_TOO_LONG, e.Type, e.Length, e.Actual)
+}
+
+// Unwrap returns ErrValueTooLong
+func (e *ValueTooLongError) Unwrap() error {
+	return ErrValueTooLong
+}
+
+// FieldError is returned when field can not be decoded or is rejected on
+// encoding. Err is the cause.
+type FieldError struct {
+	Field int
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("field %d: %s", e.Field, e.Err)
+}
+
+// Unwrap returns the cause
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// MessageTooLargeError is returned when packed message is larger than
+// MaxSize. It matches ErrMessageTooLarge.
+type MessageTooLargeError struct {
+	Size   int // size of packed message
+	Limit  int
+	Fields []PlanField // the largest fields, largest first
+}
+
+func (e *MessageTooLargeError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %d bytes, limit is %d", ERR_MESSAGE_TOO_LARGE, e.Size, e.Limit)
+	for i, f := range e.Fields {
+		if i == 0 {
+			b.WriteString("; largest fields:")
+		} else {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, " %d (%d bytes)", f.Field, len(f.Data))
+	}
+	return b.String()
+}
+
+// Unwrap returns ErrMessageTooLarge
+func (e *MessageTooLargeError) Unwrap() error {
+	return ErrMessageTooLarge
+}
+
+// badMtiRawError is returned when raw data is too short for MTI. It
+// matches ErrBadRaw.
+type badMtiRawError struct{}
+
+func (badMtiRawError) Error() string {
+	return "bad MTI raw data"
+}
+
+// Unwrap returns ErrBadRaw
+func (badMtiRawError) Unwrap() error {
+	return ErrBadRaw
+}
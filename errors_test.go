@@ -0,0 +1,39 @@
+package iso8583
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorsIs(t *testing.T) {
+	_, err := NewNumeric("123").Bytes(ASCII, ASCII, 2)
+	assert.True(t, errors.Is(err, ErrValueTooLong))
+	var tooLong *ValueTooLongError
+	assert.True(t, errors.As(err, &tooLong))
+	assert.Equal(t, &ValueTooLongError{"Numeric", 2, 3}, tooLong)
+
+	_, err = NewLlvar([]byte("abc")).Bytes(BCD, ASCII, 5)
+	assert.True(t, errors.Is(err, ErrInvalidEncoder))
+
+	_, err = (&Llnumeric{}).Load([]byte("x1"), ASCII, ASCII, 5)
+	assert.True(t, errors.Is(err, ErrParseLengthFailed))
+	assert.EqualError(t, err, "parse length head failed: x1")
+
+	type data struct {
+		F41 *Alphanumeric `field:"41" length:"8"`
+	}
+	iso := NewMessage("", &data{&Alphanumeric{}})
+	err = iso.Load([]byte("0200\x00\x00\x00\x00\x00\x80\x00\x00TERM"))
+	assert.True(t, errors.Is(err, ErrBadRaw))
+	var fieldErr *FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, 41, fieldErr.Field)
+	assert.EqualError(t, err, "field 41: bad raw data")
+
+	_, err = NewCurrency("ABC")
+	assert.True(t, errors.Is(err, ErrUnknownCurrency))
+	_, err = ParseTrack1("B123")
+	assert.True(t, errors.Is(err, ErrInvalidTrack1))
+}
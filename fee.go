@@ -0,0 +1,131 @@
+package iso8583
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Indicators of FeeAmount
+const (
+	FEE_CREDIT byte = 'C'
+	FEE_DEBIT  byte = 'D'
+)
+
+// A FeeAmount contains amount in "x+n" format, where x is credit (C) or
+// debit (D) indicator, for ex. fee amounts of fields 28-31. Length is
+// number of digits without indicator. Supported encoders of digits are
+// ascii, ebcdic, bcd and rbcd. Indicator is ebcdic if any of encoders of
+// encode tag is ebcdic (for ex. `encode:"ebcdic,bcd"` for ebcdic indicator
+// and bcd digits), otherwise it is ascii.
+type FeeAmount struct {
+	Indicator byte
+	Value     string
+}
+
+// NewFeeAmount create new FeeAmount field from amount in minor units,
+// negative amount is debit
+func NewFeeAmount(amount int64) *FeeAmount {
+	if amount < 0 {
+		return &FeeAmount{FEE_DEBIT, strconv.FormatInt(-amount, 10)}
+	}
+	return &FeeAmount{FEE_CREDIT, strconv.FormatInt(amount, 10)}
+}
+
+// ParseFeeAmount parses amount in "x+n" format, for ex. "D00000150"
+func ParseFeeAmount(s string) (*FeeAmount, error) {
+	if len(s) < 2 || (s[0] != FEE_CREDIT && s[0] != FEE_DEBIT) || !isDigits(s[1:]) {
+		return nil, fmt.Errorf("%w: %s", ErrBadAmount, s)
+	}
+	return &FeeAmount{s[0], s[1:]}, nil
+}
+
+// Int64 returns signed amount in minor units, debit is negative
+func (f *FeeAmount) Int64() (int64, error) {
+	n, err := strconv.ParseInt(f.Value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%w: %s", ErrBadAmount, f.Value)
+	}
+	if f.Indicator == FEE_DEBIT {
+		return -n, nil
+	}
+	return n, nil
+}
+
+// String returns amount in "x+n" format
+func (f *FeeAmount) String() string {
+	return string(f.Indicator) + f.Value
+}
+
+// IsEmpty check FeeAmount field for empty value
+func (f *FeeAmount) IsEmpty() bool {
+	return len(f.Value) == 0
+}
+
+// Bytes encode FeeAmount field to bytes
+func (f *FeeAmount) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
+	return f.bytesText(encoder, lenEncoder, length, nil)
+}
+
+// Load decode FeeAmount field from bytes
+func (f *FeeAmount) Load(raw []byte, encoder, lenEncoder, length int) (int, error) {
+	return f.loadText(raw, encoder, lenEncoder, length, nil)
+}
+
+// indicatorEncoder returns encoder of indicator
+func indicatorEncoder(encoder, lenEncoder int) int {
+	if encoder == EBCDIC || lenEncoder == EBCDIC {
+		return EBCDIC
+	}
+	return ASCII
+}
+
+func (f *FeeAmount) bytesText(encoder, lenEncoder, length int, cp *CodePage) ([]byte, error) {
+	if f.Indicator != FEE_CREDIT && f.Indicator != FEE_DEBIT {
+		return nil, fmt.Errorf("%w: %s", ErrBadAmount, f.String())
+	}
+	ret, err := encodeText(string(f.Indicator), indicatorEncoder(encoder, lenEncoder), cp)
+	if err != nil {
+		return nil, err
+	}
+	digitsEncoder := encoder
+	if encoder == EBCDIC {
+		digitsEncoder = ASCII
+	}
+	d, err := NewNumeric(f.Value).Bytes(digitsEncoder, lenEncoder, length)
+	if err != nil {
+		return nil, err
+	}
+	if encoder == EBCDIC {
+		d = codePageOrDefault(cp).Encode(d)
+	}
+	return append(ret, d...), nil
+}
+
+func (f *FeeAmount) loadText(raw []byte, encoder, lenEncoder, length int, cp *CodePage) (int, error) {
+	if len(raw) < 1 {
+		return 0, ErrBadRaw
+	}
+	ind, err := decodeText(raw[:1], indicatorEncoder(encoder, lenEncoder), cp)
+	if err != nil {
+		return 0, err
+	}
+	if ind[0] != FEE_CREDIT && ind[0] != FEE_DEBIT {
+		return 0, fmt.Errorf("%w: indicator %q", ErrBadAmount, ind[0])
+	}
+	digits := raw[1:]
+	digitsEncoder := encoder
+	if encoder == EBCDIC {
+		if length < 0 || len(digits) < length {
+			return 0, ErrBadRaw
+		}
+		digits, digitsEncoder = codePageOrDefault(cp).Decode(digits[:length]), ASCII
+	}
+	n := &Numeric{}
+	read, err := n.Load(digits, digitsEncoder, lenEncoder, length)
+	if err != nil {
+		return 0, err
+	}
+	f.Indicator = ind[0]
+	f.Value = n.Value
+	return read + 1, nil
+}
@@ -0,0 +1,60 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFeeAmount(t *testing.T) {
+	type test struct {
+		F28 *FeeAmount `field:"28" length:"8"`
+		F29 *FeeAmount `field:"29" length:"8" encode:"bcd"`
+	}
+	data := &test{NewFeeAmount(-150), NewFeeAmount(25)}
+	iso := NewMessage("0200", data)
+	res, err := iso.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "D00000150", string(res[12:21]))
+	assert.Equal(t, "4300000025", fmt.Sprintf("%x", res[21:]))
+
+	loaded := &test{&FeeAmount{}, &FeeAmount{}}
+	assert.Nil(t, NewMessage("", loaded).Load(res))
+	assert.Equal(t, &FeeAmount{FEE_DEBIT, "00000150"}, loaded.F28)
+	n, err := loaded.F28.Int64()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-150), n)
+	n, err = loaded.F29.Int64()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(25), n)
+
+	assert.Nil(t, iso.SetString(28, "C00000001"))
+	s, err := iso.GetString(28)
+	assert.Nil(t, err)
+	assert.Equal(t, "C00000001", s)
+	assert.True(t, errors.Is(iso.SetString(28, "X1"), ErrBadAmount))
+	assert.True(t, errors.Is(iso.SetString(28, "C000000001"), ErrValueTooLong))
+
+	_, err = ParseFeeAmount("D")
+	assert.True(t, errors.Is(err, ErrBadAmount))
+	err = NewMessage("", loaded).Load(append(res[:12:12], "X00000150"...))
+	assert.True(t, errors.Is(err, ErrBadAmount))
+}
+
+func TestFeeAmountEbcdic(t *testing.T) {
+	type test struct {
+		F28 *FeeAmount `field:"28" length:"8" encode:"ebcdic"`
+		F29 *FeeAmount `field:"29" length:"8" encode:"ebcdic,bcd"`
+	}
+	data := &test{NewFeeAmount(-150), NewFeeAmount(25)}
+	iso := NewMessage("0200", data)
+	res, err := iso.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "c4f0f0f0f0f0f1f5f0"+"c300000025", fmt.Sprintf("%x", res[12:]))
+
+	loaded := &test{&FeeAmount{}, &FeeAmount{}}
+	assert.Nil(t, NewMessage("", loaded).Load(res))
+	assert.Equal(t, &FeeAmount{FEE_DEBIT, "00000150"}, loaded.F28)
+	assert.Equal(t, &FeeAmount{FEE_CREDIT, "00000025"}, loaded.F29)
+}
@@ -1,9 +1,7 @@
 package iso8583
 
 import (
-	"errors"
-	"fmt"
-	"strconv"
+	"encoding/hex"
 	"strings"
 )
 
@@ -14,6 +12,13 @@ const (
 	BCD
 	// rBCD is "right-aligned" BCD with odd length (for ex. "643" as [6 67] == "0643"), only for Numeric, Llnumeric and Lllnumeric fields
 	rBCD
+	// EBCDIC is EBCDIC encoding (code page 037 by default) of MTI, length heads, LlvarText, LllvarText, Llnumeric and Lllnumeric fields
+	EBCDIC
+	// BINARY is 2 bytes big-endian binary encoding of numeric value, only for MTI
+	// and length heads (1 byte for 2 digits, 2 bytes for 3 digits)
+	BINARY
+	// ZONED is EBCDIC zoned decimal with sign in zone of the last digit (for ex. "-12" as [0xf1 0xd2]), only for Numeric fields
+	ZONED
 )
 
 const (
@@ -23,10 +28,14 @@ const (
 	ERR_MISSING_LENGTH         string = "missing length"
 	ERR_VALUE_TOO_LONG         string = "length of value is longer than definition; type=%s, def_len=%d, len=%d"
 	ERR_BAD_RAW                string = "bad raw data"
+	ERR_MESSAGE_TOO_LARGE      string = "message is too large"
 	ERR_PARSE_LENGTH_FAILED    string = "parse length head failed"
 )
 
-// Iso8583Type interface for ISO 8583 fields
+// Iso8583Type interface for ISO 8583 fields. Load of Bytes output with the
+// same arguments must restore the same value (fixed length values are
+// padded), this round trip is checked in roundtrip_test.go for all field
+// types and encoders.
 type Iso8583Type interface {
 	// Byte representation of current field.
 	Bytes(encoder, lenEncoder, length int) ([]byte, error)
@@ -60,7 +69,10 @@ func (n *Numeric) IsEmpty() bool {
 func (n *Numeric) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
 	val := []byte(n.Value)
 	if length == -1 {
-		return nil, errors.New(ERR_MISSING_LENGTH)
+		return nil, ErrMissingLength
+	}
+	if encoder == ZONED {
+		return zoned(n.Value, length)
 	}
 	// if encoder == rBCD then length can be, for example, 3,
 	// but value can be, for example, "0631" (after decode from rBCD, because BCD use 1 byte for 2 digits),
@@ -73,7 +85,7 @@ func (n *Numeric) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
 	}
 
 	if len(val) > length {
-		return nil, errors.New(fmt.Sprintf(ERR_VALUE_TOO_LONG, "Numeric", length, len(val)))
+		return nil, valueTooLong("Numeric", length, len(val))
 	}
 	if len(val) < length {
 		val = append([]byte(strings.Repeat("0", length-len(val))), val...)
@@ -86,38 +98,48 @@ func (n *Numeric) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
 	case ASCII:
 		return val, nil
 	default:
-		return nil, errors.New(ERR_INVALID_ENCODER)
+		return nil, ErrInvalidEncoder
 	}
 }
 
 // Load decode Numeric field from bytes
 func (n *Numeric) Load(raw []byte, encoder, lenEncoder, length int) (int, error) {
 	if length == -1 {
-		return 0, errors.New(ERR_MISSING_LENGTH)
+		return 0, ErrMissingLength
 	}
 	switch encoder {
 	case BCD:
 		l := (length + 1) / 2
 		if len(raw) < l {
-			return 0, errors.New(ERR_BAD_RAW)
+			return 0, ErrBadRaw
 		}
 		n.Value = string(bcdl2Ascii(raw[:l], length))
 		return l, nil
 	case rBCD:
 		l := (length + 1) / 2
 		if len(raw) < l {
-			return 0, errors.New(ERR_BAD_RAW)
+			return 0, ErrBadRaw
 		}
 		n.Value = string(bcdr2Ascii(raw[0:l], length))
 		return l, nil
 	case ASCII:
 		if len(raw) < length {
-			return 0, errors.New(ERR_BAD_RAW)
+			return 0, ErrBadRaw
 		}
 		n.Value = string(raw[:length])
 		return length, nil
+	case ZONED:
+		if len(raw) < length {
+			return 0, ErrBadRaw
+		}
+		val, err := unzoned(raw[:length])
+		if err != nil {
+			return 0, err
+		}
+		n.Value = val
+		return length, nil
 	default:
-		return 0, errors.New(ERR_INVALID_ENCODER)
+		return 0, ErrInvalidEncoder
 	}
 }
 
@@ -138,14 +160,17 @@ func (a *Alphanumeric) IsEmpty() bool {
 	return len(a.Value) == 0
 }
 
-// Bytes encode Alphanumeric field to bytes
+// Bytes encode Alphanumeric field to bytes, ebcdic uses code page 037
 func (a *Alphanumeric) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
+	if encoder == EBCDIC {
+		return a.bytesText(encoder, lenEncoder, length, nil)
+	}
 	val := []byte(a.Value)
 	if length == -1 {
-		return nil, errors.New(ERR_MISSING_LENGTH)
+		return nil, ErrMissingLength
 	}
 	if len(val) > length {
-		return nil, errors.New(fmt.Sprintf(ERR_VALUE_TOO_LONG, "Alphanumeric", length, len(val)))
+		return nil, valueTooLong("Alphanumeric", length, len(val))
 	}
 	if len(val) < length {
 		val = append([]byte(strings.Repeat(" ", length-len(val))), val...)
@@ -153,13 +178,16 @@ func (a *Alphanumeric) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
 	return val, nil
 }
 
-// Load decode Alphanumeric field from bytes
+// Load decode Alphanumeric field from bytes, ebcdic uses code page 037
 func (a *Alphanumeric) Load(raw []byte, encoder, lenEncoder, length int) (int, error) {
+	if encoder == EBCDIC {
+		return a.loadText(raw, encoder, lenEncoder, length, nil)
+	}
 	if length == -1 {
-		return 0, errors.New(ERR_MISSING_LENGTH)
+		return 0, ErrMissingLength
 	}
 	if len(raw) < length {
-		return 0, errors.New(ERR_BAD_RAW)
+		return 0, ErrBadRaw
 	}
 	a.Value = string(raw[:length])
 	return length, nil
@@ -176,6 +204,21 @@ func NewBinary(d []byte) *Binary {
 	return &Binary{d, -1}
 }
 
+// NewBinaryHex create new Binary field from hex string, for ex.
+// "9F1A020840"
+func NewBinaryHex(s string) (*Binary, error) {
+	d, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return NewBinary(d), nil
+}
+
+// Hex returns value of Binary field as upper case hex string
+func (b *Binary) Hex() string {
+	return strings.ToUpper(hex.EncodeToString(b.Value))
+}
+
 // IsEmpty check Binary field for empty value
 func (b *Binary) IsEmpty() bool {
 	return len(b.Value) == 0
@@ -188,10 +231,10 @@ func (b *Binary) Bytes(encoder, lenEncoder, l int) ([]byte, error) {
 		length = b.FixLen
 	}
 	if length == -1 {
-		return nil, errors.New(ERR_MISSING_LENGTH)
+		return nil, ErrMissingLength
 	}
 	if len(b.Value) > length {
-		return nil, errors.New(fmt.Sprintf(ERR_VALUE_TOO_LONG, "Binary", length, len(b.Value)))
+		return nil, valueTooLong("Binary", length, len(b.Value))
 	}
 	if len(b.Value) < length {
 		return append(b.Value, make([]byte, length-len(b.Value))...), nil
@@ -202,10 +245,10 @@ func (b *Binary) Bytes(encoder, lenEncoder, l int) ([]byte, error) {
 // Load decode Binary field from bytes
 func (b *Binary) Load(raw []byte, encoder, lenEncoder, length int) (int, error) {
 	if length == -1 {
-		return 0, errors.New(ERR_MISSING_LENGTH)
+		return 0, ErrMissingLength
 	}
 	if len(raw) < length {
-		return 0, errors.New(ERR_BAD_RAW)
+		return 0, ErrBadRaw
 	}
 	b.Value = raw[:length]
 	b.FixLen = length
@@ -230,30 +273,15 @@ func (l *Llvar) IsEmpty() bool {
 // Bytes encode Llvar field to bytes
 func (l *Llvar) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
 	if length != -1 && len(l.Value) > length {
-		return nil, errors.New(fmt.Sprintf(ERR_VALUE_TOO_LONG, "Llvar", length, len(l.Value)))
+		return nil, valueTooLong("Llvar", length, len(l.Value))
 	}
 	if encoder != ASCII {
-		return nil, errors.New(ERR_INVALID_ENCODER)
+		return nil, ErrInvalidEncoder
 	}
 
-	lenStr := fmt.Sprintf("%02d", len(l.Value))
-	contentLen := []byte(lenStr)
-	var lenVal []byte
-	switch lenEncoder {
-	case ASCII:
-		lenVal = contentLen
-		if len(lenVal) > 2 {
-			return nil, errors.New(ERR_INVALID_LENGTH_HEAD)
-		}
-	case rBCD:
-		fallthrough
-	case BCD:
-		lenVal = rbcd(contentLen)
-		if len(lenVal) > 1 {
-			return nil, errors.New(ERR_INVALID_LENGTH_HEAD)
-		}
-	default:
-		return nil, errors.New(ERR_INVALID_LENGTH_ENCODER)
+	lenVal, err := encodeLengthHead(len(l.Value), 2, lenEncoder)
+	if err != nil {
+		return nil, err
 	}
 	return append(lenVal, l.Value...), nil
 }
@@ -261,40 +289,25 @@ func (l *Llvar) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
 // Load decode Llvar field from bytes
 func (l *Llvar) Load(raw []byte, encoder, lenEncoder, length int) (read int, err error) {
 	// parse length head:
-	var contentLen int
-	switch lenEncoder {
-	case ASCII:
-		read = 2
-		contentLen, err = strconv.Atoi(string(raw[:read]))
-		if err != nil {
-			return 0, errors.New(ERR_PARSE_LENGTH_FAILED + ": " + string(raw[:2]))
-		}
-	case rBCD:
-		fallthrough
-	case BCD:
-		read = 1
-		contentLen, err = strconv.Atoi(string(bcdr2Ascii(raw[:read], 2)))
-		if err != nil {
-			return 0, errors.New(ERR_PARSE_LENGTH_FAILED + ": " + string(raw[0]))
-		}
-	default:
-		return 0, errors.New(ERR_INVALID_LENGTH_ENCODER)
+	contentLen, read, err := decodeLengthHead(raw, 2, lenEncoder)
+	if err != nil {
+		return 0, err
 	}
 	if len(raw) < (read + contentLen) {
-		return 0, errors.New(ERR_BAD_RAW)
+		return 0, ErrBadRaw
 	}
 	// parse body:
 	l.Value = raw[read : read+contentLen]
 	read += contentLen
 	if encoder != ASCII {
-		return 0, errors.New(ERR_INVALID_ENCODER)
+		return 0, ErrInvalidEncoder
 	}
 
 	return read, nil
 }
 
 // A Llnumeric contains numeric value only in non-fix length, contains length in first 2 symbols. It holds numeric
-// value as a string. Supportted encoder are ascii, bcd and rbcd. Length is
+// value as a string. Supportted encoder are ascii, bcd, rbcd and ebcdic. Length is
 // required for marshalling and unmarshalling.
 type Llnumeric struct {
 	Value string
@@ -314,7 +327,7 @@ func (l *Llnumeric) IsEmpty() bool {
 func (l *Llnumeric) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
 	raw := []byte(l.Value)
 	if length != -1 && len(raw) > length {
-		return nil, errors.New(fmt.Sprintf(ERR_VALUE_TOO_LONG, "Llnumeric", length, len(raw)))
+		return nil, valueTooLong("Llnumeric", length, len(raw))
 	}
 
 	val := raw
@@ -324,74 +337,54 @@ func (l *Llnumeric) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
 		val = lbcd(raw)
 	case rBCD:
 		val = rbcd(raw)
+	case EBCDIC:
+		return l.bytesText(encoder, lenEncoder, length, nil)
 	default:
-		return nil, errors.New(ERR_INVALID_ENCODER)
+		return nil, ErrInvalidEncoder
 	}
 
-	lenStr := fmt.Sprintf("%02d", len(raw)) // length of digital characters
-	contentLen := []byte(lenStr)
-	var lenVal []byte
-	switch lenEncoder {
-	case ASCII:
-		lenVal = contentLen
-		if len(lenVal) > 2 {
-			return nil, errors.New(ERR_INVALID_LENGTH_HEAD)
-		}
-	case rBCD:
-		fallthrough
-	case BCD:
-		lenVal = rbcd(contentLen)
-		if len(lenVal) > 1 || len(contentLen) > 3 {
-			return nil, errors.New(ERR_INVALID_LENGTH_HEAD)
-		}
-	default:
-		return nil, errors.New(ERR_INVALID_LENGTH_ENCODER)
+	lenVal, err := encodeLengthHead(len(raw), 2, lenEncoder)
+	if err != nil {
+		return nil, err
 	}
 	return append(lenVal, val...), nil
 }
 
 // Load decode Llnumeric field from bytes
 func (l *Llnumeric) Load(raw []byte, encoder, lenEncoder, length int) (read int, err error) {
+	if encoder == EBCDIC {
+		return l.loadText(raw, encoder, lenEncoder, length, nil)
+	}
 	// parse length head:
-	var contentLen int
-	switch lenEncoder {
-	case ASCII:
-		read = 2
-		contentLen, err = strconv.Atoi(string(raw[:read]))
-		if err != nil {
-			return 0, errors.New(ERR_PARSE_LENGTH_FAILED + ": " + string(raw[:2]))
-		}
-	case rBCD:
-		fallthrough
-	case BCD:
-		read = 1
-		contentLen, err = strconv.Atoi(string(bcdr2Ascii(raw[:read], 2)))
-		if err != nil {
-			return 0, errors.New(ERR_PARSE_LENGTH_FAILED + ": " + string(raw[0]))
-		}
-	default:
-		return 0, errors.New(ERR_INVALID_LENGTH_ENCODER)
+	contentLen, read, err := decodeLengthHead(raw, 2, lenEncoder)
+	if err != nil {
+		return 0, err
 	}
 
 	// parse body:
 	switch encoder {
 	case ASCII:
 		if len(raw) < (read + contentLen) {
-			return 0, errors.New(ERR_BAD_RAW)
+			return 0, ErrBadRaw
 		}
 		l.Value = string(raw[read : read+contentLen])
 		read += contentLen
-	case rBCD:
-		fallthrough
 	case BCD:
 		bcdLen := (contentLen + 1) / 2
 		if len(raw) < (read + bcdLen) {
-			return 0, errors.New(ERR_BAD_RAW)
+			return 0, ErrBadRaw
 		}
 		l.Value = string(bcdl2Ascii(raw[read:read+bcdLen], contentLen))
 		read += bcdLen
+	case rBCD:
+		bcdLen := (contentLen + 1) / 2
+		if len(raw) < (read + bcdLen) {
+			return 0, ErrBadRaw
+		}
+		l.Value = string(bcdr2Ascii(raw[read:read+bcdLen], contentLen))
+		read += bcdLen
 	default:
-		return 0, errors.New(ERR_INVALID_ENCODER)
+		return 0, ErrInvalidEncoder
 	}
 	return read, nil
 }
@@ -414,30 +407,15 @@ func (l *Lllvar) IsEmpty() bool {
 // Bytes encode Lllvar field to bytes
 func (l *Lllvar) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
 	if length != -1 && len(l.Value) > length {
-		return nil, errors.New(fmt.Sprintf(ERR_VALUE_TOO_LONG, "Lllvar", length, len(l.Value)))
+		return nil, valueTooLong("Lllvar", length, len(l.Value))
 	}
 	if encoder != ASCII {
-		return nil, errors.New(ERR_INVALID_ENCODER)
+		return nil, ErrInvalidEncoder
 	}
 
-	lenStr := fmt.Sprintf("%03d", len(l.Value))
-	contentLen := []byte(lenStr)
-	var lenVal []byte
-	switch lenEncoder {
-	case ASCII:
-		lenVal = contentLen
-		if len(lenVal) > 3 {
-			return nil, errors.New(ERR_INVALID_LENGTH_HEAD)
-		}
-	case rBCD:
-		fallthrough
-	case BCD:
-		lenVal = rbcd(contentLen)
-		if len(lenVal) > 2 || len(contentLen) > 3 {
-			return nil, errors.New(ERR_INVALID_LENGTH_HEAD)
-		}
-	default:
-		return nil, errors.New(ERR_INVALID_LENGTH_ENCODER)
+	lenVal, err := encodeLengthHead(len(l.Value), 3, lenEncoder)
+	if err != nil {
+		return nil, err
 	}
 	return append(lenVal, l.Value...), nil
 }
@@ -445,40 +423,25 @@ func (l *Lllvar) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
 // Load decode Lllvar field from bytes
 func (l *Lllvar) Load(raw []byte, encoder, lenEncoder, length int) (read int, err error) {
 	// parse length head:
-	var contentLen int
-	switch lenEncoder {
-	case ASCII:
-		read = 3
-		contentLen, err = strconv.Atoi(string(raw[:read]))
-		if err != nil {
-			return 0, errors.New(ERR_PARSE_LENGTH_FAILED + ": " + string(raw[:3]))
-		}
-	case rBCD:
-		fallthrough
-	case BCD:
-		read = 2
-		contentLen, err = strconv.Atoi(string(bcdr2Ascii(raw[:read], 3)))
-		if err != nil {
-			return 0, errors.New(ERR_PARSE_LENGTH_FAILED + ": " + string(raw[:2]))
-		}
-	default:
-		return 0, errors.New(ERR_INVALID_LENGTH_ENCODER)
+	contentLen, read, err := decodeLengthHead(raw, 3, lenEncoder)
+	if err != nil {
+		return 0, err
 	}
 	if len(raw) < (read + contentLen) {
-		return 0, errors.New(ERR_BAD_RAW)
+		return 0, ErrBadRaw
 	}
 	// parse body:
 	l.Value = raw[read : read+contentLen]
 	read += contentLen
 	if encoder != ASCII {
-		return 0, errors.New(ERR_INVALID_ENCODER)
+		return 0, ErrInvalidEncoder
 	}
 
 	return read, nil
 }
 
 // A Lllnumeric contains numeric value only in non-fix length, contains length in first 3 symbols. It holds numeric
-// value as a string. Supportted encoder are ascii, bcd and rbcd. Length is
+// value as a string. Supportted encoder are ascii, bcd, rbcd and ebcdic. Length is
 // required for marshalling and unmarshalling.
 type Lllnumeric struct {
 	Value string
@@ -498,7 +461,7 @@ func (l *Lllnumeric) IsEmpty() bool {
 func (l *Lllnumeric) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
 	raw := []byte(l.Value)
 	if length != -1 && len(raw) > length {
-		return nil, errors.New(fmt.Sprintf(ERR_VALUE_TOO_LONG, "Lllnumeric", length, len(raw)))
+		return nil, valueTooLong("Lllnumeric", length, len(raw))
 	}
 
 	val := raw
@@ -508,74 +471,54 @@ func (l *Lllnumeric) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
 		val = lbcd(raw)
 	case rBCD:
 		val = rbcd(raw)
+	case EBCDIC:
+		return l.bytesText(encoder, lenEncoder, length, nil)
 	default:
-		return nil, errors.New(ERR_INVALID_ENCODER)
+		return nil, ErrInvalidEncoder
 	}
 
-	lenStr := fmt.Sprintf("%03d", len(raw)) // length of digital characters
-	contentLen := []byte(lenStr)
-	var lenVal []byte
-	switch lenEncoder {
-	case ASCII:
-		lenVal = contentLen
-		if len(lenVal) > 3 {
-			return nil, errors.New(ERR_INVALID_LENGTH_HEAD)
-		}
-	case rBCD:
-		fallthrough
-	case BCD:
-		lenVal = rbcd(contentLen)
-		if len(lenVal) > 2 || len(contentLen) > 3 {
-			return nil, errors.New(ERR_INVALID_LENGTH_HEAD)
-		}
-	default:
-		return nil, errors.New(ERR_INVALID_LENGTH_ENCODER)
+	lenVal, err := encodeLengthHead(len(raw), 3, lenEncoder)
+	if err != nil {
+		return nil, err
 	}
 	return append(lenVal, val...), nil
 }
 
 // Load decode Lllnumeric field from bytes
 func (l *Lllnumeric) Load(raw []byte, encoder, lenEncoder, length int) (read int, err error) {
+	if encoder == EBCDIC {
+		return l.loadText(raw, encoder, lenEncoder, length, nil)
+	}
 	// parse length head:
-	var contentLen int
-	switch lenEncoder {
-	case ASCII:
-		read = 3
-		contentLen, err = strconv.Atoi(string(raw[:read]))
-		if err != nil {
-			return 0, errors.New(ERR_PARSE_LENGTH_FAILED + ": " + string(raw[:3]))
-		}
-	case rBCD:
-		fallthrough
-	case BCD:
-		read = 2
-		contentLen, err = strconv.Atoi(string(bcdr2Ascii(raw[:read], 2)))
-		if err != nil {
-			return 0, errors.New(ERR_PARSE_LENGTH_FAILED + ": " + string(raw[:2]))
-		}
-	default:
-		return 0, errors.New(ERR_INVALID_LENGTH_ENCODER)
+	contentLen, read, err := decodeLengthHead(raw, 3, lenEncoder)
+	if err != nil {
+		return 0, err
 	}
 
 	// parse body:
 	switch encoder {
 	case ASCII:
 		if len(raw) < (read + contentLen) {
-			return 0, errors.New(ERR_BAD_RAW)
+			return 0, ErrBadRaw
 		}
 		l.Value = string(raw[read : read+contentLen])
 		read += contentLen
-	case rBCD:
-		fallthrough
 	case BCD:
 		bcdLen := (contentLen + 1) / 2
 		if len(raw) < (read + bcdLen) {
-			return 0, errors.New(ERR_BAD_RAW)
+			return 0, ErrBadRaw
 		}
 		l.Value = string(bcdl2Ascii(raw[read:read+bcdLen], contentLen))
 		read += bcdLen
+	case rBCD:
+		bcdLen := (contentLen + 1) / 2
+		if len(raw) < (read + bcdLen) {
+			return 0, ErrBadRaw
+		}
+		l.Value = string(bcdr2Ascii(raw[read:read+bcdLen], contentLen))
+		read += bcdLen
 	default:
-		return 0, errors.New(ERR_INVALID_ENCODER)
+		return 0, ErrInvalidEncoder
 	}
 	return read, nil
 }
@@ -0,0 +1,132 @@
+package iso8583
+
+// FieldSpec describes how a field is packed, it is built from field tags
+// and options of Message. New options are added as new members, so
+// FieldV2 implementations don't break.
+type FieldSpec struct {
+	Index     int
+	Encode    int
+	LenEncode int
+	Length    int    // defined length, -1 if not defined
+	Pad       string // PAD_ZERO, PAD_SPACE or PAD_RIGHT
+	Filler    byte   // filler nibble of odd length bcd values, 0 or 0xf
+	Charset   string // character class, for ex. CHARSET_ANS, empty if not set
+	Quirks    Quirks
+	CodePage  *CodePage
+}
+
+// FieldV2 is field type which takes FieldSpec instead of positional
+// encoders and length of Iso8583Type. Types of Data members may implement
+// either of them; FieldV2Of and Iso8583TypeOf adapt one to another.
+type FieldV2 interface {
+	// BytesSpec returns byte representation of the field
+	BytesSpec(spec *FieldSpec) ([]byte, error)
+
+	// LoadSpec unmarshal raw into the field and returns the number of
+	// bytes actually read
+	LoadSpec(raw []byte, spec *FieldSpec) (int, error)
+
+	// IsEmpty check is field empty
+	IsEmpty() bool
+}
+
+// FieldV2Of returns f as FieldV2. Iso8583Type is packed with all options of
+// spec which it supports, as it is packed as a member of Data.
+func FieldV2Of(f Iso8583Type) FieldV2 {
+	if v2, ok := f.(FieldV2); ok {
+		return v2
+	}
+	if a, ok := f.(*v2Adapter); ok {
+		return a.field
+	}
+	return &v1Adapter{f}
+}
+
+// Iso8583TypeOf returns f as Iso8583Type, which Bytes and Load pass only
+// encoders and length to f
+func Iso8583TypeOf(f FieldV2) Iso8583Type {
+	if v1, ok := f.(Iso8583Type); ok {
+		return v1
+	}
+	if a, ok := f.(*v1Adapter); ok {
+		return a.field
+	}
+	return &v2Adapter{f}
+}
+
+// v1Adapter is FieldV2 of Iso8583Type
+type v1Adapter struct {
+	field Iso8583Type
+}
+
+func (a *v1Adapter) BytesSpec(spec *FieldSpec) ([]byte, error) {
+	return spec.info(a.field).bytes()
+}
+
+func (a *v1Adapter) LoadSpec(raw []byte, spec *FieldSpec) (int, error) {
+	return spec.info(a.field).load(raw)
+}
+
+func (a *v1Adapter) IsEmpty() bool {
+	return a.field.IsEmpty()
+}
+
+// v2Adapter is Iso8583Type of FieldV2
+type v2Adapter struct {
+	field FieldV2
+}
+
+func (a *v2Adapter) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
+	return a.field.BytesSpec(&FieldSpec{Encode: encoder, LenEncode: lenEncoder, Length: length, Pad: PAD_ZERO})
+}
+
+func (a *v2Adapter) Load(raw []byte, encoder, lenEncoder, length int) (int, error) {
+	return a.field.LoadSpec(raw, &FieldSpec{Encode: encoder, LenEncode: lenEncoder, Length: length, Pad: PAD_ZERO})
+}
+
+func (a *v2Adapter) IsEmpty() bool {
+	return a.field.IsEmpty()
+}
+
+// info returns fieldInfo of field packed according to spec
+func (s *FieldSpec) info(field Iso8583Type) *fieldInfo {
+	return &fieldInfo{
+		Index:     s.Index,
+		Encode:    s.Encode,
+		LenEncode: s.LenEncode,
+		Length:    s.Length,
+		Pad:       parsePadStr(s.Pad),
+		Filler:    s.Filler,
+		Charset:   s.Charset,
+		Quirks:    s.Quirks,
+		CodePage:  s.CodePage,
+		Field:     field,
+	}
+}
+
+// spec returns FieldSpec of f
+func (f *fieldInfo) spec() *FieldSpec {
+	return &FieldSpec{
+		Index:     f.Index,
+		Encode:    f.Encode,
+		LenEncode: f.LenEncode,
+		Length:    f.Length,
+		Pad:       padName(f.Pad),
+		Filler:    f.Filler,
+		Charset:   f.Charset,
+		Quirks:    f.Quirks,
+		CodePage:  f.CodePage,
+	}
+}
+
+// fieldV2 returns FieldV2 implemented by type of Data member, or nil if it
+// implements Iso8583Type only
+func (f *fieldInfo) fieldV2() FieldV2 {
+	if a, ok := f.Field.(*v2Adapter); ok {
+		return a.field
+	}
+	if v2, ok := f.Field.(FieldV2); ok {
+		return v2
+	}
+	return nil
+}
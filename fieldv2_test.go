@@ -0,0 +1,74 @@
+package iso8583
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// upperText is text which is packed in upper case, padded as Numeric
+type upperText struct {
+	Value string
+}
+
+func (u *upperText) BytesSpec(spec *FieldSpec) ([]byte, error) {
+	if spec.Length < 0 {
+		return nil, errors.New("length required")
+	}
+	n := FieldV2Of(NewNumeric(strings.ToUpper(u.Value)))
+	return n.BytesSpec(spec)
+}
+
+func (u *upperText) LoadSpec(raw []byte, spec *FieldSpec) (int, error) {
+	n := &Numeric{}
+	read, err := FieldV2Of(n).LoadSpec(raw, spec)
+	u.Value = n.Value
+	return read, err
+}
+
+func (u *upperText) IsEmpty() bool {
+	return len(u.Value) == 0
+}
+
+func TestFieldV2(t *testing.T) {
+	type test struct {
+		F2  *Llvar     `field:"2"`
+		F43 *upperText `field:"43" length:"6" pad:"right"`
+	}
+
+	iso := NewMessage("0100", &test{NewLlvar([]byte("ab")), &upperText{"ab"}})
+	res, err := iso.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "02ab"+"AB    ", string(res[12:]))
+
+	iso2 := NewMessage("", &test{&Llvar{}, &upperText{}})
+	err = iso2.Load(res)
+	assert.Nil(t, err)
+	assert.Equal(t, &test{NewLlvar([]byte("ab")), &upperText{"AB"}}, iso2.Data)
+
+	type test2 struct {
+		F43 *upperText `field:"43"`
+	}
+	iso = NewMessage("0100", &test2{&upperText{"ab"}})
+	_, err = iso.Bytes()
+	assert.EqualError(t, err, "length required")
+}
+
+func TestFieldV2Adapters(t *testing.T) {
+	n := NewNumeric("42")
+	v2 := FieldV2Of(n)
+	res, err := v2.BytesSpec(&FieldSpec{Length: 6, Pad: PAD_SPACE})
+	assert.Nil(t, err)
+	assert.Equal(t, "    42", string(res))
+	assert.Equal(t, n, Iso8583TypeOf(v2))
+
+	u := &upperText{"ab"}
+	v1 := Iso8583TypeOf(u)
+	res, err = v1.Bytes(ASCII, ASCII, 4)
+	assert.Nil(t, err)
+	assert.Equal(t, "00AB", string(res))
+	assert.Equal(t, u, FieldV2Of(v1))
+	assert.False(t, v1.IsEmpty())
+}
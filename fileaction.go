@@ -0,0 +1,71 @@
+package iso8583
+
+import (
+	"fmt"
+	"time"
+)
+
+// File update codes (field 91) of file action messages
+const (
+	FILE_UPDATE_ADD     string = "1" // record is added
+	FILE_UPDATE_CHANGE  string = "2" // record is changed
+	FILE_UPDATE_DELETE  string = "3" // record is deleted
+	FILE_UPDATE_REPLACE string = "4" // whole file is replaced
+	FILE_UPDATE_INQUIRY string = "5" // record is inquired
+)
+
+// FileAction contains fields of 0302/0312 file action messages, for ex.
+// updates of hot-card file. Field 101 is name of the file, field 48
+// carries record data if the file has any besides PAN.
+type FileAction struct {
+	F2   *Llnumeric    `field:"2" length:"19"`
+	F7   *Numeric      `field:"7" length:"10"`
+	F11  *Numeric      `field:"11" length:"6"`
+	F14  *Numeric      `field:"14" length:"4"`
+	F39  *Alphanumeric `field:"39" length:"2"`
+	F48  *Lllvar       `field:"48" length:"999"`
+	F91  *Alphanumeric `field:"91" length:"1"`
+	F101 *LlvarText    `field:"101" length:"17"`
+}
+
+// NewFileAction creates new 0302 message which applies file update code
+// to record of pan in file. Transmission date and time (field 7) is t in
+// UTC.
+func NewFileAction(file, code, pan, stan string, t time.Time) *Message {
+	return NewMessage("0302", &FileAction{
+		F2:   NewLlnumeric(pan),
+		F7:   NewNumeric(t.UTC().Format("0102150405")),
+		F11:  NewNumeric(stan),
+		F91:  NewAlphanumeric(code),
+		F101: NewLlvarText(file),
+	})
+}
+
+// NewHotCard creates new 0302 message which adds pan to hot-card file
+func NewHotCard(file, pan, stan string, t time.Time) *Message {
+	return NewFileAction(file, FILE_UPDATE_ADD, pan, stan, t)
+}
+
+// NewHotCardRemoval creates new 0302 message which deletes pan from
+// hot-card file
+func NewHotCardRemoval(file, pan, stan string, t time.Time) *Message {
+	return NewFileAction(file, FILE_UPDATE_DELETE, pan, stan, t)
+}
+
+// NewFileActionResponse creates 0312 response to 0302 request with
+// response code (field 39). Record data (field 48) is returned only for
+// inquiries.
+func NewFileActionResponse(req *Message, code string) (*Message, error) {
+	data, ok := req.Data.(*FileAction)
+	if !ok || req.Mti != "0302" {
+		return nil, fmt.Errorf("not a file action request: %s", req.Mti)
+	}
+	resp := req.Clone()
+	resp.Mti = "0312"
+	respData := resp.Data.(*FileAction)
+	respData.F39 = NewAlphanumeric(code)
+	if data.F91 == nil || data.F91.Value != FILE_UPDATE_INQUIRY {
+		respData.F48 = nil
+	}
+	return resp, nil
+}
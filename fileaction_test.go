@@ -0,0 +1,44 @@
+package iso8583
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestFileAction(t *testing.T) {
+	tm := time.Date(2026, 10, 15, 13, 4, 5, 0, time.UTC)
+
+	req := NewHotCard("HOTCARDS", "4276555555555558", "000001", tm)
+	res, err := req.Bytes()
+	assert.Nil(t, err)
+
+	parser := Parser{}
+	parser.Register("0302", &FileAction{})
+	parsed, err := parser.Parse(res)
+	assert.Nil(t, err)
+	data := parsed.Data.(*FileAction)
+	assert.Equal(t, FILE_UPDATE_ADD, data.F91.Value)
+	assert.Equal(t, "HOTCARDS", data.F101.Value)
+	assert.Equal(t, "4276555555555558", data.F2.Value)
+	assert.Equal(t, CLASS_FILE_ACTION, MtiClass(parsed.Mti))
+
+	removal := NewHotCardRemoval("HOTCARDS", "4276555555555558", "000002", tm)
+	assert.Equal(t, FILE_UPDATE_DELETE, removal.Data.(*FileAction).F91.Value)
+	removal.Data.(*FileAction).F48 = NewLllvar([]byte("record"))
+	resp, err := NewFileActionResponse(removal, "00")
+	assert.Nil(t, err)
+	assert.Equal(t, "0312", resp.Mti)
+	assert.Equal(t, "00", resp.Data.(*FileAction).F39.Value)
+	assert.Nil(t, resp.Data.(*FileAction).F48)
+	assert.Nil(t, removal.Data.(*FileAction).F39)
+
+	inquiry := NewFileAction("HOTCARDS", FILE_UPDATE_INQUIRY, "4276555555555558", "000003", tm)
+	inquiry.Data.(*FileAction).F48 = NewLllvar([]byte("record"))
+	resp, err = NewFileActionResponse(inquiry, "00")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("record"), resp.Data.(*FileAction).F48.Value)
+
+	_, err = NewFileActionResponse(resp, "00")
+	assert.EqualError(t, err, "not a file action request: 0312")
+}
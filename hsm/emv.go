@@ -0,0 +1,93 @@
+package hsm
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// CardMasterKey derives card master key from double length issuer master
+// key by EMV option A
+func CardMasterKey(imk []byte, pan, panSeq string) ([]byte, error) {
+	if len(imk) != 16 {
+		return nil, fmt.Errorf("issuer master key must be double length")
+	}
+	y := pan + panSeq
+	if len(y) < 16 {
+		y = strings.Repeat("0", 16-len(y)) + y
+	}
+	y = y[len(y)-16:]
+	raw, err := hex.DecodeString(y)
+	if err != nil {
+		return nil, fmt.Errorf("bad PAN: %s", pan)
+	}
+	return encryptHalves(imk, raw)
+}
+
+// encryptHalves returns double length key of left block and its inversion
+// encrypted with k
+func encryptHalves(k, left []byte) ([]byte, error) {
+	b, err := tdes(k)
+	if err != nil {
+		return nil, err
+	}
+	right := make([]byte, 8)
+	for i := range right {
+		right[i] = left[i] ^ 0xff
+	}
+	ret := make([]byte, 16)
+	b.Encrypt(ret[:8], left)
+	b.Encrypt(ret[8:], right)
+	return ret, nil
+}
+
+// SessionKey derives EMV common session key from card master key and
+// application transaction counter atc
+func SessionKey(mk, atc []byte) ([]byte, error) {
+	if len(atc) != 2 {
+		return nil, fmt.Errorf("ATC must be 2 bytes")
+	}
+	b, err := tdes(mk)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]byte, 16)
+	b.Encrypt(ret[:8], []byte{atc[0], atc[1], 0xf0, 0, 0, 0, 0, 0})
+	b.Encrypt(ret[8:], []byte{atc[0], atc[1], 0x0f, 0, 0, 0, 0, 0})
+	return ret, nil
+}
+
+// deriveSessionKey derives session key of card pan from issuer master key
+func deriveSessionKey(imk []byte, pan, panSeq string, atc []byte) ([]byte, error) {
+	mk, err := CardMasterKey(imk, pan, panSeq)
+	if err != nil {
+		return nil, err
+	}
+	return SessionKey(mk, atc)
+}
+
+// ApplicationCryptogram computes ARQC, TC or AAC of data with session key
+// sk: ISO 9797-1 MAC algorithm 3 with padding method 2
+func ApplicationCryptogram(sk, data []byte) ([]byte, error) {
+	if len(sk) != 16 {
+		return nil, fmt.Errorf("session key must be double length")
+	}
+	return retailMAC(sk, pad2(data))
+}
+
+// ARPC computes response cryptogram by EMV method 1: arqc XOR-ed with
+// 2 byte authorisation response code arc, encrypted with session key sk
+func ARPC(sk, arqc, arc []byte) ([]byte, error) {
+	if len(arqc) != 8 || len(arc) != 2 {
+		return nil, fmt.Errorf("ARQC must be 8 bytes and ARC 2 bytes")
+	}
+	b, err := tdes(sk)
+	if err != nil {
+		return nil, err
+	}
+	ret := append([]byte(nil), arqc...)
+	ret[0] ^= arc[0]
+	ret[1] ^= arc[1]
+	b.Encrypt(ret, ret)
+	return ret, nil
+}
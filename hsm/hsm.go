@@ -0,0 +1,52 @@
+// Package hsm defines interface of Hardware Security Module used for MAC,
+// PIN, CVV and EMV cryptogram operations, and Software reference
+// implementation with clear keys for tests. Production code plugs adapters
+// of HSM commands (for ex. Thales or Atalla) instead.
+package hsm
+
+import (
+	"errors"
+)
+
+const (
+	ERR_VERIFICATION_FAILED string = "verification failed"
+	ERR_UNKNOWN_KEY         string = "unknown key"
+	ERR_BAD_PIN_BLOCK       string = "bad PIN block"
+)
+
+var (
+	ErrVerificationFailed = errors.New(ERR_VERIFICATION_FAILED)
+	ErrUnknownKey         = errors.New(ERR_UNKNOWN_KEY)
+	ErrBadPinBlock        = errors.New(ERR_BAD_PIN_BLOCK)
+)
+
+// Key is reference to a key of HSM, for ex. key name or key block
+// encrypted under local master key
+type Key string
+
+// HSM performs cryptographic operations with keys it holds. Verify
+// methods return ErrVerificationFailed if value doesn't match.
+type HSM interface {
+	// GenerateMAC returns 8 byte MAC of data
+	GenerateMAC(key Key, data []byte) ([]byte, error)
+	// VerifyMAC checks MAC of data
+	VerifyMAC(key Key, data, mac []byte) error
+	// TranslatePIN reencrypts ISO 9564 format 0 PIN block of pan from key
+	// from to key to
+	TranslatePIN(from, to Key, pinBlock []byte, pan string) ([]byte, error)
+	// VerifyCVV checks CVV of pan, expiry (YYMM) and service code
+	VerifyCVV(key Key, pan, expiry, serviceCode, cvv string) error
+	// VerifyARQC checks EMV application cryptogram of transaction data
+	// (for ex. assembled from CDOL1), generated with session key of card
+	// pan and PAN sequence number panSeq for application transaction
+	// counter atc. key is issuer master key for application cryptograms.
+	VerifyARQC(key Key, pan, panSeq string, atc, data, arqc []byte) error
+}
+
+// ARPCGenerator is optional interface of HSM which generates EMV response
+// cryptograms
+type ARPCGenerator interface {
+	// GenerateARPC returns response cryptogram of arqc and 2 byte
+	// authorisation response code arc, arguments are as of VerifyARQC
+	GenerateARPC(key Key, pan, panSeq string, atc, arqc, arc []byte) ([]byte, error)
+}
@@ -0,0 +1,83 @@
+package hsm
+
+import (
+	"context"
+
+	"github.com/ideazxy/iso8583"
+)
+
+// macField returns number of MAC field of msg: 128 if message has
+// secondary bitmap and defines field 128, otherwise 64
+func macField(msg *iso8583.Message) int {
+	if _, err := msg.GetField(128); err != nil {
+		return 64
+	}
+	if msg.SecondBitmap {
+		return 128
+	}
+	for i := 65; i < 128; i++ {
+		if f, _ := msg.GetField(i); f != nil && !f.IsEmpty() {
+			return 128
+		}
+	}
+	return 64
+}
+
+// Sign sets MAC of packed msg to field 64 or 128 (Binary of 8 bytes),
+// which is the last field of the message. MAC covers all bytes of packed
+// message before it.
+func Sign(h HSM, key Key, msg *iso8583.Message) error {
+	i := macField(msg)
+	if err := msg.SetField(i, make([]byte, 8)); err != nil {
+		return err
+	}
+	raw, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+	mac, err := h.GenerateMAC(key, raw[:len(raw)-8])
+	if err != nil {
+		return err
+	}
+	return msg.SetField(i, mac)
+}
+
+// VerifyRaw checks MAC in the last 8 bytes of packed message raw, without
+// transport header
+func VerifyRaw(h HSM, key Key, raw []byte) error {
+	if len(raw) < 8 {
+		return ErrVerificationFailed
+	}
+	return h.VerifyMAC(key, raw[:len(raw)-8], raw[len(raw)-8:])
+}
+
+// Verify checks MAC of msg by packing it again, so msg must be packed
+// canonically by its sender (see iso8583.VerifyCanonical), otherwise use
+// VerifyRaw with received bytes
+func Verify(h HSM, key Key, msg *iso8583.Message) error {
+	f, err := msg.GetField(macField(msg))
+	if err != nil {
+		return err
+	}
+	if f == nil || f.IsEmpty() {
+		return ErrVerificationFailed
+	}
+	raw, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+	return VerifyRaw(h, key, raw)
+}
+
+// VerifyRequests returns iso8583.Middleware which rejects requests with
+// invalid MAC
+func VerifyRequests(h HSM, key Key) iso8583.Middleware {
+	return func(next iso8583.HandlerFunc) iso8583.HandlerFunc {
+		return func(ctx context.Context, req *iso8583.Message) (*iso8583.Message, error) {
+			if err := Verify(h, key, req); err != nil {
+				return nil, err
+			}
+			return next(ctx, req)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package hsm
+
+import (
+	"context"
+	"github.com/ideazxy/iso8583"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type testMessage struct {
+	F3   *iso8583.Numeric `field:"3" length:"6"`
+	F11  *iso8583.Numeric `field:"11" length:"6"`
+	F64  *iso8583.Binary  `field:"64" length:"8"`
+	F70  *iso8583.Numeric `field:"70" length:"3"`
+	F128 *iso8583.Binary  `field:"128" length:"8"`
+}
+
+func TestSignVerify(t *testing.T) {
+	h := newTestHSM(t)
+	msg := iso8583.NewMessage("0200", &testMessage{F3: iso8583.NewNumeric("000000"), F11: iso8583.NewNumeric("000001")})
+	assert.Nil(t, Sign(h, "mac", msg))
+	data := msg.Data.(*testMessage)
+	assert.Equal(t, 8, len(data.F64.Value))
+	assert.Nil(t, data.F128)
+	assert.Nil(t, Verify(h, "mac", msg))
+
+	raw, err := msg.Bytes()
+	assert.Nil(t, err)
+	assert.Nil(t, VerifyRaw(h, "mac", raw))
+	raw[5] ^= 1
+	assert.Equal(t, ErrVerificationFailed, VerifyRaw(h, "mac", raw))
+
+	// secondary bitmap moves MAC to field 128
+	data.F64 = nil
+	data.F70 = iso8583.NewNumeric("301")
+	assert.Nil(t, Sign(h, "mac", msg))
+	assert.Nil(t, data.F64)
+	assert.Equal(t, 8, len(data.F128.Value))
+
+	r := &iso8583.Router{}
+	r.Handle("0200", "", "", func(ctx context.Context, req *iso8583.Message) (*iso8583.Message, error) {
+		return req, nil
+	})
+	r.Use(VerifyRequests(h, "mac"))
+	_, err = r.Serve(context.Background(), msg)
+	assert.Nil(t, err)
+	data.F11.Value = "000002"
+	_, err = r.Serve(context.Background(), msg)
+	assert.Equal(t, ErrVerificationFailed, err)
+}
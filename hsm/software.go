@@ -0,0 +1,260 @@
+package hsm
+
+import (
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Software is HSM with clear keys in memory, for tests and development
+// only. Keys are single (8 bytes), double (16 bytes) or triple (24 bytes)
+// length DES keys.
+//
+// MAC is ISO 9797-1 algorithm 3 (retail MAC) with padding method 1 for
+// double and triple length keys and algorithm 1 for single length ones.
+// CVV is computed with the Visa CVV algorithm from double length key.
+// Application cryptogram is ISO 9797-1 algorithm 3 MAC with padding method
+// 2, computed with EMV common session key derived from card master key,
+// which is derived from issuer master key by EMV option A.
+type Software struct {
+	Keys map[Key][]byte
+}
+
+// NewSoftware creates Software with keys in hex
+func NewSoftware(keys map[Key]string) (*Software, error) {
+	s := &Software{Keys: make(map[Key][]byte, len(keys))}
+	for name, k := range keys {
+		b, err := hex.DecodeString(k)
+		if err != nil || (len(b) != 8 && len(b) != 16 && len(b) != 24) {
+			return nil, fmt.Errorf("bad key %s", name)
+		}
+		s.Keys[name] = b
+	}
+	return s, nil
+}
+
+// block returns cipher of key name
+func (s *Software) block(name Key) (cipher.Block, []byte, error) {
+	k, ok := s.Keys[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnknownKey, name)
+	}
+	b, err := tdes(k)
+	return b, k, err
+}
+
+// tdes returns DES cipher of single length key, or triple DES cipher of
+// double or triple length one
+func tdes(k []byte) (cipher.Block, error) {
+	switch len(k) {
+	case 8:
+		return des.NewCipher(k)
+	case 16:
+		return des.NewTripleDESCipher(append(append([]byte(nil), k...), k[:8]...))
+	default:
+		return des.NewTripleDESCipher(k)
+	}
+}
+
+// retailMAC computes ISO 9797-1 MAC algorithm 3 of padded data, or
+// algorithm 1 with single length key
+func retailMAC(k, data []byte) ([]byte, error) {
+	left, err := des.NewCipher(k[:8])
+	if err != nil {
+		return nil, err
+	}
+	mac := make([]byte, 8)
+	for i := 0; i < len(data); i += 8 {
+		subtle.XORBytes(mac, mac, data[i:i+8])
+		left.Encrypt(mac, mac)
+	}
+	if len(k) == 8 {
+		return mac, nil
+	}
+	final, err := tdes(k)
+	if err != nil {
+		return nil, err
+	}
+	// decrypting with key A after encrypting with it is identity, so
+	// E(A) D(B) E(A) of the last block equals algorithm 3 final steps
+	left.Decrypt(mac, mac)
+	final.Encrypt(mac, mac)
+	return mac, nil
+}
+
+// pad1 pads data with zeros to multiple of 8 bytes (ISO 9797-1 method 1)
+func pad1(data []byte) []byte {
+	ret := append([]byte(nil), data...)
+	for len(ret)%8 != 0 || len(ret) == 0 {
+		ret = append(ret, 0)
+	}
+	return ret
+}
+
+// pad2 pads data with 0x80 and zeros to multiple of 8 bytes (ISO 9797-1
+// method 2)
+func pad2(data []byte) []byte {
+	ret := append(append([]byte(nil), data...), 0x80)
+	for len(ret)%8 != 0 {
+		ret = append(ret, 0)
+	}
+	return ret
+}
+
+// GenerateMAC returns 8 byte MAC of data
+func (s *Software) GenerateMAC(key Key, data []byte) ([]byte, error) {
+	_, k, err := s.block(key)
+	if err != nil {
+		return nil, err
+	}
+	return retailMAC(k, pad1(data))
+}
+
+// VerifyMAC checks MAC of data
+func (s *Software) VerifyMAC(key Key, data, mac []byte) error {
+	expected, err := s.GenerateMAC(key, data)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(expected, mac) != 1 {
+		return ErrVerificationFailed
+	}
+	return nil
+}
+
+// panBlock returns PAN block of ISO 9564 format 0: rightmost 12 digits of
+// pan without check digit
+func panBlock(pan string) ([]byte, error) {
+	if len(pan) < 13 {
+		return nil, fmt.Errorf("%w: PAN is too short", ErrBadPinBlock)
+	}
+	return hex.DecodeString("0000" + pan[len(pan)-13:len(pan)-1])
+}
+
+// TranslatePIN reencrypts ISO 9564 format 0 PIN block
+func (s *Software) TranslatePIN(from, to Key, pinBlock []byte, pan string) ([]byte, error) {
+	in, _, err := s.block(from)
+	if err != nil {
+		return nil, err
+	}
+	out, _, err := s.block(to)
+	if err != nil {
+		return nil, err
+	}
+	if len(pinBlock) != 8 {
+		return nil, ErrBadPinBlock
+	}
+	pb, err := panBlock(pan)
+	if err != nil {
+		return nil, err
+	}
+	clear := make([]byte, 8)
+	in.Decrypt(clear, pinBlock)
+	plain := make([]byte, 8)
+	subtle.XORBytes(plain, clear, pb)
+	digits := hex.EncodeToString(plain)
+	n := int(plain[0] & 0x0f)
+	if plain[0]>>4 != 0 || n < 4 || n > 12 || strings.Trim(digits[2:2+n], "0123456789") != "" || strings.Trim(digits[2+n:], "f") != "" {
+		return nil, ErrBadPinBlock
+	}
+	ret := make([]byte, 8)
+	out.Encrypt(ret, clear)
+	return ret, nil
+}
+
+// cvv computes Visa CVV of pan, expiry and service code with double length
+// key
+func cvv(k []byte, pan, expiry, serviceCode string) (string, error) {
+	if len(k) != 16 {
+		return "", fmt.Errorf("CVV key must be double length")
+	}
+	data := pan + expiry + serviceCode
+	if len(data) > 32 || strings.Trim(data, "0123456789") != "" {
+		return "", fmt.Errorf("bad CVV data")
+	}
+	data += strings.Repeat("0", 32-len(data))
+	raw, _ := hex.DecodeString(data)
+	a, err := des.NewCipher(k[:8])
+	if err != nil {
+		return "", err
+	}
+	ab, err := tdes(k)
+	if err != nil {
+		return "", err
+	}
+	block := make([]byte, 8)
+	a.Encrypt(block, raw[:8])
+	subtle.XORBytes(block, block, raw[8:])
+	ab.Encrypt(block, block)
+
+	// decimalize: digits first, then letters minus 10
+	h := strings.ToUpper(hex.EncodeToString(block))
+	var digits, letters []byte
+	for i := 0; i < len(h); i++ {
+		if h[i] <= '9' {
+			digits = append(digits, h[i])
+		} else {
+			letters = append(letters, h[i]-'A'+'0')
+		}
+	}
+	return string(append(digits, letters...)[:3]), nil
+}
+
+// VerifyCVV checks Visa CVV (and CVV2, iCVV with their service codes)
+func (s *Software) VerifyCVV(key Key, pan, expiry, serviceCode, value string) error {
+	_, k, err := s.block(key)
+	if err != nil {
+		return err
+	}
+	expected, err := cvv(k, pan, expiry, serviceCode)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(value)) != 1 {
+		return ErrVerificationFailed
+	}
+	return nil
+}
+
+// GenerateARQC returns application cryptogram of data. Software uses it to
+// emulate cards in tests.
+func (s *Software) GenerateARQC(key Key, pan, panSeq string, atc, data []byte) ([]byte, error) {
+	_, imk, err := s.block(key)
+	if err != nil {
+		return nil, err
+	}
+	sk, err := deriveSessionKey(imk, pan, panSeq, atc)
+	if err != nil {
+		return nil, err
+	}
+	return ApplicationCryptogram(sk, data)
+}
+
+// VerifyARQC checks application cryptogram of data
+func (s *Software) VerifyARQC(key Key, pan, panSeq string, atc, data, arqc []byte) error {
+	expected, err := s.GenerateARQC(key, pan, panSeq, atc, data)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(expected, arqc) != 1 {
+		return ErrVerificationFailed
+	}
+	return nil
+}
+
+// GenerateARPC returns response cryptogram of arqc and authorisation
+// response code arc
+func (s *Software) GenerateARPC(key Key, pan, panSeq string, atc, arqc, arc []byte) ([]byte, error) {
+	_, imk, err := s.block(key)
+	if err != nil {
+		return nil, err
+	}
+	sk, err := deriveSessionKey(imk, pan, panSeq, atc)
+	if err != nil {
+		return nil, err
+	}
+	return ARPC(sk, arqc, arc)
+}
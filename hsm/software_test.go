@@ -0,0 +1,89 @@
+package hsm
+
+import (
+	"crypto/des"
+	"encoding/hex"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newTestHSM(t *testing.T) *Software {
+	h, err := NewSoftware(map[Key]string{
+		"mac":  "0123456789ABCDEFFEDCBA9876543210",
+		"cvk":  "0123456789ABCDEFFEDCBA9876543210",
+		"zpk1": "0123456789ABCDEFFEDCBA9876543210",
+		"zpk2": "1111111111111111FEDCBA9876543210",
+		"imk":  "0123456789ABCDEFFEDCBA9876543210",
+	})
+	assert.Nil(t, err)
+	return h
+}
+
+func TestMAC(t *testing.T) {
+	h := newTestHSM(t)
+	// ANSI X9.19 test vector
+	mac, err := h.GenerateMAC("mac", []byte("Now is the time for all "))
+	assert.Nil(t, err)
+	assert.Equal(t, "a1c72e74ea3fa9b6", hex.EncodeToString(mac))
+	assert.Nil(t, h.VerifyMAC("mac", []byte("Now is the time for all "), mac))
+	assert.Equal(t, ErrVerificationFailed, h.VerifyMAC("mac", []byte("Now is the time for al1 "), mac))
+
+	_, err = h.GenerateMAC("none", nil)
+	assert.True(t, errors.Is(err, ErrUnknownKey))
+	_, err = NewSoftware(map[Key]string{"bad": "0123"})
+	assert.EqualError(t, err, "bad key bad")
+}
+
+func TestCVV(t *testing.T) {
+	h := newTestHSM(t)
+	assert.Nil(t, h.VerifyCVV("cvk", "4123456789012345", "8701", "101", "561"))
+	assert.Equal(t, ErrVerificationFailed, h.VerifyCVV("cvk", "4123456789012345", "8701", "101", "562"))
+}
+
+func TestTranslatePIN(t *testing.T) {
+	h := newTestHSM(t)
+	pan := "4000001234562000"
+	clear, _ := hex.DecodeString("041234ffffffffff")
+	pb, _ := panBlock(pan)
+	for i := range clear {
+		clear[i] ^= pb[i]
+	}
+	zpk1, _ := tdes(h.Keys["zpk1"])
+	zpk2, _ := tdes(h.Keys["zpk2"])
+	in := make([]byte, 8)
+	zpk1.Encrypt(in, clear)
+
+	out, err := h.TranslatePIN("zpk1", "zpk2", in, pan)
+	assert.Nil(t, err)
+	assert.NotEqual(t, in, out)
+	dec := make([]byte, 8)
+	zpk2.Decrypt(dec, out)
+	assert.Equal(t, clear, dec)
+
+	// wrong PAN breaks format
+	_, err = h.TranslatePIN("zpk1", "zpk2", in, "4000001234569999")
+	assert.Equal(t, ErrBadPinBlock, err)
+	_, err = h.TranslatePIN("zpk1", "zpk2", in[:4], pan)
+	assert.Equal(t, ErrBadPinBlock, err)
+}
+
+func TestARQC(t *testing.T) {
+	h := newTestHSM(t)
+	atc := []byte{0x00, 0x1c}
+	data, _ := hex.DecodeString("000000001000000000000000084000000000000840260101007ba9ab3c")
+	arqc, err := h.GenerateARQC("imk", "5413330089020011", "01", atc, data)
+	assert.Nil(t, err)
+	assert.Equal(t, 8, len(arqc))
+	assert.Nil(t, h.VerifyARQC("imk", "5413330089020011", "01", atc, data, arqc))
+	assert.Equal(t, ErrVerificationFailed, h.VerifyARQC("imk", "5413330089020011", "01", []byte{0, 0x1d}, data, arqc))
+
+	// card master key derivation by option A
+	mk, err := CardMasterKey(h.Keys["imk"], "5413330089020011", "01")
+	assert.Nil(t, err)
+	b, _ := des.NewTripleDESCipher(append(append([]byte(nil), h.Keys["imk"]...), h.Keys["imk"][:8]...))
+	left := make([]byte, 8)
+	// rightmost 16 digits of PAN and PAN sequence number
+	b.Encrypt(left, []byte{0x13, 0x33, 0x00, 0x89, 0x02, 0x00, 0x11, 0x01})
+	assert.Equal(t, left, mk[:8])
+}
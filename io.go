@@ -0,0 +1,53 @@
+package iso8583
+
+import (
+	"errors"
+	"io"
+)
+
+const (
+	ERR_UNBOUNDED_READER string = "reader must contain one message of known size"
+)
+
+var ErrUnboundedReader = errors.New(ERR_UNBOUNDED_READER)
+
+// WriteTo writes packed Message to w without framing. It implements
+// io.WriterTo. Messages are written to connection with length header by
+// WriteMessage of batch.Framing.
+func (m *Message) WriteTo(w io.Writer) (int64, error) {
+	b, err := m.Bytes()
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(b)
+	return int64(n), err
+}
+
+// ReadFrom loads Message from r, which must contain exactly one message
+// and report its size: *bytes.Reader, *bytes.Buffer, *strings.Reader or
+// *io.LimitedReader. It implements io.ReaderFrom. Other readers, like
+// net.Conn, are rejected with ErrUnboundedReader, messages are read from
+// connection by ReadMessage of batch.Framing.
+func (m *Message) ReadFrom(r io.Reader) (int64, error) {
+	var raw []byte
+	var err error
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		raw = make([]byte, v.Len())
+		var n int
+		n, err = io.ReadFull(r, raw)
+		if err == io.ErrUnexpectedEOF {
+			err = nil
+		}
+		raw = raw[:n]
+	case *io.LimitedReader:
+		// N is only a limit, so buffer grows with data read
+		raw, err = io.ReadAll(v)
+	default:
+		return 0, ErrUnboundedReader
+	}
+	if err != nil {
+		return int64(len(raw)), err
+	}
+	return int64(len(raw)), m.Load(raw)
+}
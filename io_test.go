@@ -0,0 +1,68 @@
+package iso8583
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+func TestWriteToReadFrom(t *testing.T) {
+	iso := NewMessage("0200", &testTransaction{
+		F11: NewNumeric("000123"),
+		F41: NewAlphanumeric("00000321"),
+	})
+
+	var _ io.WriterTo = iso
+	var _ io.ReaderFrom = iso
+
+	buf := &bytes.Buffer{}
+	n, err := iso.WriteTo(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4+8+6+8), n)
+
+	iso2 := NewMessage("", &testTransaction{F11: &Numeric{}, F41: &Alphanumeric{}})
+	n, err = iso2.ReadFrom(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4+8+6+8), n)
+	assert.Equal(t, "0200", iso2.Mti)
+	assert.Equal(t, "00000321", iso2.Data.(*testTransaction).F41.Value)
+
+	// size of reader must be known
+	_, err = iso.WriteTo(buf)
+	assert.Nil(t, err)
+	n, err = iso2.ReadFrom(&io.LimitedReader{R: buf, N: int64(buf.Len())})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4+8+6+8), n)
+	// limit is not allocated up front
+	_, err = iso.WriteTo(buf)
+	assert.Nil(t, err)
+	n, err = iso2.ReadFrom(io.LimitReader(buf, 1<<30))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4+8+6+8), n)
+	_, err = iso2.ReadFrom(io.MultiReader(buf))
+	assert.Equal(t, ErrUnboundedReader, err)
+
+	iso.Mti = ""
+	_, err = iso.WriteTo(buf)
+	assert.EqualError(t, err, "MTI is required")
+}
+
+func TestMessageLen(t *testing.T) {
+	raw, err := NewMessage("0200", &testTransaction{
+		F11: NewNumeric("000123"),
+		F41: NewAlphanumeric("00000321"),
+	}).Bytes()
+	assert.Nil(t, err)
+
+	p := &Parser{}
+	p.Register("0200", &testTransaction{})
+	n, err := p.MessageLen(append(raw, "0200"...))
+	assert.Nil(t, err)
+	assert.Equal(t, len(raw), n)
+
+	_, err = p.MessageLen(raw[:len(raw)-1])
+	assert.EqualError(t, err, "field 41: bad raw data")
+	_, err = p.MessageLen(raw[:6])
+	assert.NotNil(t, err)
+}
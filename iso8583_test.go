@@ -2,6 +2,7 @@ package iso8583
 
 import (
 	"bytes"
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -64,7 +65,7 @@ type TestISO2 struct {
 	F58 *Lllvar       `field:"58" length:"255" encode:"ascii,ascii"`
 	F59 *Llvar        `field:"59" length:"255" encode:"rbcd,ascii"`
 	F60 *Lllnumeric   `field:"60" length:"999" encode:"bcd,ascii"`
-	F61 *Lllnumeric   `field:"60" length:"999" encode:"bcd,rbcd"`
+	F61 *Lllnumeric   `field:"61" length:"999" encode:"bcd,rbcd"`
 	F63 *Lllnumeric   `field:"63" length:"999" encode:"rbcd,bcd"`
 	F64 *Binary       `field:"64" length:"32"`
 }
@@ -95,7 +96,7 @@ func TestEncode(t *testing.T) {
 		F120: NewLllnumeric("Another test text"),
 	}
 
-	iso := Message{"0100", ASCII, true, data}
+	iso := Message{Mti: "0100", MtiEncode: ASCII, SecondBitmap: true, Data: data}
 
 	res, err := iso.Bytes()
 
@@ -115,7 +116,7 @@ func TestDecode(t *testing.T) {
 	input := []byte{48, 49, 48, 48, 242, 60, 36, 129, 40, 224, 152, 0, 0, 0, 0, 0, 0, 0, 1, 0, 49, 54, 52, 50, 55, 54, 53, 53, 53, 53, 53, 53, 53, 53, 53, 53, 53, 53, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 55, 55, 55, 48, 48, 48, 55, 48, 49, 49, 49, 49, 56, 52, 52, 48, 48, 48, 49, 50, 51, 49, 51, 49, 56, 52, 52, 48, 55, 48, 49, 49, 57, 48, 50, 6, 67, 57, 48, 49, 48, 50, 48, 54, 49, 50, 51, 52, 53, 54, 51, 55, 52, 50, 55, 54, 53, 53, 53, 53, 53, 53, 53, 53, 53, 53, 53, 53, 61, 49, 50, 51, 52, 53, 54, 55, 56, 57, 48, 49, 50, 51, 52, 53, 54, 55, 56, 57, 48, 57, 56, 55, 54, 53, 52, 51, 50, 49, 48, 48, 49, 48, 48, 48, 48, 48, 51, 50, 49, 49, 50, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 51, 52, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 84, 101, 115, 116, 32, 116, 101, 120, 116, 100, 48, 1, 2, 3, 4, 5, 6, 7, 8, 49, 50, 51, 52, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 49, 55, 65, 110, 111, 116, 104, 101, 114, 32, 116, 101, 115, 116, 32, 116, 101, 120, 116}
 
 	// init empty iso message struct
-	iso := Message{"", ASCII, true, newDataIso()}
+	iso := Message{Mti: "", MtiEncode: ASCII, SecondBitmap: true, Data: newDataIso()}
 
 	// parse data from bytes to iso struct
 	err := iso.Load(input)
@@ -219,7 +220,7 @@ func TestEncodeDecode(t *testing.T) {
 		F63: NewLllnumeric("123abc456ef7890"),
 	}
 
-	iso := Message{"0110", ASCII, false, data}
+	iso := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data}
 
 	res, err := iso.Bytes()
 
@@ -227,7 +228,7 @@ func TestEncodeDecode(t *testing.T) {
 		t.Error("ISO Encode error:", err)
 	}
 
-	iso2 := Message{"0110", ASCII, false, data}
+	iso2 := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data}
 
 	err = iso2.Load(res)
 
@@ -249,7 +250,7 @@ func TestFieldNumericEncodeErrors(t *testing.T) {
 		F2: NewNumeric("123456"),
 	}
 
-	iso := Message{"0110", ASCII, false, data1}
+	iso := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data1}
 
 	_, err := iso.Bytes()
 
@@ -263,7 +264,7 @@ func TestFieldNumericEncodeErrors(t *testing.T) {
 		F2: NewNumeric("123456"),
 	}
 
-	iso = Message{"0110", ASCII, false, data2}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data2}
 
 	_, err = iso.Bytes()
 
@@ -277,7 +278,7 @@ func TestFieldNumericEncodeErrors(t *testing.T) {
 		F2: NewNumeric("123456"),
 	}
 
-	iso = Message{"0110", ASCII, false, data3}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data3}
 
 	_, err = iso.Bytes()
 
@@ -294,7 +295,7 @@ func TestFieldAlphanumericEncodeErrors(t *testing.T) {
 		F2: NewAlphanumeric("abcdef"),
 	}
 
-	iso := Message{"0110", ASCII, false, data1}
+	iso := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data1}
 
 	_, err := iso.Bytes()
 
@@ -308,7 +309,7 @@ func TestFieldAlphanumericEncodeErrors(t *testing.T) {
 		F2: NewAlphanumeric("abcdef"),
 	}
 
-	iso = Message{"0110", ASCII, false, data2}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data2}
 
 	_, err = iso.Bytes()
 
@@ -325,7 +326,7 @@ func TestFieldBinaryEncodeErrors(t *testing.T) {
 		F2: NewBinary([]byte("abcdef")),
 	}
 
-	iso := Message{"0110", ASCII, false, data1}
+	iso := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data1}
 
 	_, err := iso.Bytes()
 
@@ -339,7 +340,7 @@ func TestFieldBinaryEncodeErrors(t *testing.T) {
 		F2: NewBinary([]byte("abcdef")),
 	}
 
-	iso = Message{"0110", ASCII, false, data2}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data2}
 
 	_, err = iso.Bytes()
 
@@ -356,7 +357,7 @@ func TestFieldLlnumericEncodeErrors(t *testing.T) {
 		F2: NewLlnumeric("123456"),
 	}
 
-	iso := Message{"0110", ASCII, false, data1}
+	iso := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data1}
 
 	_, err := iso.Bytes()
 
@@ -370,7 +371,7 @@ func TestFieldLlnumericEncodeErrors(t *testing.T) {
 		F2: NewLlnumeric("123456"),
 	}
 
-	iso = Message{"0110", ASCII, false, data2}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data2}
 
 	_, err = iso.Bytes()
 
@@ -384,7 +385,7 @@ func TestFieldLlnumericEncodeErrors(t *testing.T) {
 		F2: NewLlnumeric(string(bytes.Repeat([]byte("a"), 100))),
 	}
 
-	iso = Message{"0110", ASCII, false, data3}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data3}
 
 	_, err = iso.Bytes()
 
@@ -398,7 +399,7 @@ func TestFieldLlnumericEncodeErrors(t *testing.T) {
 		F2: NewLlnumeric(string(bytes.Repeat([]byte("a"), 100))),
 	}
 
-	iso = Message{"0110", ASCII, false, data4}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data4}
 
 	_, err = iso.Bytes()
 
@@ -412,7 +413,7 @@ func TestFieldLlnumericEncodeErrors(t *testing.T) {
 		F2: NewLlnumeric("123456"),
 	}
 
-	iso = Message{"0110", ASCII, false, data5}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data5}
 
 	_, err = iso.Bytes()
 
@@ -429,7 +430,7 @@ func TestFieldLllnumericEncodeErrors(t *testing.T) {
 		F2: NewLllnumeric("123456"),
 	}
 
-	iso := Message{"0110", ASCII, false, data1}
+	iso := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data1}
 
 	_, err := iso.Bytes()
 
@@ -443,7 +444,7 @@ func TestFieldLllnumericEncodeErrors(t *testing.T) {
 		F2: NewLllnumeric("123456"),
 	}
 
-	iso = Message{"0110", ASCII, false, data2}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data2}
 
 	_, err = iso.Bytes()
 
@@ -457,7 +458,7 @@ func TestFieldLllnumericEncodeErrors(t *testing.T) {
 		F2: NewLllnumeric(string(bytes.Repeat([]byte("a"), 1000))),
 	}
 
-	iso = Message{"0110", ASCII, false, data3}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data3}
 
 	_, err = iso.Bytes()
 
@@ -471,7 +472,7 @@ func TestFieldLllnumericEncodeErrors(t *testing.T) {
 		F2: NewLllnumeric(string(bytes.Repeat([]byte("a"), 1000))),
 	}
 
-	iso = Message{"0110", ASCII, false, data4}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data4}
 
 	_, err = iso.Bytes()
 
@@ -485,7 +486,7 @@ func TestFieldLllnumericEncodeErrors(t *testing.T) {
 		F2: NewLllnumeric("123456"),
 	}
 
-	iso = Message{"0110", ASCII, false, data5}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data5}
 
 	_, err = iso.Bytes()
 
@@ -502,7 +503,7 @@ func TestFieldLlvarEncodeErrors(t *testing.T) {
 		F2: NewLlvar([]byte("123456")),
 	}
 
-	iso := Message{"0110", ASCII, false, data1}
+	iso := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data1}
 
 	_, err := iso.Bytes()
 
@@ -516,7 +517,7 @@ func TestFieldLlvarEncodeErrors(t *testing.T) {
 		F2: NewLlvar([]byte("123456")),
 	}
 
-	iso = Message{"0110", ASCII, false, data2}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data2}
 
 	_, err = iso.Bytes()
 
@@ -530,7 +531,7 @@ func TestFieldLlvarEncodeErrors(t *testing.T) {
 		F2: NewLlvar(bytes.Repeat([]byte("a"), 100)),
 	}
 
-	iso = Message{"0110", ASCII, false, data3}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data3}
 
 	_, err = iso.Bytes()
 
@@ -544,7 +545,7 @@ func TestFieldLlvarEncodeErrors(t *testing.T) {
 		F2: NewLlvar(bytes.Repeat([]byte("a"), 100)),
 	}
 
-	iso = Message{"0110", ASCII, false, data4}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data4}
 
 	_, err = iso.Bytes()
 
@@ -558,7 +559,7 @@ func TestFieldLlvarEncodeErrors(t *testing.T) {
 		F2: NewLlvar([]byte("123456")),
 	}
 
-	iso = Message{"0110", ASCII, false, data5}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data5}
 
 	_, err = iso.Bytes()
 
@@ -575,7 +576,7 @@ func TestFieldLllvarEncodeErrors(t *testing.T) {
 		F2: NewLllvar([]byte("123456")),
 	}
 
-	iso := Message{"0110", ASCII, false, data1}
+	iso := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data1}
 
 	_, err := iso.Bytes()
 
@@ -589,7 +590,7 @@ func TestFieldLllvarEncodeErrors(t *testing.T) {
 		F2: NewLllvar([]byte("123456")),
 	}
 
-	iso = Message{"0110", ASCII, false, data2}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data2}
 
 	_, err = iso.Bytes()
 
@@ -603,7 +604,7 @@ func TestFieldLllvarEncodeErrors(t *testing.T) {
 		F2: NewLllvar(bytes.Repeat([]byte("a"), 1000)),
 	}
 
-	iso = Message{"0110", ASCII, false, data3}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data3}
 
 	_, err = iso.Bytes()
 
@@ -617,7 +618,7 @@ func TestFieldLllvarEncodeErrors(t *testing.T) {
 		F2: NewLllvar(bytes.Repeat([]byte("a"), 1000)),
 	}
 
-	iso = Message{"0110", ASCII, false, data4}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data4}
 
 	_, err = iso.Bytes()
 
@@ -631,7 +632,7 @@ func TestFieldLllvarEncodeErrors(t *testing.T) {
 		F2: NewLllvar([]byte("123456")),
 	}
 
-	iso = Message{"0110", ASCII, false, data5}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data5}
 
 	_, err = iso.Bytes()
 
@@ -647,7 +648,7 @@ func TestFieldNumericDecodeErrors(t *testing.T) {
 		F2: NewNumeric("123456"),
 	}
 
-	iso := Message{"0110", ASCII, false, data1}
+	iso := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data1}
 
 	isoBytes, err := iso.Bytes()
 
@@ -667,7 +668,7 @@ func TestFieldNumericDecodeErrors(t *testing.T) {
 		F2: NewNumeric("123456"),
 	}
 
-	iso = Message{"0110", ASCII, false, data2}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data2}
 
 	isoBytes, err = iso.Bytes()
 
@@ -687,7 +688,7 @@ func TestFieldNumericDecodeErrors(t *testing.T) {
 		F2: NewNumeric("123456"),
 	}
 
-	iso = Message{"0110", ASCII, false, data3}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data3}
 
 	isoBytes, err = iso.Bytes()
 
@@ -707,7 +708,7 @@ func TestFieldNumericDecodeErrors(t *testing.T) {
 		F2: NewNumeric(""),
 	}
 
-	iso = Message{"0110", ASCII, false, data4}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data4}
 
 	err = iso.Load(isoBytes)
 
@@ -721,7 +722,7 @@ func TestFieldNumericDecodeErrors(t *testing.T) {
 		F2: NewNumeric(""),
 	}
 
-	iso = Message{"0110", ASCII, false, data5}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data5}
 
 	err = iso.Load(isoBytes)
 
@@ -737,7 +738,7 @@ func TestFieldLlnumericDecodeErrors(t *testing.T) {
 		F2: NewLlnumeric("123456"),
 	}
 
-	iso := Message{"0110", ASCII, false, data1}
+	iso := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data1}
 
 	isoBytes, err := iso.Bytes()
 
@@ -757,7 +758,7 @@ func TestFieldLlnumericDecodeErrors(t *testing.T) {
 		F2: NewLlnumeric("123456"),
 	}
 
-	iso = Message{"0110", ASCII, false, data2}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data2}
 
 	isoBytes, err = iso.Bytes()
 
@@ -777,7 +778,7 @@ func TestFieldLlnumericDecodeErrors(t *testing.T) {
 		F2: NewLlnumeric("123456"),
 	}
 
-	iso = Message{"0110", ASCII, false, data3}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data3}
 
 	isoBytes, err = iso.Bytes()
 
@@ -797,7 +798,7 @@ func TestFieldLlnumericDecodeErrors(t *testing.T) {
 		F2: NewLlnumeric(""),
 	}
 
-	iso = Message{"0110", ASCII, false, data4}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data4}
 
 	err = iso.Load(isoBytes)
 
@@ -811,7 +812,7 @@ func TestFieldLlnumericDecodeErrors(t *testing.T) {
 		F2: NewLlnumeric("543210"),
 	}
 
-	iso = Message{"0110", ASCII, false, data5}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data5}
 
 	isoBytes, err = iso.Bytes()
 
@@ -831,7 +832,7 @@ func TestFieldLlnumericDecodeErrors(t *testing.T) {
 		F2: NewLlnumeric(""),
 	}
 
-	iso = Message{"0110", ASCII, false, data6}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data6}
 
 	err = iso.Load(isoBytes)
 
@@ -845,7 +846,7 @@ func TestFieldLlnumericDecodeErrors(t *testing.T) {
 		F2: NewLlnumeric("543210"),
 	}
 
-	iso = Message{"0110", ASCII, false, data7}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data7}
 
 	isoBytes, err = iso.Bytes()
 
@@ -866,7 +867,7 @@ func TestFieldLlnumericDecodeErrors(t *testing.T) {
 		F2: NewLlnumeric(""),
 	}
 
-	iso = Message{"0110", ASCII, false, data8}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data8}
 
 	err = iso.Load(isoBytes)
 
@@ -882,7 +883,7 @@ func TestFieldLllnumericDecodeErrors(t *testing.T) {
 		F2: NewLllnumeric("123456"),
 	}
 
-	iso := Message{"0110", ASCII, false, data1}
+	iso := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data1}
 
 	isoBytes, err := iso.Bytes()
 
@@ -902,7 +903,7 @@ func TestFieldLllnumericDecodeErrors(t *testing.T) {
 		F2: NewLllnumeric("123456"),
 	}
 
-	iso = Message{"0110", ASCII, false, data2}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data2}
 
 	isoBytes, err = iso.Bytes()
 
@@ -922,7 +923,7 @@ func TestFieldLllnumericDecodeErrors(t *testing.T) {
 		F2: NewLllnumeric("123456"),
 	}
 
-	iso = Message{"0110", ASCII, false, data3}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data3}
 
 	isoBytes, err = iso.Bytes()
 
@@ -942,7 +943,7 @@ func TestFieldLllnumericDecodeErrors(t *testing.T) {
 		F2: NewLllnumeric(""),
 	}
 
-	iso = Message{"0110", ASCII, false, data4}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data4}
 
 	err = iso.Load(isoBytes)
 
@@ -956,7 +957,7 @@ func TestFieldLllnumericDecodeErrors(t *testing.T) {
 		F2: NewLllnumeric("543210"),
 	}
 
-	iso = Message{"0110", ASCII, false, data5}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data5}
 
 	isoBytes, err = iso.Bytes()
 
@@ -977,7 +978,7 @@ func TestFieldLllnumericDecodeErrors(t *testing.T) {
 		F2: NewLllnumeric(""),
 	}
 
-	iso = Message{"0110", ASCII, false, data6}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data6}
 
 	err = iso.Load(isoBytes)
 
@@ -991,7 +992,7 @@ func TestFieldLllnumericDecodeErrors(t *testing.T) {
 		F2: NewLllnumeric("543210"),
 	}
 
-	iso = Message{"0110", ASCII, false, data7}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data7}
 
 	isoBytes, err = iso.Bytes()
 
@@ -1013,7 +1014,7 @@ func TestFieldLllnumericDecodeErrors(t *testing.T) {
 		F2: NewLllnumeric(""),
 	}
 
-	iso = Message{"0110", ASCII, false, data8}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data8}
 
 	err = iso.Load(isoBytes)
 
@@ -1029,7 +1030,7 @@ func TestFieldLlvarDecodeErrors(t *testing.T) {
 		F2: NewLlvar([]byte("123456")),
 	}
 
-	iso := Message{"0110", ASCII, false, data1}
+	iso := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data1}
 
 	isoBytes, err := iso.Bytes()
 
@@ -1049,7 +1050,7 @@ func TestFieldLlvarDecodeErrors(t *testing.T) {
 		F2: NewLlvar([]byte("123456")),
 	}
 
-	iso = Message{"0110", ASCII, false, data2}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data2}
 
 	isoBytes, err = iso.Bytes()
 
@@ -1069,7 +1070,7 @@ func TestFieldLlvarDecodeErrors(t *testing.T) {
 		F2: NewLlvar([]byte("123456")),
 	}
 
-	iso = Message{"0110", ASCII, false, data3}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data3}
 
 	isoBytes, err = iso.Bytes()
 
@@ -1089,7 +1090,7 @@ func TestFieldLlvarDecodeErrors(t *testing.T) {
 		F2: NewLlvar(nil),
 	}
 
-	iso = Message{"0110", ASCII, false, data4}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data4}
 
 	err = iso.Load(isoBytes)
 
@@ -1103,7 +1104,7 @@ func TestFieldLlvarDecodeErrors(t *testing.T) {
 		F2: NewLlvar([]byte("543210")),
 	}
 
-	iso = Message{"0110", ASCII, false, data5}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data5}
 
 	isoBytes, err = iso.Bytes()
 
@@ -1123,7 +1124,7 @@ func TestFieldLlvarDecodeErrors(t *testing.T) {
 		F2: NewLlvar(nil),
 	}
 
-	iso = Message{"0110", ASCII, false, data6}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data6}
 
 	err = iso.Load(isoBytes)
 
@@ -1137,7 +1138,7 @@ func TestFieldLlvarDecodeErrors(t *testing.T) {
 		F2: NewLlvar([]byte("543210")),
 	}
 
-	iso = Message{"0110", ASCII, false, data7}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data7}
 
 	isoBytes, err = iso.Bytes()
 
@@ -1158,7 +1159,7 @@ func TestFieldLlvarDecodeErrors(t *testing.T) {
 		F2: NewLlvar(nil),
 	}
 
-	iso = Message{"0110", ASCII, false, data8}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data8}
 
 	err = iso.Load(isoBytes)
 
@@ -1174,7 +1175,7 @@ func TestFieldLllvarDecodeErrors(t *testing.T) {
 		F2: NewLllvar([]byte("123456")),
 	}
 
-	iso := Message{"0110", ASCII, false, data1}
+	iso := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data1}
 
 	isoBytes, err := iso.Bytes()
 
@@ -1194,7 +1195,7 @@ func TestFieldLllvarDecodeErrors(t *testing.T) {
 		F2: NewLllvar([]byte("123456")),
 	}
 
-	iso = Message{"0110", ASCII, false, data2}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data2}
 
 	isoBytes, err = iso.Bytes()
 
@@ -1214,7 +1215,7 @@ func TestFieldLllvarDecodeErrors(t *testing.T) {
 		F2: NewLllvar([]byte("123456")),
 	}
 
-	iso = Message{"0110", ASCII, false, data3}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data3}
 
 	isoBytes, err = iso.Bytes()
 
@@ -1234,7 +1235,7 @@ func TestFieldLllvarDecodeErrors(t *testing.T) {
 		F2: NewLllvar(nil),
 	}
 
-	iso = Message{"0110", ASCII, false, data4}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data4}
 
 	err = iso.Load(isoBytes)
 
@@ -1248,7 +1249,7 @@ func TestFieldLllvarDecodeErrors(t *testing.T) {
 		F2: NewLllvar([]byte("543210")),
 	}
 
-	iso = Message{"0110", ASCII, false, data5}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data5}
 
 	isoBytes, err = iso.Bytes()
 
@@ -1269,7 +1270,7 @@ func TestFieldLllvarDecodeErrors(t *testing.T) {
 		F2: NewLllvar(nil),
 	}
 
-	iso = Message{"0110", ASCII, false, data6}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data6}
 
 	err = iso.Load(isoBytes)
 
@@ -1283,7 +1284,7 @@ func TestFieldLllvarDecodeErrors(t *testing.T) {
 		F2: NewLllvar([]byte("543210")),
 	}
 
-	iso = Message{"0110", ASCII, false, data7}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data7}
 
 	isoBytes, err = iso.Bytes()
 
@@ -1305,7 +1306,7 @@ func TestFieldLllvarDecodeErrors(t *testing.T) {
 		F2: NewLllvar(nil),
 	}
 
-	iso = Message{"0110", ASCII, false, data8}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data8}
 
 	err = iso.Load(isoBytes)
 
@@ -1321,7 +1322,7 @@ func TestFieldAlphanumericDecodeErrors(t *testing.T) {
 		F2: NewAlphanumeric("123456"),
 	}
 
-	iso := Message{"0110", ASCII, false, data1}
+	iso := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data1}
 
 	isoBytes, err := iso.Bytes()
 
@@ -1341,7 +1342,7 @@ func TestFieldAlphanumericDecodeErrors(t *testing.T) {
 		F2: NewAlphanumeric("123456"),
 	}
 
-	iso = Message{"0110", ASCII, false, data2}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data2}
 
 	err = iso.Load(isoBytes)
 
@@ -1357,7 +1358,7 @@ func TestFieldBinaryDecodeErrors(t *testing.T) {
 		F2: NewBinary([]byte("123456")),
 	}
 
-	iso := Message{"0110", ASCII, false, data1}
+	iso := Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data1}
 
 	isoBytes, err := iso.Bytes()
 
@@ -1377,7 +1378,7 @@ func TestFieldBinaryDecodeErrors(t *testing.T) {
 		F2: NewBinary([]byte("123456")),
 	}
 
-	iso = Message{"0110", ASCII, false, data2}
+	iso = Message{Mti: "0110", MtiEncode: ASCII, SecondBitmap: false, Data: data2}
 
 	err = iso.Load(isoBytes)
 
@@ -1510,7 +1511,7 @@ func TestMessage(t *testing.T) {
 		AB *Llnumeric `field:"ab" length:"19"`
 	}
 
-	iso := Message{"", ASCII, true, TestIso{*newDataIso(), NewLlnumeric("")}}
+	iso := Message{Mti: "", MtiEncode: ASCII, SecondBitmap: true, Data: TestIso{*newDataIso(), NewLlnumeric("")}}
 
 	input := []byte{48, 49, 48, 48, 114, 60, 36, 129, 40, 224, 152, 0, 49, 54, 52, 50, 55, 54, 53, 53, 53, 53, 53, 53, 53, 53, 53, 53, 53, 53, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 55, 55, 55, 48, 48, 48, 55, 48, 49, 49, 49, 49, 56, 52, 52, 48, 48, 48, 49, 50, 51, 49, 51, 49, 56, 52, 52, 48, 55, 48, 49, 49, 57, 48, 50, 6, 67, 57, 48, 49, 48, 50, 48, 54, 49, 50, 51, 52, 53, 54, 51, 55, 52, 50, 55, 54, 53, 53, 53, 53, 53, 53, 53, 53, 53, 53, 53, 53, 61, 49, 50, 51, 52, 53, 54, 55, 56, 57, 48, 49, 50, 51, 52, 53, 54, 55, 56, 57, 48, 57, 56, 55, 54, 53, 52, 51, 50, 49, 48, 48, 49, 48, 48, 48, 48, 48, 51, 50, 49, 49, 50, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 51, 52, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 84, 101, 115, 116, 32, 116, 101, 120, 116, 100, 48, 1, 2, 3, 4, 5, 6, 7, 8, 49, 50, 51, 52, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48, 48}
 
@@ -1522,7 +1523,7 @@ func TestMessage(t *testing.T) {
 		F2 *Llnumeric `field:"2" length:"19"`
 	}
 
-	iso = Message{"", ASCII, true, TestIso2{}}
+	iso = Message{Mti: "", MtiEncode: ASCII, SecondBitmap: true, Data: TestIso2{}}
 
 	err = iso.Load(input)
 
@@ -1530,12 +1531,147 @@ func TestMessage(t *testing.T) {
 
 }
 
+type TerminalInfo struct {
+	F41 *Alphanumeric `field:"41" length:"8"`
+	F42 *Alphanumeric `field:"42" length:"15"`
+}
+
+type CardInfo struct {
+	F2  *Llnumeric `field:"2" length:"19"`
+	F14 *Numeric   `field:"14" length:"4"`
+}
+
+func TestEmbeddedFieldGroups(t *testing.T) {
+	type test struct {
+		TerminalInfo
+		*CardInfo
+		F4 *Numeric `field:"4" length:"12"`
+	}
+
+	data := &test{
+		TerminalInfo: TerminalInfo{NewAlphanumeric("TERM0001"), NewAlphanumeric("MERCHANT")},
+		CardInfo:     &CardInfo{NewLlnumeric("4276555555555555"), NewNumeric("2512")},
+		F4:           NewNumeric("100"),
+	}
+
+	iso := NewMessage("0100", data)
+
+	res, err := iso.Bytes()
+
+	assert.Nil(t, err)
+
+	parser := Parser{}
+	parser.Register("0100", &test{})
+
+	iso2, err := parser.Parse(res)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "TERM0001", iso2.Data.(*test).F41.Value)
+	assert.Equal(t, "4276555555555555", iso2.Data.(*test).F2.Value)
+	assert.Equal(t, "2512", iso2.Data.(*test).F14.Value)
+
+	assert.Nil(t, iso2.SetString(42, "SHOP"))
+	assert.Equal(t, "SHOP", iso2.Data.(*test).F42.Value)
+
+	// nil embedded struct has no fields
+	data.CardInfo = nil
+
+	res, err = iso.Bytes()
+
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x10, 0, 0, 0, 0, 0xc0, 0, 0}, res[4:12])
+}
+
+func TestCaptureRaw(t *testing.T) {
+	data := &TestISO{
+		F2:  NewLlnumeric("4276555555555555"),
+		F4:  NewNumeric("77700"),
+		F52: NewBinary([]byte{1, 2, 3, 4, 5, 6, 7, 8}),
+	}
+
+	res, err := NewMessage("0100", data).Bytes()
+
+	assert.Nil(t, err)
+
+	iso := Message{Mti: "", MtiEncode: ASCII, Data: newDataIso(), CaptureRaw: true}
+
+	err = iso.Load(res)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("164276555555555555"), iso.RawField(2))
+	assert.Equal(t, []byte("000000077700"), iso.RawField(4))
+	assert.Equal(t, []byte{1, 2, 3, 4, 5, 6, 7, 8}, iso.RawField(52))
+	assert.Nil(t, iso.RawField(3))
+
+	clone := iso.Clone()
+	clone.RawField(2)[0] = 'x'
+
+	assert.Equal(t, byte('1'), iso.RawField(2)[0])
+
+	iso.CaptureRaw = false
+
+	err = iso.Load(res)
+
+	assert.Nil(t, err)
+	assert.Nil(t, iso.RawField(2))
+}
+
+func TestPassThrough(t *testing.T) {
+	type test struct {
+		F2  *Llnumeric    `field:"2" length:"19"`
+		F4  *Numeric      `field:"4" length:"12"`
+		F43 *Alphanumeric `field:"43" length:"10"`
+		F64 *Binary       `field:"64" length:"8"`
+	}
+
+	// amount is not zero padded and merchant name is right padded by sender
+	input := []byte("0200")
+	input = append(input, 0x50, 0, 0, 0, 0, 0x20, 0, 0x01)
+	input = append(input, []byte("164276555555555555       77700SHOP      ")...)
+	input = append(input, 1, 2, 3, 4, 5, 6, 7, 8)
+
+	iso := Message{Data: &test{&Llnumeric{}, &Numeric{}, &Alphanumeric{}, NewBinary(nil)}, PassThrough: true}
+
+	err := iso.Load(input)
+
+	assert.Nil(t, err)
+
+	res, err := iso.Bytes()
+
+	assert.Nil(t, err)
+	assert.Equal(t, input, res)
+
+	iso.Data.(*test).F2.Value = "5413330089020011"
+	iso.Data.(*test).F64.Value = []byte{8, 7, 6, 5, 4, 3, 2, 1}
+
+	res, err = iso.Bytes()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "165413330089020011       77700SHOP      ", string(res[12:52]))
+	assert.Equal(t, []byte{8, 7, 6, 5, 4, 3, 2, 1}, res[52:])
+
+	// without pass-through untouched fields are re-encoded
+	iso.PassThrough = false
+
+	res, err = iso.Bytes()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "165413330089020011       77700SHOP      ", string(res[12:52]))
+
+	iso.Data.(*test).F4.Value = "77700"
+
+	res, err = iso.Bytes()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "165413330089020011000000077700SHOP      ", string(res[12:52]))
+}
+
 func TestMTIError(t *testing.T) {
 	data := &TestISO{
 		F2: NewLlnumeric("4276555555555555"),
 	}
 
-	iso := Message{"01000", ASCII, true, data}
+	iso := Message{Mti: "01000", MtiEncode: ASCII, SecondBitmap: true, Data: data}
 
 	_, err := iso.Bytes()
 
@@ -1553,13 +1689,13 @@ func TestMTIError(t *testing.T) {
 
 	assert.EqualError(t, err, "MTI is required")
 
-	iso = Message{"0100", BCD, true, data}
+	iso = Message{Mti: "0100", MtiEncode: BCD, SecondBitmap: true, Data: data}
 
 	res, err := iso.Bytes()
 
 	assert.Empty(t, err)
 
-	iso = Message{"", BCD, true, data}
+	iso = Message{Mti: "", MtiEncode: BCD, SecondBitmap: true, Data: data}
 
 	err = iso.Load(res[0:1])
 
@@ -1578,13 +1714,13 @@ func TestMTIBCD(t *testing.T) {
 		F2: NewLlnumeric("4276555555555555"),
 	}
 
-	iso := Message{"0100", BCD, true, data}
+	iso := Message{Mti: "0100", MtiEncode: BCD, SecondBitmap: true, Data: data}
 
 	res, err := iso.Bytes()
 
 	assert.Empty(t, err)
 
-	iso2 := Message{"0100", BCD, true, data}
+	iso2 := Message{Mti: "0100", MtiEncode: BCD, SecondBitmap: true, Data: data}
 
 	err = iso2.Load(res)
 
@@ -1593,6 +1729,125 @@ func TestMTIBCD(t *testing.T) {
 	assert.Equal(t, iso, iso2)
 }
 
+func TestSecondBitmapAuto(t *testing.T) {
+	data := &TestISO{
+		F2:   NewLlnumeric("4276555555555555"),
+		F120: NewLllnumeric("Another test text"),
+	}
+
+	iso := Message{Mti: "0100", MtiEncode: ASCII, SecondBitmap: false, Data: data}
+
+	res, err := iso.Bytes()
+
+	assert.Empty(t, err)
+
+	iso.SecondBitmap = true
+
+	forced, err := iso.Bytes()
+
+	assert.Empty(t, err)
+	assert.Equal(t, forced, res)
+	assert.Equal(t, byte(0xc0), res[4])
+
+	iso2 := Message{Mti: "", MtiEncode: ASCII, SecondBitmap: false, Data: newDataIso()}
+
+	err = iso2.Load(res)
+
+	assert.Empty(t, err)
+	assert.Equal(t, "Another test text", iso2.Data.(*TestISO).F120.Value)
+
+	iso.DisableSecondBitmap = true
+
+	_, err = iso.Bytes()
+
+	assert.EqualError(t, err, "field 120 requires secondary bitmap")
+
+	data.F120 = nil
+
+	_, err = iso.Bytes()
+
+	assert.EqualError(t, err, "secondary bitmap is both forced and disabled")
+
+	iso.SecondBitmap = false
+
+	res, err = iso.Bytes()
+
+	assert.Empty(t, err)
+	assert.Equal(t, byte(0x40), res[4])
+}
+
+func TestSecondBitmapFieldErrors(t *testing.T) {
+	type test struct {
+		F2   *Llnumeric    `field:"2" length:"19"`
+		F70  *Numeric      `field:"70" length:"3"`
+		F100 *Llnumeric    `field:"100" length:"11"`
+		F120 *Lllnumeric   `field:"120" length:"999"`
+		F130 *Alphanumeric `field:"130" length:"2"`
+	}
+
+	data := &test{F2: NewLlnumeric("4276555555555555"), F70: NewNumeric("301"),
+		F100: NewLlnumeric("123"), F120: NewLllnumeric("1")}
+	iso := NewMessage("0100", data)
+	iso.DisableSecondBitmap = true
+
+	// the lowest field is reported
+	for i := 0; i < 10; i++ {
+		_, err := iso.Bytes()
+		assert.EqualError(t, err, "field 70 requires secondary bitmap")
+	}
+
+	iso.DisableSecondBitmap = false
+	data.F130 = NewAlphanumeric("AB")
+	_, err := iso.Bytes()
+	assert.EqualError(t, err, "field 130 is beyond secondary bitmap")
+}
+
+func TestMTIEncodings(t *testing.T) {
+	data := &TestISO{
+		F2: NewLlnumeric("4276555555555555"),
+	}
+
+	iso := Message{Mti: "0200", MtiEncode: EBCDIC, SecondBitmap: false, Data: data}
+
+	res, err := iso.Bytes()
+
+	assert.Empty(t, err)
+	assert.Equal(t, []byte{0xf0, 0xf2, 0xf0, 0xf0}, res[:4])
+
+	iso2 := Message{Mti: "", MtiEncode: EBCDIC, SecondBitmap: false, Data: newDataIso()}
+
+	err = iso2.Load(res)
+
+	assert.Empty(t, err)
+	assert.Equal(t, "0200", iso2.Mti)
+	assert.Equal(t, "4276555555555555", iso2.Data.(*TestISO).F2.Value)
+
+	iso = Message{Mti: "0200", MtiEncode: BINARY, SecondBitmap: false, Data: data}
+
+	res, err = iso.Bytes()
+
+	assert.Empty(t, err)
+	assert.Equal(t, []byte{0x00, 0xc8}, res[:2])
+
+	iso2 = Message{Mti: "", MtiEncode: BINARY, SecondBitmap: false, Data: newDataIso()}
+
+	err = iso2.Load(res)
+
+	assert.Empty(t, err)
+	assert.Equal(t, "0200", iso2.Mti)
+	assert.Equal(t, "4276555555555555", iso2.Data.(*TestISO).F2.Value)
+
+	_, err = decodeMti([]byte{0xff, 0xff}, BINARY, nil)
+
+	assert.EqualError(t, err, "bad MTI raw data")
+
+	iso.MtiEncode = 10
+
+	_, err = iso.Bytes()
+
+	assert.EqualError(t, err, "invalid encode type")
+}
+
 func TestParseFieldsErrors(t *testing.T) {
 	type test1 struct {
 		F2 *Llnumeric `field:"abc" length:"19"`
@@ -1602,7 +1857,7 @@ func TestParseFieldsErrors(t *testing.T) {
 		F2: NewLlnumeric("4276555555555555"),
 	}
 
-	iso := Message{"0100", BCD, true, data1}
+	iso := Message{Mti: "0100", MtiEncode: BCD, SecondBitmap: true, Data: data1}
 
 	_, err := iso.Bytes()
 
@@ -1616,7 +1871,7 @@ func TestParseFieldsErrors(t *testing.T) {
 		F2: NewLlnumeric("4276555555555555"),
 	}
 
-	iso = Message{"0100", BCD, true, data2}
+	iso = Message{Mti: "0100", MtiEncode: BCD, SecondBitmap: true, Data: data2}
 
 	_, err = iso.Bytes()
 
@@ -1630,19 +1885,65 @@ func TestParseFieldsErrors(t *testing.T) {
 		F2: string("123abc"),
 	}
 
-	iso = Message{"0100", BCD, true, data3}
+	iso = Message{Mti: "0100", MtiEncode: BCD, SecondBitmap: true, Data: data3}
 
 	_, err = iso.Bytes()
 
 	assert.EqualError(t, err, "Critical error:field must be Iso8583Type")
 
-	iso = Message{"0100", BCD, true, nil}
+	iso = Message{Mti: "0100", MtiEncode: BCD, SecondBitmap: true, Data: nil}
 
 	_, err = iso.Bytes()
 
 	assert.EqualError(t, err, "Critical error:data must be a struct")
 }
 
+func TestFieldPresence(t *testing.T) {
+	type test struct {
+		F3  *Numeric `field:"3,required" length:"6"`
+		F54 *Llvar   `field:"54,present" length:"255"`
+		F55 *Llvar   `field:"55,omitempty" length:"255"`
+		F56 *Llvar   `field:"56" length:"255"`
+	}
+
+	data := &test{
+		F3:  NewNumeric("123456"),
+		F54: NewLlvar(nil),
+		F55: NewLlvar(nil),
+		F56: NewLlvar(nil),
+	}
+
+	iso := NewMessage("0110", data)
+
+	assert.Nil(t, iso.Validate())
+
+	res, err := iso.Bytes()
+
+	assert.Nil(t, err)
+	// only fields 3 and 54 are in bitmap, field 54 has zero length
+	assert.Equal(t, "3031313020000000000004003132333435363030", fmt.Sprintf("%x", res))
+
+	iso2 := NewMessage("", &test{F3: NewNumeric(""), F54: NewLlvar([]byte("abc"))})
+
+	err = iso2.Load(res)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "123456", iso2.Data.(*test).F3.Value)
+	assert.Equal(t, 0, len(iso2.Data.(*test).F54.Value))
+
+	data.F3 = nil
+
+	assert.EqualError(t, iso.Validate(), "field 3 is required")
+
+	type test2 struct {
+		F3 *Numeric `field:"3,mandatory" length:"6"`
+	}
+
+	iso = NewMessage("0110", &test2{NewNumeric("1")})
+
+	assert.EqualError(t, iso.Validate(), "Critical error:unknown field option: mandatory")
+}
+
 // newDataIso creates DataIso
 func newDataIso() *TestISO {
 	return &TestISO{
@@ -1670,3 +1971,51 @@ func newDataIso() *TestISO {
 		F120: NewLllnumeric(""),
 	}
 }
+
+func TestLoadWithoutMTI(t *testing.T) {
+	data := &testTransaction{F11: NewNumeric("000123"), F41: NewAlphanumeric("TERM0001")}
+	res, err := NewMessage("0200", data).Bytes()
+	assert.Nil(t, err)
+
+	iso := NewMessage("0200", &testTransaction{F11: &Numeric{}, F41: &Alphanumeric{}})
+	err = iso.LoadWithoutMTI(res[4:])
+	assert.Nil(t, err)
+	assert.Equal(t, data, iso.Data)
+	assert.Equal(t, "0200", iso.Mti)
+
+	parser := &Parser{}
+	parser.Register("0200", &testTransaction{})
+	msg, err := parser.ParseWithoutMTI("0200", res[4:])
+	assert.Nil(t, err)
+	assert.Equal(t, "000123", msg.Data.(*testTransaction).F11.Value)
+
+	_, err = parser.ParseWithoutMTI("0210", res[4:])
+	assert.EqualError(t, err, "no template registered for MTI: 0210")
+}
+
+func TestSkipMTI(t *testing.T) {
+	data := &testTransaction{F11: NewNumeric("000123"), F41: NewAlphanumeric("TERM0001")}
+	full, err := NewMessage("0200", data).Bytes()
+	assert.Nil(t, err)
+
+	msg := NewMessage("0200", data)
+	msg.SkipMTI = true
+	res, err := msg.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, full[4:], res)
+
+	iso := NewMessage("0200", &testTransaction{F11: &Numeric{}, F41: &Alphanumeric{}})
+	iso.SkipMTI = true
+	assert.Nil(t, iso.Load(res))
+	assert.Equal(t, data, iso.Data)
+	assert.Equal(t, "0200", iso.Mti)
+
+	parser := &Parser{SkipMTI: true}
+	parser.Register("0200", &testTransaction{})
+	_, err = parser.Parse(res)
+	assert.EqualError(t, err, "raw data has no MTI, use ParseWithoutMTI")
+	parsed, err := parser.ParseWithoutMTI("0200", res)
+	assert.Nil(t, err)
+	assert.True(t, parsed.SkipMTI)
+	assert.Equal(t, "TERM0001", parsed.Data.(*testTransaction).F41.Value)
+}
@@ -0,0 +1,63 @@
+// Package iso8583test provides helpers to benchmark packing and parsing of
+// messages and to guard their allocation budget in tests.
+package iso8583test
+
+import (
+	"github.com/ideazxy/iso8583"
+	"testing"
+)
+
+// BenchmarkPack benchmarks msg.Bytes, reporting allocations and bytes per
+// message
+func BenchmarkPack(b *testing.B, msg *iso8583.Message) {
+	raw, err := msg.Bytes()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.SetBytes(int64(len(raw)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		msg.Bytes()
+	}
+}
+
+// BenchmarkParse benchmarks p.Parse(raw), reporting allocations and bytes
+// per message
+func BenchmarkParse(b *testing.B, p *iso8583.Parser, raw []byte) {
+	if _, err := p.Parse(raw); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.SetBytes(int64(len(raw)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.Parse(raw)
+	}
+}
+
+// AllocsPerPackBelow fails t if packing msg makes more than budget heap
+// allocations on average
+func AllocsPerPackBelow(t testing.TB, msg *iso8583.Message, budget float64) {
+	t.Helper()
+	allocs, err := iso8583.AllocsPerPack(msg, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if allocs > budget {
+		t.Errorf("packing %s makes %.1f allocations, budget is %.1f", msg.Mti, allocs, budget)
+	}
+}
+
+// AllocsPerParseBelow fails t if parsing raw makes more than budget heap
+// allocations on average
+func AllocsPerParseBelow(t testing.TB, p *iso8583.Parser, raw []byte, budget float64) {
+	t.Helper()
+	allocs, err := iso8583.AllocsPerParse(p, raw, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if allocs > budget {
+		t.Errorf("parsing makes %.1f allocations, budget is %.1f", allocs, budget)
+	}
+}
@@ -0,0 +1,50 @@
+package iso8583test
+
+import (
+	"github.com/ideazxy/iso8583"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func newEcho() *iso8583.Message {
+	return iso8583.NewEcho("000001", time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+}
+
+func TestAllocsBelow(t *testing.T) {
+	msg := newEcho()
+	AllocsPerPackBelow(t, msg, 1000)
+
+	raw, err := msg.Bytes()
+	assert.Nil(t, err)
+	p := &iso8583.Parser{}
+	p.Register("0800", &iso8583.NetworkManagement{})
+	AllocsPerParseBelow(t, p, raw, 1000)
+
+	tb := &recorder{}
+	AllocsPerPackBelow(tb, msg, 0)
+	assert.True(t, tb.failed)
+}
+
+// recorder is testing.TB which records failure
+type recorder struct {
+	testing.TB
+	failed bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failed = true
+}
+
+func BenchmarkPackEcho(b *testing.B) {
+	BenchmarkPack(b, newEcho())
+}
+
+func BenchmarkParseEcho(b *testing.B) {
+	raw, _ := newEcho().Bytes()
+	p := &iso8583.Parser{}
+	p.Register("0800", &iso8583.NetworkManagement{})
+	BenchmarkParse(b, p, raw)
+}
@@ -0,0 +1,165 @@
+package iso8583
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonMessage is JSON form of message accepted by UnmarshalJSON
+type jsonMessage struct {
+	Mti    string                     `json:"mti"`
+	Fields map[string]json.RawMessage `json:"fields"`
+}
+
+// MarshalJSON encodes message as {"mti":"0200","fields":{"2":"...",...}}.
+// Fields are ordered by number and values are always strings, so leading
+// zeros of numeric values are kept and diffs of messages are stable. Values
+// of binary fields (Binary, Llvar and Lllvar), fields with value_format:"hex"
+// tag and other than text or numeric fields (packed according to their
+// tags) are upper case hex, so bytes which are not UTF-8 are kept. Text
+// values of variable length are declared with LlvarText and LllvarText.
+// Extra fields are encoded too.
+func (m *Message) MarshalJSON() (ret []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("Critical error:" + fmt.Sprint(r))
+			ret = nil
+		}
+	}()
+
+	fields, err := m.fields()
+	if err != nil {
+		return nil, err
+	}
+	if err := m.addExtraFields(fields); err != nil {
+		return nil, err
+	}
+	indexes := make([]int, 0, len(fields))
+	for i, info := range fields {
+		if !info.Field.IsEmpty() || info.Present {
+			indexes = append(indexes, i)
+		}
+	}
+	sort.Ints(indexes)
+
+	buf := &bytes.Buffer{}
+	mti, _ := json.Marshal(m.Mti)
+	buf.WriteString(`{"mti":`)
+	buf.Write(mti)
+	buf.WriteString(`,"fields":{`)
+	for n, i := range indexes {
+		info := fields[i]
+		var s string
+		if m.jsonHex(info) {
+			v := []byte(fieldValue(info.Field))
+			if !isProtoText(info.Field) {
+				info.Quirks = m.Quirks
+				info.CodePage = m.CodePage
+				if v, err = info.bytes(); err != nil {
+					return nil, &FieldError{i, err}
+				}
+			}
+			s = strings.ToUpper(hex.EncodeToString(v))
+		} else {
+			s = fieldValue(info.Field)
+		}
+		v, err := json.Marshal(s)
+		if err != nil {
+			return nil, &FieldError{i, err}
+		}
+		if n > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(`"` + strconv.Itoa(i) + `":`)
+		buf.Write(v)
+	}
+	buf.WriteString(`}}`)
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON decodes message encoded by MarshalJSON. Mti is set if it is
+// empty. Values may be JSON numbers too, they are taken as written and are
+// never converted to float64. Nil fields are allocated, Data must be a
+// pointer to struct. Fields which Data doesn't define are decoded by
+// CatchAll into Extra.
+func (m *Message) UnmarshalJSON(b []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("Critical error:" + fmt.Sprint(r))
+		}
+	}()
+
+	var doc jsonMessage
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return err
+	}
+	if m.Mti == "" {
+		m.Mti = doc.Mti
+	}
+	m.Extra = nil
+
+	values := make(map[int]string, len(doc.Fields))
+	indexes := make([]int, 0, len(doc.Fields))
+	for k, raw := range doc.Fields {
+		i, err := strconv.Atoi(k)
+		if err != nil || i < 2 || i > 128 {
+			return fmt.Errorf("bad field index: %s", k)
+		}
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			var num json.Number
+			if json.Unmarshal(raw, &num) != nil {
+				return fmt.Errorf("field %d: value must be string or number", i)
+			}
+			s = num.String()
+		}
+		values[i] = s
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	for _, i := range indexes {
+		v := []byte(values[i])
+		if info, ok := m.jsonFieldInfo(i); ok && m.jsonHex(info) {
+			if v, err = hex.DecodeString(values[i]); err != nil {
+				return fmt.Errorf("field %d: value must be hex", i)
+			}
+		}
+		if err := m.setProtoField(i, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsonFieldInfo returns info of field i defined by Data or CatchAll
+func (m *Message) jsonFieldInfo(i int) (*fieldInfo, bool) {
+	sf, _, ok := findField(m.Data, i)
+	if !ok {
+		return m.catchAllInfo(i, nil)
+	}
+	if sf.Type.Kind() != reflect.Ptr {
+		return nil, false
+	}
+	return newFieldInfo(sf, reflect.New(sf.Type.Elem())), true
+}
+
+// jsonHex checks if JSON value of field is hex
+func (m *Message) jsonHex(info *fieldInfo) bool {
+	switch info.Field.(type) {
+	case *Binary, *Llvar, *Lllvar:
+		return true
+	}
+	if !isProtoText(info.Field) {
+		return true
+	}
+	sf, _, ok := findField(m.Data, info.Index)
+	return ok && isHexFormat(sf)
+}
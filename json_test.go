@@ -0,0 +1,67 @@
+package iso8583
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	type test struct {
+		F2  *Llnumeric          `field:"2" length:"19"`
+		F4  *Numeric            `field:"4" length:"12"`
+		F11 *Numeric            `field:"11" length:"6"`
+		F41 *Alphanumeric       `field:"41" length:"8"`
+		F48 *testAdditionalData `field:"48" length:"999"`
+		F52 *Binary             `field:"52" length:"8"`
+		F62 *Llvar              `field:"62"`
+		F63 *LllvarText         `field:"63" length:"999"`
+	}
+
+	data := &test{
+		F2:  NewLlnumeric("4276555555555558"),
+		F4:  NewNumeric("000000001250"),
+		F11: NewNumeric("000001"),
+		F41: NewAlphanumeric("TERM01"),
+		F48: &testAdditionalData{
+			S1: NewNumeric("7"),
+			S3: NewLlvar([]byte("hello")),
+		},
+		F52: NewBinary([]byte{0, 1, 2, 0xff}),
+		F62: NewLlvar([]byte{0x9f, 0x27}),
+		F63: NewLllvarText("text"),
+	}
+	msg := NewMessage("0100", data)
+	msg.CatchAll = []CatchAllField{{First: 120, Last: 127}}
+	msg.Extra = map[int]Iso8583Type{120: NewLllvar([]byte("private"))}
+	b, err := json.Marshal(msg)
+	assert.Nil(t, err)
+	// composite F48 is packed, so it is hex
+	assert.Equal(t, `{"mti":"0100","fields":{"2":"4276555555555558","4":"000000001250","11":"000001",`+
+		`"41":"TERM01","48":"303133303720202020303568656C6C6F","52":"000102FF","62":"9F27","63":"text","120":"70726976617465"}}`, string(b))
+
+	into := NewMessage("", &test{})
+	into.CatchAll = msg.CatchAll
+	assert.Nil(t, json.Unmarshal(b, into))
+	assert.Equal(t, "0100", into.Mti)
+	res := into.Data.(*test)
+	assert.Equal(t, data.F2, res.F2)
+	assert.Equal(t, "000001", res.F11.Value)
+	assert.Equal(t, []byte("hello"), res.F48.S3.Value)
+	assert.Equal(t, data.F52.Value, res.F52.Value)
+	assert.Equal(t, data.F62, res.F62)
+	assert.Equal(t, data.F63, res.F63)
+	assert.Equal(t, NewLllvar([]byte("private")), into.Extra[120])
+
+	// numbers are taken as written, not as float64
+	into = NewMessage("", &test{})
+	assert.Nil(t, json.Unmarshal([]byte(`{"mti":"0100","fields":{"2":4276555555555558,"4":1250}}`), into))
+	res = into.Data.(*test)
+	assert.Equal(t, "4276555555555558", res.F2.Value)
+	assert.Equal(t, "000000001250", res.F4.Value)
+
+	assert.EqualError(t, json.Unmarshal([]byte(`{"fields":{"4":true}}`), into), "field 4: value must be string or number")
+	assert.EqualError(t, json.Unmarshal([]byte(`{"fields":{"x":"1"}}`), into), "bad field index: x")
+	assert.EqualError(t, json.Unmarshal([]byte(`{"fields":{"52":"zz"}}`), into), "field 52: value must be hex")
+	assert.EqualError(t, json.Unmarshal([]byte(`{"fields":{"3":"1"}}`), into), "field 3 not defined")
+}
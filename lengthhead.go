@@ -0,0 +1,82 @@
+package iso8583
+
+import (
+	"fmt"
+)
+
+// encodeLengthHead encodes length head of n with digits digits. Length
+// head encoder is independent of value encoder: ascii, bcd, rbcd, ebcdic or
+// binary. Binary head is 1 byte for 2 digits and 2 bytes big-endian for 3
+// digits, so it holds up to 255 or 65535.
+func encodeLengthHead(n, digits, lenEncoder int) ([]byte, error) {
+	if lenEncoder == BINARY {
+		size := lengthHeadLen(digits, lenEncoder)
+		if n < 0 || n >= 1<<(8*uint(size)) {
+			return nil, ErrInvalidLengthHead
+		}
+		head := make([]byte, size)
+		for i := size - 1; i >= 0; i, n = i-1, n>>8 {
+			head[i] = byte(n)
+		}
+		return head, nil
+	}
+	s := fmt.Sprintf("%0*d", digits, n)
+	if len(s) > digits {
+		return nil, ErrInvalidLengthHead
+	}
+	switch lenEncoder {
+	case ASCII:
+		return []byte(s), nil
+	case BCD, rBCD:
+		return rbcd([]byte(s)), nil
+	case EBCDIC:
+		return CodePage037.Encode([]byte(s)), nil
+	}
+	return nil, ErrInvalidLengthEncoder
+}
+
+// decodeLengthHead decodes length head with digits digits, it returns the
+// length and size of the head
+func decodeLengthHead(raw []byte, digits, lenEncoder int) (n, read int, err error) {
+	switch lenEncoder {
+	case ASCII, BCD, rBCD, EBCDIC, BINARY:
+	default:
+		return 0, 0, ErrInvalidLengthEncoder
+	}
+	read = lengthHeadLen(digits, lenEncoder)
+	if len(raw) < read {
+		return 0, 0, ErrBadRaw
+	}
+	head := raw[:read]
+	if lenEncoder == BINARY {
+		for _, b := range head {
+			n = n<<8 | int(b)
+		}
+		return n, read, nil
+	}
+	switch lenEncoder {
+	case BCD, rBCD:
+		head = bcdr2Ascii(head, digits)
+	case EBCDIC:
+		head = CodePage037.Decode(head)
+	}
+	n, err = parseLength(string(head))
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %s", ErrParseLengthFailed, string(raw[:read]))
+	}
+	return n, read, nil
+}
+
+// lengthHeadLen returns length of encoded length head with digits digits
+func lengthHeadLen(digits, lenEncoder int) int {
+	if lenEncoder == BINARY {
+		if digits > 2 {
+			return 2
+		}
+		return 1
+	}
+	if lenEncoder == BCD || lenEncoder == rBCD {
+		return (digits + 1) / 2
+	}
+	return digits
+}
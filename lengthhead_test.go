@@ -0,0 +1,81 @@
+package iso8583
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLengthHeadEncoders(t *testing.T) {
+	type test struct {
+		F2  *Llnumeric  `field:"2" length:"19" encode:"ebcdic,bcd"`
+		F32 *Llnumeric  `field:"32" length:"11" encode:"bcd,ebcdic"`
+		F35 *Lllnumeric `field:"35" length:"37" encode:"ascii,ebcdic"`
+		F48 *Lllvar     `field:"48" length:"999" encode:"ebcdic,ascii"`
+		F63 *LlvarText  `field:"63" length:"99" encode:"bcd,ebcdic"`
+	}
+	data := &test{
+		NewLlnumeric("4276555555555"),
+		NewLlnumeric("123"),
+		NewLllnumeric("45"),
+		NewLllvar([]byte("ab")),
+		NewLlvarText("ok"),
+	}
+	res, err := NewMessage("0200", data).Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "f1f3"+"42765555555550"+"03"+"f1f2f3"+"303032"+"f4f5"+"f0f0f2"+"6162"+"02"+"9692",
+		fmt.Sprintf("%x", res[12:]))
+
+	loaded := &test{&Llnumeric{}, &Llnumeric{}, &Lllnumeric{}, &Lllvar{}, &LlvarText{}}
+	assert.Nil(t, NewMessage("", loaded).Load(res))
+	assert.Equal(t, data, loaded)
+
+	_, _, err = decodeLengthHead([]byte{0xf1}, 2, EBCDIC)
+	assert.Equal(t, ErrBadRaw, err)
+	_, _, err = decodeLengthHead([]byte("12"), 2, ZONED)
+	assert.Equal(t, ErrInvalidLengthEncoder, err)
+
+	head, err := encodeLengthHead(255, 2, BINARY)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0xff}, head)
+	head, err = encodeLengthHead(300, 3, BINARY)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x01, 0x2c}, head)
+	n, read, err := decodeLengthHead([]byte{0x01, 0x2c, 0}, 3, BINARY)
+	assert.Nil(t, err)
+	assert.Equal(t, 300, n)
+	assert.Equal(t, 2, read)
+	_, err = encodeLengthHead(256, 2, BINARY)
+	assert.Equal(t, ErrInvalidLengthHead, err)
+	_, _, err = decodeLengthHead([]byte{0xc1, 0xf1}, 2, EBCDIC)
+	assert.EqualError(t, err, "parse length head failed: \xc1\xf1")
+	_, err = encodeLengthHead(100, 2, EBCDIC)
+	assert.Equal(t, ErrInvalidLengthHead, err)
+}
+
+func TestEbcdicNumericCodePage(t *testing.T) {
+	type test struct {
+		F2  *Llnumeric  `field:"2" length:"19" encode:"ebcdic"`
+		F35 *Lllnumeric `field:"35" length:"37" encode:"ascii,ebcdic"`
+	}
+	// code page with swapped digits 1 and 2
+	table := [256]byte{}
+	for i := range table {
+		table[i] = CodePage037.Encode([]byte{byte(i)})[0]
+	}
+	table['1'], table['2'] = table['2'], table['1']
+	cp, err := NewCodePage(table)
+	assert.Nil(t, err)
+
+	data := &test{NewLlnumeric("12"), NewLllnumeric("1")}
+	iso := NewMessage("0100", data)
+	iso.CodePage = cp
+	res, err := iso.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "3032f2f1"+"303031f2", fmt.Sprintf("%x", res[12:]))
+
+	iso2 := NewMessage("", &test{&Llnumeric{}, &Lllnumeric{}})
+	iso2.CodePage = cp
+	assert.Nil(t, iso2.Load(res))
+	assert.Equal(t, data, iso2.Data)
+}
@@ -0,0 +1,197 @@
+package iso8583
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LinkState is health state of a connection supervised by Link
+type LinkState int
+
+const (
+	// LinkDown means there is no connection
+	LinkDown LinkState = iota
+	// LinkConnected means connection is established and echo tests succeed
+	LinkConnected
+	// LinkDegraded means connection is established but last echo test failed
+	LinkDegraded
+)
+
+func (s LinkState) String() string {
+	switch s {
+	case LinkDown:
+		return "down"
+	case LinkConnected:
+		return "connected"
+	case LinkDegraded:
+		return "degraded"
+	}
+	return fmt.Sprintf("LinkState(%d)", int(s))
+}
+
+// Link supervises a connection: it connects with exponential backoff,
+// sends echo test message when connection is idle for IdleTimeout and
+// reconnects after MaxEchoFailures failed echo tests in a row. Connection
+// itself is managed by Connect, Echo and Close functions.
+type Link struct {
+	// Connect establishes connection
+	Connect func(ctx context.Context) error
+	// Echo sends echo test message and waits for response
+	Echo func(ctx context.Context, msg *Message) error
+	// Close closes connection before reconnect, optional
+	Close func() error
+	// OnStateChange is called on each state change, optional
+	OnStateChange func(state LinkState)
+	// Stan generates STANs of echo test messages. It should be shared with
+	// Session of the connection, so echo and sign-on messages don't repeat
+	// STANs. Link creates its own generator if it is nil.
+	Stan *StanGenerator
+
+	IdleTimeout     time.Duration // default is 1 minute
+	MinBackoff      time.Duration // default is 1 second
+	MaxBackoff      time.Duration // default is 1 minute
+	MaxEchoFailures int           // default is 3
+
+	lastActivity atomic.Int64 // unix nano
+	mu           sync.Mutex
+	state        LinkState
+}
+
+// Touch records traffic on connection, so echo test is postponed
+func (l *Link) Touch() {
+	l.lastActivity.Store(time.Now().UnixNano())
+}
+
+// State returns current state, so applications can gate traffic on it
+func (l *Link) State() LinkState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.state
+}
+
+func (l *Link) setState(state LinkState) {
+	l.mu.Lock()
+	changed := l.state != state
+	l.state = state
+	l.mu.Unlock()
+	if changed && l.OnStateChange != nil {
+		l.OnStateChange(state)
+	}
+}
+
+// Run supervises connection until ctx is done, closes it and returns
+// ctx.Err()
+func (l *Link) Run(ctx context.Context) error {
+	idleTimeout, minBackoff, maxBackoff, maxFailures := l.IdleTimeout, l.MinBackoff, l.MaxBackoff, l.MaxEchoFailures
+	if idleTimeout <= 0 {
+		idleTimeout = time.Minute
+	}
+	if minBackoff <= 0 {
+		minBackoff = time.Second
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = time.Minute
+	}
+	if maxBackoff < minBackoff {
+		maxBackoff = minBackoff
+	}
+	if maxFailures <= 0 {
+		maxFailures = 3
+	}
+
+	backoff := minBackoff
+	connected := false
+	for {
+		if err := l.Connect(ctx); err != nil {
+			l.setState(LinkDown)
+			if err := sleepContext(ctx, backoff); err != nil {
+				return err
+			}
+			if backoff *= 2; backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+			continue
+		}
+		backoff = minBackoff
+		if connected {
+			instrumentConn(ConnInstrumentation.OnReconnect)
+		} else {
+			instrumentConn(ConnInstrumentation.OnConnect)
+		}
+		connected = true
+		l.setState(LinkConnected)
+		l.Touch()
+
+		lastEcho := time.Time{}
+		for failures := 0; failures < maxFailures; {
+			last := time.Unix(0, l.lastActivity.Load())
+			if lastEcho.After(last) {
+				last = lastEcho
+			}
+			if idle := time.Since(last); idle < idleTimeout {
+				if err := sleepContext(ctx, idleTimeout-idle); err != nil {
+					return l.stop(err)
+				}
+				continue
+			}
+
+			lastEcho = time.Now()
+			if err := l.echo(ctx, lastEcho); err != nil {
+				if ctx.Err() != nil {
+					return l.stop(ctx.Err())
+				}
+				failures++
+				l.setState(LinkDegraded)
+				continue
+			}
+			failures = 0
+			l.setState(LinkConnected)
+		}
+
+		l.setState(LinkDown)
+		instrumentConn(ConnInstrumentation.OnDisconnect)
+		if l.Close != nil {
+			l.Close()
+		}
+	}
+}
+
+// echo sends echo test message with next STAN of Stan, which is created if
+// it is nil
+func (l *Link) echo(ctx context.Context, t time.Time) error {
+	l.mu.Lock()
+	if l.Stan == nil {
+		l.Stan = &StanGenerator{}
+	}
+	g := l.Stan
+	l.mu.Unlock()
+	stan, err := g.Generate()
+	if err != nil {
+		return err
+	}
+	return l.Echo(ctx, NewEcho(stan, t))
+}
+
+// stop closes established connection when Run is done
+func (l *Link) stop(err error) error {
+	l.setState(LinkDown)
+	instrumentConn(ConnInstrumentation.OnDisconnect)
+	if l.Close != nil {
+		l.Close()
+	}
+	return err
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,104 @@
+package iso8583
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLink(t *testing.T) {
+	var mu sync.Mutex
+	var states []LinkState
+	connects, echoes, closes := 0, 0, 0
+	ctx, cancel := context.WithCancel(context.Background())
+	fail := errors.New("connection refused")
+	// generator is shared with Session of the connection
+	stan, _ := NewStanGenerator("000041")
+
+	link := &Link{
+		Connect: func(ctx context.Context) error {
+			connects++
+			if connects == 1 {
+				return fail
+			}
+			return nil
+		},
+		Echo: func(ctx context.Context, msg *Message) error {
+			echoes++
+			assert.Equal(t, NMI_ECHO, msg.Data.(*NetworkManagement).F70.Value)
+			assert.Equal(t, stan.Last(), msg.Data.(*NetworkManagement).F11.Value)
+			switch {
+			case echoes == 1:
+				return nil
+			case echoes <= 3:
+				return fail
+			case connects == 3:
+				cancel()
+			}
+			return nil
+		},
+		Close: func() error {
+			closes++
+			return nil
+		},
+		OnStateChange: func(state LinkState) {
+			mu.Lock()
+			states = append(states, state)
+			mu.Unlock()
+		},
+		IdleTimeout:     time.Millisecond,
+		MinBackoff:      time.Millisecond,
+		MaxEchoFailures: 2,
+		Stan:            stan,
+	}
+	assert.Equal(t, LinkDown, link.State())
+
+	err := link.Run(ctx)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 3, connects)
+	assert.Equal(t, 2, closes)
+	// STANs continue across reconnects
+	assert.Equal(t, fmt.Sprintf("%06d", 41+echoes), stan.Last())
+	assert.Equal(t, []LinkState{LinkConnected, LinkDegraded, LinkDown, LinkConnected, LinkDown}, states)
+	assert.Equal(t, "degraded", LinkDegraded.String())
+}
+
+func TestLinkTouch(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	echoes, closes := 0, 0
+	link := &Link{
+		Connect:     func(ctx context.Context) error { return nil },
+		Echo:        func(ctx context.Context, msg *Message) error { echoes++; return nil },
+		Close:       func() error { closes++; return nil },
+		IdleTimeout: 30 * time.Millisecond,
+	}
+	go func() {
+		for ctx.Err() == nil {
+			link.Touch()
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	assert.Equal(t, context.DeadlineExceeded, link.Run(ctx))
+	assert.Equal(t, 0, echoes)
+	assert.Equal(t, 1, closes)
+	assert.Equal(t, LinkDown, link.State())
+}
+
+func TestLinkDefaultIdleTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	echoes := 0
+	link := &Link{
+		Connect: func(ctx context.Context) error { return nil },
+		Echo:    func(ctx context.Context, msg *Message) error { echoes++; return nil },
+	}
+	assert.Equal(t, context.DeadlineExceeded, link.Run(ctx))
+	assert.Equal(t, 0, echoes)
+}
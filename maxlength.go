@@ -0,0 +1,49 @@
+package iso8583
+
+import "reflect"
+
+// ISOMaxLengths are maximum lengths of variable fields by ISO 8583:1987,
+// in digits, characters or bytes of value. Presets of networks set them to
+// MaxLengths of Message and Parser.
+var ISOMaxLengths = map[int]int{
+	2: 19, 32: 11, 33: 11, 34: 28, 35: 37, 36: 104, 44: 25, 45: 76,
+	46: 999, 47: 999, 48: 999, 54: 120, 55: 999, 56: 999, 57: 999,
+	58: 999, 59: 999, 60: 999, 61: 999, 62: 999, 63: 999, 72: 999,
+	99: 11, 100: 11, 102: 28, 103: 28, 104: 100, 105: 999, 106: 999,
+	107: 999, 108: 999, 109: 999, 110: 999, 111: 999, 112: 999, 113: 999,
+	114: 999, 115: 999, 116: 999, 117: 999, 118: 999, 119: 999, 120: 999,
+	121: 999, 122: 999, 123: 999, 124: 999, 125: 999, 126: 999, 127: 999,
+}
+
+// MaxLengthsWith returns copy of ISOMaxLengths with overrides applied, for
+// networks which deviate from the standard. Zero removes the limit. Each
+// network preset keeps its own copy, so changing limits of one network
+// doesn't affect the others or ISOMaxLengths.
+func MaxLengthsWith(overrides map[int]int) map[int]int {
+	ret := make(map[int]int, len(ISOMaxLengths)+len(overrides))
+	for i, n := range ISOMaxLengths {
+		ret[i] = n
+	}
+	for i, n := range overrides {
+		if n == 0 {
+			delete(ret, i)
+			continue
+		}
+		ret[i] = n
+	}
+	return ret
+}
+
+// checkMaxLength returns FieldError if value of field is longer than max.
+// Compressed value is checked as it is sent.
+func checkMaxLength(i int, info *fieldInfo, max int) error {
+	c, err := info.compressed()
+	if err != nil {
+		return &FieldError{i, err}
+	}
+	f := c.truncated().Field
+	if n := len(fieldValue(f)); n > max {
+		return &FieldError{i, valueTooLong(reflect.Indirect(reflect.ValueOf(f)).Type().Name(), max, n)}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package iso8583
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMaxLengths(t *testing.T) {
+	type test struct {
+		F2  *Llnumeric `field:"2" length:"99"`
+		F32 *Llnumeric `field:"32" length:"99"`
+		F55 *Lllvar    `field:"55" length:"999"`
+	}
+	data := &test{
+		F2:  NewLlnumeric("42765555555555555555"),
+		F32: NewLlnumeric("123456"),
+		F55: NewLllvar(make([]byte, 300)),
+	}
+	iso := NewMessage("0100", data)
+	_, err := iso.Bytes()
+	assert.Nil(t, err)
+
+	iso.MaxLengths = ISOMaxLengths
+	_, err = iso.Bytes()
+	var fe *FieldError
+	assert.True(t, errors.As(err, &fe))
+	assert.Equal(t, 2, fe.Field)
+	assert.True(t, errors.Is(err, ErrValueTooLong))
+	assert.Equal(t, "field 2: length of value is longer than definition; type=Llnumeric, def_len=19, len=20", err.Error())
+
+	data.F2.Value = "4276555555555555555"
+	_, err = iso.Bytes()
+	assert.Nil(t, err)
+
+	iso.MaxLengths = MaxLengthsWith(map[int]int{2: 0, 55: 255})
+	data.F2.Value = "42765555555555555555"
+	_, err = iso.Bytes()
+	assert.Equal(t, "field 55: length of value is longer than definition; type=Lllvar, def_len=255, len=300", err.Error())
+	assert.Equal(t, 999, ISOMaxLengths[55])
+
+	p := &Parser{MaxLengths: ISOMaxLengths}
+	p.Register("0100", &test{})
+	data.F55.Value = []byte{1}
+	res, err := NewMessage("0100", data).Bytes()
+	assert.Nil(t, err)
+	msg, err := p.Parse(res)
+	assert.Nil(t, err)
+	_, err = msg.Bytes()
+	assert.True(t, errors.Is(err, ErrValueTooLong))
+}
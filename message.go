@@ -4,14 +4,29 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
 	TAG_FIELD  string = "field"
 	TAG_ENCODE string = "encode"
 	TAG_LENGTH string = "length"
+	TAG_PAD    string = "pad"
+	TAG_FILLER string = "filler"
+)
+
+// Options of field tag, for example `field:"54,present"`
+const (
+	// OPT_OMITEMPTY omits empty field on packing (default)
+	OPT_OMITEMPTY string = "omitempty"
+	// OPT_PRESENT packs field even if it is empty, only nil field is absent
+	OPT_PRESENT string = "present"
+	// OPT_REQUIRED is OPT_PRESENT for mandatory field, Validate reports it
+	// if it is nil
+	OPT_REQUIRED string = "required"
 )
 
 type fieldInfo struct {
@@ -19,24 +34,171 @@ type fieldInfo struct {
 	Encode    int
 	LenEncode int
 	Length    int
+	Present   bool
+	Required  bool
+	Pad       int
+	Filler    byte
+	Truncate  int
+	Transform string
+	Charset   string
+	Compress  string
+	Quirks    Quirks
+	CodePage  *CodePage
 	Field     Iso8583Type
 }
 
+// bytes encode field according to its tags
+func (f *fieldInfo) bytes() ([]byte, error) {
+	f, err := f.compressed()
+	if err != nil {
+		return nil, err
+	}
+	f = f.truncated()
+	if v2 := f.fieldV2(); v2 != nil {
+		return v2.BytesSpec(f.spec())
+	}
+	if p, ok := f.Field.(paddedField); ok && f.Pad != padZero {
+		return p.bytesPadded(f.Encode, f.Length, f.Pad)
+	}
+	var d []byte
+	if t, ok := f.Field.(textField); ok {
+		d, err = t.bytesText(f.Encode, f.LenEncode, f.length(), f.CodePage)
+	} else {
+		d, err = f.Field.Bytes(f.Encode, f.LenEncode, f.length())
+	}
+	if err != nil {
+		return nil, err
+	}
+	if f.Filler != 0 {
+		d = fillNibble(d, f)
+	}
+	if digits := f.lengthHead(); digits > 0 {
+		return f.bytesLengthInBytes(d, digits)
+	}
+	return d, nil
+}
+
+// load decode field according to its tags
+func (f *fieldInfo) load(raw []byte) (int, error) {
+	if v2 := f.fieldV2(); v2 != nil {
+		return v2.LoadSpec(raw, f.spec())
+	}
+	if p, ok := f.Field.(paddedField); ok && f.Pad != padZero {
+		return p.loadPadded(raw, f.Encode, f.Length, f.Pad)
+	}
+	if digits := f.lengthHead(); digits > 0 {
+		return f.loadLengthInBytes(raw, digits)
+	}
+	if t, ok := f.Field.(textField); ok {
+		return t.loadText(raw, f.Encode, f.LenEncode, f.length(), f.CodePage)
+	}
+	return f.Field.Load(raw, f.Encode, f.LenEncode, f.length())
+}
+
 // Message is structure for ISO 8583 message encode and decode
 type Message struct {
-	Mti          string
-	MtiEncode    int
+	Mti       string
+	MtiEncode int
+	// SecondBitmap forces secondary bitmap on packing. Otherwise it is added
+	// automatically when any of fields 65-128 is present.
 	SecondBitmap bool
 	Data         interface{}
+	// BitmapEncode is encoding of bitmaps, default is BITMAP_BINARY
+	BitmapEncode string
+	// CaptureRaw makes Load retain raw bytes of each field (including
+	// length head), see RawField
+	CaptureRaw bool
+	// PassThrough makes Load retain raw bytes and values of fields, and
+	// Bytes reuse raw bytes of fields which values are not changed since
+	// then, so forwarded message is byte-identical except changed fields
+	PassThrough bool
+	// CodePage is used by EBCDIC encoder, default is CodePage037
+	CodePage *CodePage
+	// Quirks are wire compatibility flags for non-standard hosts
+	Quirks Quirks
+	// StrictNumeric makes packing fail with FieldError if value of Numeric,
+	// Llnumeric or Lllnumeric field contains anything but digits
+	StrictNumeric bool
+	// Truncation overrides truncation policy of fields by index, for ex.
+	// {43: TRUNCATE_RIGHT}
+	Truncation map[int]string
+	// CatchAll decodes fields which are not defined in Data into Extra
+	CatchAll []CatchAllField
+	// Extra holds fields decoded by CatchAll, they are packed too
+	Extra map[int]Iso8583Type
+	// Transforms override transforms of loaded fields by index, for ex.
+	// {43: "trim,upper"}
+	Transforms map[int]string
+	// Compression overrides compression of fields by index, for ex.
+	// {127: COMPRESS_ZLIB}
+	Compression map[int]string
+	// Charsets override charsets of fields by index, for ex. {41: "ans"}
+	Charsets map[int]string
+	// Clock makes packing fill empty fields 7, 12 and 13 with its current
+	// time, optional
+	Clock Clock
+	// Location is zone of local time and date (fields 12 and 13) filled
+	// by Clock, nil is location of time returned by Clock
+	Location *time.Location
+	// MaxLengths limits length of field values by index on packing, for
+	// ex. ISOMaxLengths
+	MaxLengths map[int]int
+	// MaxSize limits size of packed message, for ex. to 4096 bytes hosts
+	// accept, 0 means no limit
+	MaxSize int
+	// CollectErrors makes Load and Validate report all problems they find
+	// as FieldErrors instead of the first one. Load keeps parsing after
+	// fields with non-digit numeric values, values outside of their
+	// charsets or longer than MaxLengths, and stops only at a field which
+	// can't be decoded.
+	CollectErrors bool
+	// CorrelationID is an opaque ID which ties message to upstream request,
+	// for ex. of an API. It is not packed, but it is carried to responses
+	// by Correlator and Router and to reversals, and reported to
+	// Instrumentation and Tracer.
+	CorrelationID string
+	// DisableSecondBitmap makes packing fail if any of fields 65-128 is
+	// present, for hosts which don't support secondary bitmap
+	DisableSecondBitmap bool
+	// SkipMTI makes Bytes omit MTI and Load read message which starts at
+	// bitmap, for transports which deliver MTI separately. Mti is left as
+	// is by Load.
+	SkipMTI bool
+
+	raw     map[int][]byte
+	snap    map[int]interface{}
+	present map[int]bool // fields present by SetPresent
 }
 
 // NewMessage creates new Message structure
 func NewMessage(mti string, data interface{}) *Message {
-	return &Message{mti, ASCII, false, data}
+	return &Message{Mti: mti, MtiEncode: ASCII, Data: data}
+}
+
+// Bytes marshall Message to bytes. Fields are emitted in ascending order
+// of their numbers. Secondary bitmap is emitted if any of fields 65-128 is
+// present or SecondBitmap is set, even if there are no such fields. See
+// VerifyCanonical.
+func (m *Message) Bytes() ([]byte, error) {
+	start := time.Now()
+	ret, err := m.pack()
+	instrument(OpPack, m, ret, m.mtiLen(), start, err)
+	return ret, err
+}
+
+func (m *Message) pack() ([]byte, error) {
+	p, err := m.Plan()
+	if err != nil {
+		return nil, err
+	}
+	return p.Bytes(), nil
 }
 
-// Bytes marshall Message to bytes
-func (m *Message) Bytes() (ret []byte, err error) {
+// Plan validates and encodes fields of message without assembling it, so
+// invalid message is rejected before network resources are reserved and
+// exact size of message is known, see Plan. Message is packed by writing
+// the plan.
+func (m *Message) Plan() (ret *Plan, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			err = errors.New("Critical error:" + fmt.Sprint(r))
@@ -44,24 +206,61 @@ func (m *Message) Bytes() (ret []byte, err error) {
 		}
 	}()
 
-	ret = make([]byte, 0)
+	ret = &Plan{}
+
+	if err := checkBitmapEncode(m.BitmapEncode); err != nil {
+		return nil, err
+	}
+	if err := m.stampTime(); err != nil {
+		return nil, err
+	}
 
 	// generate MTI:
-	mtiBytes, err := m.encodeMti()
+	if !m.SkipMTI {
+		mtiBytes, err := m.encodeMti()
+		if err != nil {
+			return nil, err
+		}
+		ret.Mti = mtiBytes
+	}
+
+	// generate bitmap and fields:
+	fields, err := m.fields()
 	if err != nil {
 		return nil, err
 	}
-	ret = append(ret, mtiBytes...)
+	if err := m.addExtraFields(fields); err != nil {
+		return nil, err
+	}
 
-	// generate bitmap and fields:
-	fields := parseFields(m.Data)
+	secondBitmap := m.SecondBitmap || m.Quirks&QUIRK_SECOND_BITMAP != 0
+	indexes := make([]int, 0, len(fields))
+	for i, info := range fields {
+		if !info.Field.IsEmpty() || info.Present {
+			indexes = append(indexes, i)
+		}
+	}
+	sort.Ints(indexes)
+	for _, i := range indexes {
+		if i > 128 {
+			return nil, fmt.Errorf("field %d is beyond secondary bitmap", i)
+		}
+		if i > 64 {
+			if m.DisableSecondBitmap {
+				return nil, fmt.Errorf("field %d requires secondary bitmap", i)
+			}
+			secondBitmap = true
+		}
+	}
+	if secondBitmap && m.DisableSecondBitmap {
+		return nil, errors.New("secondary bitmap is both forced and disabled")
+	}
 
 	byteNum := 8
-	if m.SecondBitmap {
+	if secondBitmap {
 		byteNum = 16
 	}
 	bitmap := make([]byte, byteNum)
-	data := make([]byte, 0, 512)
 
 	for byteIndex := 0; byteIndex < byteNum; byteIndex++ {
 		for bitIndex := 0; bitIndex < 8; bitIndex++ {
@@ -69,7 +268,7 @@ func (m *Message) Bytes() (ret []byte, err error) {
 			i := byteIndex*8 + bitIndex + 1
 
 			// if we need second bitmap (additional 8 bytes) - set first bit in first bitmap
-			if m.SecondBitmap && i == 1 {
+			if secondBitmap && i == 1 {
 				step := uint(7 - bitIndex)
 				bitmap[byteIndex] |= (0x01 << step)
 			}
@@ -77,7 +276,8 @@ func (m *Message) Bytes() (ret []byte, err error) {
 			if info, ok := fields[i]; ok {
 
 				// if field is empty, then we can't add it to bitmap
-				if info.Field.IsEmpty() {
+				// unless it is explicitly present
+				if info.Field.IsEmpty() && !info.Present {
 					continue
 				}
 
@@ -85,17 +285,45 @@ func (m *Message) Bytes() (ret []byte, err error) {
 				step := uint(7 - bitIndex)
 				bitmap[byteIndex] |= (0x01 << step)
 				// append data:
-				d, err := info.Field.Bytes(info.Encode, info.LenEncode, info.Length)
+				if d, ok := m.passThroughBytes(info); ok {
+					ret.Fields = append(ret.Fields, PlanField{i, d})
+					continue
+				}
+				if m.StrictNumeric && !isNumericValue(info.Field) {
+					return nil, &FieldError{i, ErrNotNumeric}
+				}
+				if c, ok := m.Charsets[i]; ok {
+					info.Charset = c
+				}
+				if err := info.checkCharset(); err != nil {
+					return nil, &FieldError{i, err}
+				}
+				info.Quirks = m.Quirks
+				info.CodePage = m.CodePage
+				if t, ok := m.Truncation[i]; ok {
+					info.Truncate = parseTruncateStr(t)
+				}
+				if c, ok := m.Compression[i]; ok {
+					info.Compress = parseCompressStr(c)
+				}
+				if max, ok := m.MaxLengths[i]; ok {
+					if err := checkMaxLength(i, info, max); err != nil {
+						return nil, err
+					}
+				}
+				d, err := info.bytes()
 				if err != nil {
 					return nil, err
 				}
-				data = append(data, d...)
+				ret.Fields = append(ret.Fields, PlanField{i, d})
 			}
 		}
 	}
-	ret = append(ret, bitmap...)
-	ret = append(ret, data...)
+	ret.Bitmap = encodeBitmap(bitmap, m.BitmapEncode)
 
+	if m.MaxSize > 0 && ret.Len() > m.MaxSize {
+		return nil, messageTooLarge(ret, m.MaxSize)
+	}
 	return ret, nil
 }
 
@@ -112,63 +340,193 @@ func (m *Message) encodeMti() ([]byte, error) {
 		return nil, errors.New("MTI is invalid")
 	}
 
-	switch m.MtiEncode {
-	case BCD:
+	switch m.Quirks.mtiEncode(m.MtiEncode) {
+	case ASCII:
+		return []byte(m.Mti), nil
+	case BCD, rBCD:
 		return bcd([]byte(m.Mti)), nil
+	case EBCDIC:
+		return codePageOrDefault(m.CodePage).Encode([]byte(m.Mti)), nil
+	case BINARY:
+		n, _ := strconv.Atoi(m.Mti)
+		return []byte{byte(n >> 8), byte(n)}, nil
 	default:
-		return []byte(m.Mti), nil
+		return nil, errors.New("invalid encode type")
 	}
 }
 
-func parseFields(msg interface{}) map[int]*fieldInfo {
+// parseFields returns fields of struct msg by index. Unknown tag options
+// are returned as error matching ErrInvalidTag.
+func parseFields(msg interface{}) (map[int]*fieldInfo, error) {
 	fields := make(map[int]*fieldInfo)
 
 	v := reflect.Indirect(reflect.ValueOf(msg))
 	if v.Kind() != reflect.Struct {
 		panic("data must be a struct")
 	}
-	for i := 0; i < v.NumField(); i++ {
-		if isPtrOrInterface(v.Field(i).Kind()) && v.Field(i).IsNil() {
-			continue
+	if err := checkTags(v.Type()); err != nil {
+		return nil, err
+	}
+	walkFields(v, func(sf reflect.StructField, fv reflect.Value) {
+		if isPtrOrInterface(fv.Kind()) && fv.IsNil() {
+			return
 		}
+		info := newFieldInfo(sf, fv)
+		fields[info.Index] = info
+	})
+	return fields, nil
+}
+
+// walkFields calls fn for each struct field of v with field tag. Fields of
+// embedded structs without field tag are walked too, so common field groups
+// can be reused in several messages. Nil embedded pointers are skipped. It
+// panics if two struct fields have the same field number.
+func walkFields(v reflect.Value, fn func(sf reflect.StructField, fv reflect.Value)) {
+	walkFieldsOf(v, v.Type(), make(map[int]string), fn)
+}
 
+// walkFieldsOf walks fields of v, which is tp or embedded in tp. seen holds
+// names of walked struct fields by field number.
+func walkFieldsOf(v reflect.Value, tp reflect.Type, seen map[int]string, fn func(sf reflect.StructField, fv reflect.Value)) {
+	for i := 0; i < v.NumField(); i++ {
 		sf := v.Type().Field(i)
-		if sf.Tag == "" || sf.Tag.Get(TAG_FIELD) == "" {
+		if sf.Tag.Get(TAG_FIELD) == "" {
+			if sf.Anonymous {
+				if ev := reflect.Indirect(v.Field(i)); ev.Kind() == reflect.Struct {
+					walkFieldsOf(ev, tp, seen, fn)
+				}
+			}
 			continue
 		}
+		index, _, _ := parseFieldTag(sf.Tag.Get(TAG_FIELD))
+		if name, ok := seen[index]; ok {
+			panic(duplicateField(tp, index, name, sf.Name))
+		}
+		seen[index] = sf.Name
+		fn(sf, v.Field(i))
+	}
+}
 
-		index, err := strconv.Atoi(sf.Tag.Get(TAG_FIELD))
-		if err != nil {
-			panic("value of field must be numeric")
-		}
-
-		encode := 0
-		lenEncode := 0
-		if raw := sf.Tag.Get(TAG_ENCODE); raw != "" {
-			enc := strings.Split(raw, ",")
-			if len(enc) == 2 {
-				lenEncode = parseEncodeStr(enc[0])
-				encode = parseEncodeStr(enc[1])
-			} else {
-				encode = parseEncodeStr(enc[0])
-			}
+// duplicateField returns message of duplicate field number index of struct
+// fields a and b of tp
+func duplicateField(tp reflect.Type, index int, a, b string) string {
+	return fmt.Sprintf("duplicate field %d in %s: %s and %s", index, tp, a, b)
+}
+
+// newFieldInfo parses tags of struct field sf, which value is v
+func newFieldInfo(sf reflect.StructField, v reflect.Value) *fieldInfo {
+	index, present, required := parseFieldTag(sf.Tag.Get(TAG_FIELD))
+
+	encode := 0
+	lenEncode := 0
+	if raw := sf.Tag.Get(TAG_ENCODE); raw != "" {
+		enc := strings.Split(raw, ",")
+		if len(enc) == 2 {
+			lenEncode = parseEncodeStr(enc[0])
+			encode = parseEncodeStr(enc[1])
+		} else {
+			encode = parseEncodeStr(enc[0])
 		}
+	}
 
-		length := -1
-		if l := sf.Tag.Get(TAG_LENGTH); l != "" {
-			length, err = strconv.Atoi(l)
-			if err != nil {
-				panic("value of length must be numeric")
-			}
+	length := -1
+	if l := sf.Tag.Get(TAG_LENGTH); l != "" {
+		var err error
+		length, err = parseLength(l)
+		if err != nil {
+			panic("value of length must be numeric")
 		}
+	}
+
+	pad := parsePadStr(sf.Tag.Get(TAG_PAD))
+	filler := parseFillerStr(sf.Tag.Get(TAG_FILLER))
+	truncate := parseTruncateStr(sf.Tag.Get(TAG_TRUNCATE))
+	transform := sf.Tag.Get(TAG_TRANSFORM)
+	compress := parseCompressStr(sf.Tag.Get(TAG_COMPRESS))
+	charset := sf.Tag.Get(TAG_CHARSET)
+	if charset != "" {
+		parseCharsetStr(charset)
+	}
 
-		field, ok := v.Field(i).Interface().(Iso8583Type)
-		if !ok {
+	field, ok := v.Interface().(Iso8583Type)
+	if v2, isV2 := v.Interface().(FieldV2); !ok && isV2 {
+		field, ok = Iso8583TypeOf(v2), true
+	}
+	if !ok {
+		switch {
+		case isStructSlice(v):
+			// slice of structs holds repeating groups
+			field = newRepeated(v, sf.Tag.Get(TAG_COMPOSITE), sf.Tag.Get(TAG_REPEAT))
+		case isStructPtr(v):
+			// nested struct is a composite field
+			field = &composite{v, sf.Tag.Get(TAG_COMPOSITE)}
+		default:
 			panic("field must be Iso8583Type")
 		}
-		fields[index] = &fieldInfo{index, encode, lenEncode, length, field}
 	}
-	return fields
+	return &fieldInfo{
+		Index:     index,
+		Encode:    encode,
+		LenEncode: lenEncode,
+		Length:    length,
+		Present:   present,
+		Required:  required,
+		Pad:       pad,
+		Filler:    filler,
+		Truncate:  truncate,
+		Transform: transform,
+		Charset:   charset,
+		Compress:  compress,
+		Field:     field,
+	}
+}
+
+// parseFieldTag parses field index and options from field tag
+func parseFieldTag(tag string) (index int, present, required bool) {
+	opts := strings.Split(tag, ",")
+	index, err := strconv.Atoi(opts[0])
+	if err != nil {
+		panic("value of field must be numeric")
+	}
+	for _, opt := range opts[1:] {
+		switch opt {
+		case OPT_OMITEMPTY:
+		case OPT_PRESENT:
+			present = true
+		case OPT_REQUIRED:
+			present = true
+			required = true
+		default:
+			panic("unknown field option: " + opt)
+		}
+	}
+	return index, present, required
+}
+
+// Validate checks that all required fields of Message are set
+func (m *Message) Validate() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("Critical error:" + fmt.Sprint(r))
+		}
+	}()
+
+	v := reflect.Indirect(reflect.ValueOf(m.Data))
+	if v.Kind() != reflect.Struct {
+		panic("data must be a struct")
+	}
+	var errs FieldErrors
+	walkFields(v, func(sf reflect.StructField, fv reflect.Value) {
+		index, _, required := parseFieldTag(sf.Tag.Get(TAG_FIELD))
+		if (err == nil || m.CollectErrors) && required && isPtrOrInterface(fv.Kind()) && fv.IsNil() {
+			err = fmt.Errorf("field %d is required", index)
+			errs = append(errs, err)
+		}
+	})
+	if m.CollectErrors && len(errs) > 0 {
+		return errs
+	}
+	return err
 }
 
 func isPtrOrInterface(k reflect.Kind) bool {
@@ -185,39 +543,100 @@ func parseEncodeStr(str string) int {
 		return BCD
 	case "rbcd":
 		return rBCD
+	case "zoned":
+		return ZONED
+	case "ebcdic":
+		return EBCDIC
+	case "binary":
+		return BINARY
 	}
 	return -1
 }
 
 // Load unmarshall Message from bytes
-func (m *Message) Load(raw []byte) (err error) {
+func (m *Message) Load(raw []byte) error {
+	start := time.Now()
+	_, err := m.load(raw)
+	instrument(OpUnpack, m, raw, m.mtiLen(), start, err)
+	return err
+}
+
+// LoadWithoutMTI unmarshall Message from bytes which start at bitmap, for
+// transports which deliver MTI separately. Mti is left as is.
+func (m *Message) LoadWithoutMTI(raw []byte) error {
+	start := time.Now()
+	_, err := m.loadFields(raw)
+	instrument(OpUnpack, m, raw, 0, start, err)
+	return err
+}
+
+// load unmarshall Message from bytes and returns its length, bytes after
+// the message are ignored
+func (m *Message) load(raw []byte) (n int, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			err = errors.New("Critical error:" + fmt.Sprint(r))
+			n = 0
 		}
 	}()
 
-	if m.Mti == "" {
-		m.Mti, err = decodeMti(raw, m.MtiEncode)
+	if m.Mti == "" && !m.SkipMTI {
+		m.Mti, err = decodeMti(raw, m.Quirks.mtiEncode(m.MtiEncode), m.CodePage)
 		if err != nil {
-			return err
+			return 0, err
 		}
 	}
-	start := 4
-	if m.MtiEncode == BCD {
-		start = 2
+	l := m.mtiLen()
+	n, err = m.loadFields(raw[l:])
+	if err != nil {
+		return 0, err
+	}
+	return l + n, nil
+}
+
+// mtiLen returns length of MTI in raw message, which is 0 with SkipMTI
+func (m *Message) mtiLen() int {
+	if m.SkipMTI {
+		return 0
+	}
+	return mtiLen(m.Quirks.mtiEncode(m.MtiEncode))
+}
+
+// loadFields unmarshall bitmap and fields and returns their length
+func (m *Message) loadFields(raw []byte) (n int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("Critical error:" + fmt.Sprint(r))
+			n = 0
+		}
+	}()
+
+	fields, err := parseFields(m.Data)
+	if err != nil {
+		return 0, err
 	}
+	var errs FieldErrors
 
-	fields := parseFields(m.Data)
+	m.raw = nil
+	m.snap = nil
+	m.present = nil
+	if m.CaptureRaw || m.PassThrough {
+		m.raw = make(map[int][]byte)
+	}
+	if m.PassThrough {
+		m.snap = make(map[int]interface{})
+	}
+	m.Extra = nil
 
-	byteNum := 8
-	if raw[start]&0x80 == 0x80 {
+	bitByte, start, err := decodeBitmap(raw, m.BitmapEncode)
+	if err != nil {
+		return 0, err
+	}
+	byteNum := len(bitByte)
+	if byteNum == 16 {
 		// 1st bit == 1
 		m.SecondBitmap = true
-		byteNum = 16
 	}
-	bitByte := raw[start : start+byteNum]
-	start += byteNum
 
 	for byteIndex := 0; byteIndex < byteNum; byteIndex++ {
 		for bitIndex := 0; bitIndex < 8; bitIndex++ {
@@ -233,14 +652,87 @@ func (m *Message) Load(raw []byte) (err error) {
 			}
 			f, ok := fields[i]
 			if !ok {
-				return fmt.Errorf("field %d not defined", i)
+				if f, ok = m.catchAllInfo(i, nil); !ok {
+					return 0, m.collectError(errs, fmt.Errorf("field %d not defined", i))
+				}
+				if m.Extra == nil {
+					m.Extra = make(map[int]Iso8583Type)
+				}
+				m.Extra[i] = f.Field
+			}
+			f.Quirks = m.Quirks
+			f.CodePage = m.CodePage
+			l, err := f.load(raw[start:])
+			if err == nil {
+				if c, ok := m.Compression[i]; ok {
+					f.Compress = parseCompressStr(c)
+				}
+				err = f.decompress(m.maxDecompressed())
 			}
-			l, err := f.Field.Load(raw[start:], f.Encode, f.LenEncode, f.Length)
 			if err != nil {
-				return fmt.Errorf("field %d: %s", i, err)
+				return 0, m.collectError(errs, &FieldError{i, err})
+			}
+			if t, ok := m.Transforms[i]; ok {
+				f.Transform = t
+			}
+			f.transform()
+			if c, ok := m.Charsets[i]; ok {
+				f.Charset = c
+			}
+			if m.raw != nil {
+				m.raw[i] = append([]byte(nil), raw[start:start+l]...)
+			}
+			if m.snap != nil {
+				m.snap[i] = deepCopy(fieldValueOf(f.Field)).Interface()
+			}
+			if m.CollectErrors {
+				errs = append(errs, m.checkLoaded(f)...)
+			} else if err := f.checkCharset(); err != nil {
+				return 0, &FieldError{i, err}
 			}
 			start += l
 		}
 	}
-	return nil
+	if len(errs) > 0 {
+		return start, errs
+	}
+	return start, nil
+}
+
+// RawField returns raw bytes of field i (including length head) retained
+// by the last Load with CaptureRaw or PassThrough enabled, or nil if there
+// is no such field
+func (m *Message) RawField(i int) []byte {
+	return m.raw[i]
+}
+
+// passThroughBytes returns raw bytes of field retained by Load if its value
+// is not changed since then
+func (m *Message) passThroughBytes(info *fieldInfo) ([]byte, bool) {
+	if !m.PassThrough {
+		return nil, false
+	}
+	raw, ok := m.raw[info.Index]
+	if !ok {
+		return nil, false
+	}
+	if !reflect.DeepEqual(fieldValueOf(info.Field).Interface(), m.snap[info.Index]) {
+		return nil, false
+	}
+	return raw, true
+}
+
+// fieldValueOf returns value of field, for composite field it is the
+// nested struct and for repeating groups it is the slice
+func fieldValueOf(f Iso8583Type) reflect.Value {
+	if c, ok := f.(*composite); ok {
+		return c.value
+	}
+	if r, ok := f.(*repeated); ok {
+		return r.value
+	}
+	if a, ok := f.(*v2Adapter); ok {
+		return reflect.ValueOf(a.field)
+	}
+	return reflect.ValueOf(f)
 }
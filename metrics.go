@@ -0,0 +1,150 @@
+package iso8583
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Operations reported to Instrumentation
+const (
+	OpPack   string = "pack"
+	OpUnpack string = "unpack"
+)
+
+// Metrics of a single pack or unpack operation
+type Metrics struct {
+	Op       string
+	Mti      string
+	Size     int // bytes packed or given to unpack
+	Fields   int // fields in bitmap, without secondary bitmap bit
+	Duration time.Duration
+	// CorrelationID is CorrelationID of the message
+	CorrelationID string
+}
+
+// Instrumentation receives metrics of Message packing and unpacking, for
+// example to update Prometheus counters and histograms. Implementations
+// must be safe for concurrent use.
+type Instrumentation interface {
+	// OnPack is called after successful Message.Bytes
+	OnPack(m Metrics)
+
+	// OnUnpack is called after successful Message.Load
+	OnUnpack(m Metrics)
+
+	// OnError is called when Message.Bytes or Message.Load fails
+	OnError(m Metrics, err error)
+}
+
+// ConnInstrumentation is optionally implemented by Instrumentation to
+// receive connection events of Link
+type ConnInstrumentation interface {
+	// OnConnect is called when Link establishes its first connection
+	OnConnect()
+
+	// OnReconnect is called when Link establishes connection again after
+	// it was lost
+	OnReconnect()
+
+	// OnDisconnect is called when Link drops connection after failed echo
+	// tests
+	OnDisconnect()
+}
+
+// NopInstrumentation is Instrumentation that does nothing, it is default
+type NopInstrumentation struct{}
+
+// OnPack does nothing
+func (NopInstrumentation) OnPack(m Metrics) {}
+
+// OnUnpack does nothing
+func (NopInstrumentation) OnUnpack(m Metrics) {}
+
+// OnError does nothing
+func (NopInstrumentation) OnError(m Metrics, err error) {}
+
+// OnConnect does nothing
+func (NopInstrumentation) OnConnect() {}
+
+// OnReconnect does nothing
+func (NopInstrumentation) OnReconnect() {}
+
+// OnDisconnect does nothing
+func (NopInstrumentation) OnDisconnect() {}
+
+type instrumentationHolder struct {
+	Instrumentation
+}
+
+var instrumentation atomic.Value
+
+func init() {
+	instrumentation.Store(instrumentationHolder{NopInstrumentation{}})
+}
+
+// SetInstrumentation sets Instrumentation for all messages, nil restores
+// NopInstrumentation
+func SetInstrumentation(i Instrumentation) {
+	if i == nil {
+		i = NopInstrumentation{}
+	}
+	instrumentation.Store(instrumentationHolder{i})
+}
+
+// instrument reports operation on raw message, whose bitmap starts at
+// offset. Fields are counted only when Instrumentation is installed.
+func instrument(op string, msg *Message, raw []byte, offset int, start time.Time, err error) {
+	i := instrumentation.Load().(instrumentationHolder).Instrumentation
+	if _, ok := i.(NopInstrumentation); ok {
+		return
+	}
+	fields := countFields(raw, offset, msg.BitmapEncode)
+	m := Metrics{op, msg.Mti, len(raw), fields, time.Since(start), msg.CorrelationID}
+	switch {
+	case err != nil:
+		i.OnError(m, err)
+	case op == OpPack:
+		i.OnPack(m)
+	default:
+		i.OnUnpack(m)
+	}
+}
+
+// instrumentConn reports connection event to installed Instrumentation if
+// it implements ConnInstrumentation
+func instrumentConn(event func(ConnInstrumentation)) {
+	i := instrumentation.Load().(instrumentationHolder).Instrumentation
+	if _, ok := i.(NopInstrumentation); ok {
+		return
+	}
+	if c, ok := i.(ConnInstrumentation); ok {
+		event(c)
+	}
+}
+
+// countFields counts fields in bitmap of raw message, which starts at
+// start (after MTI) and is encoded with enc
+func countFields(raw []byte, start int, enc string) (n int) {
+	defer func() {
+		if r := recover(); r != nil {
+			n = 0
+		}
+	}()
+	if len(raw) < start {
+		return 0
+	}
+	bitmap, _, err := decodeBitmap(raw[start:], enc)
+	if err != nil {
+		return 0
+	}
+	for i, b := range bitmap {
+		if i == 0 {
+			// field 1 is the second bitmap
+			b &= 0x7f
+		}
+		for ; b != 0; b &= b - 1 {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,113 @@
+package iso8583
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testInstrumentation struct {
+	mu     sync.Mutex
+	events []Metrics
+	errs   []error
+	conns  []string
+}
+
+func (i *testInstrumentation) OnConnect() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.conns = append(i.conns, "connect")
+}
+
+func (i *testInstrumentation) OnReconnect() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.conns = append(i.conns, "reconnect")
+}
+
+func (i *testInstrumentation) OnDisconnect() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.conns = append(i.conns, "disconnect")
+}
+
+func (i *testInstrumentation) OnPack(m Metrics) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.events = append(i.events, m)
+}
+
+func (i *testInstrumentation) OnUnpack(m Metrics) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.events = append(i.events, m)
+}
+
+func (i *testInstrumentation) OnError(m Metrics, err error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.events = append(i.events, m)
+	i.errs = append(i.errs, err)
+}
+
+func TestInstrumentation(t *testing.T) {
+	ins := &testInstrumentation{}
+	SetInstrumentation(ins)
+	defer SetInstrumentation(nil)
+
+	iso := NewMessage("0200", &testTransaction{
+		F11: NewNumeric("000123"),
+		F41: NewAlphanumeric("00000321"),
+	})
+	res, err := iso.Bytes()
+	assert.Nil(t, err)
+
+	iso2 := NewMessage("", &testTransaction{F11: &Numeric{}, F41: &Alphanumeric{}})
+	err = iso2.Load(res)
+	assert.Nil(t, err)
+
+	err = iso2.Load(res[:20])
+	assert.EqualError(t, err, "field 41: bad raw data")
+
+	assert.Equal(t, 3, len(ins.events))
+	assert.Equal(t, OpPack, ins.events[0].Op)
+	assert.Equal(t, "0200", ins.events[0].Mti)
+	assert.Equal(t, len(res), ins.events[0].Size)
+	assert.Equal(t, 2, ins.events[0].Fields)
+	assert.Equal(t, OpUnpack, ins.events[1].Op)
+	assert.Equal(t, 2, ins.events[1].Fields)
+	assert.Equal(t, OpUnpack, ins.events[2].Op)
+	assert.Equal(t, 20, ins.events[2].Size)
+	assert.EqualError(t, ins.errs[0], "field 41: bad raw data")
+}
+
+func TestConnInstrumentation(t *testing.T) {
+	ins := &testInstrumentation{}
+	SetInstrumentation(ins)
+	defer SetInstrumentation(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	connects, echoes := 0, 0
+	link := &Link{
+		Connect: func(ctx context.Context) error {
+			connects++
+			return nil
+		},
+		Echo: func(ctx context.Context, msg *Message) error {
+			echoes++
+			if connects == 2 {
+				cancel()
+				return nil
+			}
+			return errors.New("timeout")
+		},
+		IdleTimeout:     time.Millisecond,
+		MinBackoff:      time.Millisecond,
+		MaxEchoFailures: 1,
+	}
+	assert.Equal(t, context.Canceled, link.Run(ctx))
+	assert.Equal(t, []string{"connect", "disconnect", "reconnect", "disconnect"}, ins.conns)
+}
@@ -0,0 +1,125 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// MoovMessage is the subset of methods of *iso8583.Message of
+// github.com/moov-io/iso8583 used by ToMoov and FromMoov, so messages are
+// converted between the libraries without dependency on it. FromMoov also
+// requires GetFields method, which returns map of set fields by their
+// numbers (map[int]field.Field of moov-io). It is called by reflection, as
+// its result type can't be declared here without the dependency.
+type MoovMessage interface {
+	MTI(mti string)
+	GetMTI() (string, error)
+	BinaryField(id int, val []byte) error
+	GetBytes(id int) ([]byte, error)
+}
+
+// ToMoov copies MTI and present fields of message into dst, which spec
+// must define them. Values of text, numeric and binary fields are copied as
+// is, other fields (for ex. composite ones) are not supported. Extra fields
+// are copied too.
+func (m *Message) ToMoov(dst MoovMessage) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("Critical error:" + fmt.Sprint(r))
+		}
+	}()
+
+	fields, err := m.fields()
+	if err != nil {
+		return err
+	}
+	if err := m.addExtraFields(fields); err != nil {
+		return err
+	}
+	indexes := make([]int, 0, len(fields))
+	for i, info := range fields {
+		if !info.Field.IsEmpty() || info.Present {
+			indexes = append(indexes, i)
+		}
+	}
+	sort.Ints(indexes)
+
+	dst.MTI(m.Mti)
+	for _, i := range indexes {
+		info := fields[i]
+		if !isProtoText(info.Field) {
+			return fmt.Errorf("field %d: %s is not supported", i, reflect.TypeOf(info.Field).Elem().Name())
+		}
+		if err := dst.BinaryField(i, []byte(fieldValue(info.Field))); err != nil {
+			return &FieldError{i, err}
+		}
+	}
+	return nil
+}
+
+// FromMoov copies MTI and fields which are set in src and Data defines.
+// Mti is set if it is empty. Set fields with empty value are present, see
+// SetPresent. Fields which are not set in src are left nil, Data must be a
+// pointer to struct.
+func (m *Message) FromMoov(src MoovMessage) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("Critical error:" + fmt.Sprint(r))
+		}
+	}()
+
+	if m.Mti == "" {
+		if m.Mti, err = src.GetMTI(); err != nil {
+			return err
+		}
+	}
+	v := reflect.Indirect(reflect.ValueOf(m.Data))
+	if v.Kind() != reflect.Struct {
+		panic("data must be a struct")
+	}
+	indexes, err := moovFieldIDs(src)
+	if err != nil {
+		return err
+	}
+	paths := typeFieldPaths(v.Type())
+	for _, i := range indexes {
+		if _, ok := paths[i]; !ok {
+			continue
+		}
+		val, err := src.GetBytes(i)
+		if err != nil {
+			return &FieldError{i, err}
+		}
+		if err := m.SetField(i, val); err != nil {
+			return err
+		}
+		if len(val) == 0 {
+			if err := m.SetPresent(i); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// moovFieldIDs returns sorted numbers of fields set in src by its GetFields
+// method
+func moovFieldIDs(src MoovMessage) ([]int, error) {
+	method := reflect.ValueOf(src).MethodByName("GetFields")
+	if !method.IsValid() {
+		return nil, errors.New("moov message has no GetFields method")
+	}
+	tp := method.Type()
+	if tp.NumIn() != 0 || tp.NumOut() != 1 || tp.Out(0).Kind() != reflect.Map || tp.Out(0).Key().Kind() != reflect.Int {
+		return nil, errors.New("GetFields of moov message must return map[int]field.Field")
+	}
+	keys := method.Call(nil)[0].MapKeys()
+	ret := make([]int, 0, len(keys))
+	for _, k := range keys {
+		ret = append(ret, int(k.Int()))
+	}
+	sort.Ints(ret)
+	return ret, nil
+}
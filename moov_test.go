@@ -0,0 +1,100 @@
+package iso8583
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// testMoovMessage mimics *iso8583.Message of moov-io with spec of fields 2-64
+type testMoovMessage struct {
+	mti    string
+	fields map[int][]byte
+}
+
+func (t *testMoovMessage) MTI(mti string) {
+	t.mti = mti
+}
+
+func (t *testMoovMessage) GetMTI() (string, error) {
+	return t.mti, nil
+}
+
+func (t *testMoovMessage) BinaryField(id int, val []byte) error {
+	if id > 64 {
+		return fmt.Errorf("field %d is not defined by the spec", id)
+	}
+	t.fields[id] = val
+	return nil
+}
+
+func (t *testMoovMessage) GetFields() map[int]interface{} {
+	ret := make(map[int]interface{}, len(t.fields))
+	for id, val := range t.fields {
+		ret[id] = val
+	}
+	return ret
+}
+
+func (t *testMoovMessage) GetBytes(id int) ([]byte, error) {
+	if id > 64 {
+		return nil, fmt.Errorf("field %d is not defined by the spec", id)
+	}
+	return t.fields[id], nil
+}
+
+func TestMoov(t *testing.T) {
+	type test struct {
+		F2  *Llnumeric          `field:"2" length:"19"`
+		F4  *Numeric            `field:"4" length:"12"`
+		F41 *Alphanumeric       `field:"41" length:"8"`
+		F48 *testAdditionalData `field:"48" length:"999"`
+		F52 *Binary             `field:"52" length:"8"`
+		F62 *Llvar              `field:"62" length:"99"`
+	}
+
+	data := &test{
+		F2:  NewLlnumeric("4276555555555558"),
+		F4:  NewNumeric("000000001250"),
+		F41: NewAlphanumeric("TERM01"),
+		F52: NewBinary([]byte{0, 1, 0xfe, 0xff}),
+	}
+	moov := &testMoovMessage{fields: make(map[int][]byte)}
+	assert.Nil(t, NewMessage("0100", data).ToMoov(moov))
+	assert.Equal(t, "0100", moov.mti)
+	assert.Equal(t, map[int][]byte{
+		2:  []byte("4276555555555558"),
+		4:  []byte("000000001250"),
+		41: []byte("TERM01"),
+		52: {0, 1, 0xfe, 0xff},
+	}, moov.fields)
+
+	// moov-io drops leading zeros of numeric values, they are padded back
+	moov.fields[4] = []byte("1250")
+	// set field with empty value is present
+	moov.fields[62] = []byte{}
+	msg := NewMessage("", &test{})
+	assert.Nil(t, msg.FromMoov(moov))
+	assert.Equal(t, "0100", msg.Mti)
+	res := msg.Data.(*test)
+	assert.Equal(t, data.F2, res.F2)
+	assert.Equal(t, data.F4, res.F4)
+	assert.Equal(t, "TERM01", res.F41.Value)
+	assert.Equal(t, data.F52.Value, res.F52.Value)
+	assert.Nil(t, res.F48)
+	present, err := msg.IsPresent(62)
+	assert.Nil(t, err)
+	assert.True(t, present)
+
+	data.F48 = &testAdditionalData{S1: NewNumeric("7")}
+	assert.EqualError(t, NewMessage("0100", data).ToMoov(moov), "field 48: composite is not supported")
+
+	type test2 struct {
+		F70 *Numeric `field:"70" length:"3"`
+	}
+	assert.EqualError(t, NewMessage("0800", &test2{NewNumeric("301")}).ToMoov(moov), "field 70: field 70 is not defined by the spec")
+	// fields which are not set in src are not read
+	msg = NewMessage("", &test2{})
+	assert.Nil(t, msg.FromMoov(moov))
+	assert.Nil(t, msg.Data.(*test2).F70)
+}
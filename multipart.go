@@ -0,0 +1,163 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Value of "more data" flag of Collector which means more parts follow
+const MORE_DATA string = "1"
+
+const (
+	ERR_MULTIPART_TOO_LARGE string = "multipart payload is too large"
+	ERR_TOO_MANY_PENDING    string = "too many pending multipart responses"
+)
+
+var (
+	ErrMultipartTooLarge = errors.New(ERR_MULTIPART_TOO_LARGE)
+	ErrTooManyPending    = errors.New(ERR_TOO_MANY_PENDING)
+)
+
+// DefaultCollectorTTL is default TTL of Collector
+const DefaultCollectorTTL = 5 * time.Minute
+
+// Multipart is logical response assembled by Collector
+type Multipart struct {
+	// Parts are messages in order they were added, the last one has no
+	// "more data" flag
+	Parts []*Message
+	// Data is concatenated DataField of the parts
+	Data []byte
+}
+
+// Collector assembles logical response which spans several messages, for
+// ex. file download where each response carries part of the file in
+// DataField and MoreField tells whether more parts follow. Parts belong to
+// the same response if they have the same MTI, STAN (field 11) and terminal
+// (field 41). It is safe for concurrent use.
+type Collector struct {
+	// DataField is field with part of payload
+	DataField int
+	// MoreField is "more data" flag field
+	MoreField int
+	// More is value of MoreField which means more parts follow, MORE_DATA
+	// if empty
+	More string
+	// MaxSize limits size of payload, 0 means no limit
+	MaxSize int
+	// MaxParts limits number of parts of response, 0 means no limit
+	MaxParts int
+	// MaxPending limits number of responses with more parts to come, first
+	// part of another response fails with ErrTooManyPending when it is
+	// reached. 0 means no limit.
+	MaxPending int
+	// TTL drops parts of response when its next part doesn't come in
+	// time, default is DefaultCollectorTTL
+	TTL time.Duration
+
+	mu      sync.Mutex
+	pending map[string]*pendingMultipart
+}
+
+// pendingMultipart is response with more parts to come
+type pendingMultipart struct {
+	*Multipart
+	expires time.Time
+}
+
+// multipartKey returns key of parts of the same response as msg
+func multipartKey(msg *Message) (string, error) {
+	key, err := KeyOf(msg)
+	if err != nil {
+		return "", err
+	}
+	return msg.Mti + "/" + key.Stan + "/" + key.Terminal, nil
+}
+
+// Add adds part msg. It returns the assembled response when msg is the last
+// part, or nil if more parts follow. Parts collected so far are dropped on
+// error.
+func (c *Collector) Add(msg *Message) (*Multipart, error) {
+	key, err := multipartKey(msg)
+	if err != nil {
+		return nil, err
+	}
+	data, err := msg.GetField(c.DataField)
+	if err != nil {
+		return nil, err
+	}
+	more, err := msg.GetString(c.MoreField)
+	if err != nil {
+		return nil, err
+	}
+	flag := c.More
+	if flag == "" {
+		flag = MORE_DATA
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	mp := c.pending[key]
+	if mp == nil || now.After(mp.expires) {
+		c.evict(now)
+		if more == flag && c.MaxPending > 0 && len(c.pending) >= c.MaxPending {
+			return nil, ErrTooManyPending
+		}
+		mp = &pendingMultipart{Multipart: &Multipart{}}
+	}
+	if data != nil {
+		mp.Data = append(mp.Data, fieldValue(data)...)
+	}
+	mp.Parts = append(mp.Parts, msg)
+	if (c.MaxSize > 0 && len(mp.Data) > c.MaxSize) || (c.MaxParts > 0 && len(mp.Parts) > c.MaxParts) {
+		delete(c.pending, key)
+		return nil, fmt.Errorf("%w: %d bytes in %d parts", ErrMultipartTooLarge, len(mp.Data), len(mp.Parts))
+	}
+	if more == flag {
+		if c.pending == nil {
+			c.pending = make(map[string]*pendingMultipart)
+		}
+		ttl := c.TTL
+		if ttl <= 0 {
+			ttl = DefaultCollectorTTL
+		}
+		mp.expires = now.Add(ttl)
+		c.pending[key] = mp
+		return nil, nil
+	}
+	delete(c.pending, key)
+	return mp.Multipart, nil
+}
+
+// evict drops responses which next part didn't come in time. It is called
+// with c.mu held.
+func (c *Collector) evict(now time.Time) {
+	for key, mp := range c.pending {
+		if now.After(mp.expires) {
+			delete(c.pending, key)
+		}
+	}
+}
+
+// Discard drops parts collected for response msg belongs to, for ex. after
+// its request timed out
+func (c *Collector) Discard(msg *Message) {
+	key, err := multipartKey(msg)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.pending, key)
+}
+
+// Pending returns number of responses with more parts to come
+func (c *Collector) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evict(time.Now())
+	return len(c.pending)
+}
@@ -0,0 +1,99 @@
+package iso8583
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type downloadPart struct {
+	F11 *Numeric      `field:"11" length:"6"`
+	F39 *Alphanumeric `field:"39" length:"2"`
+	F48 *Lllvar       `field:"48" length:"999"`
+	F60 *Alphanumeric `field:"60" length:"1"`
+}
+
+func newDownloadPart(stan, data, more string) *Message {
+	return NewMessage("0382", &downloadPart{
+		F11: NewNumeric(stan),
+		F39: NewAlphanumeric("00"),
+		F48: NewLllvar([]byte(data)),
+		F60: NewAlphanumeric(more),
+	})
+}
+
+func TestCollector(t *testing.T) {
+	c := &Collector{DataField: 48, MoreField: 60}
+
+	mp, err := c.Add(newDownloadPart("000001", "abc", MORE_DATA))
+	assert.Nil(t, err)
+	assert.Nil(t, mp)
+	mp, err = c.Add(newDownloadPart("000002", "xyz", "0"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("xyz"), mp.Data)
+	mp, err = c.Add(newDownloadPart("000001", "def", MORE_DATA))
+	assert.Nil(t, err)
+	assert.Nil(t, mp)
+	assert.Equal(t, 1, c.Pending())
+
+	// parsed parts
+	res, err := newDownloadPart("000001", "ghi", "0").Bytes()
+	assert.Nil(t, err)
+	parser := Parser{}
+	parser.Register("0382", &downloadPart{})
+	last, err := parser.Parse(res)
+	assert.Nil(t, err)
+	mp, err = c.Add(last)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("abcdefghi"), mp.Data)
+	assert.Equal(t, 3, len(mp.Parts))
+	assert.Equal(t, last, mp.Parts[2])
+	assert.Equal(t, 0, c.Pending())
+
+	c = &Collector{DataField: 48, MoreField: 60, More: "M", MaxSize: 5}
+	_, err = c.Add(newDownloadPart("000001", "abc", "M"))
+	assert.Nil(t, err)
+	_, err = c.Add(newDownloadPart("000001", "def", "M"))
+	assert.True(t, errors.Is(err, ErrMultipartTooLarge))
+	assert.EqualError(t, err, "multipart payload is too large: 6 bytes in 2 parts")
+	assert.Equal(t, 0, c.Pending())
+
+	_, err = c.Add(newDownloadPart("000001", "abc", "M"))
+	assert.Nil(t, err)
+	c.Discard(newDownloadPart("000001", "", ""))
+	assert.Equal(t, 0, c.Pending())
+
+	_, err = c.Add(newDownloadPart("", "abc", "M"))
+	assert.Equal(t, ErrMissingStan, err)
+}
+
+func TestCollectorLimits(t *testing.T) {
+	c := &Collector{DataField: 48, MoreField: 60, MaxParts: 2, MaxPending: 1}
+	_, err := c.Add(newDownloadPart("000001", "a", MORE_DATA))
+	assert.Nil(t, err)
+	_, err = c.Add(newDownloadPart("000002", "a", MORE_DATA))
+	assert.Equal(t, ErrTooManyPending, err)
+	// single part response is not pending
+	mp, err := c.Add(newDownloadPart("000002", "a", "0"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("a"), mp.Data)
+
+	_, err = c.Add(newDownloadPart("000001", "b", MORE_DATA))
+	assert.Nil(t, err)
+	_, err = c.Add(newDownloadPart("000001", "c", MORE_DATA))
+	assert.EqualError(t, err, "multipart payload is too large: 3 bytes in 3 parts")
+	assert.Equal(t, 0, c.Pending())
+
+	// parts are dropped after TTL
+	c = &Collector{DataField: 48, MoreField: 60, MaxPending: 1, TTL: 20 * time.Millisecond}
+	_, err = c.Add(newDownloadPart("000001", "a", MORE_DATA))
+	assert.Nil(t, err)
+	time.Sleep(40 * time.Millisecond)
+	assert.Equal(t, 0, c.Pending())
+	_, err = c.Add(newDownloadPart("000002", "b", MORE_DATA))
+	assert.Nil(t, err)
+	mp, err = c.Add(newDownloadPart("000002", "c", "0"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("bc"), mp.Data)
+}
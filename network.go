@@ -0,0 +1,102 @@
+package iso8583
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Network management information codes (field 70)
+const (
+	NMI_SIGN_ON    string = "001"
+	NMI_SIGN_OFF   string = "002"
+	NMI_KEY_CHANGE string = "101"
+	NMI_ECHO       string = "301"
+)
+
+// NetworkManagement contains fields of 0800/0810 network management
+// messages
+type NetworkManagement struct {
+	F7  *Numeric      `field:"7" length:"10"`
+	F11 *Numeric      `field:"11" length:"6"`
+	F39 *Alphanumeric `field:"39" length:"2"`
+	F48 *Lllvar       `field:"48" length:"999"`
+	F70 *Numeric      `field:"70" length:"3"`
+}
+
+// NewNetworkManagement creates new 0800 message with network management
+// information code nmi. Transmission date and time (field 7) is t in UTC.
+func NewNetworkManagement(nmi, stan string, t time.Time) *Message {
+	return NewMessage("0800", &NetworkManagement{
+		F7:  NewNumeric(t.UTC().Format("0102150405")),
+		F11: NewNumeric(stan),
+		F70: NewNumeric(nmi),
+	})
+}
+
+// NewSignOn creates new 0800 sign-on message
+func NewSignOn(stan string, t time.Time) *Message {
+	return NewNetworkManagement(NMI_SIGN_ON, stan, t)
+}
+
+// NewSignOff creates new 0800 sign-off message
+func NewSignOff(stan string, t time.Time) *Message {
+	return NewNetworkManagement(NMI_SIGN_OFF, stan, t)
+}
+
+// NewEcho creates new 0800 echo test message
+func NewEcho(stan string, t time.Time) *Message {
+	return NewNetworkManagement(NMI_ECHO, stan, t)
+}
+
+// NewKeyChange creates new 0800 key change message, key data is sent in
+// field 48
+func NewKeyChange(stan string, t time.Time, keyData []byte) *Message {
+	msg := NewNetworkManagement(NMI_KEY_CHANGE, stan, t)
+	msg.Data.(*NetworkManagement).F48 = NewLllvar(keyData)
+	return msg
+}
+
+// NewNetworkManagementResponse creates 0810 response to 0800 request with
+// response code (field 39)
+func NewNetworkManagementResponse(req *Message, code string) (*Message, error) {
+	data, ok := req.Data.(*NetworkManagement)
+	if !ok || data == nil || req.Mti != "0800" {
+		return nil, fmt.Errorf("not a network management request: %s", req.Mti)
+	}
+	resp := req.Clone()
+	resp.Mti = "0810"
+	respData := resp.Data.(*NetworkManagement)
+	respData.F39 = NewAlphanumeric(code)
+	if data.F70 == nil || data.F70.Value != NMI_KEY_CHANGE {
+		respData.F48 = nil
+	}
+	return resp, nil
+}
+
+// RunEcho sends echo test message every interval until ctx is done or send
+// fails. STANs of echo messages are generated by stan, which should be
+// shared with Session of the connection; nil starts new sequence from
+// 000001. It returns error of send or ctx.Err().
+func RunEcho(ctx context.Context, interval time.Duration, stan *StanGenerator, send func(*Message) error) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	if stan == nil {
+		stan = &StanGenerator{}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case t := <-ticker.C:
+			n, err := stan.Generate()
+			if err != nil {
+				return err
+			}
+			if err := send(NewEcho(n, t)); err != nil {
+				return err
+			}
+		}
+	}
+}
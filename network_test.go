@@ -0,0 +1,69 @@
+package iso8583
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNetworkManagement(t *testing.T) {
+	tm := time.Date(2026, 10, 15, 13, 4, 5, 0, time.UTC)
+
+	req := NewSignOn("000001", tm)
+	res, err := req.Bytes()
+	assert.Nil(t, err)
+
+	parser := Parser{}
+	parser.Register("0800", &NetworkManagement{})
+	parsed, err := parser.Parse(res)
+	assert.Nil(t, err)
+	data := parsed.Data.(*NetworkManagement)
+	assert.Equal(t, NMI_SIGN_ON, data.F70.Value)
+	assert.Equal(t, "1015130405", data.F7.Value)
+
+	assert.Equal(t, NMI_SIGN_OFF, NewSignOff("000002", tm).Data.(*NetworkManagement).F70.Value)
+	assert.Equal(t, NMI_ECHO, NewEcho("000003", tm).Data.(*NetworkManagement).F70.Value)
+
+	keyChange := NewKeyChange("000004", tm, []byte("0123456789ABCDEF"))
+	resp, err := NewNetworkManagementResponse(keyChange, "00")
+	assert.Nil(t, err)
+	assert.Equal(t, "0810", resp.Mti)
+	assert.Equal(t, "00", resp.Data.(*NetworkManagement).F39.Value)
+	assert.Equal(t, []byte("0123456789ABCDEF"), resp.Data.(*NetworkManagement).F48.Value)
+	assert.Nil(t, keyChange.Data.(*NetworkManagement).F39)
+
+	_, err = NewNetworkManagementResponse(resp, "00")
+	assert.EqualError(t, err, "not a network management request: 0810")
+}
+
+func TestRunEcho(t *testing.T) {
+	var sent []*Message
+	fail := errors.New("connection closed")
+	err := RunEcho(context.Background(), time.Millisecond, nil, func(msg *Message) error {
+		sent = append(sent, msg)
+		if len(sent) == 3 {
+			return fail
+		}
+		return nil
+	})
+	assert.Equal(t, fail, err)
+	assert.Equal(t, 3, len(sent))
+	assert.Equal(t, "000003", sent[2].Data.(*NetworkManagement).F11.Value)
+
+	// generator shared with Session continues its sequence
+	sent = nil
+	stan, _ := NewStanGenerator("000041")
+	err = RunEcho(context.Background(), time.Millisecond, stan, func(msg *Message) error {
+		sent = append(sent, msg)
+		return fail
+	})
+	assert.Equal(t, fail, err)
+	assert.Equal(t, "000042", sent[0].Data.(*NetworkManagement).F11.Value)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = RunEcho(ctx, time.Hour, nil, func(msg *Message) error { return nil })
+	assert.Equal(t, context.Canceled, err)
+}
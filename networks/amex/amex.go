@@ -0,0 +1,72 @@
+// Package amex contains preset for American Express Global Network
+// Services messages: field dictionary and parser for common message types.
+//
+// AMEX uses ISO 8583:1993 message layout with version 1 MTIs (1100, 1110,
+// 1420, 1804, ...), function code in field 24, action code in field 39 and
+// 12 positions POS data code in field 22. MTI and text fields are in ASCII
+// or EBCDIC by agreement, this preset uses ASCII.
+package amex
+
+import (
+	"github.com/ideazxy/iso8583"
+)
+
+// MtiEncode is encoding of MTI in AMEX messages
+const MtiEncode = iso8583.ASCII
+
+// Fields contains common AMEX fields of authorization, reversal and
+// network management messages
+type Fields struct {
+	F2  *iso8583.Llnumeric    `field:"2" length:"19"`
+	F3  *iso8583.Numeric      `field:"3" length:"6"`
+	F4  *iso8583.Numeric      `field:"4" length:"12"`
+	F7  *iso8583.Numeric      `field:"7" length:"10"`
+	F11 *iso8583.Numeric      `field:"11" length:"6"`
+	F12 *iso8583.Numeric      `field:"12" length:"12"`
+	F13 *iso8583.Numeric      `field:"13" length:"4"`
+	F14 *iso8583.Numeric      `field:"14" length:"4"`
+	F19 *iso8583.Numeric      `field:"19" length:"3"`
+	F22 *iso8583.Alphanumeric `field:"22" length:"12"`
+	F24 *iso8583.Numeric      `field:"24" length:"3"`
+	F25 *iso8583.Numeric      `field:"25" length:"4"`
+	F26 *iso8583.Numeric      `field:"26" length:"4"`
+	F32 *iso8583.Llnumeric    `field:"32" length:"11"`
+	F33 *iso8583.Llnumeric    `field:"33" length:"11"`
+	F35 *iso8583.Llvar        `field:"35" length:"37"`
+	F37 *iso8583.Alphanumeric `field:"37" length:"12"`
+	F38 *iso8583.Alphanumeric `field:"38" length:"6"`
+	F39 *iso8583.Numeric      `field:"39" length:"3"`
+	F41 *iso8583.Alphanumeric `field:"41" length:"8"`
+	F42 *iso8583.Alphanumeric `field:"42" length:"15"`
+	F43 *iso8583.Llvar        `field:"43" length:"99"`
+	F45 *iso8583.Llvar        `field:"45" length:"76"`
+	F49 *iso8583.Currency     `field:"49" length:"3"`
+	F52 *iso8583.Binary       `field:"52" length:"8"`
+	F55 *iso8583.Lllvar       `field:"55" length:"261"`
+	F60 *iso8583.Lllvar       `field:"60" length:"100"`
+	F62 *iso8583.Lllvar       `field:"62" length:"60"`
+	F63 *iso8583.Lllvar       `field:"63" length:"205"`
+}
+
+// MaxLengths are maximum lengths of variable fields of AMEX messages
+var MaxLengths = iso8583.MaxLengthsWith(nil)
+
+// MTIs registered by NewParser
+var MTIs = []string{"1100", "1110", "1120", "1130", "1420", "1430", "1804", "1814"}
+
+// NewParser creates parser for AMEX messages
+func NewParser() *iso8583.Parser {
+	p := &iso8583.Parser{MtiEncode: MtiEncode, MaxLengths: MaxLengths}
+	for _, mti := range MTIs {
+		p.Register(mti, &Fields{})
+	}
+	return p
+}
+
+// NewMessage creates new AMEX message
+func NewMessage(mti string, data *Fields) *iso8583.Message {
+	msg := iso8583.NewMessage(mti, data)
+	msg.MtiEncode = MtiEncode
+	msg.MaxLengths = MaxLengths
+	return msg
+}
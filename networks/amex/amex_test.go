@@ -0,0 +1,26 @@
+package amex
+
+import (
+	"github.com/ideazxy/iso8583"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPackParse(t *testing.T) {
+	msg := NewMessage("1804", &Fields{
+		F7:  iso8583.NewNumeric("1015120000"),
+		F11: iso8583.NewNumeric("000007"),
+		F24: iso8583.NewNumeric("831"),
+		F33: iso8583.NewLlnumeric("12345"),
+	})
+
+	raw, err := msg.Bytes()
+	assert.Nil(t, err)
+
+	parsed, err := NewParser().Parse(raw)
+	assert.Nil(t, err)
+	assert.Equal(t, "1804", parsed.Mti)
+	data := parsed.Data.(*Fields)
+	assert.Equal(t, "831", data.F24.Value)
+	assert.Equal(t, "12345", data.F33.Value)
+}
@@ -0,0 +1,80 @@
+// Package mastercard contains preset for Mastercard CIS (Customer
+// Interface Specification) messages: field dictionary and parser for common
+// message types.
+//
+// CIS messages have no header, MTI and text fields are in EBCDIC or ASCII
+// by agreement, variable length heads are decimal digits in the same
+// charset and bitmap is binary. This preset uses ASCII. Field 48 carries
+// private data subelements (PDS) in TLV format and is exposed as raw
+// Lllvar, which value is parsed by ParsePDS. Structs of IPM messages can
+// use PrivateData type for PDS fields instead.
+package mastercard
+
+import (
+	"github.com/ideazxy/iso8583"
+)
+
+// MtiEncode is encoding of MTI in CIS messages
+const MtiEncode = iso8583.ASCII
+
+// Fields contains common CIS fields of authorization, reversal and
+// network management messages
+type Fields struct {
+	F2   *iso8583.Llnumeric            `field:"2" length:"19"`
+	F3   *iso8583.Numeric              `field:"3" length:"6"`
+	F4   *iso8583.Numeric              `field:"4" length:"12"`
+	F6   *iso8583.Numeric              `field:"6" length:"12"`
+	F7   *iso8583.Numeric              `field:"7" length:"10"`
+	F10  *iso8583.Numeric              `field:"10" length:"8"`
+	F11  *iso8583.Numeric              `field:"11" length:"6"`
+	F12  *iso8583.Numeric              `field:"12" length:"6"`
+	F13  *iso8583.Numeric              `field:"13" length:"4"`
+	F14  *iso8583.Numeric              `field:"14" length:"4"`
+	F15  *iso8583.Numeric              `field:"15" length:"4"`
+	F18  *iso8583.Numeric              `field:"18" length:"4"`
+	F22  *iso8583.Numeric              `field:"22" length:"3"`
+	F23  *iso8583.Numeric              `field:"23" length:"3"`
+	F26  *iso8583.Numeric              `field:"26" length:"2"`
+	F32  *iso8583.Llnumeric            `field:"32" length:"6"`
+	F33  *iso8583.Llnumeric            `field:"33" length:"6"`
+	F35  *iso8583.Llvar                `field:"35" length:"37"`
+	F37  *iso8583.Alphanumeric         `field:"37" length:"12"`
+	F38  *iso8583.Alphanumeric         `field:"38" length:"6"`
+	F39  *iso8583.Alphanumeric         `field:"39" length:"2"`
+	F41  *iso8583.Alphanumeric         `field:"41" length:"8"`
+	F42  *iso8583.Alphanumeric         `field:"42" length:"15"`
+	F43  *iso8583.Alphanumeric         `field:"43" length:"40"`
+	F48  *iso8583.Lllvar               `field:"48" length:"999"`
+	F49  *iso8583.Currency             `field:"49" length:"3"`
+	F51  *iso8583.Currency             `field:"51" length:"3"`
+	F52  *iso8583.Binary               `field:"52" length:"8"`
+	F55  *iso8583.Lllvar               `field:"55" length:"255"`
+	F61  *iso8583.Lllvar               `field:"61" length:"26"`
+	F63  *iso8583.Lllvar               `field:"63" length:"50"`
+	F70  *iso8583.Numeric              `field:"70" length:"3"`
+	F90  *iso8583.OriginalDataElements `field:"90" length:"42"`
+	F127 *iso8583.Lllvar               `field:"127" length:"100"`
+}
+
+// MaxLengths are maximum lengths of variable fields of CIS messages
+var MaxLengths = iso8583.MaxLengthsWith(nil)
+
+// MTIs registered by NewParser
+var MTIs = []string{"0100", "0110", "0120", "0130", "0400", "0410", "0420", "0430", "0800", "0810", "0820"}
+
+// NewParser creates parser for CIS messages
+func NewParser() *iso8583.Parser {
+	p := &iso8583.Parser{MtiEncode: MtiEncode, MaxLengths: MaxLengths}
+	for _, mti := range MTIs {
+		p.Register(mti, &Fields{})
+	}
+	return p
+}
+
+// NewMessage creates new CIS message
+func NewMessage(mti string, data *Fields) *iso8583.Message {
+	msg := iso8583.NewMessage(mti, data)
+	msg.MtiEncode = MtiEncode
+	msg.MaxLengths = MaxLengths
+	return msg
+}
@@ -0,0 +1,32 @@
+package mastercard
+
+import (
+	"github.com/ideazxy/iso8583"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPackParse(t *testing.T) {
+	f49, _ := iso8583.NewCurrency("EUR")
+	msg := NewMessage("0100", &Fields{
+		F2:   iso8583.NewLlnumeric("5413330089020011"),
+		F3:   iso8583.NewNumeric("000000"),
+		F4:   iso8583.NewNumeric("000000002500"),
+		F11:  iso8583.NewNumeric("000042"),
+		F48:  iso8583.NewLllvar([]byte("T4200701V")),
+		F49:  f49,
+		F127: iso8583.NewLllvar([]byte("private")),
+	})
+
+	raw, err := msg.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "0100", string(raw[:4]))
+
+	parsed, err := NewParser().Parse(raw)
+	assert.Nil(t, err)
+	data := parsed.Data.(*Fields)
+	assert.Equal(t, "5413330089020011", data.F2.Value)
+	assert.Equal(t, "978", data.F49.Value)
+	assert.Equal(t, []byte("T4200701V"), data.F48.Value)
+	assert.Equal(t, []byte("private"), data.F127.Value)
+}
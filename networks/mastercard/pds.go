@@ -0,0 +1,130 @@
+package mastercard
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/ideazxy/iso8583"
+)
+
+const (
+	ERR_BAD_PDS     string = "bad PDS data"
+	ERR_BAD_PDS_TAG string = "PDS tag must be in range 1-9999"
+)
+
+// PDS is private data subelement: 4 digit tag, 3 digit length and value
+type PDS struct {
+	Tag   int
+	Value string
+}
+
+// ParsePDS parses PDS list, for ex. value of field 48 of IPM messages
+func ParsePDS(data []byte) ([]PDS, error) {
+	var ret []PDS
+	for pos := 0; pos < len(data); {
+		if len(data)-pos < 7 {
+			return nil, fmt.Errorf("%s at %d", ERR_BAD_PDS, pos)
+		}
+		tag, err := strconv.Atoi(string(data[pos : pos+4]))
+		if err != nil || tag < 1 {
+			return nil, fmt.Errorf("%s at %d", ERR_BAD_PDS, pos)
+		}
+		n, err := strconv.Atoi(string(data[pos+4 : pos+7]))
+		if err != nil || n < 0 || len(data)-pos-7 < n {
+			return nil, fmt.Errorf("%s at %d", ERR_BAD_PDS, pos)
+		}
+		ret = append(ret, PDS{tag, string(data[pos+7 : pos+7+n])})
+		pos += 7 + n
+	}
+	return ret, nil
+}
+
+// PackPDS packs PDS list in order of its items
+func PackPDS(list []PDS) ([]byte, error) {
+	ret := make([]byte, 0, 64)
+	for _, p := range list {
+		if p.Tag < 1 || p.Tag > 9999 {
+			return nil, fmt.Errorf("%s: %d", ERR_BAD_PDS_TAG, p.Tag)
+		}
+		if len(p.Value) > 999 {
+			return nil, &iso8583.ValueTooLongError{Type: fmt.Sprintf("PDS %04d", p.Tag), Length: 999, Actual: len(p.Value)}
+		}
+		ret = append(ret, fmt.Sprintf("%04d%03d", p.Tag, len(p.Value))...)
+		ret = append(ret, p.Value...)
+	}
+	return ret, nil
+}
+
+// PrivateData is Lllvar field which value is PDS list (field 48, 62, 123,
+// 124, 125 of IPM messages). Use it in message struct as
+//
+//	F48 *mastercard.PrivateData `field:"48" length:"999"`
+type PrivateData struct {
+	Subelements []PDS
+}
+
+// GetPDS returns value of subelement tag, or false if there is no such
+// subelement
+func (p *PrivateData) GetPDS(tag int) (string, bool) {
+	for _, s := range p.Subelements {
+		if s.Tag == tag {
+			return s.Value, true
+		}
+	}
+	return "", false
+}
+
+// SetPDS sets value of subelement tag, keeping subelements sorted by tag
+func (p *PrivateData) SetPDS(tag int, value string) error {
+	if tag < 1 || tag > 9999 {
+		return errors.New(ERR_BAD_PDS_TAG)
+	}
+	i := sort.Search(len(p.Subelements), func(i int) bool { return p.Subelements[i].Tag >= tag })
+	if i < len(p.Subelements) && p.Subelements[i].Tag == tag {
+		p.Subelements[i].Value = value
+		return nil
+	}
+	p.Subelements = append(p.Subelements, PDS{})
+	copy(p.Subelements[i+1:], p.Subelements[i:])
+	p.Subelements[i] = PDS{tag, value}
+	return nil
+}
+
+// DeletePDS removes subelement tag
+func (p *PrivateData) DeletePDS(tag int) {
+	for i, s := range p.Subelements {
+		if s.Tag == tag {
+			p.Subelements = append(p.Subelements[:i], p.Subelements[i+1:]...)
+			return
+		}
+	}
+}
+
+// IsEmpty check PrivateData field for empty value
+func (p *PrivateData) IsEmpty() bool {
+	return len(p.Subelements) == 0
+}
+
+// Bytes encode PrivateData field to bytes
+func (p *PrivateData) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
+	body, err := PackPDS(p.Subelements)
+	if err != nil {
+		return nil, err
+	}
+	return iso8583.NewLllvar(body).Bytes(encoder, lenEncoder, length)
+}
+
+// Load decode PrivateData field from bytes
+func (p *PrivateData) Load(raw []byte, encoder, lenEncoder, length int) (int, error) {
+	v := &iso8583.Lllvar{}
+	read, err := v.Load(raw, encoder, lenEncoder, length)
+	if err != nil {
+		return 0, err
+	}
+	if p.Subelements, err = ParsePDS(v.Value); err != nil {
+		return 0, err
+	}
+	return read, nil
+}
@@ -0,0 +1,48 @@
+package mastercard
+
+import (
+	"github.com/ideazxy/iso8583"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPrivateData(t *testing.T) {
+	type ipm struct {
+		F24 *iso8583.Numeric `field:"24" length:"3"`
+		F48 *PrivateData     `field:"48" length:"999"`
+	}
+	pds := &PrivateData{}
+	assert.Nil(t, pds.SetPDS(165, "M"))
+	assert.Nil(t, pds.SetPDS(23, "POI"))
+	assert.Nil(t, pds.SetPDS(158, "MCC"))
+	assert.Nil(t, pds.SetPDS(23, "CT6"))
+	assert.NotNil(t, pds.SetPDS(10000, "x"))
+
+	msg := iso8583.NewMessage("1240", &ipm{F24: iso8583.NewNumeric("200"), F48: pds})
+	raw, err := msg.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "028"+"0023003CT6"+"0158003MCC"+"0165001M", string(raw[len(raw)-31:]))
+
+	p := &iso8583.Parser{}
+	p.Register("1240", &ipm{})
+	parsed, err := p.Parse(raw)
+	assert.Nil(t, err)
+	loaded := parsed.Data.(*ipm).F48
+	assert.Equal(t, pds.Subelements, loaded.Subelements)
+	v, ok := loaded.GetPDS(165)
+	assert.True(t, ok)
+	assert.Equal(t, "M", v)
+	loaded.DeletePDS(158)
+	_, ok = loaded.GetPDS(158)
+	assert.False(t, ok)
+
+	list, err := ParsePDS([]byte("0023003CT6"))
+	assert.Nil(t, err)
+	assert.Equal(t, []PDS{{23, "CT6"}}, list)
+	_, err = ParsePDS([]byte("0023003CT60158"))
+	assert.EqualError(t, err, "bad PDS data at 10")
+	_, err = ParsePDS([]byte("0023009CT6"))
+	assert.EqualError(t, err, "bad PDS data at 0")
+	_, err = PackPDS([]PDS{{0, "x"}})
+	assert.EqualError(t, err, "PDS tag must be in range 1-9999: 0")
+}
@@ -0,0 +1,229 @@
+// Package postilion contains preset for Postilion realtime private data
+// (field 127): field with its own bitmap and subfields 127.2-127.39.
+//
+// Field 127 is packed as 6 digit ascii length, 8 bytes bitmap and present
+// subfields. Use it in message struct as
+//
+//	F127 *postilion.PrivateData `field:"127"`
+package postilion
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ideazxy/iso8583"
+)
+
+// MaxLen is maximum length of field 127 data (without length head)
+const MaxLen = 999999
+
+const (
+	ERR_BAD_STRUCTURED_DATA string = "bad structured data"
+)
+
+// PrivateData contains subfields of field 127
+type PrivateData struct {
+	SwitchKey                 *iso8583.Llvar        `field:"2" length:"32"`
+	RoutingInformation        *iso8583.Alphanumeric `field:"3" length:"48"`
+	PosData                   *iso8583.Alphanumeric `field:"4" length:"22"`
+	ServiceStationData        *iso8583.Alphanumeric `field:"5" length:"73"`
+	AuthorizationProfile      *iso8583.Numeric      `field:"6" length:"2"`
+	CheckData                 *iso8583.Llvar        `field:"7" length:"50"`
+	RetentionData             *iso8583.Lllvar       `field:"8" length:"999"`
+	AdditionalNodeData        *iso8583.Lllvar       `field:"9" length:"255"`
+	Cvv2                      *iso8583.Numeric      `field:"10" length:"3"`
+	OriginalKey               *iso8583.Llvar        `field:"11" length:"32"`
+	TerminalOwner             *iso8583.Llvar        `field:"12" length:"25"`
+	PosGeographicData         *iso8583.Alphanumeric `field:"13" length:"17"`
+	SponsorBank               *iso8583.Alphanumeric `field:"14" length:"8"`
+	AddressVerificationData   *iso8583.Llvar        `field:"15" length:"29"`
+	AddressVerificationResult *iso8583.Alphanumeric `field:"16" length:"1"`
+	CardholderInformation     *iso8583.Llvar        `field:"17" length:"50"`
+	ValidationData            *iso8583.Llvar        `field:"18" length:"50"`
+	BankDetails               *iso8583.Alphanumeric `field:"19" length:"31"`
+	AuthorizerDateSettlement  *iso8583.Numeric      `field:"20" length:"8"`
+	RecordIdentification      *iso8583.Llvar        `field:"21" length:"12"`
+	StructuredData            *Lllllvar             `field:"22" length:"99999"`
+	PayeeNameAndAddress       *iso8583.Alphanumeric `field:"23" length:"253"`
+	PayerAccount              *iso8583.Llvar        `field:"24" length:"28"`
+	IccData                   *Llllvar              `field:"25" length:"8000"`
+	OriginalNode              *iso8583.Llvar        `field:"26" length:"20"`
+	CardVerificationResult    *iso8583.Alphanumeric `field:"27" length:"1"`
+	AmexCardIdentifier        *iso8583.Numeric      `field:"28" length:"4"`
+	SecureData3D              *iso8583.Binary       `field:"29" length:"40"`
+	SecureResult3D            *iso8583.Alphanumeric `field:"30" length:"1"`
+	IssuerNetworkID           *iso8583.Llvar        `field:"31" length:"11"`
+	UcafData                  *iso8583.Llvar        `field:"32" length:"33"`
+	ExtendedTransactionType   *iso8583.Numeric      `field:"33" length:"4"`
+	AccountTypeQualifiers     *iso8583.Numeric      `field:"34" length:"2"`
+	AcquirerNetworkID         *iso8583.Llvar        `field:"35" length:"11"`
+	CustomerID                *iso8583.Llvar        `field:"36" length:"25"`
+	ExtendedResponseCode      *iso8583.Alphanumeric `field:"37" length:"4"`
+	AdditionalPosDataCode     *iso8583.Llvar        `field:"38" length:"99"`
+	OriginalResponseCode      *iso8583.Alphanumeric `field:"39" length:"2"`
+}
+
+// IsEmpty check PrivateData field for empty value
+func (p *PrivateData) IsEmpty() bool {
+	v := reflect.ValueOf(p).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		if !f.IsNil() && !f.Interface().(iso8583.Iso8583Type).IsEmpty() {
+			return false
+		}
+	}
+	return true
+}
+
+// Bytes encode PrivateData field to bytes
+func (p *PrivateData) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
+	body, err := iso8583.PackBitmapped(p)
+	if err != nil {
+		return nil, err
+	}
+	return packVar(body, 6, MaxLen)
+}
+
+// Load decode PrivateData field from bytes
+func (p *PrivateData) Load(raw []byte, encoder, lenEncoder, length int) (int, error) {
+	body, read, err := loadVar(raw, 6)
+	if err != nil {
+		return 0, err
+	}
+	if err := iso8583.LoadBitmapped(body, p); err != nil {
+		return 0, err
+	}
+	return read, nil
+}
+
+// Llllvar contains bytes in non-fixed length field with 4 digit ascii
+// length head (field 127.25)
+type Llllvar struct {
+	Value []byte
+}
+
+// IsEmpty check Llllvar field for empty value
+func (l *Llllvar) IsEmpty() bool {
+	return len(l.Value) == 0
+}
+
+// Bytes encode Llllvar field to bytes
+func (l *Llllvar) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
+	return packVar(l.Value, 4, length)
+}
+
+// Load decode Llllvar field from bytes
+func (l *Llllvar) Load(raw []byte, encoder, lenEncoder, length int) (read int, err error) {
+	l.Value, read, err = loadVar(raw, 4)
+	return read, err
+}
+
+// Lllllvar contains bytes in non-fixed length field with 5 digit ascii
+// length head (field 127.22)
+type Lllllvar struct {
+	Value []byte
+}
+
+// IsEmpty check Lllllvar field for empty value
+func (l *Lllllvar) IsEmpty() bool {
+	return len(l.Value) == 0
+}
+
+// Bytes encode Lllllvar field to bytes
+func (l *Lllllvar) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
+	return packVar(l.Value, 5, length)
+}
+
+// Load decode Lllllvar field from bytes
+func (l *Lllllvar) Load(raw []byte, encoder, lenEncoder, length int) (read int, err error) {
+	l.Value, read, err = loadVar(raw, 5)
+	return read, err
+}
+
+func packVar(value []byte, digits, length int) ([]byte, error) {
+	if length > 0 && len(value) > length {
+		return nil, &iso8583.ValueTooLongError{Type: fmt.Sprintf("L%dvar", digits), Length: length, Actual: len(value)}
+	}
+	head := fmt.Sprintf("%0*d", digits, len(value))
+	if len(head) > digits {
+		return nil, iso8583.ErrInvalidLengthHead
+	}
+	return append([]byte(head), value...), nil
+}
+
+func loadVar(raw []byte, digits int) ([]byte, int, error) {
+	if len(raw) < digits {
+		return nil, 0, iso8583.ErrBadRaw
+	}
+	l, err := strconv.Atoi(string(raw[:digits]))
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %s", iso8583.ErrParseLengthFailed, raw[:digits])
+	}
+	if len(raw) < digits+l {
+		return nil, 0, iso8583.ErrBadRaw
+	}
+	return append([]byte(nil), raw[digits:digits+l]...), digits + l, nil
+}
+
+// ParseStructuredData parses field 127.22 data: sequence of key and value
+// pairs, each of them prefixed with length of length digit and length (for
+// ex. "14Name15Value").
+func ParseStructuredData(data []byte) (map[string]string, error) {
+	ret := make(map[string]string)
+	s := string(data)
+	for len(s) > 0 {
+		key, rest, err := nextStructuredItem(s)
+		if err != nil {
+			return nil, err
+		}
+		value, rest, err := nextStructuredItem(rest)
+		if err != nil {
+			return nil, err
+		}
+		ret[key] = value
+		s = rest
+	}
+	return ret, nil
+}
+
+func nextStructuredItem(s string) (string, string, error) {
+	if len(s) < 1 || s[0] < '1' || s[0] > '9' {
+		return "", "", errors.New(ERR_BAD_STRUCTURED_DATA)
+	}
+	n := int(s[0] - '0')
+	if len(s) < 1+n {
+		return "", "", errors.New(ERR_BAD_STRUCTURED_DATA)
+	}
+	l, err := strconv.Atoi(s[1 : 1+n])
+	if err != nil || len(s) < 1+n+l {
+		return "", "", errors.New(ERR_BAD_STRUCTURED_DATA)
+	}
+	return s[1+n : 1+n+l], s[1+n+l:], nil
+}
+
+// FormatStructuredData formats field 127.22 data, keys are sorted
+func FormatStructuredData(data map[string]string) []byte {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		writeStructuredItem(&b, k)
+		writeStructuredItem(&b, data[k])
+	}
+	return []byte(b.String())
+}
+
+func writeStructuredItem(b *strings.Builder, s string) {
+	l := strconv.Itoa(len(s))
+	b.WriteString(strconv.Itoa(len(l)))
+	b.WriteString(l)
+	b.WriteString(s)
+}
@@ -0,0 +1,47 @@
+package postilion
+
+import (
+	"github.com/ideazxy/iso8583"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type testMessage struct {
+	F11  *iso8583.Numeric `field:"11" length:"6"`
+	F127 *PrivateData     `field:"127"`
+}
+
+func TestPrivateData(t *testing.T) {
+	data := &testMessage{
+		F11: iso8583.NewNumeric("000001"),
+		F127: &PrivateData{
+			SwitchKey:      iso8583.NewLlvar([]byte("KEY1")),
+			Cvv2:           iso8583.NewNumeric("123"),
+			StructuredData: &Lllllvar{FormatStructuredData(map[string]string{"Name": "Value"})},
+		},
+	}
+	msg := iso8583.NewMessage("0200", data)
+	res, err := msg.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "000035"+"\x40\x40\x04\x00\x00\x00\x00\x00"+"04KEY1"+"123"+"00013"+"14Name15Value", string(res[4+16+6:]))
+
+	p := &iso8583.Parser{}
+	p.Register("0200", &testMessage{})
+	parsed, err := p.Parse(res)
+	assert.Nil(t, err)
+	pd := parsed.Data.(*testMessage).F127
+	assert.Equal(t, "KEY1", string(pd.SwitchKey.Value))
+	assert.Equal(t, "123", pd.Cvv2.Value)
+	assert.True(t, pd.IccData.IsEmpty())
+	sd, err := ParseStructuredData(pd.StructuredData.Value)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"Name": "Value"}, sd)
+
+	empty := &testMessage{F11: iso8583.NewNumeric("000001"), F127: &PrivateData{}}
+	res, err = iso8583.NewMessage("0200", empty).Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, 4+8+6, len(res))
+
+	_, err = ParseStructuredData([]byte("14Name1"))
+	assert.EqualError(t, err, ERR_BAD_STRUCTURED_DATA)
+}
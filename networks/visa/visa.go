@@ -0,0 +1,302 @@
+// Package visa contains preset for Visa BASE I messages: field dictionary,
+// message header and parser for common message types.
+//
+// BASE I sends numeric fields and MTI in BCD, bitmap in binary, text
+// fields in EBCDIC and length of variable length fields in one binary byte
+// (number of digits of numeric fields, of bytes of others). The preset
+// doesn't include field 35 (track 2 data in z format). Messages rejected
+// by BASE I come back with 26-byte reject header carrying reject code,
+// followed by the original header.
+package visa
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/ideazxy/iso8583"
+)
+
+// MtiEncode is encoding of MTI in BASE I messages
+const MtiEncode = iso8583.BCD
+
+// HeaderLen is length of BASE I message header
+const HeaderLen = 22
+
+// RejectHeaderLen is length of header of message rejected by BASE I. It is
+// followed by header of the original message.
+const RejectHeaderLen = 26
+
+const (
+	ERR_BAD_HEADER        string = "bad BASE I header"
+	ERR_BAD_STATION_ID    string = "station ID must contain 6 digits"
+	ERR_MESSAGE_TOO_LONG  string = "message is too long for BASE I header"
+	ERR_BAD_HEADER_LENGTH string = "bad BASE I header length"
+	ERR_BAD_REJECT_CODE   string = "reject code must contain 4 digits"
+)
+
+// Fields contains common BASE I fields of authorization, reversal and
+// network management messages
+type Fields struct {
+	F2  *iso8583.Llnumeric            `field:"2" length:"19" encode:"binary,bcd"`
+	F3  *iso8583.Numeric              `field:"3" length:"6" encode:"bcd"`
+	F4  *iso8583.Numeric              `field:"4" length:"12" encode:"bcd"`
+	F7  *iso8583.Numeric              `field:"7" length:"10" encode:"bcd"`
+	F11 *iso8583.Numeric              `field:"11" length:"6" encode:"bcd"`
+	F12 *iso8583.Numeric              `field:"12" length:"6" encode:"bcd"`
+	F13 *iso8583.Numeric              `field:"13" length:"4" encode:"bcd"`
+	F14 *iso8583.Numeric              `field:"14" length:"4" encode:"bcd"`
+	F18 *iso8583.Numeric              `field:"18" length:"4" encode:"bcd"`
+	F19 *iso8583.Numeric              `field:"19" length:"3" encode:"rbcd"`
+	F22 *iso8583.Numeric              `field:"22" length:"4" encode:"bcd"`
+	F25 *iso8583.Numeric              `field:"25" length:"2" encode:"bcd"`
+	F32 *iso8583.Llnumeric            `field:"32" length:"11" encode:"binary,bcd"`
+	F37 *iso8583.Alphanumeric         `field:"37" length:"12" encode:"ebcdic"`
+	F38 *iso8583.Alphanumeric         `field:"38" length:"6" encode:"ebcdic"`
+	F39 *iso8583.Alphanumeric         `field:"39" length:"2" encode:"ebcdic"`
+	F41 *iso8583.Alphanumeric         `field:"41" length:"8" encode:"ebcdic"`
+	F42 *iso8583.Alphanumeric         `field:"42" length:"15" encode:"ebcdic"`
+	F43 *iso8583.Alphanumeric         `field:"43" length:"40" encode:"ebcdic"`
+	F44 *AdditionalResponseData       `field:"44" length:"25" encode:"binary,ascii" composite:"llvar"`
+	F49 *iso8583.Currency             `field:"49" length:"3" encode:"rbcd"`
+	F52 *iso8583.Binary               `field:"52" length:"8"`
+	F54 *iso8583.LlvarText            `field:"54" length:"120" encode:"binary,ebcdic"`
+	F55 *iso8583.Llvar                `field:"55" length:"255" encode:"binary,ascii"`
+	F62 *CustomPaymentService         `field:"62" length:"255" encode:"binary,ascii" composite:"llvar,bitmap8"`
+	F63 *PrivateUse                   `field:"63" length:"255" encode:"binary,ascii" composite:"llvar,bitmap3"`
+	F70 *iso8583.Numeric              `field:"70" length:"3" encode:"rbcd"`
+	F90 *iso8583.OriginalDataElements `field:"90" length:"42" encode:"bcd"`
+}
+
+// AdditionalResponseData contains positional subfields of field 44 of
+// responses. Host sends only leading subfields it sets, the rest of them
+// are empty after loading.
+type AdditionalResponseData struct {
+	ResponseSource       *iso8583.Alphanumeric `field:"1" length:"1" encode:"ebcdic"`  // 44.1
+	AddressVerification  *iso8583.Alphanumeric `field:"2" length:"1" encode:"ebcdic"`  // 44.2, AVS result
+	Reserved3            *iso8583.Alphanumeric `field:"3" length:"1" encode:"ebcdic"`  // 44.3
+	CardProductType      *iso8583.Alphanumeric `field:"4" length:"1" encode:"ebcdic"`  // 44.4
+	CvvResult            *iso8583.Alphanumeric `field:"5" length:"1" encode:"ebcdic"`  // 44.5, CVV/iCVV result
+	PacmDiversionLevel   *iso8583.Alphanumeric `field:"6" length:"2" encode:"ebcdic"`  // 44.6
+	PacmDiversionReason  *iso8583.Alphanumeric `field:"7" length:"1" encode:"ebcdic"`  // 44.7
+	CardAuthentication   *iso8583.Alphanumeric `field:"8" length:"1" encode:"ebcdic"`  // 44.8
+	Reserved9            *iso8583.Alphanumeric `field:"9" length:"1" encode:"ebcdic"`  // 44.9
+	Cvv2Result           *iso8583.Alphanumeric `field:"10" length:"1" encode:"ebcdic"` // 44.10
+	OriginalResponseCode *iso8583.Alphanumeric `field:"11" length:"2" encode:"ebcdic"` // 44.11
+	CheckSettlementCode  *iso8583.Alphanumeric `field:"12" length:"1" encode:"ebcdic"` // 44.12
+	CavvResult           *iso8583.Alphanumeric `field:"13" length:"1" encode:"ebcdic"` // 44.13
+	ResponseReasonCode   *iso8583.Alphanumeric `field:"14" length:"4" encode:"ebcdic"` // 44.14
+}
+
+// CustomPaymentService contains subfields of field 62 (Custom Payment
+// Service Fields), which are packed after 8 byte bitmap of present ones
+type CustomPaymentService struct {
+	AuthorizationCharacteristics *iso8583.Alphanumeric `field:"1" length:"1" encode:"ebcdic"`  // 62.1, ACI
+	TransactionID                *iso8583.Numeric      `field:"2" length:"15" encode:"rbcd"`   // 62.2
+	ValidationCode               *iso8583.Alphanumeric `field:"3" length:"4" encode:"ebcdic"`  // 62.3
+	MarketSpecificData           *iso8583.Alphanumeric `field:"4" length:"1" encode:"ebcdic"`  // 62.4
+	Duration                     *iso8583.Numeric      `field:"5" length:"2" encode:"bcd"`     // 62.5
+	PrestigiousProperty          *iso8583.Alphanumeric `field:"6" length:"1" encode:"ebcdic"`  // 62.6
+	PurchaseIdentifier           *iso8583.Alphanumeric `field:"7" length:"26" encode:"ebcdic"` // 62.7
+	MerchantVerificationValue    *iso8583.Numeric      `field:"20" length:"10" encode:"bcd"`   // 62.20
+	ProductID                    *iso8583.Alphanumeric `field:"23" length:"2" encode:"ebcdic"` // 62.23
+}
+
+// PrivateUse contains subfields of field 63 (SMS Private-Use Fields), which
+// are packed after 3 byte bitmap of present ones
+type PrivateUse struct {
+	NetworkID           *iso8583.Numeric      `field:"1" length:"4" encode:"bcd"`     // 63.1
+	TimeLimit           *iso8583.Numeric      `field:"2" length:"4" encode:"bcd"`     // 63.2, preauthorization time limit
+	MessageReasonCode   *iso8583.Numeric      `field:"3" length:"4" encode:"bcd"`     // 63.3
+	StipReasonCode      *iso8583.Numeric      `field:"4" length:"4" encode:"bcd"`     // 63.4, STIP/switch reason code
+	FeeProgramIndicator *iso8583.Alphanumeric `field:"19" length:"3" encode:"ebcdic"` // 63.19
+}
+
+// MaxLengths are maximum lengths of variable fields of BASE I messages
+var MaxLengths = iso8583.MaxLengthsWith(nil)
+
+// MTIs registered by NewParser
+var MTIs = []string{"0100", "0110", "0120", "0130", "0400", "0410", "0420", "0430", "0800", "0810"}
+
+// NewParser creates parser for BASE I messages without header
+func NewParser() *iso8583.Parser {
+	p := &iso8583.Parser{MtiEncode: MtiEncode, MaxLengths: MaxLengths}
+	for _, mti := range MTIs {
+		p.Register(mti, &Fields{})
+	}
+	return p
+}
+
+// NewMessage creates new BASE I message
+func NewMessage(mti string, data *Fields) *iso8583.Message {
+	msg := iso8583.NewMessage(mti, data)
+	msg.MtiEncode = MtiEncode
+	msg.MaxLengths = MaxLengths
+	return msg
+}
+
+// Header is BASE I message header
+type Header struct {
+	TextFormat    byte   // H3, 0x02 for BASE I
+	MessageLength int    // H4, total length of header and message, set by Bytes
+	DestinationID string // H5, 6 digits
+	SourceID      string // H6, 6 digits
+	RoundTripInfo byte   // H7
+	BaseIFlags    uint16 // H8
+	StatusFlags   [3]byte
+	BatchNumber   byte
+	Reserved      [3]byte
+	UserInfo      byte
+	// RejectBitmap (H13) and RejectCode (H14, 4 digits) are set in header
+	// of rejected message only
+	RejectBitmap uint16
+	RejectCode   string
+	// Original is header of rejected message, which follows reject header
+	Original *Header
+}
+
+// Rejected checks if h is header of message rejected by BASE I
+func (h *Header) Rejected() bool {
+	return h.RejectCode != ""
+}
+
+// len returns length of encoded header
+func (h *Header) len() int {
+	if h.Rejected() {
+		return RejectHeaderLen
+	}
+	return HeaderLen
+}
+
+// NewHeader creates new BASE I header
+func NewHeader(destinationID, sourceID string) *Header {
+	return &Header{TextFormat: 0x02, DestinationID: destinationID, SourceID: sourceID}
+}
+
+// Bytes encode header for message of msgLen bytes. For reject header,
+// msgLen includes header of the original message.
+func (h *Header) Bytes(msgLen int) ([]byte, error) {
+	if len(h.DestinationID) != 6 || len(h.SourceID) != 6 {
+		return nil, errors.New(ERR_BAD_STATION_ID)
+	}
+	headerLen := h.len()
+	total := headerLen + msgLen
+	if total > 0xffff {
+		return nil, errors.New(ERR_MESSAGE_TOO_LONG)
+	}
+	h.MessageLength = total
+
+	dst, err := iso8583.NewNumeric(h.DestinationID).Bytes(iso8583.BCD, iso8583.ASCII, 6)
+	if err != nil {
+		return nil, errors.New(ERR_BAD_STATION_ID)
+	}
+	src, err := iso8583.NewNumeric(h.SourceID).Bytes(iso8583.BCD, iso8583.ASCII, 6)
+	if err != nil {
+		return nil, errors.New(ERR_BAD_STATION_ID)
+	}
+
+	ret := make([]byte, 0, headerLen)
+	ret = append(ret, byte(headerLen), 0x01, h.TextFormat, byte(total>>8), byte(total))
+	ret = append(ret, dst...)
+	ret = append(ret, src...)
+	ret = append(ret, h.RoundTripInfo, byte(h.BaseIFlags>>8), byte(h.BaseIFlags))
+	ret = append(ret, h.StatusFlags[:]...)
+	ret = append(ret, h.BatchNumber)
+	ret = append(ret, h.Reserved[:]...)
+	ret = append(ret, h.UserInfo)
+	if h.Rejected() {
+		code, err := iso8583.NewNumeric(h.RejectCode).Bytes(iso8583.BCD, iso8583.ASCII, 4)
+		if err != nil || len(h.RejectCode) != 4 {
+			return nil, errors.New(ERR_BAD_REJECT_CODE)
+		}
+		ret = append(ret, byte(h.RejectBitmap>>8), byte(h.RejectBitmap))
+		ret = append(ret, code...)
+	}
+	return ret, nil
+}
+
+// Load decode header from bytes
+func (h *Header) Load(raw []byte) (int, error) {
+	if len(raw) < HeaderLen {
+		return 0, errors.New(ERR_BAD_HEADER)
+	}
+	headerLen := int(raw[0])
+	if headerLen != HeaderLen && headerLen != RejectHeaderLen {
+		return 0, errors.New(ERR_BAD_HEADER_LENGTH)
+	}
+	if len(raw) < headerLen {
+		return 0, errors.New(ERR_BAD_HEADER)
+	}
+	dst := &iso8583.Numeric{}
+	if _, err := dst.Load(raw[5:8], iso8583.BCD, iso8583.ASCII, 6); err != nil {
+		return 0, err
+	}
+	src := &iso8583.Numeric{}
+	if _, err := src.Load(raw[8:11], iso8583.BCD, iso8583.ASCII, 6); err != nil {
+		return 0, err
+	}
+	h.TextFormat = raw[2]
+	h.MessageLength = int(binary.BigEndian.Uint16(raw[3:5]))
+	h.DestinationID = dst.Value
+	h.SourceID = src.Value
+	h.RoundTripInfo = raw[11]
+	h.BaseIFlags = binary.BigEndian.Uint16(raw[12:14])
+	copy(h.StatusFlags[:], raw[14:17])
+	h.BatchNumber = raw[17]
+	copy(h.Reserved[:], raw[18:21])
+	h.UserInfo = raw[21]
+	h.RejectBitmap = 0
+	h.RejectCode = ""
+	if headerLen == RejectHeaderLen {
+		code := &iso8583.Numeric{}
+		if _, err := code.Load(raw[24:26], iso8583.BCD, iso8583.ASCII, 4); err != nil {
+			return 0, err
+		}
+		h.RejectBitmap = binary.BigEndian.Uint16(raw[22:24])
+		h.RejectCode = code.Value
+	}
+	return headerLen, nil
+}
+
+// Pack marshalls message with header. Header of the original message is
+// packed after reject header.
+func Pack(h *Header, msg *iso8583.Message) ([]byte, error) {
+	body, err := msg.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	if h.Rejected() && h.Original != nil {
+		orig, err := h.Original.Bytes(len(body))
+		if err != nil {
+			return nil, err
+		}
+		body = append(orig, body...)
+	}
+	head, err := h.Bytes(len(body))
+	if err != nil {
+		return nil, err
+	}
+	return append(head, body...), nil
+}
+
+// Unpack parses message with header. For message rejected by BASE I, it
+// returns reject header with Original header of the message.
+func Unpack(p *iso8583.Parser, raw []byte) (*Header, *iso8583.Message, error) {
+	h := &Header{}
+	n, err := h.Load(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	if h.Rejected() {
+		h.Original = &Header{}
+		m, err := h.Original.Load(raw[n:])
+		if err != nil {
+			return nil, nil, err
+		}
+		n += m
+	}
+	msg, err := p.Parse(raw[n:])
+	if err != nil {
+		return nil, nil, err
+	}
+	return h, msg, nil
+}
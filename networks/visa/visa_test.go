@@ -0,0 +1,145 @@
+package visa
+
+import (
+	"github.com/ideazxy/iso8583"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPackUnpack(t *testing.T) {
+	f49, _ := iso8583.NewCurrency("USD")
+	msg := NewMessage("0100", &Fields{
+		F2:  iso8583.NewLlnumeric("4761739001010010"),
+		F3:  iso8583.NewNumeric("000000"),
+		F4:  iso8583.NewNumeric("000000001000"),
+		F11: iso8583.NewNumeric("000001"),
+		F41: iso8583.NewAlphanumeric("TERM0001"),
+		F49: f49,
+	})
+
+	raw, err := Pack(NewHeader("000000", "123456"), msg)
+	assert.Nil(t, err)
+	assert.Equal(t, byte(HeaderLen), raw[0])
+	assert.Equal(t, []byte{0x01, 0x00}, raw[HeaderLen:HeaderLen+2])
+	// BASE I Technical Specifications, Vol. 1, field format conventions:
+	// length of variable length numeric field is one binary byte counting
+	// digits, text is EBCDIC
+	f2 := HeaderLen + 2 + 8
+	assert.Equal(t, []byte{0x10, 0x47, 0x61, 0x73, 0x90, 0x01, 0x01, 0x00, 0x10}, raw[f2:f2+9])
+	assert.Contains(t, string(raw), "\xe3\xc5\xd9\xd4\xf0\xf0\xf0\xf1") // TERM0001
+
+	h, parsed, err := Unpack(NewParser(), raw)
+	assert.Nil(t, err)
+	assert.Equal(t, len(raw), h.MessageLength)
+	assert.Equal(t, "123456", h.SourceID)
+	assert.Equal(t, "0100", parsed.Mti)
+	data := parsed.Data.(*Fields)
+	assert.Equal(t, "4761739001010010", data.F2.Value)
+	assert.Equal(t, "840", data.F49.Value)
+	assert.Equal(t, "TERM0001", data.F41.Value)
+
+	_, err = Pack(NewHeader("0", "123456"), msg)
+	assert.EqualError(t, err, "station ID must contain 6 digits")
+
+	_, _, err = Unpack(NewParser(), raw[:10])
+	assert.EqualError(t, err, "bad BASE I header")
+}
+
+func TestReject(t *testing.T) {
+	msg := NewMessage("0100", &Fields{
+		F3:  iso8583.NewNumeric("000000"),
+		F11: iso8583.NewNumeric("000001"),
+	})
+	h := NewHeader("123456", "000000")
+	h.RejectBitmap = 0x8000
+	h.RejectCode = "0012"
+	h.Original = NewHeader("000000", "123456")
+	raw, err := Pack(h, msg)
+	assert.Nil(t, err)
+	assert.Equal(t, byte(RejectHeaderLen), raw[0])
+	assert.Equal(t, []byte{0x80, 0x00, 0x00, 0x12}, raw[22:26])
+	assert.Equal(t, byte(HeaderLen), raw[RejectHeaderLen])
+
+	rejected, parsed, err := Unpack(NewParser(), raw)
+	assert.Nil(t, err)
+	assert.True(t, rejected.Rejected())
+	assert.Equal(t, "0012", rejected.RejectCode)
+	assert.Equal(t, uint16(0x8000), rejected.RejectBitmap)
+	assert.Equal(t, len(raw), rejected.MessageLength)
+	assert.Equal(t, "123456", rejected.Original.SourceID)
+	assert.False(t, rejected.Original.Rejected())
+	assert.Equal(t, len(raw)-RejectHeaderLen, rejected.Original.MessageLength)
+	assert.Equal(t, "000001", parsed.Data.(*Fields).F11.Value)
+
+	h.RejectCode = "12"
+	_, err = Pack(h, msg)
+	assert.EqualError(t, err, "reject code must contain 4 digits")
+	_, _, err = Unpack(NewParser(), raw[:24])
+	assert.EqualError(t, err, "bad BASE I header")
+}
+
+func TestAdditionalResponseData(t *testing.T) {
+	msg := NewMessage("0110", &Fields{
+		F11: iso8583.NewNumeric("000001"),
+		F39: iso8583.NewAlphanumeric("00"),
+		F44: &AdditionalResponseData{
+			ResponseSource:      iso8583.NewAlphanumeric("5"),
+			AddressVerification: iso8583.NewAlphanumeric("Y"),
+			Cvv2Result:          iso8583.NewAlphanumeric("M"),
+		},
+	})
+	raw, err := msg.Bytes()
+	assert.Nil(t, err)
+
+	parsed, err := NewParser().Parse(raw)
+	assert.Nil(t, err)
+	f44 := parsed.Data.(*Fields).F44
+	assert.Equal(t, "Y", f44.AddressVerification.Value)
+	assert.Equal(t, "M", f44.Cvv2Result.Value)
+	assert.Equal(t, " ", f44.CvvResult.Value)
+
+	// host sends only leading subfields
+	short := append(raw[:len(raw)-20:len(raw)-20], 0x02, 0xf5, 0xd5)
+	parsed, err = NewParser().Parse(short)
+	assert.Nil(t, err)
+	f44 = parsed.Data.(*Fields).F44
+	assert.Equal(t, "N", f44.AddressVerification.Value)
+	assert.True(t, f44.Cvv2Result.IsEmpty())
+}
+
+func TestPrivateFields(t *testing.T) {
+	msg := NewMessage("0110", &Fields{
+		F62: &CustomPaymentService{
+			AuthorizationCharacteristics: iso8583.NewAlphanumeric("Y"),
+			TransactionID:                iso8583.NewNumeric("123456789012345"),
+		},
+		F63: &PrivateUse{
+			NetworkID:      iso8583.NewNumeric("0002"),
+			StipReasonCode: iso8583.NewNumeric("9020"),
+		},
+	})
+	raw, err := msg.Bytes()
+	assert.Nil(t, err)
+	// BASE I Technical Specifications, Vol. 1, field format conventions:
+	// variable length fields start with one byte binary length of data,
+	// alphanumeric data is in EBCDIC ('Y' is 0xe8)
+	f62 := []byte{0x11, 0xc0, 0, 0, 0, 0, 0, 0, 0, 0xe8, 0x01, 0x23, 0x45, 0x67, 0x89, 0x01, 0x23, 0x45}
+	f63 := []byte{0x07, 0x90, 0, 0, 0x00, 0x02, 0x90, 0x20}
+	assert.Equal(t, append(f62, f63...), raw[2+8:])
+
+	parsed, err := NewParser().Parse(raw)
+	assert.Nil(t, err)
+	data := parsed.Data.(*Fields)
+	assert.Equal(t, "Y", data.F62.AuthorizationCharacteristics.Value)
+	assert.Equal(t, "123456789012345", data.F62.TransactionID.Value)
+	assert.Equal(t, "0002", data.F63.NetworkID.Value)
+	assert.Equal(t, "9020", data.F63.StipReasonCode.Value)
+	assert.True(t, data.F63.MessageReasonCode.IsEmpty())
+}
+
+func TestMaxLengths(t *testing.T) {
+	MaxLengths[2] = 16
+	defer func() { MaxLengths[2] = 19 }()
+	assert.Equal(t, 19, iso8583.ISOMaxLengths[2])
+	assert.Equal(t, 16, NewMessage("0100", &Fields{}).MaxLengths[2])
+}
@@ -0,0 +1,103 @@
+package iso8583
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+)
+
+const (
+	ERR_NOT_NUMERIC      string = "value is not numeric"
+	ERR_NUMERIC_OVERFLOW string = "numeric value overflows uint64"
+)
+
+var (
+	ErrNotNumeric      = errors.New(ERR_NOT_NUMERIC)
+	ErrNumericOverflow = errors.New(ERR_NUMERIC_OVERFLOW)
+)
+
+// NewNumericUint64 create new Numeric field from v
+func NewNumericUint64(v uint64) *Numeric {
+	return &Numeric{strconv.FormatUint(v, 10)}
+}
+
+// NewNumericBig create new Numeric field from non-negative v of any size
+func NewNumericBig(v *big.Int) (*Numeric, error) {
+	if v.Sign() < 0 {
+		return nil, ErrNotNumeric
+	}
+	return &Numeric{v.String()}, nil
+}
+
+// Uint64 returns value of Numeric field as uint64. Values up to 19 digits
+// always fit, longer ones return ErrNumericOverflow if they don't.
+func (n *Numeric) Uint64() (uint64, error) {
+	return numericUint64(n.Value)
+}
+
+// BigInt returns value of Numeric field of any length as big.Int
+func (n *Numeric) BigInt() (*big.Int, error) {
+	return numericBig(n.Value)
+}
+
+// Uint64 returns value of Llnumeric field as uint64
+func (l *Llnumeric) Uint64() (uint64, error) {
+	return numericUint64(l.Value)
+}
+
+// BigInt returns value of Llnumeric field as big.Int
+func (l *Llnumeric) BigInt() (*big.Int, error) {
+	return numericBig(l.Value)
+}
+
+// Uint64 returns value of Lllnumeric field as uint64
+func (l *Lllnumeric) Uint64() (uint64, error) {
+	return numericUint64(l.Value)
+}
+
+// BigInt returns value of Lllnumeric field as big.Int
+func (l *Lllnumeric) BigInt() (*big.Int, error) {
+	return numericBig(l.Value)
+}
+
+func numericUint64(s string) (uint64, error) {
+	if s == "" || !isDigits(s) {
+		return 0, ErrNotNumeric
+	}
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, ErrNumericOverflow
+	}
+	return v, nil
+}
+
+func numericBig(s string) (*big.Int, error) {
+	if s == "" || !isDigits(s) {
+		return nil, ErrNotNumeric
+	}
+	v, _ := new(big.Int).SetString(s, 10)
+	return v, nil
+}
+
+// isNumericValue checks that value of numeric field contains digits only.
+// It is true for other field types.
+func isNumericValue(f Iso8583Type) bool {
+	switch v := f.(type) {
+	case *Numeric:
+		return isDigits(v.Value)
+	case *Llnumeric:
+		return isDigits(v.Value)
+	case *Lllnumeric:
+		return isDigits(v.Value)
+	}
+	return true
+}
+
+// parseLength parses length head or length tag. Unlike strconv.Atoi it
+// accepts digits only, so sign is rejected and result is never negative.
+func parseLength(s string) (int, error) {
+	if s == "" || !isDigits(s) {
+		return 0, ErrNotNumeric
+	}
+	return strconv.Atoi(s)
+}
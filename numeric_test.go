@@ -0,0 +1,61 @@
+package iso8583
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+)
+
+func TestNumericAccessors(t *testing.T) {
+	v, err := NewNumeric("0000000123").Uint64()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(123), v)
+
+	v, err = NewNumeric("18446744073709551615").Uint64()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(18446744073709551615), v)
+
+	_, err = NewNumeric("18446744073709551616").Uint64()
+	assert.Equal(t, ErrNumericOverflow, err)
+	_, err = NewNumeric("-1").Uint64()
+	assert.Equal(t, ErrNotNumeric, err)
+	_, err = NewLlnumeric("").Uint64()
+	assert.Equal(t, ErrNotNumeric, err)
+
+	b, err := NewLllnumeric("123456789012345678901234567890").BigInt()
+	assert.Nil(t, err)
+	assert.Equal(t, "123456789012345678901234567890", b.String())
+	_, err = NewNumeric("12a").BigInt()
+	assert.Equal(t, ErrNotNumeric, err)
+
+	assert.Equal(t, "18446744073709551615", NewNumericUint64(18446744073709551615).Value)
+	n, err := NewNumericBig(new(big.Int).Lsh(big.NewInt(1), 70))
+	assert.Nil(t, err)
+	assert.Equal(t, "1180591620717411303424", n.Value)
+	_, err = NewNumericBig(big.NewInt(-1))
+	assert.Equal(t, ErrNotNumeric, err)
+}
+
+func TestLengthHeadSign(t *testing.T) {
+	_, err := (&Llvar{}).Load([]byte("-1abc"), ASCII, ASCII, 10)
+	assert.EqualError(t, err, "parse length head failed: -1")
+	_, err = (&Lllnumeric{}).Load([]byte("+01abc"), ASCII, ASCII, 10)
+	assert.EqualError(t, err, "parse length head failed: +01")
+}
+
+func TestStrictNumeric(t *testing.T) {
+	data := &testTransaction{F3: NewNumeric("00a000"), F11: NewNumeric("000001")}
+	iso := NewMessage("0200", data)
+	_, err := iso.Bytes()
+	assert.Nil(t, err)
+
+	iso.StrictNumeric = true
+	_, err = iso.Bytes()
+	assert.EqualError(t, err, "field 3: value is not numeric")
+	assert.True(t, errors.Is(err, ErrNotNumeric))
+
+	data.F3.Value = "001000"
+	_, err = iso.Bytes()
+	assert.Nil(t, err)
+}
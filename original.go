@@ -0,0 +1,96 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// originalDataLen is length of n42 field 90 layout
+const originalDataLen = 42
+
+// OriginalDataElements contains field 90 subfields in fixed n42 layout:
+// original MTI (n4), STAN (n6), transmission date and time (n10,
+// MMDDhhmmss), acquiring institution ID (n11) and forwarding institution
+// ID (n11). Subfields are zero padded on the left. Supported encoder are
+// ascii, bcd and rbcd. Length is always 42, length tag is ignored.
+type OriginalDataElements struct {
+	Mti                  string
+	Stan                 string
+	TransmissionDateTime string
+	AcquirerID           string
+	ForwarderID          string
+}
+
+// NewOriginalDataElements create new OriginalDataElements field from
+// original message fields 11, 7, 32 and 33
+func NewOriginalDataElements(orig *Message) (ret *OriginalDataElements, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("Critical error:" + fmt.Sprint(r))
+			ret = nil
+		}
+	}()
+
+	ret = &OriginalDataElements{Mti: orig.Mti}
+	fields, err := parseFields(orig.Data)
+	if err != nil {
+		return nil, err
+	}
+	if info, ok := fields[11]; ok {
+		ret.Stan = fieldValue(info.Field)
+	}
+	if info, ok := fields[7]; ok {
+		ret.TransmissionDateTime = fieldValue(info.Field)
+	}
+	if info, ok := fields[32]; ok {
+		ret.AcquirerID = fieldValue(info.Field)
+	}
+	if info, ok := fields[33]; ok {
+		ret.ForwarderID = fieldValue(info.Field)
+	}
+	return ret, nil
+}
+
+// IsEmpty check OriginalDataElements field for empty value
+func (o *OriginalDataElements) IsEmpty() bool {
+	return o.Mti == "" && o.Stan == "" && o.TransmissionDateTime == "" &&
+		o.AcquirerID == "" && o.ForwarderID == ""
+}
+
+// Bytes encode OriginalDataElements field to bytes
+func (o *OriginalDataElements) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
+	parts := []struct {
+		val string
+		len int
+	}{
+		{o.Mti, 4},
+		{o.Stan, 6},
+		{o.TransmissionDateTime, 10},
+		{o.AcquirerID, 11},
+		{o.ForwarderID, 11},
+	}
+	val := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if len(p.val) > p.len {
+			return nil, valueTooLong("OriginalDataElements", p.len, len(p.val))
+		}
+		val = append(val, strings.Repeat("0", p.len-len(p.val))+p.val)
+	}
+	return NewNumeric(strings.Join(val, "")).Bytes(encoder, lenEncoder, originalDataLen)
+}
+
+// Load decode OriginalDataElements field from bytes
+func (o *OriginalDataElements) Load(raw []byte, encoder, lenEncoder, length int) (int, error) {
+	n := &Numeric{}
+	read, err := n.Load(raw, encoder, lenEncoder, originalDataLen)
+	if err != nil {
+		return 0, err
+	}
+	o.Mti = n.Value[0:4]
+	o.Stan = n.Value[4:10]
+	o.TransmissionDateTime = n.Value[10:20]
+	o.AcquirerID = n.Value[20:31]
+	o.ForwarderID = n.Value[31:42]
+	return read, nil
+}
@@ -0,0 +1,47 @@
+package iso8583
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestOriginalDataElements(t *testing.T) {
+	type original struct {
+		F7  *Numeric   `field:"7" length:"10"`
+		F11 *Numeric   `field:"11" length:"6"`
+		F32 *Llnumeric `field:"32" length:"11"`
+	}
+	type reversal struct {
+		F11 *Numeric              `field:"11" length:"6"`
+		F90 *OriginalDataElements `field:"90" length:"42" encode:"bcd"`
+	}
+
+	orig := NewMessage("0200", &original{
+		F7:  NewNumeric("0701111844"),
+		F11: NewNumeric("000123"),
+		F32: NewLlnumeric("123456"),
+	})
+
+	f90, err := NewOriginalDataElements(orig)
+	assert.Nil(t, err)
+	assert.Equal(t, &OriginalDataElements{"0200", "000123", "0701111844", "123456", ""}, f90)
+
+	iso := NewMessage("0400", &reversal{NewNumeric("000124"), f90})
+	iso.SecondBitmap = true
+	res, err := iso.Bytes()
+	assert.Nil(t, err)
+
+	iso2 := NewMessage("", &reversal{NewNumeric(""), &OriginalDataElements{}})
+	err = iso2.Load(res)
+	assert.Nil(t, err)
+	assert.Equal(t, &OriginalDataElements{"0200", "000123", "0701111844", "00000123456", "00000000000"}, iso2.Data.(*reversal).F90)
+
+	_, err = (&OriginalDataElements{Stan: "1234567"}).Bytes(ASCII, ASCII, 42)
+	assert.EqualError(t, err, "length of value is longer than definition; type=OriginalDataElements, def_len=6, len=7")
+
+	_, err = (&OriginalDataElements{}).Load([]byte("0200"), ASCII, ASCII, 42)
+	assert.EqualError(t, err, "bad raw data")
+
+	_, err = NewOriginalDataElements(NewMessage("0200", nil))
+	assert.EqualError(t, err, "Critical error:data must be a struct")
+}
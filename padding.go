@@ -0,0 +1,84 @@
+package iso8583
+
+import (
+	"strings"
+)
+
+// Padding policies of Numeric field, set by pad tag (for ex. `pad:"space"`)
+const (
+	PAD_ZERO  string = "zero"  // left padded with zeros (default)
+	PAD_SPACE string = "space" // left padded with spaces
+	PAD_RIGHT string = "right" // left-justified, right padded with spaces
+)
+
+const (
+	padZero = iota
+	padSpace
+	padRight
+)
+
+func parsePadStr(str string) int {
+	switch str {
+	case "", PAD_ZERO:
+		return padZero
+	case PAD_SPACE:
+		return padSpace
+	case PAD_RIGHT:
+		return padRight
+	}
+	panic("unknown padding: " + str)
+}
+
+// padName returns padding policy string of pad
+func padName(pad int) string {
+	switch pad {
+	case padSpace:
+		return PAD_SPACE
+	case padRight:
+		return PAD_RIGHT
+	}
+	return PAD_ZERO
+}
+
+// paddedField is implemented by fields which support padding policies
+// other than zero padding
+type paddedField interface {
+	bytesPadded(encoder, length, pad int) ([]byte, error)
+	loadPadded(raw []byte, encoder, length, pad int) (int, error)
+}
+
+// bytesPadded encode Numeric field padded with spaces, only ascii encoder
+// is supported
+func (n *Numeric) bytesPadded(encoder, length, pad int) ([]byte, error) {
+	if length == -1 {
+		return nil, ErrMissingLength
+	}
+	if encoder != ASCII {
+		return nil, ErrInvalidEncoder
+	}
+	if len(n.Value) > length {
+		return nil, valueTooLong("Numeric", length, len(n.Value))
+	}
+	spaces := strings.Repeat(" ", length-len(n.Value))
+	if pad == padRight {
+		return []byte(n.Value + spaces), nil
+	}
+	return []byte(spaces + n.Value), nil
+}
+
+// loadPadded decode Numeric field padded with spaces, padding is removed
+func (n *Numeric) loadPadded(raw []byte, encoder, length, pad int) (int, error) {
+	if encoder != ASCII {
+		return 0, ErrInvalidEncoder
+	}
+	read, err := n.Load(raw, encoder, ASCII, length)
+	if err != nil {
+		return 0, err
+	}
+	if pad == padRight {
+		n.Value = strings.TrimRight(n.Value, " ")
+	} else {
+		n.Value = strings.TrimLeft(n.Value, " ")
+	}
+	return read, nil
+}
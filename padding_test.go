@@ -0,0 +1,49 @@
+package iso8583
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNumericPadding(t *testing.T) {
+	type test struct {
+		F3  *Numeric `field:"3" length:"6" pad:"zero"`
+		F4  *Numeric `field:"4" length:"12" pad:"space"`
+		F11 *Numeric `field:"11" length:"6" pad:"right"`
+	}
+
+	iso := NewMessage("0100", &test{NewNumeric("1"), NewNumeric("777"), NewNumeric("42")})
+	res, err := iso.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "000001"+"         777"+"42    ", string(res[12:]))
+
+	iso2 := NewMessage("", &test{&Numeric{}, &Numeric{}, &Numeric{}})
+	err = iso2.Load(res)
+	assert.Nil(t, err)
+	assert.Equal(t, &test{NewNumeric("000001"), NewNumeric("777"), NewNumeric("42")}, iso2.Data)
+
+	type test2 struct {
+		F4 *Numeric `field:"4" length:"12" encode:"bcd" pad:"space"`
+	}
+
+	iso = NewMessage("0100", &test2{NewNumeric("777")})
+	_, err = iso.Bytes()
+	assert.EqualError(t, err, "invalid encoder")
+
+	type test3 struct {
+		F4 *Numeric `field:"4" length:"2" pad:"right"`
+	}
+
+	iso = NewMessage("0100", &test3{NewNumeric("777")})
+	_, err = iso.Bytes()
+	assert.EqualError(t, err, "length of value is longer than definition; type=Numeric, def_len=2, len=3")
+
+	type test4 struct {
+		F4 *Numeric `field:"4" length:"12" pad:"x"`
+	}
+
+	iso = NewMessage("0100", &test4{NewNumeric("777")})
+	_, err = iso.Bytes()
+	assert.ErrorIs(t, err, ErrInvalidTag)
+	assert.EqualError(t, err, "field 4: invalid tag: unknown padding: x")
+}
@@ -0,0 +1,66 @@
+package iso8583
+
+import (
+	"runtime"
+	"sync"
+)
+
+// PackAll marshall messages using workers goroutines (GOMAXPROCS if
+// workers <= 0). Returned slice is in order of msgs. On error, it is the
+// error of the first failed message, and bytes of failed messages are nil.
+func PackAll(msgs []*Message, workers int) ([][]byte, error) {
+	ret := make([][]byte, len(msgs))
+	err := runAll(len(msgs), workers, func(i int) (err error) {
+		ret[i], err = msgs[i].Bytes()
+		return err
+	})
+	return ret, err
+}
+
+// UnpackAll parses messages using workers goroutines (GOMAXPROCS if
+// workers <= 0). Returned slice is in order of raws. On error, it is the
+// error of the first failed message, and failed messages are nil.
+func (p *Parser) UnpackAll(raws [][]byte, workers int) ([]*Message, error) {
+	ret := make([]*Message, len(raws))
+	err := runAll(len(raws), workers, func(i int) (err error) {
+		ret[i], err = p.Parse(raws[i])
+		return err
+	})
+	return ret, err
+}
+
+// runAll calls fn for 0..n-1 in worker pool and returns error with the
+// lowest index
+func runAll(n, workers int, fn func(i int) error) error {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > n {
+		workers = n
+	}
+
+	errs := make([]error, n)
+	jobs := make(chan int, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				errs[i] = fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return batchError(i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package iso8583
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newTestBatch(n int) []*Message {
+	msgs := make([]*Message, n)
+	for i := range msgs {
+		msgs[i] = NewMessage("0200", &testTransaction{
+			F3:  NewNumeric("000000"),
+			F4:  NewNumeric(fmt.Sprintf("%012d", i)),
+			F11: NewNumeric(fmt.Sprintf("%06d", i%1000000)),
+			F41: NewAlphanumeric("TERM0001"),
+		})
+	}
+	return msgs
+}
+
+func TestPackAll(t *testing.T) {
+	msgs := newTestBatch(100)
+	res, err := PackAll(msgs, 4)
+	assert.Nil(t, err)
+	assert.Equal(t, 100, len(res))
+	for i, msg := range msgs {
+		b, err := msg.Bytes()
+		assert.Nil(t, err)
+		assert.Equal(t, b, res[i])
+	}
+
+	parser := &Parser{}
+	parser.Register("0200", &testTransaction{})
+	parsed, err := parser.UnpackAll(res, 0)
+	assert.Nil(t, err)
+	for i, msg := range parsed {
+		assert.Equal(t, fmt.Sprintf("%06d", i), msg.Data.(*testTransaction).F11.Value)
+	}
+
+	msgs[7].Data.(*testTransaction).F11.Value = "1234567"
+	msgs[9].Data.(*testTransaction).F11.Value = "1234567"
+	res, err = PackAll(msgs, 3)
+	assert.EqualError(t, err, "message 7: length of value is longer than definition; type=Numeric, def_len=6, len=7")
+	assert.Nil(t, res[7])
+	assert.NotNil(t, res[8])
+
+	res[5] = nil
+	_, err = parser.UnpackAll(res[:7], 2)
+	assert.EqualError(t, err, "message 5: bad MTI raw data")
+
+	res, err = PackAll(nil, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(res))
+}
+
+func BenchmarkBytesBatch(b *testing.B) {
+	msgs := newTestBatch(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, msg := range msgs {
+			msg.Bytes()
+		}
+	}
+}
+
+func BenchmarkPackAll(b *testing.B) {
+	msgs := newTestBatch(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		PackAll(msgs, 0)
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	raws, _ := PackAll(newTestBatch(10000), 0)
+	parser := &Parser{}
+	parser.Register("0200", &testTransaction{})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, raw := range raws {
+			parser.Parse(raw)
+		}
+	}
+}
+
+func BenchmarkUnpackAll(b *testing.B) {
+	raws, _ := PackAll(newTestBatch(10000), 0)
+	parser := &Parser{}
+	parser.Register("0200", &testTransaction{})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		parser.UnpackAll(raws, 0)
+	}
+}
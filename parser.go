@@ -4,14 +4,44 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"time"
 )
 
 // Parser for ISO 8583 messages
 type Parser struct {
 	messages  map[string]reflect.Type
 	MtiEncode int
+	// BitmapEncode is set to BitmapEncode of parsed messages
+	BitmapEncode string
+	// CodePage is used by EBCDIC encoder, default is CodePage037
+	CodePage *CodePage
+	// Quirks are wire compatibility flags for non-standard hosts
+	Quirks Quirks
+	// Truncation is set to Truncation of parsed messages
+	Truncation map[int]string
+	// CatchAll is set to CatchAll of parsed messages
+	CatchAll []CatchAllField
+	// Transforms is set to Transforms of parsed messages
+	Transforms map[int]string
+	// Compression is set to Compression of parsed messages
+	Compression map[int]string
+	// Charsets is set to Charsets of parsed messages
+	Charsets map[int]string
+	// Location is set to Location of parsed messages
+	Location *time.Location
+	// MaxLengths is set to MaxLengths of parsed messages
+	MaxLengths map[int]int
+	// MaxSize is set to MaxSize of parsed messages
+	MaxSize int
+	// CollectErrors is set to CollectErrors of parsed messages
+	CollectErrors bool
+	// SkipMTI is set to SkipMTI of parsed messages, raw messages start at
+	// bitmap and are parsed by ParseWithoutMTI
+	SkipMTI bool
 }
 
+var errSkipMTI = errors.New("raw data has no MTI, use ParseWithoutMTI")
+
 // Register MTI
 func (p *Parser) Register(mti string, tpl interface{}) (err error) {
 	defer func() {
@@ -33,21 +63,36 @@ func (p *Parser) Register(mti string, tpl interface{}) (err error) {
 	return nil
 }
 
-func decodeMti(raw []byte, encode int) (string, error) {
-	mtiLen := 4
-	if encode == BCD {
-		mtiLen = 2
+// mtiLen returns length of MTI encoded with encode
+func mtiLen(encode int) int {
+	switch encode {
+	case BCD, rBCD, BINARY:
+		return 2
+	default:
+		return 4
 	}
+}
+
+func decodeMti(raw []byte, encode int, cp *CodePage) (string, error) {
+	mtiLen := mtiLen(encode)
 	if len(raw) < mtiLen {
-		return "", errors.New("bad MTI raw data")
+		return "", badMtiRawError{}
 	}
 
 	var mti string
 	switch encode {
 	case ASCII:
 		mti = string(raw[:mtiLen])
-	case BCD:
+	case BCD, rBCD:
 		mti = string(bcd2Ascii(raw[:mtiLen]))
+	case EBCDIC:
+		mti = string(codePageOrDefault(cp).Decode(raw[:mtiLen]))
+	case BINARY:
+		n := int(raw[0])<<8 | int(raw[1])
+		if n > 9999 {
+			return "", errors.New("bad MTI raw data")
+		}
+		mti = fmt.Sprintf("%04d", n)
 	default:
 		return "", errors.New("invalid encode type")
 	}
@@ -63,11 +108,65 @@ func (p *Parser) Parse(raw []byte) (ret *Message, err error) {
 		}
 	}()
 
-	mti, err := decodeMti(raw, p.MtiEncode)
+	if p.SkipMTI {
+		return nil, errSkipMTI
+	}
+	mti, err := decodeMti(raw, p.Quirks.mtiEncode(p.MtiEncode), p.CodePage)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := p.newMessage(mti)
 	if err != nil {
 		return nil, err
 	}
+	return msg, msg.Load(raw)
+}
+
+// MessageLen returns length of message at the start of raw, computed from
+// bitmap and field lengths by parsing it, so messages concatenated without
+// framing can be split. Bytes after the message are ignored.
+func (p *Parser) MessageLen(raw []byte) (n int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("Critical error:" + fmt.Sprint(r))
+			n = 0
+		}
+	}()
 
+	if p.SkipMTI {
+		return 0, errSkipMTI
+	}
+	mti, err := decodeMti(raw, p.Quirks.mtiEncode(p.MtiEncode), p.CodePage)
+	if err != nil {
+		return 0, err
+	}
+	msg, err := p.newMessage(mti)
+	if err != nil {
+		return 0, err
+	}
+	return msg.load(raw)
+}
+
+// ParseWithoutMTI parses message which raw bytes start at bitmap, MTI is
+// supplied by transport
+func (p *Parser) ParseWithoutMTI(mti string, raw []byte) (ret *Message, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("Critical error:" + fmt.Sprint(r))
+			ret = nil
+		}
+	}()
+
+	msg, err := p.newMessage(mti)
+	if err != nil {
+		return nil, err
+	}
+	return msg, msg.LoadWithoutMTI(raw)
+}
+
+// newMessage creates message with template registered for mti
+func (p *Parser) newMessage(mti string) (*Message, error) {
 	tp, ok := p.messages[mti]
 	if !ok {
 		return nil, errors.New("no template registered for MTI: " + mti)
@@ -76,7 +175,20 @@ func (p *Parser) Parse(raw []byte) (ret *Message, err error) {
 	initStruct(tp, tpl)
 	msg := NewMessage(mti, tpl.Interface())
 	msg.MtiEncode = p.MtiEncode
-	return msg, msg.Load(raw)
+	msg.BitmapEncode = p.BitmapEncode
+	msg.CodePage = p.CodePage
+	msg.Quirks = p.Quirks
+	msg.Truncation = p.Truncation
+	msg.CatchAll = p.CatchAll
+	msg.Transforms = p.Transforms
+	msg.MaxLengths = p.MaxLengths
+	msg.MaxSize = p.MaxSize
+	msg.Charsets = p.Charsets
+	msg.Compression = p.Compression
+	msg.CollectErrors = p.CollectErrors
+	msg.SkipMTI = p.SkipMTI
+	msg.Location = p.Location
+	return msg, nil
 }
 
 func initStruct(tp reflect.Type, val reflect.Value) {
@@ -84,9 +196,18 @@ func initStruct(tp reflect.Type, val reflect.Value) {
 		field := reflect.Indirect(val).Field(i)
 		fieldType := tp.Field(i)
 		switch fieldType.Type.Kind() {
-		case reflect.Ptr: // only initialize Ptr fields
-			fieldValue := reflect.New(fieldType.Type.Elem())
-			field.Set(fieldValue)
+		case reflect.Ptr: // only initialize nil Ptr fields
+			if field.IsNil() {
+				fieldValue := reflect.New(fieldType.Type.Elem())
+				field.Set(fieldValue)
+			}
+			if fieldType.Anonymous && fieldType.Type.Elem().Kind() == reflect.Struct {
+				initStruct(fieldType.Type.Elem(), field)
+			}
+		case reflect.Struct: // embedded struct with field group
+			if fieldType.Anonymous {
+				initStruct(fieldType.Type, field.Addr())
+			}
 		}
 	}
 }
@@ -0,0 +1,65 @@
+package iso8583
+
+import (
+	"io"
+	"sort"
+)
+
+// Plan is message validated and encoded field by field by Message.Plan.
+// Len is exact size of packed message, so buffer can be allocated at once,
+// and AppendTo or WriteTo assemble it.
+type Plan struct {
+	Mti    []byte
+	Bitmap []byte
+	// Fields are present fields in order of their indexes
+	Fields []PlanField
+}
+
+// PlanField is encoded field of Plan, including length head
+type PlanField struct {
+	Field int
+	Data  []byte
+}
+
+// Len returns size of packed message
+func (p *Plan) Len() int {
+	n := len(p.Mti) + len(p.Bitmap)
+	for _, f := range p.Fields {
+		n += len(f.Data)
+	}
+	return n
+}
+
+// AppendTo appends packed message to dst and returns the extended buffer
+func (p *Plan) AppendTo(dst []byte) []byte {
+	dst = append(dst, p.Mti...)
+	dst = append(dst, p.Bitmap...)
+	for _, f := range p.Fields {
+		dst = append(dst, f.Data...)
+	}
+	return dst
+}
+
+// Bytes returns packed message
+func (p *Plan) Bytes() []byte {
+	return p.AppendTo(make([]byte, 0, p.Len()))
+}
+
+// WriteTo writes packed message to w
+func (p *Plan) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(p.Bytes())
+	return int64(n), err
+}
+
+// messageTooLarge returns MessageTooLargeError of plan p with the three
+// largest fields
+func messageTooLarge(p *Plan, limit int) error {
+	fields := append([]PlanField(nil), p.Fields...)
+	sort.SliceStable(fields, func(i, j int) bool {
+		return len(fields[i].Data) > len(fields[j].Data)
+	})
+	if len(fields) > 3 {
+		fields = fields[:3]
+	}
+	return &MessageTooLargeError{p.Len(), limit, fields}
+}
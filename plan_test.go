@@ -0,0 +1,79 @@
+package iso8583
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPlan(t *testing.T) {
+	type test struct {
+		F2  *Llnumeric    `field:"2" length:"19"`
+		F4  *Numeric      `field:"4" length:"12"`
+		F43 *Alphanumeric `field:"43" length:"10"`
+	}
+
+	msg := NewMessage("0200", &test{NewLlnumeric("4276555555555558"), NewNumeric("100"), nil})
+	p, err := msg.Plan()
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("0200"), p.Mti)
+	assert.Equal(t, []PlanField{{2, []byte("164276555555555558")}, {4, []byte("000000000100")}}, p.Fields)
+	assert.Equal(t, 4+8+18+12, p.Len())
+
+	want, err := msg.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, want, p.Bytes())
+	buf := make([]byte, 0, p.Len())
+	buf = p.AppendTo(buf)
+	assert.Equal(t, want, buf)
+	assert.Equal(t, p.Len(), cap(buf))
+	w := &bytes.Buffer{}
+	n, err := p.WriteTo(w)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len(want)), n)
+	assert.Equal(t, want, w.Bytes())
+
+	// invalid message is rejected before writing
+	msg.Data.(*test).F43 = NewAlphanumeric("ACME SUPERMARKET")
+	p, err = msg.Plan()
+	assert.Nil(t, p)
+	assert.True(t, errors.Is(err, ErrValueTooLong))
+}
+
+func TestMaxSize(t *testing.T) {
+	type test struct {
+		F2  *Llnumeric `field:"2" length:"19"`
+		F4  *Numeric   `field:"4" length:"12"`
+		F11 *Numeric   `field:"11" length:"6"`
+		F48 *Lllvar    `field:"48" length:"999"`
+		F49 *Numeric   `field:"49" length:"3"`
+	}
+
+	msg := NewMessage("0200", &test{NewLlnumeric("4276555555555558"), NewNumeric("100"),
+		NewNumeric("000001"), NewLllvar(make([]byte, 100)), NewNumeric("840")})
+	size := 4 + 8 + 18 + 12 + 6 + 103 + 3
+	msg.MaxSize = size
+	_, err := msg.Bytes()
+	assert.Nil(t, err)
+
+	msg.MaxSize = size - 1
+	_, err = msg.Bytes()
+	assert.True(t, errors.Is(err, ErrMessageTooLarge))
+	assert.EqualError(t, err, "message is too large: 154 bytes, limit is 153; "+
+		"largest fields: 48 (103 bytes), 2 (18 bytes), 4 (12 bytes)")
+	var tooLarge *MessageTooLargeError
+	assert.True(t, errors.As(err, &tooLarge))
+	assert.Equal(t, 3, len(tooLarge.Fields))
+
+	p := &Parser{MaxSize: 100}
+	p.Register("0200", &test{})
+	msg.MaxSize = 0
+	raw, err := msg.Bytes()
+	assert.Nil(t, err)
+	parsed, err := p.Parse(raw)
+	assert.Nil(t, err)
+	assert.Equal(t, 100, parsed.MaxSize)
+	_, err = parsed.Bytes()
+	assert.True(t, errors.Is(err, ErrMessageTooLarge))
+}
@@ -0,0 +1,120 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	ERR_BAD_POS_ENTRY_MODE string = "bad POS entry mode"
+	ERR_BAD_POS_DATA_CODE  string = "bad POS data code"
+)
+
+var (
+	ErrBadPosEntryMode = errors.New(ERR_BAD_POS_ENTRY_MODE)
+	ErrBadPosDataCode  = errors.New(ERR_BAD_POS_DATA_CODE)
+)
+
+// PAN entry modes, the first two digits of POS entry mode (field 22,
+// ISO 8583:1987)
+const (
+	PAN_ENTRY_UNKNOWN              string = "00"
+	PAN_ENTRY_MANUAL               string = "01"
+	PAN_ENTRY_MAGNETIC_STRIPE      string = "02"
+	PAN_ENTRY_BAR_CODE             string = "03"
+	PAN_ENTRY_OCR                  string = "04"
+	PAN_ENTRY_ICC                  string = "05"
+	PAN_ENTRY_CONTACTLESS_ICC      string = "07"
+	PAN_ENTRY_FALLBACK             string = "80" // magnetic stripe after ICC read failure
+	PAN_ENTRY_FULL_MAGNETIC_STRIPE string = "90"
+	PAN_ENTRY_CONTACTLESS_STRIPE   string = "91"
+)
+
+// PIN entry capabilities, the last digit of POS entry mode (field 22,
+// ISO 8583:1987)
+const (
+	PIN_CAPABILITY_UNKNOWN     string = "0"
+	PIN_CAPABILITY_CAN_ACCEPT  string = "1"
+	PIN_CAPABILITY_NONE        string = "2"
+	PIN_CAPABILITY_INOPERATIVE string = "8"
+)
+
+// POS condition codes (field 25)
+const (
+	POS_CONDITION_NORMAL               string = "00"
+	POS_CONDITION_CUSTOMER_NOT_PRESENT string = "01"
+	POS_CONDITION_UNATTENDED           string = "02"
+	POS_CONDITION_MERCHANT_SUSPICIOUS  string = "03"
+	POS_CONDITION_CARD_NOT_PRESENT     string = "05"
+	POS_CONDITION_PREAUTHORIZED        string = "06"
+	POS_CONDITION_MAIL_TELEPHONE       string = "08"
+	POS_CONDITION_VERIFICATION         string = "51"
+	POS_CONDITION_ECOMMERCE            string = "59"
+	POS_CONDITION_STRIPE_UNREADABLE    string = "71"
+)
+
+// PosEntryMode is 3 digit POS entry mode (field 22, ISO 8583:1987)
+type PosEntryMode struct {
+	PanEntry      string // PAN_ENTRY_*
+	PinCapability string // PIN_CAPABILITY_*
+}
+
+// ParsePosEntryMode parses 3 digit POS entry mode
+func ParsePosEntryMode(s string) (PosEntryMode, error) {
+	if len(s) != 3 || !isDigits(s) {
+		return PosEntryMode{}, fmt.Errorf("%w: %q", ErrBadPosEntryMode, s)
+	}
+	return PosEntryMode{s[:2], s[2:]}, nil
+}
+
+// String formats POS entry mode as 3 digits
+func (p PosEntryMode) String() string {
+	return p.PanEntry + p.PinCapability
+}
+
+// Card data input modes, position 7 of POS data code (field 22,
+// ISO 8583:1993)
+const (
+	CARD_INPUT_UNKNOWN         byte = '0'
+	CARD_INPUT_MANUAL          byte = '1'
+	CARD_INPUT_MAGNETIC_STRIPE byte = '2'
+	CARD_INPUT_BAR_CODE        byte = '3'
+	CARD_INPUT_OCR             byte = '4'
+	CARD_INPUT_ICC             byte = '5'
+	CARD_INPUT_KEY_ENTERED     byte = '6'
+)
+
+// PosDataCode is 12 character POS data code (field 22, ISO 8583:1993),
+// one code per position
+type PosDataCode struct {
+	CardDataInputCapability  byte // 1
+	CardholderAuthCapability byte // 2
+	CardCaptureCapability    byte // 3
+	OperatingEnvironment     byte // 4
+	CardholderPresent        byte // 5
+	CardPresent              byte // 6
+	CardDataInputMode        byte // 7, CARD_INPUT_*
+	CardholderAuthMethod     byte // 8
+	CardholderAuthEntity     byte // 9
+	CardDataOutputCapability byte // 10
+	TerminalOutputCapability byte // 11
+	PinCaptureCapability     byte // 12
+}
+
+// ParsePosDataCode parses 12 character POS data code
+func ParsePosDataCode(s string) (PosDataCode, error) {
+	if len(s) != 12 {
+		return PosDataCode{}, fmt.Errorf("%w: %q", ErrBadPosDataCode, s)
+	}
+	return PosDataCode{s[0], s[1], s[2], s[3], s[4], s[5], s[6], s[7], s[8], s[9], s[10], s[11]}, nil
+}
+
+// String formats POS data code as 12 characters
+func (p PosDataCode) String() string {
+	return string([]byte{
+		p.CardDataInputCapability, p.CardholderAuthCapability, p.CardCaptureCapability,
+		p.OperatingEnvironment, p.CardholderPresent, p.CardPresent, p.CardDataInputMode,
+		p.CardholderAuthMethod, p.CardholderAuthEntity, p.CardDataOutputCapability,
+		p.TerminalOutputCapability, p.PinCaptureCapability,
+	})
+}
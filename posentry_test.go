@@ -0,0 +1,28 @@
+package iso8583
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPosEntryMode(t *testing.T) {
+	mode, err := ParsePosEntryMode("051")
+	assert.Nil(t, err)
+	assert.Equal(t, PosEntryMode{PAN_ENTRY_ICC, PIN_CAPABILITY_CAN_ACCEPT}, mode)
+	assert.Equal(t, "051", mode.String())
+	assert.Equal(t, "072", PosEntryMode{PAN_ENTRY_CONTACTLESS_ICC, PIN_CAPABILITY_NONE}.String())
+
+	_, err = ParsePosEntryMode("05")
+	assert.True(t, errors.Is(err, ErrBadPosEntryMode))
+	_, err = ParsePosEntryMode("0a1")
+	assert.EqualError(t, err, `bad POS entry mode: "0a1"`)
+
+	code, err := ParsePosDataCode("51010151134C")
+	assert.Nil(t, err)
+	assert.Equal(t, CARD_INPUT_ICC, code.CardDataInputMode)
+	assert.Equal(t, byte('C'), code.PinCaptureCapability)
+	assert.Equal(t, "51010151134C", code.String())
+	_, err = ParsePosDataCode("510101")
+	assert.True(t, errors.Is(err, ErrBadPosDataCode))
+}
@@ -0,0 +1,97 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+const ERR_EMPTY_FIXED_LENGTH string = "fixed length field can't be present empty"
+
+// ErrEmptyFixedLength is returned by SetPresent of empty Numeric,
+// Alphanumeric or Binary field, which would be packed as made-up zeros or
+// spaces
+var ErrEmptyFixedLength = errors.New(ERR_EMPTY_FIXED_LENGTH)
+
+// fixedLengthTypes are field types without wire form of empty value
+var fixedLengthTypes = map[reflect.Type]bool{
+	reflect.TypeOf(&Numeric{}):      true,
+	reflect.TypeOf(&Alphanumeric{}): true,
+	reflect.TypeOf(&Binary{}):       true,
+}
+
+// SetPresent makes field i present on packing even if its value is empty,
+// for ex. empty Llvar which is meaningful in some specs, like `present`
+// option of field tag does. Nil field is set to empty value. Empty fixed
+// length field is rejected with ErrEmptyFixedLength.
+func (m *Message) SetPresent(i int) error {
+	f, err := m.GetField(i)
+	if err != nil {
+		return err
+	}
+	if f == nil || f.IsEmpty() {
+		if sf, _, _ := findField(m.Data, i); fixedLengthTypes[sf.Type] {
+			return &FieldError{i, ErrEmptyFixedLength}
+		}
+	}
+	if f == nil {
+		if err := m.SetString(i, ""); err != nil {
+			return err
+		}
+	}
+	if m.present == nil {
+		m.present = make(map[int]bool)
+	}
+	m.present[i] = true
+	return nil
+}
+
+// Unset makes field i absent: it is set to nil and SetPresent of it is
+// cancelled
+func (m *Message) Unset(i int) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("Critical error:" + fmt.Sprint(r))
+		}
+	}()
+
+	_, fv, ok := findField(m.Data, i)
+	if !ok {
+		return fmt.Errorf("field %d not defined", i)
+	}
+	if !isPtrOrInterface(fv.Kind()) {
+		return fmt.Errorf("field %d: can not unset %s", i, fv.Type())
+	}
+	fv.Set(reflect.Zero(fv.Type()))
+	delete(m.present, i)
+	return nil
+}
+
+// IsPresent checks if field i is packed: it is not empty, or it is
+// present by SetPresent or field tag
+func (m *Message) IsPresent(i int) (bool, error) {
+	f, err := m.GetField(i)
+	if err != nil || f == nil {
+		return false, err
+	}
+	fields, err := m.fields()
+	if err != nil {
+		return false, err
+	}
+	info, ok := fields[i]
+	return ok && (!info.Field.IsEmpty() || info.Present), nil
+}
+
+// fields returns fields of Data with presence set by SetPresent
+func (m *Message) fields() (map[int]*fieldInfo, error) {
+	fields, err := parseFields(m.Data)
+	if err != nil {
+		return nil, err
+	}
+	for i := range m.present {
+		if info, ok := fields[i]; ok {
+			info.Present = true
+		}
+	}
+	return fields, nil
+}
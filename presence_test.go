@@ -0,0 +1,78 @@
+package iso8583
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSetPresent(t *testing.T) {
+	type test struct {
+		F2  *Llnumeric `field:"2" length:"19"`
+		F11 *Numeric   `field:"11" length:"6"`
+		F48 *Llvar     `field:"48" length:"99"`
+	}
+
+	msg := NewMessage("0200", &test{F11: NewNumeric("000001")})
+	assert.Nil(t, msg.SetPresent(48))
+	present, err := msg.IsPresent(48)
+	assert.Nil(t, err)
+	assert.True(t, present)
+	res, err := msg.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "0200"+"\x00\x20\x00\x00\x00\x01\x00\x00"+"000001"+"00", string(res))
+
+	clone := msg.Clone()
+	assert.Nil(t, msg.Unset(48))
+	assert.Nil(t, msg.Data.(*test).F48)
+	present, err = msg.IsPresent(48)
+	assert.Nil(t, err)
+	assert.False(t, present)
+	res, err = msg.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "0200"+"\x00\x20\x00\x00\x00\x00\x00\x00"+"000001", string(res))
+
+	// empty value is absent without SetPresent
+	msg.Data.(*test).F48 = NewLlvar(nil)
+	present, err = msg.IsPresent(48)
+	assert.Nil(t, err)
+	assert.False(t, present)
+
+	present, err = clone.IsPresent(48)
+	assert.Nil(t, err)
+	assert.True(t, present)
+
+	assert.EqualError(t, msg.Unset(3), "field 3 not defined")
+	assert.EqualError(t, msg.SetPresent(3), "field 3 not defined")
+
+	// fixed length field has no empty wire form
+	err = msg.SetPresent(11)
+	assert.Nil(t, err)
+	msg.Data.(*test).F11 = nil
+	err = msg.SetPresent(11)
+	assert.ErrorIs(t, err, ErrEmptyFixedLength)
+	assert.EqualError(t, err, "field 11: fixed length field can't be present empty")
+	assert.Nil(t, msg.Data.(*test).F11)
+}
+
+func TestSetPresentValidateLoad(t *testing.T) {
+	type test struct {
+		F11 *Numeric `field:"11" length:"6"`
+		F48 *Llvar   `field:"48" length:"99" mti:"0100:M"`
+	}
+
+	msg := NewMessage("0100", &test{F11: NewNumeric("000001")})
+	assert.EqualError(t, msg.ValidateFor("0100"), "field 48 is mandatory for MTI 0100")
+	assert.Nil(t, msg.SetPresent(48))
+	assert.Nil(t, msg.ValidateFor("0100"))
+
+	// Load replaces presence with that of loaded data
+	raw, err := NewMessage("0100", &test{F11: NewNumeric("000001")}).Bytes()
+	assert.Nil(t, err)
+	assert.Nil(t, msg.Load(raw))
+	present, err := msg.IsPresent(48)
+	assert.Nil(t, err)
+	assert.False(t, present)
+	res, err := msg.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, raw, res)
+}
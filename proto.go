@@ -0,0 +1,289 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+const (
+	ERR_BAD_PROTO string = "bad protobuf data"
+)
+
+var ErrBadProto = errors.New(ERR_BAD_PROTO)
+
+// Protocol Buffers wire types
+const (
+	protoVarint  = 0
+	protoFixed64 = 1
+	protoBytes   = 2
+	protoFixed32 = 5
+)
+
+// ToProto encodes message as IsoMessage of iso8583.proto, so it can be
+// shipped between services over gRPC or Kafka. Values of text, numeric and
+// binary fields are kept as is, other fields (for ex. composite ones) are
+// packed according to their tags. Extra fields are encoded too.
+func (m *Message) ToProto() (ret []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("Critical error:" + fmt.Sprint(r))
+			ret = nil
+		}
+	}()
+
+	fields, err := m.fields()
+	if err != nil {
+		return nil, err
+	}
+	if err := m.addExtraFields(fields); err != nil {
+		return nil, err
+	}
+	indexes := make([]int, 0, len(fields))
+	for i, info := range fields {
+		if !info.Field.IsEmpty() || info.Present {
+			indexes = append(indexes, i)
+		}
+	}
+	sort.Ints(indexes)
+
+	ret = make([]byte, 0, 256)
+	if m.Mti != "" {
+		ret = appendProtoBytes(ret, 1, []byte(m.Mti))
+	}
+	for _, i := range indexes {
+		info := fields[i]
+		var v []byte
+		if isProtoText(info.Field) {
+			v = []byte(fieldValue(info.Field))
+		} else {
+			info.Quirks = m.Quirks
+			info.CodePage = m.CodePage
+			if v, err = info.bytes(); err != nil {
+				return nil, &FieldError{i, err}
+			}
+		}
+		entry := appendProtoVarint(nil, 1<<3|protoVarint)
+		entry = appendProtoVarint(entry, uint64(i))
+		entry = appendProtoBytes(entry, 2, v)
+		ret = appendProtoBytes(ret, 2, entry)
+	}
+	return ret, nil
+}
+
+// FromProto decodes IsoMessage of iso8583.proto into message. Mti is set if
+// it is empty. Nil fields are allocated, Data must be a pointer to struct.
+// Fields which Data doesn't define are decoded by CatchAll into Extra.
+func (m *Message) FromProto(b []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("Critical error:" + fmt.Sprint(r))
+		}
+	}()
+
+	mti, values, err := decodeProto(b)
+	if err != nil {
+		return err
+	}
+	if m.Mti == "" {
+		m.Mti = mti
+	}
+	m.Extra = nil
+
+	indexes := make([]int, 0, len(values))
+	for i := range values {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	for _, i := range indexes {
+		if err := m.setProtoField(i, values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setProtoField sets field i from its IsoMessage value v
+func (m *Message) setProtoField(i int, v []byte) error {
+	sf, fv, ok := findField(m.Data, i)
+	if !ok {
+		info, ok := m.catchAllInfo(i, nil)
+		if !ok {
+			return fmt.Errorf("field %d not defined", i)
+		}
+		if err := m.loadProtoField(info, v); err != nil {
+			return err
+		}
+		if m.Extra == nil {
+			m.Extra = make(map[int]Iso8583Type)
+		}
+		m.Extra[i] = info.Field
+		return nil
+	}
+	if sf.Type.Kind() != reflect.Ptr {
+		return fmt.Errorf("field %d must be a pointer", i)
+	}
+	nv := reflect.New(sf.Type.Elem())
+	if err := m.loadProtoField(newFieldInfo(sf, nv), v); err != nil {
+		return err
+	}
+	fv.Set(nv)
+	return nil
+}
+
+// loadProtoField sets field from its IsoMessage value v
+func (m *Message) loadProtoField(info *fieldInfo, v []byte) error {
+	info.Quirks = m.Quirks
+	info.CodePage = m.CodePage
+	if isProtoText(info.Field) {
+		return setInfoString(info.Index, info, string(v))
+	}
+	n, err := info.load(v)
+	if err != nil {
+		return &FieldError{info.Index, err}
+	}
+	if n != len(v) {
+		return &FieldError{info.Index, ErrBadProto}
+	}
+	return nil
+}
+
+// isProtoText checks if field value is carried as is by IsoMessage
+func isProtoText(f Iso8583Type) bool {
+	switch f.(type) {
+	case *Numeric, *Alphanumeric, *Llnumeric, *Lllnumeric, *Binary,
+		*Llvar, *Lllvar, *LlvarText, *LllvarText, *FeeAmount:
+		return true
+	}
+	return false
+}
+
+// ParseProto decodes IsoMessage of iso8583.proto with template registered
+// for its MTI
+func (p *Parser) ParseProto(b []byte) (ret *Message, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("Critical error:" + fmt.Sprint(r))
+			ret = nil
+		}
+	}()
+
+	mti, _, err := decodeProto(b)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := p.newMessage(mti)
+	if err != nil {
+		return nil, err
+	}
+	return msg, msg.FromProto(b)
+}
+
+// decodeProto decodes MTI and field values of IsoMessage. Unknown fields
+// are skipped, as protobuf decoders do.
+func decodeProto(b []byte) (mti string, values map[int][]byte, err error) {
+	values = make(map[int][]byte)
+	for len(b) > 0 {
+		num, wt, v, rest, err := readProtoField(b)
+		if err != nil {
+			return "", nil, err
+		}
+		b = rest
+		switch {
+		case num == 1 && wt == protoBytes:
+			mti = string(v)
+		case num == 2 && wt == protoBytes:
+			i, value, err := decodeProtoEntry(v)
+			if err != nil {
+				return "", nil, err
+			}
+			values[i] = value
+		}
+	}
+	return mti, values, nil
+}
+
+// decodeProtoEntry decodes entry of fields map
+func decodeProtoEntry(b []byte) (int, []byte, error) {
+	var key uint64
+	var value []byte
+	for len(b) > 0 {
+		num, wt, v, rest, err := readProtoField(b)
+		if err != nil {
+			return 0, nil, err
+		}
+		b = rest
+		switch {
+		case num == 1 && wt == protoVarint:
+			key, _ = readProtoVarint(v)
+		case num == 2 && wt == protoBytes:
+			value = v
+		}
+	}
+	if key > 128 {
+		return 0, nil, ErrBadProto
+	}
+	return int(key), value, nil
+}
+
+// readProtoField reads one field of b. Value of varint field is returned
+// in its encoded form.
+func readProtoField(b []byte) (num uint64, wt int, v, rest []byte, err error) {
+	key, n := readProtoVarint(b)
+	if n == 0 {
+		return 0, 0, nil, nil, ErrBadProto
+	}
+	b = b[n:]
+	num, wt = key>>3, int(key&7)
+	switch wt {
+	case protoVarint:
+		if _, n = readProtoVarint(b); n == 0 {
+			return 0, 0, nil, nil, ErrBadProto
+		}
+	case protoFixed64:
+		n = 8
+	case protoFixed32:
+		n = 4
+	case protoBytes:
+		l, ln := readProtoVarint(b)
+		if ln == 0 || l > uint64(len(b)-ln) {
+			return 0, 0, nil, nil, ErrBadProto
+		}
+		b = b[ln:]
+		n = int(l)
+	default:
+		return 0, 0, nil, nil, ErrBadProto
+	}
+	if n > len(b) {
+		return 0, 0, nil, nil, ErrBadProto
+	}
+	return num, wt, b[:n], b[n:], nil
+}
+
+// readProtoVarint reads varint from b and returns its value and length,
+// length is 0 if b has no valid varint
+func readProtoVarint(b []byte) (uint64, int) {
+	var x uint64
+	for i := 0; i < len(b) && i < 10; i++ {
+		x |= uint64(b[i]&0x7f) << (7 * uint(i))
+		if b[i] < 0x80 {
+			return x, i + 1
+		}
+	}
+	return 0, 0
+}
+
+func appendProtoVarint(b []byte, x uint64) []byte {
+	for x >= 0x80 {
+		b = append(b, byte(x)|0x80)
+		x >>= 7
+	}
+	return append(b, byte(x))
+}
+
+func appendProtoBytes(b []byte, num int, v []byte) []byte {
+	b = appendProtoVarint(b, uint64(num)<<3|protoBytes)
+	b = appendProtoVarint(b, uint64(len(v)))
+	return append(b, v...)
+}
@@ -0,0 +1,71 @@
+package iso8583
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestProto(t *testing.T) {
+	type test struct {
+		F2  *Llnumeric          `field:"2" length:"19"`
+		F4  *Numeric            `field:"4" length:"12"`
+		F11 *Numeric            `field:"11" length:"6"`
+		F41 *Alphanumeric       `field:"41" length:"8"`
+		F48 *testAdditionalData `field:"48" length:"999"`
+		F52 *Binary             `field:"52" length:"8"`
+	}
+
+	// wire format of IsoMessage{mti: "0800", fields: {11: "000001"}}
+	b, err := NewMessage("0800", &test{F11: NewNumeric("000001")}).ToProto()
+	assert.Nil(t, err)
+	assert.Equal(t, append([]byte{0x0a, 0x04, '0', '8', '0', '0', 0x12, 0x0a, 0x08, 0x0b, 0x12, 0x06}, "000001"...), b)
+
+	data := &test{
+		F2:  NewLlnumeric("4276555555555558"),
+		F4:  NewNumeric("000000001250"),
+		F11: NewNumeric("000001"),
+		F41: NewAlphanumeric("TERM01"),
+		F48: &testAdditionalData{
+			S1: NewNumeric("7"),
+			S3: NewLlvar([]byte("hello")),
+		},
+		F52: NewBinary([]byte{0, 1, 2, 3, 0xfd, 0xfe, 0xff, 0x80}),
+	}
+	msg := NewMessage("0100", data)
+	msg.CatchAll = []CatchAllField{{First: 120, Last: 127}}
+	msg.Extra = map[int]Iso8583Type{120: NewLllvar([]byte("private"))}
+	b, err = msg.ToProto()
+	assert.Nil(t, err)
+
+	p := &Parser{CatchAll: msg.CatchAll}
+	p.Register("0100", &test{})
+	parsed, err := p.ParseProto(b)
+	assert.Nil(t, err)
+	assert.Equal(t, "0100", parsed.Mti)
+	res := parsed.Data.(*test)
+	assert.Equal(t, data.F2, res.F2)
+	assert.Equal(t, "TERM01", res.F41.Value)
+	assert.Equal(t, "07", res.F48.S1.Value)
+	assert.Equal(t, []byte("hello"), res.F48.S3.Value)
+	assert.Equal(t, data.F52.Value, res.F52.Value)
+	assert.Equal(t, NewLllvar([]byte("private")), parsed.Extra[120])
+
+	// ISO bytes are the same as of the original message
+	want, err := msg.Bytes()
+	assert.Nil(t, err)
+	got, err := parsed.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, want, got)
+
+	// unknown protobuf fields are skipped
+	into := NewMessage("", &test{})
+	into.CatchAll = msg.CatchAll
+	assert.Nil(t, into.FromProto(append([]byte{0x18, 0x01}, b...)))
+	assert.Equal(t, "0100", into.Mti)
+
+	assert.Equal(t, ErrBadProto, into.FromProto([]byte{0x0a, 0x05, '0'}))
+	assert.EqualError(t, into.FromProto([]byte{0x12, 0x04, 0x08, 0x03, 0x12, 0x00}), "field 3 not defined")
+	assert.EqualError(t, into.FromProto([]byte{0x12, 0x05, 0x08, 0x0b, 0x12, 0x01, 'a'}), "field 11: value must be numeric")
+	_, err = p.ParseProto([]byte{0x0a, 0x04, '0', '2', '0', '0'})
+	assert.EqualError(t, err, "no template registered for MTI: 0200")
+}
@@ -0,0 +1,157 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const ERR_ODD_LENGTH_IN_BYTES string = "odd number of digits requires filler f with length-in-bytes quirk"
+
+// ErrOddLengthInBytes is returned when value with odd number of digits is
+// packed with QUIRK_LENGTH_IN_BYTES and filler 0, which can't be told from
+// a digit on loading
+var ErrOddLengthInBytes = errors.New(ERR_ODD_LENGTH_IN_BYTES)
+
+// Quirks is a set of wire compatibility flags for hosts which deviate from
+// the standard, set on Message or Parser
+type Quirks uint
+
+const (
+	// QUIRK_LENGTH_IN_BYTES makes length head of bcd and rbcd encoded
+	// Llnumeric and Lllnumeric fields count bytes instead of digits. Values
+	// with odd number of digits require filler f. On loading, filler
+	// nibble f is dropped, as well as filler nibble 0 of values which are
+	// one digit longer than field length.
+	QUIRK_LENGTH_IN_BYTES Quirks = 1 << iota
+	// QUIRK_BINARY_NIBBLES makes length tag of Binary fields count nibbles
+	// (hex digits) instead of bytes
+	QUIRK_BINARY_NIBBLES
+	// QUIRK_SECOND_BITMAP makes secondary bitmap always present
+	QUIRK_SECOND_BITMAP
+	// QUIRK_EBCDIC_MTI makes MTI encoded in EBCDIC regardless of MtiEncode
+	QUIRK_EBCDIC_MTI
+)
+
+var quirkNames = []string{"length-in-bytes", "binary-nibbles", "second-bitmap", "ebcdic-mti"}
+
+func (q Quirks) String() string {
+	var names []string
+	for i, name := range quirkNames {
+		if q&(1<<uint(i)) != 0 {
+			names = append(names, name)
+		}
+	}
+	if rest := q &^ (1<<uint(len(quirkNames)) - 1); rest != 0 {
+		names = append(names, fmt.Sprintf("0x%x", uint(rest)))
+	}
+	return strings.Join(names, "|")
+}
+
+// mtiEncode returns encoding of MTI adjusted by quirks
+func (q Quirks) mtiEncode(encode int) int {
+	if q&QUIRK_EBCDIC_MTI != 0 {
+		return EBCDIC
+	}
+	return encode
+}
+
+// length returns length of field adjusted by quirks
+func (f *fieldInfo) length() int {
+	if _, ok := f.Field.(*Binary); ok && f.Quirks&QUIRK_BINARY_NIBBLES != 0 && f.Length > 0 {
+		return (f.Length + 1) / 2
+	}
+	return f.Length
+}
+
+// lengthHead returns number of digits of length head of Llnumeric and
+// Lllnumeric field with bcd or rbcd value, or 0 if QUIRK_LENGTH_IN_BYTES
+// is not applied to the field
+func (f *fieldInfo) lengthHead() int {
+	if f.Quirks&QUIRK_LENGTH_IN_BYTES == 0 || (f.Encode != BCD && f.Encode != rBCD) {
+		return 0
+	}
+	switch f.Field.(type) {
+	case *Llnumeric:
+		return 2
+	case *Lllnumeric:
+		return 3
+	}
+	return 0
+}
+
+// bytesLengthInBytes replaces length head of encoded field d with number
+// of bytes of value
+func (f *fieldInfo) bytesLengthInBytes(d []byte, digits int) ([]byte, error) {
+	if len(numericValue(f.Field))%2 == 1 && f.Filler != 0x0f {
+		return nil, ErrOddLengthInBytes
+	}
+	hl := lengthHeadLen(digits, f.LenEncode)
+	head, err := encodeLengthHead(len(d)-hl, digits, f.LenEncode)
+	if err != nil {
+		return nil, err
+	}
+	return append(head, d[hl:]...), nil
+}
+
+// loadLengthInBytes decodes field which length head is number of bytes of
+// value
+func (f *fieldInfo) loadLengthInBytes(raw []byte, digits int) (int, error) {
+	n, hl, err := decodeLengthHead(raw, digits, f.LenEncode)
+	if err != nil {
+		return 0, err
+	}
+	if len(raw) < hl+n {
+		return 0, ErrBadRaw
+	}
+	// reencode head with number of digits for regular loading
+	tmp, err := encodeLengthHead(2*n, digits, f.LenEncode)
+	if err != nil {
+		return 0, err
+	}
+	tmp = append(tmp, raw[hl:hl+n]...)
+	if _, err := f.Field.Load(tmp, f.Encode, f.LenEncode, f.Length); err != nil {
+		return 0, err
+	}
+	// value is decoded from whole bytes, so odd number of digits is
+	// recovered by removing filler nibble
+	var value *string
+	switch v := f.Field.(type) {
+	case *Llnumeric:
+		value = &v.Value
+	case *Lllnumeric:
+		value = &v.Value
+	}
+	*value = trimFillerNibble(*value, f.Encode)
+	if f.Length > 0 && len(*value) == f.Length+1 {
+		*value = trimZeroNibble(*value, f.Encode)
+	}
+	if f.Length > 0 && len(*value) > f.Length {
+		return 0, fmt.Errorf(ERR_VALUE_TOO_LONG, reflect.TypeOf(f.Field).Elem().Name(), f.Length, len(*value))
+	}
+	return hl + n, nil
+}
+
+// numericValue returns value of Llnumeric or Lllnumeric field
+func numericValue(field Iso8583Type) string {
+	switch v := field.(type) {
+	case *Llnumeric:
+		return v.Value
+	case *Lllnumeric:
+		return v.Value
+	}
+	return ""
+}
+
+// trimZeroNibble removes filler nibble "0" of value decoded from whole
+// bytes
+func trimZeroNibble(value string, encode int) string {
+	switch {
+	case encode == BCD && strings.HasSuffix(value, "0"):
+		return value[:len(value)-1]
+	case encode == rBCD && strings.HasPrefix(value, "0"):
+		return value[1:]
+	}
+	return value
+}
@@ -0,0 +1,120 @@
+package iso8583
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestQuirks(t *testing.T) {
+	type test struct {
+		F2  *Llnumeric  `field:"2" length:"19" encode:"bcd,bcd" filler:"f"`
+		F35 *Lllnumeric `field:"35" length:"37" encode:"ascii,rbcd"`
+		F52 *Binary     `field:"52" length:"16"`
+	}
+	data := &test{
+		F2:  NewLlnumeric("427655555555555"),
+		F35: NewLllnumeric("1234"),
+		F52: NewBinary([]byte{1, 2, 3, 4, 5, 6, 7, 8}),
+	}
+	iso := NewMessage("0100", data)
+	iso.Quirks = QUIRK_LENGTH_IN_BYTES | QUIRK_BINARY_NIBBLES | QUIRK_SECOND_BITMAP | QUIRK_EBCDIC_MTI
+	res, err := iso.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "f0f1f0f0", fmt.Sprintf("%x", res[:4]))
+	assert.Equal(t, byte(0xc0), res[4])
+	assert.Equal(t, "08427655555555555f"+"303032"+"1234"+"0102030405060708", fmt.Sprintf("%x", res[20:]))
+
+	p := &Parser{Quirks: iso.Quirks}
+	p.Register("0100", &test{})
+	msg, err := p.Parse(res)
+	assert.Nil(t, err)
+	assert.Equal(t, "427655555555555", msg.Data.(*test).F2.Value)
+	assert.Equal(t, "1234", msg.Data.(*test).F35.Value)
+	assert.Equal(t, data.F52.Value, msg.Data.(*test).F52.Value)
+
+	assert.Equal(t, "length-in-bytes|ebcdic-mti|0x20", (QUIRK_LENGTH_IN_BYTES | QUIRK_EBCDIC_MTI | 0x20).String())
+}
+
+func TestLengthInBytesOddDigits(t *testing.T) {
+	type test struct {
+		F2  *Llnumeric  `field:"2" length:"19" encode:"bcd,rbcd" filler:"f"`
+		F35 *Lllnumeric `field:"35" length:"37" encode:"ascii,rbcd" filler:"f"`
+	}
+	data := &test{
+		F2:  NewLlnumeric("4276555555555"),
+		F35: NewLllnumeric("12345"),
+	}
+	iso := NewMessage("0100", data)
+	iso.Quirks = QUIRK_LENGTH_IN_BYTES
+	res, err := iso.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "07f4276555555555"+"303033f12345", fmt.Sprintf("%x", res[12:]))
+
+	iso2 := NewMessage("", &test{&Llnumeric{}, &Lllnumeric{}})
+	iso2.Quirks = iso.Quirks
+	assert.Nil(t, iso2.Load(res))
+	assert.Equal(t, data, iso2.Data)
+
+	// binary and ebcdic length heads
+	type heads struct {
+		F2  *Llnumeric  `field:"2" length:"19" encode:"binary,bcd" filler:"f"`
+		F35 *Lllnumeric `field:"35" length:"37" encode:"ebcdic,bcd" filler:"f"`
+	}
+	hdata := &heads{
+		F2:  NewLlnumeric("4276555555555"),
+		F35: NewLllnumeric("12345"),
+	}
+	iso = NewMessage("0100", hdata)
+	iso.Quirks = QUIRK_LENGTH_IN_BYTES
+	res, err = iso.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "074276555555555f"+"f0f0f312345f", fmt.Sprintf("%x", res[12:]))
+	iso2 = NewMessage("", &heads{&Llnumeric{}, &Lllnumeric{}})
+	iso2.Quirks = iso.Quirks
+	assert.Nil(t, iso2.Load(res))
+	assert.Equal(t, hdata, iso2.Data)
+
+	assert.Equal(t, "123", trimFillerNibble("123f", BCD))
+	assert.Equal(t, "123", trimFillerNibble("F123", rBCD))
+	assert.Equal(t, "0123", trimFillerNibble("0123", rBCD))
+	assert.Equal(t, "", trimFillerNibble("", BCD))
+}
+
+func TestLengthInBytesFillerNibble(t *testing.T) {
+	type test struct {
+		F2 *Llnumeric `field:"2" length:"19" encode:"bcd,bcd" filler:"f"`
+	}
+	type test0 struct {
+		F2 *Llnumeric `field:"2" length:"19" encode:"bcd,bcd"`
+	}
+
+	// odd length round trip with filler "f"
+	data := &test{F2: NewLlnumeric("4276555555555555558")}
+	iso := NewMessage("0100", data)
+	iso.Quirks = QUIRK_LENGTH_IN_BYTES
+	res, err := iso.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "104276555555555555558f", fmt.Sprintf("%x", res[12:]))
+	iso2 := NewMessage("", &test{&Llnumeric{}})
+	iso2.Quirks = iso.Quirks
+	assert.Nil(t, iso2.Load(res))
+	assert.Equal(t, data, iso2.Data)
+
+	// filler "0" of odd length value can't be told from a digit
+	iso = NewMessage("0100", &test0{F2: NewLlnumeric("4276555555555555558")})
+	iso.Quirks = QUIRK_LENGTH_IN_BYTES
+	_, err = iso.Bytes()
+	assert.Equal(t, ErrOddLengthInBytes, err)
+
+	// host pads value of maximum length with "0"
+	res[len(res)-1] &= 0xf0
+	iso2 = NewMessage("", &test0{&Llnumeric{}})
+	iso2.Quirks = QUIRK_LENGTH_IN_BYTES
+	assert.Nil(t, iso2.Load(res))
+	assert.Equal(t, "4276555555555555558", iso2.Data.(*test0).F2.Value)
+
+	// value longer than field length
+	res = append(res[:12], 0x11, 0x42, 0x76, 0x55, 0x55, 0x55, 0x55, 0x55, 0x55, 0x55, 0x58, 0x12)
+	assert.EqualError(t, iso2.Load(res), "field 2: length of value is longer than definition; type=Llnumeric, def_len=19, len=22")
+}
@@ -0,0 +1,50 @@
+package iso8583
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+)
+
+// Utilities which need randomness take source r, so tests can pass
+// deterministic reader and production code FIPS-approved DRBG. Nil r is
+// crypto/rand.Reader.
+
+// randReader returns r, or crypto/rand.Reader if r is nil
+func randReader(r io.Reader) io.Reader {
+	if r == nil {
+		return rand.Reader
+	}
+	return r
+}
+
+// NewNonce returns size random bytes from r, for ex. for key change
+// messages (see NewKeyChange)
+func NewNonce(r io.Reader, size int) ([]byte, error) {
+	b := make([]byte, size)
+	if _, err := io.ReadFull(randReader(r), b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// NewCorrelationID returns random 32 hex digits ID from r, which can be
+// set to CorrelationID of Message
+func NewCorrelationID(r io.Reader) (string, error) {
+	b, err := NewNonce(r, 16)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// NewRandomStanGenerator creates StanGenerator which starts at random STAN
+// from r, for hosts which reject sequences restarting from 000001
+func NewRandomStanGenerator(r io.Reader) (*StanGenerator, error) {
+	b, err := NewNonce(r, 4)
+	if err != nil {
+		return nil, err
+	}
+	return &StanGenerator{last: int(binary.BigEndian.Uint32(b) % 999999)}, nil
+}
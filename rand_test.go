@@ -0,0 +1,35 @@
+package iso8583
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRandom(t *testing.T) {
+	// 0x000003e8 is 1000
+	g, err := NewRandomStanGenerator(bytes.NewReader([]byte{0, 0, 0x03, 0xe8}))
+	assert.Nil(t, err)
+	assert.Equal(t, "001001", g.Next())
+	g, err = NewRandomStanGenerator(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff}))
+	assert.Nil(t, err)
+	assert.Equal(t, "971590", g.Next())
+
+	id, err := NewCorrelationID(bytes.NewReader(bytes.Repeat([]byte{0xab}, 16)))
+	assert.Nil(t, err)
+	assert.Equal(t, strings.Repeat("ab", 16), id)
+
+	nonce, err := NewNonce(nil, 8)
+	assert.Nil(t, err)
+	assert.Len(t, nonce, 8)
+	id, err = NewCorrelationID(nil)
+	assert.Nil(t, err)
+	assert.Len(t, id, 32)
+
+	_, err = NewNonce(bytes.NewReader([]byte{1, 2}), 8)
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+	_, err = NewRandomStanGenerator(bytes.NewReader(nil))
+	assert.Equal(t, io.EOF, err)
+}
@@ -0,0 +1,152 @@
+package iso8583
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	TAG_REPEAT string = "repeat"
+)
+
+// Repeating groups of subfields are members of slice of structs (or of
+// pointers to structs) type, for ex. `field:"48" repeat:"count2"`. Each
+// group is packed as body of composite field, groups follow one another and
+// the result is packed according to composite tag. Number of groups is set
+// by repeat tag:
+//
+//   - end - groups up to the end of field (default)
+//   - countN - number of groups is in N ascii digits before them, for ex. count2
+const (
+	REPEAT_END   string = "end"
+	REPEAT_COUNT string = "count"
+)
+
+// repeated is Iso8583Type for slice of structs with repeating groups
+type repeated struct {
+	value  reflect.Value
+	kind   string
+	digits int // digits of count of groups, 0 if groups are up to the end
+}
+
+// isStructSlice checks that v is slice of structs or of pointers to structs
+func isStructSlice(v reflect.Value) bool {
+	if v.Kind() != reflect.Slice {
+		return false
+	}
+	tp := v.Type().Elem()
+	if tp.Kind() == reflect.Ptr {
+		tp = tp.Elem()
+	}
+	return tp.Kind() == reflect.Struct
+}
+
+// parseRepeatStr returns digits of count of groups. It panics if str is
+// unknown.
+func parseRepeatStr(str string) int {
+	if str == "" || str == REPEAT_END {
+		return 0
+	}
+	if strings.HasPrefix(str, REPEAT_COUNT) {
+		if n, err := strconv.Atoi(str[len(REPEAT_COUNT):]); err == nil && n > 0 && n < 10 {
+			return n
+		}
+	}
+	panic("unknown repeat: " + str)
+}
+
+// newRepeated returns repeated for slice v with composite tag kind
+func newRepeated(v reflect.Value, kind, repeat string) *repeated {
+	r := &repeated{v, kind, parseRepeatStr(repeat)}
+	if _, bitmap := r.group(reflect.Value{}).options(); bitmap > 0 {
+		panic("repeating groups can't be bitmapped")
+	}
+	return r
+}
+
+// group returns composite of group g, which is nil or empty for new group
+func (r *repeated) group(g reflect.Value) *composite {
+	tp := r.value.Type().Elem()
+	switch {
+	case tp.Kind() == reflect.Struct && g.IsValid():
+		return &composite{g.Addr(), r.kind}
+	case tp.Kind() == reflect.Ptr && g.IsValid() && !g.IsNil():
+		return &composite{g, r.kind}
+	case tp.Kind() == reflect.Ptr:
+		tp = tp.Elem()
+	}
+	return &composite{reflect.New(tp), r.kind}
+}
+
+// IsEmpty checks if there are no groups
+func (r *repeated) IsEmpty() bool {
+	return r.value.Len() == 0
+}
+
+// Bytes encode groups to bytes
+func (r *repeated) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
+	n := r.value.Len()
+	body := make([]byte, 0, 64)
+	if r.digits > 0 {
+		count := fmt.Sprintf("%0*d", r.digits, n)
+		if len(count) > r.digits {
+			return nil, fmt.Errorf("%d groups don't fit in %d digits", n, r.digits)
+		}
+		body = append(body, count...)
+	}
+	for g := 0; g < n; g++ {
+		d, err := r.group(r.value.Index(g)).body()
+		if err != nil {
+			return nil, fmt.Errorf("group %d: %w", g, err)
+		}
+		body = append(body, d...)
+	}
+	return packComposite(r.kind, body, encoder, lenEncoder, length)
+}
+
+// Load decode groups from bytes, the slice is replaced
+func (r *repeated) Load(raw []byte, encoder, lenEncoder, length int) (int, error) {
+	body, read, err := loadComposite(r.kind, raw, encoder, lenEncoder, length)
+	if err != nil {
+		return 0, err
+	}
+
+	count, start := -1, 0
+	if r.digits > 0 {
+		if len(body) < r.digits {
+			return 0, ErrBadRaw
+		}
+		if count, err = strconv.Atoi(string(body[:r.digits])); err != nil {
+			return 0, ErrBadRaw
+		}
+		start = r.digits
+	}
+	groups := reflect.MakeSlice(r.value.Type(), 0, 4)
+	for g := 0; g != count && (count >= 0 || start < len(body)); g++ {
+		if start >= len(body) {
+			return 0, fmt.Errorf("group %d: %w", g, ErrBadRaw)
+		}
+		c := r.group(reflect.Value{})
+		initStruct(c.value.Type().Elem(), c.value)
+		l, err := c.loadBody(body[start:], true)
+		if err != nil {
+			return 0, fmt.Errorf("group %d: %w", g, err)
+		}
+		if l == 0 {
+			return 0, fmt.Errorf("group %d: %w", g, ErrBadRaw)
+		}
+		start += l
+		if r.value.Type().Elem().Kind() == reflect.Ptr {
+			groups = reflect.Append(groups, c.value)
+		} else {
+			groups = reflect.Append(groups, c.value.Elem())
+		}
+	}
+	if start != len(body) {
+		return 0, ErrBadRaw
+	}
+	r.value.Set(groups)
+	return read, nil
+}
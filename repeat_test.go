@@ -0,0 +1,80 @@
+package iso8583
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type testGroup struct {
+	S1 *Numeric      `field:"1" length:"2"`
+	S2 *Alphanumeric `field:"2" length:"3"`
+}
+
+func TestRepeated(t *testing.T) {
+	type test struct {
+		F3  *Numeric     `field:"3" length:"6"`
+		F48 []*testGroup `field:"48" length:"999" repeat:"count2"`
+		F62 []testGroup  `field:"62" length:"99" composite:"llvar"`
+	}
+
+	data := &test{
+		F3: NewNumeric("000000"),
+		F48: []*testGroup{
+			{NewNumeric("1"), NewAlphanumeric("ab")},
+			{NewNumeric("2"), nil},
+		},
+		F62: []testGroup{
+			{NewNumeric("3"), NewAlphanumeric("xyz")},
+		},
+	}
+	msg := NewMessage("0100", data)
+	res, err := msg.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "000000"+"012"+"02"+"01 ab"+"02   "+"05"+"03xyz", string(res[12:]))
+
+	msg2 := NewMessage("", &test{F3: &Numeric{}})
+	assert.Nil(t, msg2.Load(res))
+	assert.Equal(t, &test{
+		F3: NewNumeric("000000"),
+		F48: []*testGroup{
+			{NewNumeric("01"), NewAlphanumeric(" ab")},
+			{NewNumeric("02"), NewAlphanumeric("   ")},
+		},
+		F62: []testGroup{
+			{NewNumeric("03"), NewAlphanumeric("xyz")},
+		},
+	}, msg2.Data)
+
+	// groups are parsed by Parser too
+	p := &Parser{}
+	p.Register("0100", &test{})
+	parsed, err := p.Parse(res)
+	assert.Nil(t, err)
+	assert.Len(t, parsed.Data.(*test).F48, 2)
+
+	// empty slice is absent
+	res, err = NewMessage("0100", &test{F3: NewNumeric("1")}).Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "000001", string(res[12:]))
+
+	// count doesn't match groups
+	bad := []byte("0100" + "\x20\x00\x00\x00\x00\x01\x00\x00" + "000000" + "007" + "02" + "01 ab")
+	assert.EqualError(t, NewMessage("", &test{F3: &Numeric{}}).Load(bad), "field 48: group 1: bad raw data")
+
+	// data ends inside the last group
+	bad = []byte("0100" + "\x20\x00\x00\x00\x00\x00\x00\x04" + "000000" + "07" + "03xyz" + "04")
+	assert.EqualError(t, NewMessage("", &test{F3: &Numeric{}}).Load(bad), "field 62: group 1: subfield 2: bad raw data")
+
+	type test2 struct {
+		F48 []testGroup `field:"48" length:"999" repeat:"count1"`
+	}
+	_, err = NewMessage("0100", &test2{make([]testGroup, 10)}).Bytes()
+	assert.EqualError(t, err, "10 groups don't fit in 1 digits")
+
+	type test3 struct {
+		F48 []testGroup `field:"48" length:"999" repeat:"all"`
+	}
+	_, err = NewMessage("0100", &test3{make([]testGroup, 1)}).Bytes()
+	assert.ErrorIs(t, err, ErrInvalidTag)
+	assert.EqualError(t, err, "field 48: invalid tag: unknown repeat: all")
+}
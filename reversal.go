@@ -0,0 +1,88 @@
+package iso8583
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+const (
+	ERR_NOT_REVERSIBLE string = "message is not a reversible request"
+)
+
+var ErrNotReversible = errors.New(ERR_NOT_REVERSIBLE)
+
+// isReversible checks that mti is authorization or financial request
+// (x100, x200)
+func isReversible(mti string) bool {
+	return len(mti) == 4 && (mti[1] == '1' || mti[1] == '2') && mti[2] == '0'
+}
+
+// NewReversal builds reversal (x400) of authorization or financial request
+// orig: a copy of orig with field 90 (if defined as OriginalDataElements)
+// filled from orig. Reversal of repeat (for ex. 0201) reverses the original
+// request, so it is 0400 with original MTI 0200 in field 90.
+func NewReversal(orig *Message) (ret *Message, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("Critical error:" + fmt.Sprint(r))
+			ret = nil
+		}
+	}()
+
+	if !isReversible(orig.Mti) {
+		return nil, ErrNotReversible
+	}
+	mti := MtiOriginal(orig.Mti)
+	ret = orig.Clone()
+	ret.Mti = mti[:1] + "40" + mti[3:]
+	ret.raw = nil
+	ret.snap = nil
+
+	if sf, fv, ok := findField(ret.Data, 90); ok && sf.Type == reflect.TypeOf(&OriginalDataElements{}) {
+		ode, err := NewOriginalDataElements(orig)
+		if err != nil {
+			return nil, err
+		}
+		ode.Mti = mti
+		fv.Set(reflect.ValueOf(ode))
+	}
+	return ret, nil
+}
+
+// AutoReversal sends requests with timeout and queues reversal of a
+// request into SAF when its response doesn't come in time
+type AutoReversal struct {
+	SAF     *SAF
+	Timeout time.Duration
+	// Decorate is called with reversal before queueing it, optional. It
+	// can change the reversal or veto it by returning false.
+	Decorate func(orig, reversal *Message) bool
+}
+
+// Send calls send with req and ctx limited by Timeout. If it fails with
+// context.DeadlineExceeded because of Timeout, reversal of req is
+// submitted to SAF. Error of send is returned in any case.
+func (a *AutoReversal) Send(ctx context.Context, req *Message, send func(ctx context.Context, req *Message) (*Message, error)) (*Message, error) {
+	tctx, cancel := context.WithTimeout(ctx, a.Timeout)
+	defer cancel()
+
+	resp, err := send(tctx, req)
+	if err == nil || !errors.Is(err, context.DeadlineExceeded) || ctx.Err() != nil || !isReversible(req.Mti) {
+		return resp, err
+	}
+
+	reversal, rerr := NewReversal(req)
+	if rerr != nil {
+		return nil, fmt.Errorf("%w; reversal: %s", err, rerr)
+	}
+	if a.Decorate != nil && !a.Decorate(req, reversal) {
+		return nil, err
+	}
+	if rerr := a.SAF.Submit(ctx, reversal); rerr != nil {
+		return nil, fmt.Errorf("%w; reversal: %s", err, rerr)
+	}
+	return nil, err
+}
@@ -0,0 +1,94 @@
+package iso8583
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNewReversal(t *testing.T) {
+	type auth struct {
+		F7  *Numeric              `field:"7" length:"10"`
+		F11 *Numeric              `field:"11" length:"6"`
+		F32 *Llnumeric            `field:"32" length:"11"`
+		F90 *OriginalDataElements `field:"90" length:"42"`
+	}
+	orig := NewMessage("0200", &auth{
+		F7:  NewNumeric("1015120000"),
+		F11: NewNumeric("000123"),
+		F32: NewLlnumeric("12345"),
+	})
+	rev, err := NewReversal(orig)
+	assert.Nil(t, err)
+	assert.Equal(t, "0400", rev.Mti)
+	data := rev.Data.(*auth)
+	assert.Equal(t, "000123", data.F11.Value)
+	assert.Equal(t, &OriginalDataElements{"0200", "000123", "1015120000", "12345", ""}, data.F90)
+	assert.Nil(t, orig.Data.(*auth).F90)
+
+	// repeat is reversed as the original request
+	orig.Mti = "0201"
+	rev, err = NewReversal(orig)
+	assert.Nil(t, err)
+	assert.Equal(t, "0400", rev.Mti)
+	assert.Equal(t, "0200", rev.Data.(*auth).F90.Mti)
+
+	orig.Mti = "0103"
+	rev, err = NewReversal(orig)
+	assert.Nil(t, err)
+	assert.Equal(t, "0402", rev.Mti)
+	assert.Equal(t, "0102", rev.Data.(*auth).F90.Mti)
+
+	_, err = NewReversal(NewMessage("0210", &auth{}))
+	assert.Equal(t, ErrNotReversible, err)
+}
+
+func TestAutoReversal(t *testing.T) {
+	var queued []string
+	saf := &SAF{
+		Store: &MemorySAFStore{},
+		Send: func(ctx context.Context, e *SAFEntry) error {
+			queued = append(queued, e.Mti)
+			return nil
+		},
+	}
+	vetoed := false
+	a := &AutoReversal{
+		SAF:     saf,
+		Timeout: time.Millisecond,
+		Decorate: func(orig, reversal *Message) bool {
+			return !vetoed
+		},
+	}
+	timeout := func(ctx context.Context, req *Message) (*Message, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	req := NewMessage("0200", &testTransaction{F11: NewNumeric("000001")})
+
+	_, err := a.Send(context.Background(), req, timeout)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, []string{"0400"}, queued)
+
+	vetoed = true
+	_, err = a.Send(context.Background(), req, timeout)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, 1, len(queued))
+
+	vetoed = false
+	fail := errors.New("connection reset")
+	_, err = a.Send(context.Background(), req, func(ctx context.Context, req *Message) (*Message, error) {
+		return nil, fail
+	})
+	assert.Equal(t, fail, err)
+	assert.Equal(t, 1, len(queued))
+
+	resp, err := a.Send(context.Background(), req, func(ctx context.Context, req *Message) (*Message, error) {
+		return NewMessage("0210", &testTransaction{}), nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "0210", resp.Mti)
+	assert.Equal(t, 1, len(queued))
+}
@@ -0,0 +1,149 @@
+package iso8583
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"strings"
+	"testing"
+	"testing/quick"
+)
+
+// randomDigits returns random numeric string with length in [0, max]
+func randomDigits(r *rand.Rand, max int) string {
+	b := make([]byte, r.Intn(max+1))
+	for i := range b {
+		b[i] = byte('0' + r.Intn(10))
+	}
+	return string(b)
+}
+
+// randomBytes returns random bytes with length in [0, max]
+func randomBytes(r *rand.Rand, max int) []byte {
+	b := make([]byte, r.Intn(max+1))
+	r.Read(b)
+	return b
+}
+
+var (
+	allEncoders    = []int{ASCII, BCD, rBCD}
+	lengthEncoders = []int{ASCII, BCD, rBCD}
+)
+
+func TestRoundTripNumeric(t *testing.T) {
+	for _, enc := range allEncoders {
+		f := func(seed int64) bool {
+			r := rand.New(rand.NewSource(seed))
+			length := 1 + r.Intn(30)
+			val := randomDigits(r, length)
+			b, err := NewNumeric(val).Bytes(enc, ASCII, length)
+			if err != nil {
+				return false
+			}
+			n := &Numeric{}
+			read, err := n.Load(b, enc, ASCII, length)
+			expected := strings.Repeat("0", length-len(val)) + val
+			return err == nil && read == len(b) && n.Value == expected
+		}
+		assert.Nil(t, quick.Check(f, nil), "encoder %d", enc)
+	}
+}
+
+func TestRoundTripAlphanumericBinary(t *testing.T) {
+	f := func(seed int64) bool {
+		r := rand.New(rand.NewSource(seed))
+		length := 1 + r.Intn(50)
+		val := randomDigits(r, length)
+		b, err := NewAlphanumeric(strings.Repeat("a", length-len(val))+val).Bytes(ASCII, ASCII, length)
+		if err != nil {
+			return false
+		}
+		a := &Alphanumeric{}
+		read, err := a.Load(b, ASCII, ASCII, length)
+		if err != nil || read != length || a.Value != string(b) {
+			return false
+		}
+
+		raw := randomBytes(r, length)
+		b, err = NewBinary(raw).Bytes(ASCII, ASCII, length)
+		if err != nil {
+			return false
+		}
+		bin := NewBinary(nil)
+		read, err = bin.Load(b, ASCII, ASCII, length)
+		return err == nil && read == length && string(bin.Value[:len(raw)]) == string(raw)
+	}
+	assert.Nil(t, quick.Check(f, nil))
+}
+
+func TestRoundTripVar(t *testing.T) {
+	for _, lenEnc := range lengthEncoders {
+		f := func(seed int64) bool {
+			r := rand.New(rand.NewSource(seed))
+
+			val := randomBytes(r, 99)
+			b, err := NewLlvar(val).Bytes(ASCII, lenEnc, 99)
+			if err != nil {
+				return false
+			}
+			ll := &Llvar{}
+			read, err := ll.Load(b, ASCII, lenEnc, 99)
+			if err != nil || read != len(b) || string(ll.Value) != string(val) {
+				return false
+			}
+
+			val = randomBytes(r, 999)
+			b, err = NewLllvar(val).Bytes(ASCII, lenEnc, 999)
+			if err != nil {
+				return false
+			}
+			lll := &Lllvar{}
+			read, err = lll.Load(b, ASCII, lenEnc, 999)
+			return err == nil && read == len(b) && string(lll.Value) == string(val)
+		}
+		assert.Nil(t, quick.Check(f, nil), "length encoder %d", lenEnc)
+	}
+}
+
+func TestRoundTripVarNumeric(t *testing.T) {
+	for _, lenEnc := range lengthEncoders {
+		for _, enc := range allEncoders {
+			f := func(seed int64) bool {
+				r := rand.New(rand.NewSource(seed))
+
+				val := randomDigits(r, 99)
+				b, err := NewLlnumeric(val).Bytes(enc, lenEnc, 99)
+				if err != nil {
+					return false
+				}
+				ll := &Llnumeric{}
+				read, err := ll.Load(b, enc, lenEnc, 99)
+				if err != nil || read != len(b) || ll.Value != val {
+					return false
+				}
+
+				val = randomDigits(r, 999)
+				b, err = NewLllnumeric(val).Bytes(enc, lenEnc, 999)
+				if err != nil {
+					return false
+				}
+				lll := &Lllnumeric{}
+				read, err = lll.Load(b, enc, lenEnc, 999)
+				return err == nil && read == len(b) && lll.Value == val
+			}
+			assert.Nil(t, quick.Check(f, nil), "encoder %d, length encoder %d", enc, lenEnc)
+		}
+	}
+}
+
+func TestLllnumericBCDLengthHead(t *testing.T) {
+	val := strings.Repeat("1", 123)
+	b, err := NewLllnumeric(val).Bytes(ASCII, BCD, 999)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x01, 0x23}, b[:2])
+
+	l := &Lllnumeric{}
+	read, err := l.Load(b, ASCII, BCD, 999)
+	assert.Nil(t, err)
+	assert.Equal(t, 125, read)
+	assert.Equal(t, val, l.Value)
+}
@@ -0,0 +1,128 @@
+package iso8583
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const (
+	ERR_NO_ROUTE string = "no route for message"
+)
+
+var ErrNoRoute = errors.New(ERR_NO_ROUTE)
+
+// HandlerFunc handles request message and returns response message (nil if
+// there is no response, for ex. for advice without acknowledgement)
+type HandlerFunc func(ctx context.Context, req *Message) (*Message, error)
+
+// Middleware wraps HandlerFunc, for ex. for logging or MAC check
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Router dispatches messages to handlers by MTI, processing code (field 3)
+// prefix and function code (field 24). The most specific route wins: with
+// longer processing code prefix, then with function code.
+type Router struct {
+	mu         sync.RWMutex
+	routes     []route
+	middleware []Middleware
+}
+
+type route struct {
+	mti            string
+	processingCode string
+	functionCode   string
+	handler        HandlerFunc
+}
+
+// Handle registers handler for MTI mti. Empty processingCode (prefix of
+// field 3) or functionCode (field 24) matches any value.
+func (r *Router) Handle(mti, processingCode, functionCode string, h HandlerFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routes = append(r.routes, route{mti, processingCode, functionCode, h})
+}
+
+// Use adds middleware applied to all handlers, in order of adding (the
+// first one is outermost)
+func (r *Router) Use(mw ...Middleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.middleware = append(r.middleware, mw...)
+}
+
+// Serve dispatches req to matching handler through middleware. It returns
+// ErrNoRoute if there is no matching handler. Handling is traced, see
+// SetTracer.
+func (r *Router) Serve(ctx context.Context, req *Message) (resp *Message, err error) {
+	ctx, span := startSpan(ctx, OpReceive)
+	defer func() { endExchangeSpan(span, req, resp, err) }()
+
+	r.mu.RLock()
+	h, err := r.match(req)
+	middleware := r.middleware
+	r.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	for i := len(middleware) - 1; i >= 0; i-- {
+		h = middleware[i](h)
+	}
+	resp, err = h(ctx, req)
+	if resp != nil && resp.CorrelationID == "" {
+		resp.CorrelationID = req.CorrelationID
+	}
+	return resp, err
+}
+
+func (r *Router) match(req *Message) (h HandlerFunc, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = errors.New("Critical error:" + fmt.Sprint(rec))
+			h = nil
+		}
+	}()
+
+	var processingCode, functionCode string
+	fields, err := parseFields(req.Data)
+	if err != nil {
+		return nil, err
+	}
+	if info, ok := fields[3]; ok {
+		processingCode = fieldValue(info.Field)
+	}
+	if info, ok := fields[24]; ok {
+		functionCode = fieldValue(info.Field)
+	}
+
+	var best *route
+	for i := range r.routes {
+		rt := &r.routes[i]
+		if rt.mti != req.Mti ||
+			!strings.HasPrefix(processingCode, rt.processingCode) ||
+			(rt.functionCode != "" && rt.functionCode != functionCode) {
+			continue
+		}
+		if best == nil || len(rt.processingCode) > len(best.processingCode) ||
+			(len(rt.processingCode) == len(best.processingCode) && best.functionCode == "" && rt.functionCode != "") {
+			best = rt
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("%w: MTI %s, processing code %q", ErrNoRoute, req.Mti, processingCode)
+	}
+	return best.handler, nil
+}
+
+// ValidateRequests is Middleware which checks requests with ValidateFor
+// before passing them to handler
+func ValidateRequests(next HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, req *Message) (*Message, error) {
+		if err := req.ValidateFor(req.Mti); err != nil {
+			return nil, err
+		}
+		return next(ctx, req)
+	}
+}
@@ -0,0 +1,72 @@
+package iso8583
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRouter(t *testing.T) {
+	named := func(name string) HandlerFunc {
+		return func(ctx context.Context, req *Message) (*Message, error) {
+			return NewMessage("0210", &testTransaction{F41: NewAlphanumeric(name)}), nil
+		}
+	}
+	r := &Router{}
+	r.Handle("0200", "", "", named("any"))
+	r.Handle("0200", "01", "", named("cash"))
+	r.Handle("0200", "0130", "", named("cash-savings"))
+	r.Handle("0800", "", "301", named("echo"))
+
+	var trace []string
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *Message) (*Message, error) {
+			trace = append(trace, "outer")
+			return next(ctx, req)
+		}
+	}, func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *Message) (*Message, error) {
+			trace = append(trace, "inner")
+			return next(ctx, req)
+		}
+	})
+
+	serve := func(mti, pc string) string {
+		resp, err := r.Serve(context.Background(), NewMessage(mti, &testTransaction{F3: NewNumeric(pc)}))
+		if err != nil {
+			return err.Error()
+		}
+		return resp.Data.(*testTransaction).F41.Value
+	}
+	assert.Equal(t, "any", serve("0200", "000000"))
+	assert.Equal(t, "cash", serve("0200", "011000"))
+	assert.Equal(t, "cash-savings", serve("0200", "013000"))
+	assert.Equal(t, `no route for message: MTI 0400, processing code "000000"`, serve("0400", "000000"))
+	assert.Equal(t, []string{"outer", "inner", "outer", "inner", "outer", "inner"}, trace)
+
+	type nmm struct {
+		F24 *Numeric `field:"24" length:"3"`
+	}
+	_, err := r.Serve(context.Background(), NewMessage("0800", &nmm{NewNumeric("302")}))
+	assert.True(t, errors.Is(err, ErrNoRoute))
+	resp, err := r.Serve(context.Background(), NewMessage("0800", &nmm{NewNumeric("301")}))
+	assert.Nil(t, err)
+	assert.Equal(t, "echo", resp.Data.(*testTransaction).F41.Value)
+}
+
+func TestValidateRequests(t *testing.T) {
+	type auth struct {
+		F3  *Numeric `field:"3" length:"6" mti:"0200:M"`
+		F11 *Numeric `field:"11" length:"6"`
+	}
+	r := &Router{}
+	r.Use(ValidateRequests)
+	r.Handle("0200", "", "", func(ctx context.Context, req *Message) (*Message, error) {
+		return nil, nil
+	})
+	_, err := r.Serve(context.Background(), NewMessage("0200", &auth{F11: NewNumeric("000001")}))
+	assert.EqualError(t, err, "field 3 is mandatory for MTI 0200")
+	_, err = r.Serve(context.Background(), NewMessage("0200", &auth{F3: NewNumeric("000000")}))
+	assert.Nil(t, err)
+}
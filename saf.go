@@ -0,0 +1,279 @@
+package iso8583
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	ERR_NOT_ADVICE        string = "message is not an advice or reversal"
+	ERR_SAF_MAX_RETRIES   string = "max retries exceeded"
+	ERR_SAF_EXPIRED       string = "advice expired"
+	ERR_SAF_NOT_FOUND     string = "SAF entry not found"
+	ERR_SAF_MISSING_SEND  string = "SAF send function is not set"
+	ERR_SAF_MISSING_STORE string = "SAF store is not set"
+)
+
+var (
+	ErrNotAdvice       = errors.New(ERR_NOT_ADVICE)
+	ErrSAFMaxRetries   = errors.New(ERR_SAF_MAX_RETRIES)
+	ErrSAFExpired      = errors.New(ERR_SAF_EXPIRED)
+	ErrSAFNotFound     = errors.New(ERR_SAF_NOT_FOUND)
+	ErrSAFMissingSend  = errors.New(ERR_SAF_MISSING_SEND)
+	ErrSAFMissingStore = errors.New(ERR_SAF_MISSING_STORE)
+)
+
+// SAFEntry is an advice waiting in store-and-forward queue. Message is
+// kept packed, so persistent stores can save it as is.
+type SAFEntry struct {
+	ID          uint64
+	Mti         string
+	Raw         []byte
+	Attempts    int
+	Created     time.Time
+	NextAttempt time.Time
+}
+
+// SAFStore persists SAF entries. Entries must be returned in order of
+// adding. Implementations must be safe for concurrent use.
+type SAFStore interface {
+	// Add saves new entry and sets its ID
+	Add(e *SAFEntry) error
+	// Update saves changed Attempts and NextAttempt of entry
+	Update(e *SAFEntry) error
+	// Remove deletes entry
+	Remove(id uint64) error
+	// Entries returns all entries
+	Entries() ([]*SAFEntry, error)
+}
+
+// MemorySAFStore is in-memory SAFStore implementation, safe for concurrent
+// use
+type MemorySAFStore struct {
+	mu      sync.Mutex
+	lastID  uint64
+	entries []*SAFEntry
+}
+
+// Add saves entry in memory
+func (s *MemorySAFStore) Add(e *SAFEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastID++
+	e.ID = s.lastID
+	c := *e
+	s.entries = append(s.entries, &c)
+	return nil
+}
+
+// Update saves entry in memory
+func (s *MemorySAFStore) Update(e *SAFEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, old := range s.entries {
+		if old.ID == e.ID {
+			c := *e
+			s.entries[i] = &c
+			return nil
+		}
+	}
+	return ErrSAFNotFound
+}
+
+// Remove deletes entry from memory
+func (s *MemorySAFStore) Remove(id uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, e := range s.entries {
+		if e.ID == id {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			return nil
+		}
+	}
+	return ErrSAFNotFound
+}
+
+// Entries returns copies of all entries
+func (s *MemorySAFStore) Entries() ([]*SAFEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ret := make([]*SAFEntry, len(s.entries))
+	for i, e := range s.entries {
+		c := *e
+		ret[i] = &c
+	}
+	return ret, nil
+}
+
+// SAF is store-and-forward queue of advices and reversals: those which
+// fail to send are saved in Store and retried by Flush in order of
+// submitting.
+type SAF struct {
+	Store SAFStore
+	// Send sends packed advice and waits for its acknowledgement
+	Send func(ctx context.Context, e *SAFEntry) error
+	// MaxRetries is maximum number of attempts, 0 means no limit
+	MaxRetries int
+	// Expiry is maximum age of entry, 0 means no limit
+	Expiry time.Duration
+	// RetryDelay returns delay before next attempt after attempts failed
+	// ones, default is 30 seconds doubled for each attempt up to 1 hour
+	RetryDelay func(attempts int) time.Duration
+	// OnDrop is called when entry is dropped with ErrSAFMaxRetries or
+	// ErrSAFExpired, optional
+	OnDrop func(e *SAFEntry, reason error)
+
+	mu   sync.Mutex
+	busy bool // entry is being sent
+}
+
+// Submit packs advice or reversal msg and sends it. If sending fails, the
+// message is saved for retrying and nil is returned, or it is dropped if
+// MaxRetries is 1.
+func (s *SAF) Submit(ctx context.Context, msg *Message) error {
+	if !isReversalOrAdvice(msg.Mti) {
+		return ErrNotAdvice
+	}
+	if err := s.check(); err != nil {
+		return err
+	}
+	raw, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	e := &SAFEntry{Mti: msg.Mti, Raw: raw, Created: now, NextAttempt: now}
+
+	// keep order: send directly only if queue is empty and nothing is
+	// being sent, otherwise the entry waits for Flush
+	s.mu.Lock()
+	entries, err := s.Store.Entries()
+	if err == nil {
+		err = s.Store.Add(e)
+	}
+	direct := err == nil && len(entries) == 0 && !s.busy
+	s.busy = s.busy || direct
+	s.mu.Unlock()
+	if !direct {
+		return err
+	}
+	defer s.release()
+	_, err = s.attempt(ctx, e, now)
+	return err
+}
+
+// Flush sends due entries in order. It stops on the first failed attempt,
+// so advices are not reordered, and returns error of ctx or store only.
+// Flush returns nil at once if an entry is being sent by Submit or other
+// Flush.
+func (s *SAF) Flush(ctx context.Context) error {
+	if err := s.check(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	if s.busy {
+		s.mu.Unlock()
+		return nil
+	}
+	s.busy = true
+	s.mu.Unlock()
+	defer s.release()
+
+	entries, err := s.Store.Entries()
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		now := time.Now()
+		if s.Expiry > 0 && now.Sub(e.Created) > s.Expiry {
+			if err := s.drop(e, ErrSAFExpired); err != nil {
+				return err
+			}
+			continue
+		}
+		if now.Before(e.NextAttempt) {
+			return nil
+		}
+		if done, err := s.attempt(ctx, e, now); err != nil || !done {
+			return err
+		}
+	}
+	return nil
+}
+
+// attempt sends stored entry e. Entry is removed if it is sent, dropped
+// if it is out of retries or updated for the next attempt otherwise. It
+// returns true if entry is done, so the next one can be sent.
+func (s *SAF) attempt(ctx context.Context, e *SAFEntry, now time.Time) (bool, error) {
+	if err := s.Send(ctx, e); err == nil {
+		return true, s.Store.Remove(e.ID)
+	}
+	e.Attempts++
+	if s.MaxRetries > 0 && e.Attempts >= s.MaxRetries {
+		return true, s.drop(e, ErrSAFMaxRetries)
+	}
+	e.NextAttempt = now.Add(s.retryDelay(e.Attempts))
+	return false, s.Store.Update(e)
+}
+
+func (s *SAF) check() error {
+	if s.Store == nil {
+		return ErrSAFMissingStore
+	}
+	if s.Send == nil {
+		return ErrSAFMissingSend
+	}
+	return nil
+}
+
+func (s *SAF) release() {
+	s.mu.Lock()
+	s.busy = false
+	s.mu.Unlock()
+}
+
+// Run calls Flush every interval until ctx is done and returns ctx.Err()
+// or error of Flush
+func (s *SAF) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := s.Flush(ctx); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *SAF) drop(e *SAFEntry, reason error) error {
+	if err := s.Store.Remove(e.ID); err != nil {
+		return err
+	}
+	if s.OnDrop != nil {
+		s.OnDrop(e, reason)
+	}
+	return nil
+}
+
+func (s *SAF) retryDelay(attempts int) time.Duration {
+	if s.RetryDelay != nil {
+		return s.RetryDelay(attempts)
+	}
+	d := 30 * time.Second
+	for i := 1; i < attempts && d < time.Hour; i++ {
+		d *= 2
+	}
+	if d > time.Hour {
+		d = time.Hour
+	}
+	return d
+}
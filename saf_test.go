@@ -0,0 +1,129 @@
+package iso8583
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSAF(t *testing.T) {
+	down := true
+	var sent []string
+	var dropped []error
+	saf := &SAF{
+		Store: &MemorySAFStore{},
+		Send: func(ctx context.Context, e *SAFEntry) error {
+			if down {
+				return errors.New("link is down")
+			}
+			msg := NewMessage("", &testTransaction{F11: &Numeric{}})
+			assert.Nil(t, msg.Load(e.Raw))
+			sent = append(sent, msg.Data.(*testTransaction).F11.Value)
+			return nil
+		},
+		MaxRetries: 3,
+		RetryDelay: func(attempts int) time.Duration { return 0 },
+		OnDrop:     func(e *SAFEntry, reason error) { dropped = append(dropped, reason) },
+	}
+	ctx := context.Background()
+
+	err := saf.Submit(ctx, NewMessage("0200", &testTransaction{F11: NewNumeric("000001")}))
+	assert.Equal(t, ErrNotAdvice, err)
+
+	assert.Nil(t, saf.Submit(ctx, NewMessage("0220", &testTransaction{F11: NewNumeric("000001")})))
+	assert.Nil(t, saf.Submit(ctx, NewMessage("0420", &testTransaction{F11: NewNumeric("000002")})))
+	entries, _ := saf.Store.Entries()
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, 1, entries[0].Attempts)
+	assert.Equal(t, 0, entries[1].Attempts)
+
+	assert.Nil(t, saf.Flush(ctx))
+	entries, _ = saf.Store.Entries()
+	assert.Equal(t, 2, entries[0].Attempts)
+	assert.Equal(t, 0, entries[1].Attempts)
+
+	down = false
+	assert.Nil(t, saf.Flush(ctx))
+	assert.Equal(t, []string{"000001", "000002"}, sent)
+	entries, _ = saf.Store.Entries()
+	assert.Equal(t, 0, len(entries))
+
+	// sent directly when queue is empty
+	assert.Nil(t, saf.Submit(ctx, NewMessage("0220", &testTransaction{F11: NewNumeric("000003")})))
+	assert.Equal(t, "000003", sent[2])
+
+	down = true
+	assert.Nil(t, saf.Submit(ctx, NewMessage("0220", &testTransaction{F11: NewNumeric("000004")})))
+	assert.Nil(t, saf.Flush(ctx))
+	assert.Nil(t, saf.Flush(ctx))
+	assert.Equal(t, []error{ErrSAFMaxRetries}, dropped)
+
+	saf.Expiry = time.Nanosecond
+	assert.Nil(t, saf.Submit(ctx, NewMessage("0220", &testTransaction{F11: NewNumeric("000005")})))
+	time.Sleep(time.Millisecond)
+	assert.Nil(t, saf.Flush(ctx))
+	assert.Equal(t, []error{ErrSAFMaxRetries, ErrSAFExpired}, dropped)
+	entries, _ = saf.Store.Entries()
+	assert.Equal(t, 0, len(entries))
+}
+
+func TestSAFRetryDelay(t *testing.T) {
+	saf := &SAF{}
+	assert.Equal(t, 30*time.Second, saf.retryDelay(1))
+	assert.Equal(t, 2*time.Minute, saf.retryDelay(3))
+	assert.Equal(t, time.Hour, saf.retryDelay(20))
+}
+
+func TestSAFSubmit(t *testing.T) {
+	ctx := context.Background()
+	msg := NewMessage("0220", &testTransaction{F11: NewNumeric("000001")})
+
+	saf := &SAF{Send: func(ctx context.Context, e *SAFEntry) error { return nil }}
+	assert.Equal(t, ErrSAFMissingStore, saf.Submit(ctx, msg))
+	assert.Equal(t, ErrSAFMissingStore, saf.Flush(ctx))
+
+	// entry is dropped after the first attempt with MaxRetries 1
+	var dropped []error
+	saf = &SAF{
+		Store:      &MemorySAFStore{},
+		Send:       func(ctx context.Context, e *SAFEntry) error { return errors.New("link is down") },
+		MaxRetries: 1,
+		OnDrop:     func(e *SAFEntry, reason error) { dropped = append(dropped, reason) },
+	}
+	assert.Nil(t, saf.Submit(ctx, msg))
+	assert.Equal(t, []error{ErrSAFMaxRetries}, dropped)
+	entries, _ := saf.Store.Entries()
+	assert.Equal(t, 0, len(entries))
+
+	// lock is not held while sending, so other advices are queued
+	sending, release := make(chan struct{}), make(chan struct{})
+	var sent []string
+	saf = &SAF{
+		Store: &MemorySAFStore{},
+		Send: func(ctx context.Context, e *SAFEntry) error {
+			m := NewMessage("", &testTransaction{F11: &Numeric{}})
+			assert.Nil(t, m.Load(e.Raw))
+			if len(sent) == 0 {
+				close(sending)
+				<-release
+			}
+			sent = append(sent, m.Data.(*testTransaction).F11.Value)
+			return nil
+		},
+	}
+	done := make(chan error)
+	go func() { done <- saf.Submit(ctx, msg) }()
+	<-sending
+	assert.Nil(t, saf.Submit(ctx, NewMessage("0220", &testTransaction{F11: NewNumeric("000002")})))
+	assert.Nil(t, saf.Flush(ctx))
+	entries, _ = saf.Store.Entries()
+	assert.Equal(t, 2, len(entries))
+	close(release)
+	assert.Nil(t, <-done)
+	assert.Nil(t, saf.Flush(ctx))
+	assert.Equal(t, []string{"000001", "000002"}, sent)
+	entries, _ = saf.Store.Entries()
+	assert.Equal(t, 0, len(entries))
+}
@@ -0,0 +1,187 @@
+package iso8583
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	ERR_SEQUENCE_CONFLICT string = "sequence was changed concurrently"
+)
+
+var ErrSequenceConflict = errors.New(ERR_SEQUENCE_CONFLICT)
+
+// SequenceStore persists last values of named sequences, for ex. STAN and
+// batch number, so terminals resume them after restart
+type SequenceStore interface {
+	// Load returns last value of sequence, 0 if it is not stored
+	Load(name string) (uint64, error)
+	// Store sets sequence to value if its last value is still old,
+	// otherwise it returns ErrSequenceConflict
+	Store(name string, old, value uint64) error
+}
+
+// MemorySequenceStore is in-memory SequenceStore implementation, safe for
+// concurrent use
+type MemorySequenceStore struct {
+	mu     sync.Mutex
+	values map[string]uint64
+}
+
+// Load returns last value of sequence from memory
+func (s *MemorySequenceStore) Load(name string) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.values[name], nil
+}
+
+// Store sets sequence in memory
+func (s *MemorySequenceStore) Store(name string, old, value uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.values[name] != old {
+		return ErrSequenceConflict
+	}
+	if s.values == nil {
+		s.values = make(map[string]uint64)
+	}
+	s.values[name] = value
+	return nil
+}
+
+// FileSequenceStore is SequenceStore keeping sequences in a text file,
+// one "name value" line per sequence. The file is replaced atomically on
+// each Store, so it is never left half-written. It is safe for concurrent
+// use; processes sharing the file are serialized by flock of Path+".lock"
+// on Unix, on other systems only one process may use the file.
+type FileSequenceStore struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+// NewFileSequenceStore creates new FileSequenceStore, the file is created
+// on first Store
+func NewFileSequenceStore(path string) *FileSequenceStore {
+	return &FileSequenceStore{Path: path}
+}
+
+// Load returns last value of sequence from the file
+func (s *FileSequenceStore) Load(name string) (uint64, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+	values, err := s.read()
+	if err != nil {
+		return 0, err
+	}
+	return values[name], nil
+}
+
+// Store sets sequence in the file
+func (s *FileSequenceStore) Store(name string, old, value uint64) error {
+	if name == "" || strings.ContainsAny(name, " \n") {
+		return fmt.Errorf("bad sequence name: %q", name)
+	}
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	values, err := s.read()
+	if err != nil {
+		return err
+	}
+	if values[name] != old {
+		return ErrSequenceConflict
+	}
+	values[name] = value
+	return s.write(values)
+}
+
+// lock locks the file for this and other processes, it returns function
+// which unlocks it
+func (s *FileSequenceStore) lock() (func(), error) {
+	s.mu.Lock()
+	unlock, err := lockFile(s.Path + ".lock")
+	if err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	return func() {
+		unlock()
+		s.mu.Unlock()
+	}, nil
+}
+
+// read reads all sequences from the file
+func (s *FileSequenceStore) read() (map[string]uint64, error) {
+	values := make(map[string]uint64)
+	data, err := os.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; sc.Scan(); line++ {
+		parts := strings.Fields(sc.Text())
+		if len(parts) == 0 {
+			continue
+		}
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%s:%d: bad sequence line", s.Path, line)
+		}
+		v, err := strconv.ParseUint(parts[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: bad sequence value: %s", s.Path, line, parts[1])
+		}
+		values[parts[0]] = v
+	}
+	return values, nil
+}
+
+// write replaces the file with sequences via temporary file
+func (s *FileSequenceStore) write(values map[string]uint64) error {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var buf bytes.Buffer
+	for _, name := range names {
+		fmt.Fprintf(&buf, "%s %d\n", name, values[name])
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.Path); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(s.Path))
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package iso8583
+
+// lockFile does nothing, only one process may use FileSequenceStore
+func lockFile(path string) (func(), error) {
+	return func() {}, nil
+}
+
+// syncDir does nothing, directories can't be synced on this system
+func syncDir(dir string) error {
+	return nil
+}
@@ -0,0 +1,96 @@
+package iso8583
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStoredStanGenerator(t *testing.T) {
+	store := &MemorySequenceStore{}
+	stan := NewStoredStanGenerator(store)
+	s, err := stan.Generate()
+	assert.Nil(t, err)
+	assert.Equal(t, "000001", s)
+	assert.Equal(t, ErrSequenceConflict, store.Store("stan", 0, 5))
+	assert.Nil(t, store.Store("stan", 1, 999999))
+	s, err = stan.Generate()
+	assert.Nil(t, err)
+	assert.Equal(t, "000001", s)
+
+	batch := NewBatchGenerator(store)
+	s, err = batch.Generate()
+	assert.Nil(t, err)
+	assert.Equal(t, "001", s)
+	assert.Equal(t, "001", batch.Last())
+
+	// generators sharing the store don't reuse values
+	other := NewStoredStanGenerator(store)
+	var wg sync.WaitGroup
+	seen := sync.Map{}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(g *StanGenerator) {
+			defer wg.Done()
+			s, err := g.Generate()
+			assert.Nil(t, err)
+			_, dup := seen.LoadOrStore(s, true)
+			assert.False(t, dup)
+		}([]*StanGenerator{stan, other}[i%2])
+	}
+	wg.Wait()
+	last, err := store.Load("stan")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(51), last)
+
+	data := &NetworkManagement{}
+	got, err := stan.Stamp(NewMessage("0800", data), time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, "000052", got)
+}
+
+func TestFileSequenceStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seq")
+	stan := NewStoredStanGenerator(NewFileSequenceStore(path))
+	for i := 0; i < 3; i++ {
+		_, err := stan.Generate()
+		assert.Nil(t, err)
+	}
+	_, err := NewBatchGenerator(stan.Store).Generate()
+	assert.Nil(t, err)
+
+	// restart
+	stan = NewStoredStanGenerator(NewFileSequenceStore(path))
+	s, err := stan.Generate()
+	assert.Nil(t, err)
+	assert.Equal(t, "000004", s)
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "batch 1\nstan 4\n", string(data))
+
+	// stores of the same file, as of different processes, are serialized
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := NewStoredStanGenerator(NewFileSequenceStore(path)).Generate()
+			assert.Nil(t, err)
+		}()
+	}
+	wg.Wait()
+	s, err = stan.Generate()
+	assert.Nil(t, err)
+	assert.Equal(t, "000025", s)
+
+	assert.Equal(t, ErrSequenceConflict, stan.Store.Store("stan", 3, 10))
+	assert.EqualError(t, stan.Store.Store("a b", 0, 1), `bad sequence name: "a b"`)
+
+	assert.Nil(t, os.WriteFile(path, []byte("stan x\n"), 0o600))
+	_, err = stan.Generate()
+	assert.EqualError(t, err, path+":1: bad sequence value: x")
+	assert.Panics(t, func() { stan.Next() })
+}
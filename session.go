@@ -0,0 +1,216 @@
+package iso8583
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	ERR_SIGNED_OFF     string = "session is signed off"
+	ERR_SIGN_ON_FAILED string = "sign-on failed"
+)
+
+var (
+	ErrSignedOff    = errors.New(ERR_SIGNED_OFF)
+	ErrSignOnFailed = errors.New(ERR_SIGN_ON_FAILED)
+)
+
+// Session tracks sign-on, key exchange and spec version state of a
+// connection from network management exchanges passed to Observe, and
+// gates other traffic
+// on it: while signed off, Send rejects requests with ErrSignedOff, or
+// holds them until sign-on if Queue is set. Network management messages
+// (x8xx) are always sent. It is safe for concurrent use.
+type Session struct {
+	// Queue makes Send wait for sign-on instead of rejecting requests
+	Queue bool
+	// Stan generates STANs of sign-on messages sent by Connect. Session
+	// creates its own generator if it is nil.
+	Stan *StanGenerator
+	// Versions makes Connect negotiate spec version after sign-on and
+	// Parser return parser of the negotiated version, optional
+	Versions *VersionRegistry
+
+	mu           sync.Mutex
+	signedOn     bool
+	keyExchanged bool
+	version      string
+	ready        chan struct{} // closed while signed on
+}
+
+// SignedOn checks if session is signed on
+func (s *Session) SignedOn() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.signedOn
+}
+
+// KeyExchanged checks if key exchange succeeded since sign-on
+func (s *Session) KeyExchanged() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.keyExchanged
+}
+
+// Version returns spec version negotiated since sign-on, or "" if there is
+// none
+func (s *Session) Version() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.version
+}
+
+// Parser returns parser of negotiated spec version from Versions, or nil if
+// version is not negotiated
+func (s *Session) Parser() *Parser {
+	v := s.Version()
+	if s.Versions == nil || v == "" {
+		return nil
+	}
+	return s.Versions.Parser(v)
+}
+
+// nextStan returns next STAN of Stan, which is created if it is nil
+func (s *Session) nextStan() (string, error) {
+	s.mu.Lock()
+	if s.Stan == nil {
+		s.Stan = &StanGenerator{}
+	}
+	g := s.Stan
+	s.mu.Unlock()
+	return g.Generate()
+}
+
+// readyLocked returns channel which is closed while session is signed on
+func (s *Session) readyLocked() chan struct{} {
+	if s.ready == nil {
+		s.ready = make(chan struct{})
+	}
+	return s.ready
+}
+
+func (s *Session) setSignedOn(on bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if on == s.signedOn {
+		return
+	}
+	ready := s.readyLocked()
+	s.signedOn = on
+	if on {
+		close(ready)
+	} else {
+		s.ready = nil
+		s.keyExchanged = false
+		s.version = ""
+	}
+}
+
+// Reset signs session off, for ex. when connection is lost
+func (s *Session) Reset() {
+	s.setSignedOn(false)
+}
+
+// Observe updates state from approved network management response
+// (x810) with information code (field 70) of sign-on, sign-off, key change
+// or spec version negotiation
+func (s *Session) Observe(msg *Message) {
+	if len(msg.Mti) != 4 || msg.Mti[1:] != "810" || !hasField(msg.Data, 39) || !hasField(msg.Data, 70) {
+		return
+	}
+	if code, err := msg.GetString(39); err != nil || code != "00" {
+		return
+	}
+	nmi, err := msg.GetString(70)
+	if err != nil {
+		return
+	}
+	switch nmi {
+	case NMI_SIGN_ON:
+		s.setSignedOn(true)
+	case NMI_SIGN_OFF:
+		s.setSignedOn(false)
+	case NMI_KEY_CHANGE:
+		s.mu.Lock()
+		s.keyExchanged = s.signedOn
+		s.mu.Unlock()
+	case NMI_SPEC_VERSION:
+		if !hasField(msg.Data, 48) {
+			return
+		}
+		v, err := msg.GetString(48)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		if s.signedOn {
+			s.version = v
+		}
+		s.mu.Unlock()
+	}
+}
+
+// Send sends req with send when session allows it and observes response
+func (s *Session) Send(ctx context.Context, req *Message, send func(ctx context.Context, req *Message) (*Message, error)) (*Message, error) {
+	if MtiClass(req.Mti) != CLASS_NETWORK_MANAGEMENT {
+		s.mu.Lock()
+		signedOn, ready := s.signedOn, s.readyLocked()
+		s.mu.Unlock()
+		if !signedOn {
+			if !s.Queue {
+				return nil, ErrSignedOff
+			}
+			select {
+			case <-ready:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+	}
+	resp, err := send(ctx, req)
+	if err == nil && resp != nil {
+		s.Observe(resp)
+	}
+	return resp, err
+}
+
+// Connect returns function for Link.Connect which establishes connection
+// with connect and signs on by sending sign-on message with send. Session
+// is signed off until the sign-on is approved. If Versions is set, spec
+// version is negotiated then, and connection fails with
+// ErrNoCommonVersion if it is declined.
+func (s *Session) Connect(connect func(ctx context.Context) error, send func(ctx context.Context, req *Message) (*Message, error)) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		s.Reset()
+		if err := connect(ctx); err != nil {
+			return err
+		}
+		stan, err := s.nextStan()
+		if err != nil {
+			return err
+		}
+		if _, err := s.Send(ctx, NewSignOn(stan, time.Now()), send); err != nil {
+			return err
+		}
+		if !s.SignedOn() {
+			return fmt.Errorf("%w: STAN %s", ErrSignOnFailed, stan)
+		}
+		if s.Versions == nil {
+			return nil
+		}
+		stan, err = s.nextStan()
+		if err != nil {
+			return err
+		}
+		if _, err := s.Send(ctx, NewVersionRequest(stan, time.Now(), s.Versions.Versions()), send); err != nil {
+			return err
+		}
+		if s.Parser() == nil {
+			return fmt.Errorf("%w: STAN %s", ErrNoCommonVersion, stan)
+		}
+		return nil
+	}
+}
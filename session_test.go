@@ -0,0 +1,77 @@
+package iso8583
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSession(t *testing.T) {
+	approve := func(ctx context.Context, req *Message) (*Message, error) {
+		if req.Mti == "0800" {
+			return NewNetworkManagementResponse(req, "00")
+		}
+		return NewMessage("0210", &testTransaction{}), nil
+	}
+	s := &Session{}
+	ctx := context.Background()
+	req := NewMessage("0200", &testTransaction{F11: NewNumeric("000001")})
+
+	_, err := s.Send(ctx, req, approve)
+	assert.Equal(t, ErrSignedOff, err)
+
+	connected := false
+	connect := s.Connect(func(ctx context.Context) error {
+		connected = true
+		return nil
+	}, approve)
+	assert.Nil(t, connect(ctx))
+	assert.True(t, connected)
+	assert.True(t, s.SignedOn())
+	assert.False(t, s.KeyExchanged())
+
+	_, err = s.Send(ctx, NewKeyChange("000002", time.Now(), []byte("key")), approve)
+	assert.Nil(t, err)
+	assert.True(t, s.KeyExchanged())
+	resp, err := s.Send(ctx, req, approve)
+	assert.Nil(t, err)
+	assert.Equal(t, "0210", resp.Mti)
+
+	_, err = s.Send(ctx, NewSignOff("000003", time.Now()), approve)
+	assert.Nil(t, err)
+	assert.False(t, s.SignedOn())
+	assert.False(t, s.KeyExchanged())
+
+	// queued request is sent after sign-on
+	s.Queue = true
+	done := make(chan error)
+	go func() {
+		_, err := s.Send(ctx, req, approve)
+		done <- err
+	}()
+	_, err = s.Send(ctx, NewSignOn("000004", time.Now()), approve)
+	assert.Nil(t, err)
+	assert.Nil(t, <-done)
+
+	s.Reset()
+	timeout, cancel := context.WithTimeout(ctx, time.Millisecond)
+	defer cancel()
+	_, err = s.Send(timeout, req, approve)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	decline := func(ctx context.Context, req *Message) (*Message, error) {
+		return NewNetworkManagementResponse(req, "91")
+	}
+	s.Stan, _ = NewStanGenerator("000041")
+	err = s.Connect(func(ctx context.Context) error { return nil }, decline)(ctx)
+	assert.True(t, errors.Is(err, ErrSignOnFailed))
+	assert.EqualError(t, err, "sign-on failed: STAN 000042")
+
+	// session without Stan doesn't reuse STANs on reconnect
+	s = &Session{}
+	reconnect := s.Connect(func(ctx context.Context) error { return nil }, decline)
+	assert.EqualError(t, reconnect(ctx), "sign-on failed: STAN 000001")
+	assert.EqualError(t, reconnect(ctx), "sign-on failed: STAN 000002")
+}
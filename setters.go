@@ -0,0 +1,370 @@
+package iso8583
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Value format of Binary, Llvar and Lllvar fields in SetString and
+// GetString, set by value_format tag (for ex. `value_format:"hex"`)
+const (
+	VALUE_FORMAT_HEX string = "hex" // value is hex string, for ex. "9F1A020840"
+)
+
+const TAG_VALUE_FORMAT string = "value_format"
+
+// Time formats of Numeric fields in SetTime, set by time_format tag (for
+// ex. `time_format:"YYMM"` for expiration date). Default format is chosen
+// by field length.
+const (
+	TIME_FORMAT_MMDDHHMMSS   string = "MMDDhhmmss"
+	TIME_FORMAT_YYMMDDHHMMSS string = "YYMMDDhhmmss"
+	TIME_FORMAT_HHMMSS       string = "hhmmss"
+	TIME_FORMAT_MMDD         string = "MMDD"
+	TIME_FORMAT_YYMM         string = "YYMM"
+)
+
+const TAG_TIME_FORMAT string = "time_format"
+
+const ERR_NOT_STRUCT_POINTER string = "data must be a pointer to struct"
+
+// ErrNotStructPointer is returned by setters when Data is not a pointer
+// to struct
+var ErrNotStructPointer = errors.New(ERR_NOT_STRUCT_POINTER)
+
+// timeLayouts are time.Format layouts of time formats
+var timeLayouts = map[string]string{
+	TIME_FORMAT_MMDDHHMMSS:   "0102150405",
+	TIME_FORMAT_YYMMDDHHMMSS: "060102150405",
+	TIME_FORMAT_HHMMSS:       "150405",
+	TIME_FORMAT_MMDD:         "0102",
+	TIME_FORMAT_YYMM:         "0601",
+}
+
+// defaultTimeFormats are time formats by field length
+var defaultTimeFormats = map[int]string{
+	10: TIME_FORMAT_MMDDHHMMSS,
+	12: TIME_FORMAT_YYMMDDHHMMSS,
+	6:  TIME_FORMAT_HHMMSS,
+	4:  TIME_FORMAT_MMDD,
+}
+
+// isHexFormat checks if string values of struct field sf are hex. It
+// panics if value format is unknown.
+func isHexFormat(sf reflect.StructField) bool {
+	switch f := sf.Tag.Get(TAG_VALUE_FORMAT); f {
+	case "":
+		return false
+	case VALUE_FORMAT_HEX:
+		return true
+	default:
+		panic("unknown value format: " + f)
+	}
+}
+
+// fieldPaths caches struct field index paths of tagged fields by struct
+// type, see typeFieldPaths
+var fieldPaths sync.Map
+
+// typeFieldPaths returns index paths of struct fields of tp by field tag
+// index, including fields of embedded structs
+func typeFieldPaths(tp reflect.Type) map[int][]int {
+	if paths, ok := fieldPaths.Load(tp); ok {
+		return paths.(map[int][]int)
+	}
+	paths := make(map[int][]int)
+	names := make(map[int]string)
+	var walk func(t reflect.Type, prefix []int)
+	walk = func(t reflect.Type, prefix []int) {
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			path := append(append([]int(nil), prefix...), i)
+			if sf.Tag.Get(TAG_FIELD) == "" {
+				et := sf.Type
+				if et.Kind() == reflect.Ptr {
+					et = et.Elem()
+				}
+				if sf.Anonymous && et.Kind() == reflect.Struct {
+					walk(et, path)
+				}
+				continue
+			}
+			index, _, _ := parseFieldTag(sf.Tag.Get(TAG_FIELD))
+			if name, ok := names[index]; ok {
+				panic(duplicateField(tp, index, name, sf.Name))
+			}
+			names[index] = sf.Name
+			paths[index] = path
+		}
+	}
+	walk(tp, nil)
+	fieldPaths.Store(tp, paths)
+	return paths
+}
+
+// findField returns struct field of data with field tag index i. Fields
+// of nil embedded pointers are not found.
+func findField(data interface{}, i int) (reflect.StructField, reflect.Value, bool) {
+	v := reflect.Indirect(reflect.ValueOf(data))
+	if v.Kind() != reflect.Struct {
+		panic("data must be a struct")
+	}
+	path, ok := typeFieldPaths(v.Type())[i]
+	if !ok {
+		return reflect.StructField{}, reflect.Value{}, false
+	}
+	fv, err := v.FieldByIndexErr(path)
+	if err != nil {
+		return reflect.StructField{}, reflect.Value{}, false
+	}
+	return v.Type().FieldByIndex(path), fv, true
+}
+
+// GetField returns field i, or nil if it is absent. Generic code can access
+// fields without knowing the type of Data.
+func (m *Message) GetField(i int) (ret Iso8583Type, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("Critical error:" + fmt.Sprint(r))
+			ret = nil
+		}
+	}()
+
+	_, fv, ok := findField(m.Data, i)
+	if !ok {
+		return nil, fmt.Errorf("field %d not defined", i)
+	}
+	if isPtrOrInterface(fv.Kind()) && fv.IsNil() {
+		return nil, nil
+	}
+	f, ok := fv.Interface().(Iso8583Type)
+	if !ok {
+		return nil, fmt.Errorf("field %d: unsupported type %s", i, fv.Type())
+	}
+	return f, nil
+}
+
+// GetString returns value of field i as a string, or "" if it is absent.
+// Value of field with value_format:"hex" tag is upper case hex string.
+func (m *Message) GetString(i int) (ret string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("Critical error:" + fmt.Sprint(r))
+			ret = ""
+		}
+	}()
+
+	f, err := m.GetField(i)
+	if err != nil || f == nil {
+		return "", err
+	}
+	if sf, _, _ := findField(m.Data, i); isHexFormat(sf) {
+		return strings.ToUpper(hex.EncodeToString([]byte(fieldValue(f)))), nil
+	}
+	return fieldValue(f), nil
+}
+
+// SetField sets field i to val, which is string, []byte, int, int64,
+// time.Time (see SetString, SetAmount and SetTime) or Iso8583Type of the
+// same type as the struct field
+func (m *Message) SetField(i int, val interface{}) (err error) {
+	switch v := val.(type) {
+	case string:
+		return m.SetString(i, v)
+	case []byte:
+		return m.setString(i, string(v), false)
+	case int:
+		return m.SetAmount(i, int64(v))
+	case int64:
+		return m.SetAmount(i, v)
+	case time.Time:
+		return m.SetTime(i, v)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("Critical error:" + fmt.Sprint(r))
+		}
+	}()
+
+	sf, fv, ok := findField(m.Data, i)
+	if !ok {
+		return fmt.Errorf("field %d not defined", i)
+	}
+	rv := reflect.ValueOf(val)
+	if !rv.IsValid() || !rv.Type().AssignableTo(sf.Type) {
+		return fmt.Errorf("field %d: can not set %T to %s", i, val, sf.Type)
+	}
+	fv.Set(rv)
+	return nil
+}
+
+// SetString sets value of field i. Value is checked against field tags
+// and numeric value is zero padded to field length, so errors are returned
+// here instead of Bytes. Value of field with value_format:"hex" tag is
+// decoded from hex. Nil field is allocated, Data must be a pointer to
+// struct.
+func (m *Message) SetString(i int, val string) error {
+	return m.setString(i, val, true)
+}
+
+// setString sets value of field i, hex value is decoded if formatted is
+// true and field has value_format:"hex" tag
+func (m *Message) setString(i int, val string, formatted bool) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("Critical error:" + fmt.Sprint(r))
+		}
+	}()
+
+	if !isStructPtr(reflect.ValueOf(m.Data)) {
+		return ErrNotStructPointer
+	}
+	sf, fv, ok := findField(m.Data, i)
+	if !ok {
+		return fmt.Errorf("field %d not defined", i)
+	}
+	if sf.Type.Kind() != reflect.Ptr {
+		return fmt.Errorf("field %d must be a pointer", i)
+	}
+	nv := reflect.New(sf.Type.Elem())
+	info := newFieldInfo(sf, nv)
+
+	if formatted && isHexFormat(sf) {
+		d, err := hex.DecodeString(val)
+		if err != nil {
+			return fmt.Errorf("field %d: value must be hex", i)
+		}
+		val = string(d)
+	}
+	if err := setInfoString(i, info, val); err != nil {
+		return err
+	}
+	fv.Set(nv)
+	return nil
+}
+
+// setInfoString sets value of field i described by info, see SetString
+func setInfoString(i int, info *fieldInfo, val string) error {
+	switch f := info.Field.(type) {
+	case *Numeric:
+		if !isDigits(val) {
+			return fmt.Errorf("field %d: value must be numeric", i)
+		}
+		if info.Pad == padZero && info.Length > len(val) {
+			val = strings.Repeat("0", info.Length-len(val)) + val
+		}
+		f.Value = val
+	case *Llnumeric:
+		if !isDigits(val) {
+			return fmt.Errorf("field %d: value must be numeric", i)
+		}
+		f.Value = val
+	case *Lllnumeric:
+		if !isDigits(val) {
+			return fmt.Errorf("field %d: value must be numeric", i)
+		}
+		f.Value = val
+	case *Alphanumeric:
+		f.Value = val
+	case *Binary:
+		f.Value, f.FixLen = []byte(val), -1
+	case *Llvar:
+		f.Value = []byte(val)
+	case *Lllvar:
+		f.Value = []byte(val)
+	case *LlvarText:
+		f.Value = val
+	case *LllvarText:
+		f.Value = val
+	case *FeeAmount:
+		fee, err := ParseFeeAmount(val)
+		if err != nil {
+			return fmt.Errorf("field %d: %w", i, err)
+		}
+		*f = *fee
+	default:
+		return fmt.Errorf("field %d: unsupported type %T", i, info.Field)
+	}
+
+	if _, err := info.bytes(); err != nil {
+		return fmt.Errorf("field %d: %w", i, err)
+	}
+	return nil
+}
+
+// SetAmount sets amount in minor units to field i (for ex. field 4)
+func (m *Message) SetAmount(i int, amount int64) error {
+	if amount < 0 {
+		return fmt.Errorf("field %d: amount must not be negative", i)
+	}
+	return m.SetString(i, strconv.FormatInt(amount, 10))
+}
+
+// SetTime sets date and time to field i. Format is set by time_format tag,
+// default is chosen by field length: MMDDhhmmss (10), YYMMDDhhmmss (12),
+// hhmmss (6) and MMDD (4). Field 7 (transmission date and time) is always
+// in UTC.
+func (m *Message) SetTime(i int, t time.Time) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("Critical error:" + fmt.Sprint(r))
+		}
+	}()
+
+	sf, _, ok := findField(m.Data, i)
+	if !ok {
+		return fmt.Errorf("field %d not defined", i)
+	}
+	format := sf.Tag.Get(TAG_TIME_FORMAT)
+	if format == "" {
+		length, _ := strconv.Atoi(sf.Tag.Get(TAG_LENGTH))
+		if format, ok = defaultTimeFormats[length]; !ok {
+			return fmt.Errorf("field %d: unsupported time length %d", i, length)
+		}
+	}
+	layout, ok := timeLayouts[format]
+	if !ok {
+		return fmt.Errorf("field %d: unknown time format %s", i, format)
+	}
+	if i == 7 {
+		t = t.UTC()
+	}
+	return m.SetString(i, t.Format(layout))
+}
+
+// SetPAN sets primary account number to field i (for ex. field 2). PAN must
+// contain 12-19 digits with valid Luhn check digit.
+func (m *Message) SetPAN(i int, pan string) error {
+	if len(pan) < 12 || len(pan) > 19 || !isDigits(pan) {
+		return fmt.Errorf("field %d: PAN must contain 12-19 digits", i)
+	}
+	if !luhn(pan) {
+		return fmt.Errorf("field %d: bad PAN check digit", i)
+	}
+	return m.SetString(i, pan)
+}
+
+// luhn checks Luhn check digit of numeric string
+func luhn(s string) bool {
+	sum := 0
+	double := false
+	for i := len(s) - 1; i >= 0; i-- {
+		d := int(s[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
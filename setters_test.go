@@ -0,0 +1,155 @@
+package iso8583
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSetters(t *testing.T) {
+	type test struct {
+		F2  *Llnumeric    `field:"2" length:"19"`
+		F4  *Numeric      `field:"4" length:"12"`
+		F7  *Numeric      `field:"7" length:"10"`
+		F12 *Numeric      `field:"12" length:"6"`
+		F13 *Numeric      `field:"13" length:"4"`
+		F14 *Numeric      `field:"14" length:"4" time_format:"YYMM"`
+		F15 *Numeric      `field:"15" length:"4" time_format:"MMDDhh"`
+		F41 *Alphanumeric `field:"41" length:"8"`
+		F52 *Binary       `field:"52" length:"8"`
+	}
+
+	data := &test{}
+	iso := NewMessage("0100", data)
+	tm := time.Date(2026, 10, 15, 13, 4, 5, 0, time.UTC)
+
+	assert.Nil(t, iso.SetPAN(2, "4276555555555558"))
+	assert.Nil(t, iso.SetAmount(4, 1250))
+	assert.Nil(t, iso.SetTime(7, tm.In(time.FixedZone("UTC+3", 3*3600))))
+	assert.Nil(t, iso.SetTime(12, tm))
+	assert.Nil(t, iso.SetTime(13, tm))
+	assert.Nil(t, iso.SetTime(14, tm))
+	assert.Nil(t, iso.SetString(41, "TERM01"))
+
+	assert.Equal(t, "4276555555555558", data.F2.Value)
+	assert.Equal(t, "000000001250", data.F4.Value)
+	assert.Equal(t, "1015130405", data.F7.Value)
+	assert.Equal(t, "130405", data.F12.Value)
+	assert.Equal(t, "1015", data.F13.Value)
+	assert.Equal(t, "2610", data.F14.Value)
+	assert.Equal(t, "TERM01", data.F41.Value)
+
+	assert.EqualError(t, iso.SetPAN(2, "4276555555555555"), "field 2: bad PAN check digit")
+	assert.EqualError(t, iso.SetPAN(2, "42765"), "field 2: PAN must contain 12-19 digits")
+	assert.EqualError(t, iso.SetAmount(4, -1), "field 4: amount must not be negative")
+	assert.EqualError(t, iso.SetAmount(4, 1234567890123), "field 4: length of value is longer than definition; type=Numeric, def_len=12, len=13")
+	assert.EqualError(t, iso.SetString(4, "12a"), "field 4: value must be numeric")
+	assert.EqualError(t, iso.SetString(41, "TERMINAL1"), "field 41: length of value is longer than definition; type=Alphanumeric, def_len=8, len=9")
+	assert.EqualError(t, iso.SetString(3, "000000"), "field 3 not defined")
+	assert.EqualError(t, iso.SetTime(52, tm), "field 52: unsupported time length 8")
+	assert.EqualError(t, iso.SetTime(15, tm), "field 15: unknown time format MMDDhh")
+	assert.Nil(t, iso.SetField(52, []byte{1, 2, 3, 4, 5, 6, 7, 8}))
+	assert.EqualError(t, iso.SetField(52, make([]byte, 9)), "field 52: length of value is longer than definition; type=Binary, def_len=8, len=9")
+
+	// failed setters don't change values
+	assert.Equal(t, "000000001250", data.F4.Value)
+	assert.Equal(t, "TERM01", data.F41.Value)
+
+	// unknown time format is rejected on packing too
+	_, err := iso.Bytes()
+	assert.ErrorIs(t, err, ErrInvalidTag)
+	assert.EqualError(t, err, "field 15: invalid tag: unknown time format: MMDDhh")
+
+	iso = NewMessage("0100", test{})
+	assert.Equal(t, ErrNotStructPointer, iso.SetString(41, "TERM01"))
+}
+
+func TestGetSetField(t *testing.T) {
+	type common struct {
+		F11 *Numeric `field:"11" length:"6"`
+	}
+	type test struct {
+		*common
+		F4  *Numeric      `field:"4" length:"12"`
+		F37 *Alphanumeric `field:"37" length:"12"`
+	}
+
+	data := &test{common: &common{}}
+	iso := NewMessage("0100", data)
+
+	// generic middleware doesn't know type of Data
+	stamp := func(m *Message) error {
+		if err := m.SetField(11, "123456"); err != nil {
+			return err
+		}
+		return m.SetField(37, NewAlphanumeric("000000000001"))
+	}
+	assert.Nil(t, stamp(iso))
+	assert.Nil(t, iso.SetField(4, 1250))
+	assert.Equal(t, "123456", data.F11.Value)
+	assert.Equal(t, "000000000001", data.F37.Value)
+	assert.Equal(t, "000000001250", data.F4.Value)
+
+	f, err := iso.GetField(11)
+	assert.Nil(t, err)
+	assert.Equal(t, NewNumeric("123456"), f)
+	s, err := iso.GetString(37)
+	assert.Nil(t, err)
+	assert.Equal(t, "000000000001", s)
+
+	data.F4 = nil
+	f, err = iso.GetField(4)
+	assert.Nil(t, err)
+	assert.Nil(t, f)
+
+	_, err = iso.GetField(2)
+	assert.EqualError(t, err, "field 2 not defined")
+	assert.EqualError(t, iso.SetField(37, NewNumeric("1")), "field 37: can not set *iso8583.Numeric to *iso8583.Alphanumeric")
+
+	// fields of nil embedded struct are not defined
+	data.common = nil
+	assert.EqualError(t, iso.SetField(11, "123456"), "field 11 not defined")
+}
+
+func TestHexValues(t *testing.T) {
+	type test struct {
+		F52 *Binary `field:"52" length:"8"`
+		F55 *Lllvar `field:"55" length:"255" value_format:"hex"`
+		F64 *Binary `field:"64" length:"4" value_format:"hex"`
+	}
+
+	b, err := NewBinaryHex("9F1A020840")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x9f, 0x1a, 0x02, 0x08, 0x40}, b.Value)
+	assert.Equal(t, "9F1A020840", b.Hex())
+	_, err = NewBinaryHex("9F1")
+	assert.NotNil(t, err)
+
+	data := &test{}
+	iso := NewMessage("0100", data)
+	assert.Nil(t, iso.SetString(55, "9f1a020840"))
+	assert.Nil(t, iso.SetString(64, "01020304"))
+	assert.Nil(t, iso.SetString(52, "abc"))
+	assert.Equal(t, []byte{0x9f, 0x1a, 0x02, 0x08, 0x40}, data.F55.Value)
+	assert.Equal(t, []byte{1, 2, 3, 4}, data.F64.Value)
+	assert.Equal(t, []byte("abc"), data.F52.Value)
+
+	s, err := iso.GetString(55)
+	assert.Nil(t, err)
+	assert.Equal(t, "9F1A020840", s)
+	s, err = iso.GetString(52)
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", s)
+
+	// []byte values are not hex
+	assert.Nil(t, iso.SetField(64, []byte{5, 6, 7, 8}))
+	assert.Equal(t, []byte{5, 6, 7, 8}, data.F64.Value)
+
+	assert.EqualError(t, iso.SetString(64, "0102xx"), "field 64: value must be hex")
+	assert.EqualError(t, iso.SetString(64, "0102030405"), "field 64: length of value is longer than definition; type=Binary, def_len=4, len=5")
+
+	type bad struct {
+		F52 *Binary `field:"52" length:"8" value_format:"base64"`
+	}
+	assert.EqualError(t, NewMessage("0100", &bad{}).SetString(52, "AA"), "Critical error:unknown value format: base64")
+}
@@ -0,0 +1,94 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Spec is partner specification of message fields, for ex. decoded from JSON
+// document {"fields": {"2": {"type": "llnumeric", "length": 19, "mandatory":
+// true}}}, which is checked against tagged struct by ValidateStructAgainstSpec
+type Spec struct {
+	Fields map[int]SpecField `json:"fields"`
+}
+
+// SpecField is specification of a field. Empty members are not checked.
+type SpecField struct {
+	Type      string `json:"type"`   // type name, for ex. "numeric" or "composite", case insensitive
+	Length    int    `json:"length"` // defined length, max length of variable length types
+	Encode    string `json:"encode"` // value of encode tag, for ex. "bcd" or "ascii,bcd"
+	Mandatory bool   `json:"mandatory"`
+}
+
+// ValidateStructAgainstSpec checks tags of struct type tp (or pointer to it)
+// against spec, so drift between them is caught in tests. It reports all
+// mismatches at once as FieldErrors: wrong types, lengths and encodings,
+// mandatory fields of spec which tp doesn't define and fields of tp which
+// spec doesn't define. Subfields of composite fields are not checked.
+func ValidateStructAgainstSpec(tp reflect.Type, spec *Spec) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("Critical error:" + fmt.Sprint(r))
+		}
+	}()
+
+	found := make(map[int]error)
+	defined := make(map[int]bool)
+	for _, d := range describeType(tp) {
+		defined[d.Field] = true
+		sf, ok := spec.Fields[d.Field]
+		if !ok {
+			found[d.Field] = fmt.Errorf("field %d is not in spec", d.Field)
+			continue
+		}
+		if e := sf.mismatch(d); e != "" {
+			found[d.Field] = &FieldError{d.Field, errors.New(e)}
+		}
+	}
+	for i, sf := range spec.Fields {
+		if sf.Mandatory && !defined[i] {
+			found[i] = fmt.Errorf("field %d is mandatory in spec but not defined", i)
+		}
+	}
+
+	if len(found) == 0 {
+		return nil
+	}
+	indexes := make([]int, 0, len(found))
+	for i := range found {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	errs := make(FieldErrors, len(indexes))
+	for j, i := range indexes {
+		errs[j] = found[i]
+	}
+	return errs
+}
+
+// mismatch returns description of differences of field d from s, or empty
+// string if they match
+func (s SpecField) mismatch(d FieldDescription) string {
+	var diffs []string
+	if s.Type != "" && !strings.EqualFold(s.Type, d.Kind) {
+		diffs = append(diffs, fmt.Sprintf("type is %s, spec has %s", d.Kind, s.Type))
+	}
+	if s.Length != 0 && s.Length != d.Length {
+		diffs = append(diffs, fmt.Sprintf("length is %d, spec has %d", d.Length, s.Length))
+	}
+	if s.Encode != "" {
+		enc := strings.Split(s.Encode, ",")
+		encode, lenEncode := enc[0], ""
+		if len(enc) == 2 {
+			lenEncode, encode = enc[0], enc[1]
+		}
+		if encodeName(parseEncodeStr(encode)) != d.Encode ||
+			lenEncode != "" && encodeName(parseEncodeStr(lenEncode)) != d.LenEncode {
+			diffs = append(diffs, fmt.Sprintf("encode is %s,%s, spec has %s", d.LenEncode, d.Encode, s.Encode))
+		}
+	}
+	return strings.Join(diffs, ", ")
+}
@@ -0,0 +1,45 @@
+package iso8583
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+func TestValidateStructAgainstSpec(t *testing.T) {
+	type test struct {
+		F2  *Llnumeric          `field:"2" length:"19" encode:"bcd,bcd"`
+		F4  *Numeric            `field:"4" length:"12"`
+		F11 *Numeric            `field:"11" length:"6"`
+		F48 *testAdditionalData `field:"48" length:"999"`
+		F52 *Binary             `field:"52" length:"8"`
+	}
+
+	var spec Spec
+	assert.Nil(t, json.Unmarshal([]byte(`{"fields": {
+		"2": {"type": "llnumeric", "length": 19, "encode": "bcd,bcd", "mandatory": true},
+		"4": {"type": "numeric", "length": 12, "mandatory": true},
+		"11": {"type": "numeric", "length": 6, "encode": "ascii"},
+		"48": {"type": "composite", "length": 999},
+		"52": {"type": "binary", "length": 8},
+		"55": {"type": "lllvar", "length": 255}
+	}}`), &spec))
+	assert.Nil(t, ValidateStructAgainstSpec(reflect.TypeOf(&test{}), &spec))
+
+	spec.Fields[4] = SpecField{Type: "alphanumeric", Length: 10}
+	spec.Fields[11] = SpecField{Type: "numeric", Length: 6, Encode: "bcd"}
+	spec.Fields[39] = SpecField{Type: "alphanumeric", Length: 2, Mandatory: true}
+	delete(spec.Fields, 52)
+	err := ValidateStructAgainstSpec(reflect.TypeOf(test{}), &spec)
+	assert.EqualError(t, err, "field 4: type is Numeric, spec has alphanumeric, length is 12, spec has 10; "+
+		"field 11: encode is ascii,ascii, spec has bcd; "+
+		"field 39 is mandatory in spec but not defined; "+
+		"field 52 is not in spec")
+	assert.Len(t, err.(FieldErrors), 4)
+
+	spec.Fields[2] = SpecField{Encode: "xml"}
+	err = ValidateStructAgainstSpec(reflect.TypeOf(test{}), &spec)
+	assert.Contains(t, err.Error(), "field 2: encode is bcd,bcd, spec has xml; ")
+	assert.EqualError(t, ValidateStructAgainstSpec(reflect.TypeOf(1), &spec), "Critical error:data must be a struct")
+}
@@ -0,0 +1,149 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// StanGenerator generates rolling 6-digit STANs (field 11) from 000001 to
+// 999999, safe for concurrent use. Last STAN can be saved and passed to
+// NewStanGenerator on restart, so sequence continues, or generator can
+// keep it in SequenceStore.
+type StanGenerator struct {
+	// Store persists each value before it is returned, optional
+	Store SequenceStore
+
+	name string
+	max  int
+	mu   sync.Mutex
+	last int
+}
+
+// NewStanGenerator creates StanGenerator which continues after last STAN,
+// empty last starts from 000001
+func NewStanGenerator(last string) (*StanGenerator, error) {
+	g := &StanGenerator{}
+	if last == "" {
+		return g, nil
+	}
+	n, err := parseLength(last)
+	if err != nil || len(last) > 6 {
+		return nil, fmt.Errorf("bad STAN: %s", last)
+	}
+	g.last = n
+	return g, nil
+}
+
+// NewStoredStanGenerator creates StanGenerator of STANs saved in store as
+// "stan" sequence
+func NewStoredStanGenerator(store SequenceStore) *StanGenerator {
+	return &StanGenerator{Store: store}
+}
+
+// NewBatchGenerator creates StanGenerator of batch numbers from 001 to
+// 999 saved in store as "batch" sequence
+func NewBatchGenerator(store SequenceStore) *StanGenerator {
+	return &StanGenerator{Store: store, name: "batch", max: 999}
+}
+
+// sequence returns name and maximum value of the sequence
+func (g *StanGenerator) sequence() (string, int) {
+	if g.max == 0 {
+		return "stan", 999999
+	}
+	return g.name, g.max
+}
+
+// format zero pads n to number of digits of the maximum value
+func (g *StanGenerator) format(n int) string {
+	_, max := g.sequence()
+	return fmt.Sprintf("%0*d", len(strconv.Itoa(max)), n)
+}
+
+// Generate returns next value. With Store it is saved (compare-and-swap,
+// retried when another generator sharing the store took the value) before
+// it is returned, so it is never reused after restart.
+func (g *StanGenerator) Generate() (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	name, max := g.sequence()
+	if g.Store == nil {
+		g.last = g.last%max + 1
+		return g.format(g.last), nil
+	}
+	for {
+		last, err := g.Store.Load(name)
+		if err != nil {
+			return "", err
+		}
+		next := last%uint64(max) + 1
+		err = g.Store.Store(name, last, next)
+		if err == nil {
+			g.last = int(next)
+			return g.format(g.last), nil
+		}
+		if !errors.Is(err, ErrSequenceConflict) {
+			return "", err
+		}
+	}
+}
+
+// Next returns next STAN. It panics if Store fails, generators with Store
+// should use Generate.
+func (g *StanGenerator) Next() string {
+	stan, err := g.Generate()
+	if err != nil {
+		panic(err)
+	}
+	return stan
+}
+
+// Last returns last generated value, or "" if there is none
+func (g *StanGenerator) Last() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.last == 0 {
+		return ""
+	}
+	return g.format(g.last)
+}
+
+// Stamp sets next STAN to field 11 of msg, and RRN built from t and the
+// STAN to field 37 if msg has it. It returns the STAN.
+func (g *StanGenerator) Stamp(msg *Message, t time.Time) (string, error) {
+	stan, err := g.Generate()
+	if err != nil {
+		return "", err
+	}
+	if err := msg.SetString(11, stan); err != nil {
+		return "", err
+	}
+	if hasField(msg.Data, 37) {
+		if err := msg.SetString(37, NewRRN(t, stan)); err != nil {
+			return "", err
+		}
+	}
+	return stan, nil
+}
+
+// NewRRN builds retrieval reference number (field 37) in YDDDHHSSSSSS
+// format: last digit of year, day of year, hour and STAN of transmission
+// time t in UTC
+func NewRRN(t time.Time, stan string) string {
+	t = t.UTC()
+	return fmt.Sprintf("%d%03d%02d%06s", t.Year()%10, t.YearDay(), t.Hour(), stan)
+}
+
+// hasField checks if data defines field i
+func hasField(data interface{}, i int) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			ok = false
+		}
+	}()
+	_, _, ok = findField(data, i)
+	return ok
+}
@@ -0,0 +1,60 @@
+package iso8583
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStanGenerator(t *testing.T) {
+	g, err := NewStanGenerator("")
+	assert.Nil(t, err)
+	assert.Equal(t, "", g.Last())
+	assert.Equal(t, "000001", g.Next())
+	assert.Equal(t, "000001", g.Last())
+
+	g, err = NewStanGenerator("999998")
+	assert.Nil(t, err)
+	assert.Equal(t, "999999", g.Next())
+	assert.Equal(t, "000001", g.Next())
+
+	_, err = NewStanGenerator("12a")
+	assert.EqualError(t, err, "bad STAN: 12a")
+
+	var wg sync.WaitGroup
+	seen := sync.Map{}
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, dup := seen.LoadOrStore(g.Next(), true)
+			assert.False(t, dup)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, "000101", g.Last())
+}
+
+func TestStamp(t *testing.T) {
+	tm := time.Date(2026, 2, 3, 14, 5, 0, 0, time.UTC)
+	assert.Equal(t, "603414000042", NewRRN(tm, "000042"))
+
+	type test struct {
+		F11 *Numeric      `field:"11" length:"6"`
+		F37 *Alphanumeric `field:"37" length:"12"`
+	}
+	g, _ := NewStanGenerator("000041")
+	data := &test{}
+	stan, err := g.Stamp(NewMessage("0200", data), tm)
+	assert.Nil(t, err)
+	assert.Equal(t, "000042", stan)
+	assert.Equal(t, "000042", data.F11.Value)
+	assert.Equal(t, "603414000042", data.F37.Value)
+
+	// RRN is optional
+	nm := NewMessage("0800", &NetworkManagement{})
+	_, err = g.Stamp(nm, tm)
+	assert.Nil(t, err)
+	assert.Equal(t, "000043", nm.Data.(*NetworkManagement).F11.Value)
+}
@@ -0,0 +1,165 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const (
+	ERR_TRANSACTION_NOT_FOUND string = "original transaction not found"
+	ERR_MISSING_STAN          string = "missing STAN (field 11)"
+	ERR_NOT_REVERSAL          string = "message is not a reversal or advice"
+)
+
+var (
+	ErrTransactionNotFound = errors.New(ERR_TRANSACTION_NOT_FOUND)
+	ErrMissingStan         = errors.New(ERR_MISSING_STAN)
+	ErrNotReversal         = errors.New(ERR_NOT_REVERSAL)
+)
+
+// TransactionKey identifies an original transaction by STAN (field 11),
+// local transaction date (field 13) and card acceptor terminal ID (field 41)
+type TransactionKey struct {
+	Stan     string
+	Date     string
+	Terminal string
+}
+
+// KeyOf builds TransactionKey from message fields 11, 13 and 41
+func KeyOf(msg *Message) (key TransactionKey, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("Critical error:" + fmt.Sprint(r))
+			key = TransactionKey{}
+		}
+	}()
+
+	fields, err := parseFields(msg.Data)
+	if err != nil {
+		return TransactionKey{}, err
+	}
+	if info, ok := fields[11]; ok {
+		key.Stan = fieldValue(info.Field)
+	}
+	if info, ok := fields[13]; ok {
+		key.Date = fieldValue(info.Field)
+	}
+	if info, ok := fields[41]; ok {
+		key.Terminal = fieldValue(info.Field)
+	}
+	if key.Stan == "" {
+		return TransactionKey{}, ErrMissingStan
+	}
+	return key, nil
+}
+
+// fieldValue returns value of field as a string
+func fieldValue(f Iso8583Type) string {
+	switch v := f.(type) {
+	case *Numeric:
+		return v.Value
+	case *Alphanumeric:
+		return v.Value
+	case *Llnumeric:
+		return v.Value
+	case *Lllnumeric:
+		return v.Value
+	case *Binary:
+		return string(v.Value)
+	case *Llvar:
+		return string(v.Value)
+	case *Lllvar:
+		return string(v.Value)
+	case *FeeAmount:
+		return v.String()
+	case *LlvarText:
+		return v.Value
+	case *LllvarText:
+		return v.Value
+	}
+	return ""
+}
+
+// TransactionStore is a persistent storage of original transactions
+type TransactionStore interface {
+	// Put saves message under the key, replacing previous one
+	Put(key TransactionKey, msg *Message) error
+
+	// GetByKey returns message saved under the key. It returns
+	// ErrTransactionNotFound error if there is no such message.
+	GetByKey(key TransactionKey) (*Message, error)
+}
+
+// MemoryStore is in-memory TransactionStore implementation, safe for
+// concurrent use
+type MemoryStore struct {
+	mu  sync.RWMutex
+	txs map[TransactionKey]*Message
+}
+
+// NewMemoryStore creates new MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{txs: make(map[TransactionKey]*Message)}
+}
+
+// Put saves message in memory
+func (s *MemoryStore) Put(key TransactionKey, msg *Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.txs == nil {
+		s.txs = make(map[TransactionKey]*Message)
+	}
+	s.txs[key] = msg
+	return nil
+}
+
+// GetByKey returns message from memory
+func (s *MemoryStore) GetByKey(key TransactionKey) (*Message, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	msg, ok := s.txs[key]
+	if !ok {
+		return nil, ErrTransactionNotFound
+	}
+	return msg, nil
+}
+
+// Matcher pairs reversals and advices with their original transactions
+type Matcher struct {
+	Store TransactionStore
+}
+
+// NewMatcher creates new Matcher backed by store
+func NewMatcher(store TransactionStore) *Matcher {
+	return &Matcher{store}
+}
+
+// Record saves original transaction in the store
+func (m *Matcher) Record(msg *Message) error {
+	key, err := KeyOf(msg)
+	if err != nil {
+		return err
+	}
+	return m.Store.Put(key, msg)
+}
+
+// Match returns original transaction for reversal or advice message
+func (m *Matcher) Match(msg *Message) (*Message, error) {
+	if !isReversalOrAdvice(msg.Mti) {
+		return nil, ErrNotReversal
+	}
+	key, err := KeyOf(msg)
+	if err != nil {
+		return nil, err
+	}
+	return m.Store.GetByKey(key)
+}
+
+// isReversalOrAdvice checks message class (x4xx) and function (xx2x, xx3x) of MTI
+func isReversalOrAdvice(mti string) bool {
+	if len(mti) != 4 {
+		return false
+	}
+	return mti[1] == '4' || mti[2] == '2' || mti[2] == '3'
+}
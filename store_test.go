@@ -0,0 +1,66 @@
+package iso8583
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type testTransaction struct {
+	F3  *Numeric      `field:"3" length:"6"`
+	F4  *Numeric      `field:"4" length:"12"`
+	F11 *Numeric      `field:"11" length:"6"`
+	F13 *Numeric      `field:"13" length:"4"`
+	F41 *Alphanumeric `field:"41" length:"8"`
+}
+
+func TestMatcher(t *testing.T) {
+	original := NewMessage("0200", &testTransaction{
+		F3:  NewNumeric("000000"),
+		F4:  NewNumeric("000000077700"),
+		F11: NewNumeric("000123"),
+		F13: NewNumeric("0701"),
+		F41: NewAlphanumeric("00000321"),
+	})
+	reversal := NewMessage("0400", &testTransaction{
+		F4:  NewNumeric("000000077700"),
+		F11: NewNumeric("000123"),
+		F13: NewNumeric("0701"),
+		F41: NewAlphanumeric("00000321"),
+	})
+
+	matcher := NewMatcher(NewMemoryStore())
+
+	_, err := matcher.Match(reversal)
+	assert.EqualError(t, err, "original transaction not found")
+
+	err = matcher.Record(original)
+	assert.Nil(t, err)
+
+	res, err := matcher.Match(reversal)
+	assert.Nil(t, err)
+	assert.Equal(t, original, res)
+
+	// advice is matched too
+	advice := NewMessage("0220", reversal.Data)
+	res, err = matcher.Match(advice)
+	assert.Nil(t, err)
+	assert.Equal(t, original, res)
+
+	_, err = matcher.Match(original)
+	assert.EqualError(t, err, "message is not a reversal or advice")
+
+	err = matcher.Record(NewMessage("0200", &testTransaction{}))
+	assert.EqualError(t, err, "missing STAN (field 11)")
+
+	err = matcher.Record(NewMessage("0200", nil))
+	assert.EqualError(t, err, "Critical error:data must be a struct")
+}
+
+func TestKeyOf(t *testing.T) {
+	key, err := KeyOf(NewMessage("0400", &testTransaction{
+		F11: NewNumeric("000123"),
+		F41: NewAlphanumeric("00000321"),
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, TransactionKey{Stan: "000123", Terminal: "00000321"}, key)
+}
@@ -0,0 +1,129 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+const ERR_INVALID_TAG string = "invalid tag"
+
+// ErrInvalidTag is matched by errors of struct fields with unknown tag
+// options, for ex. unknown transform or value_format
+var ErrInvalidTag = errors.New(ERR_INVALID_TAG)
+
+var (
+	iso8583TypeType = reflect.TypeOf((*Iso8583Type)(nil)).Elem()
+	fieldV2Type     = reflect.TypeOf((*FieldV2)(nil)).Elem()
+)
+
+// tagChecks caches result of checkTags by struct type
+var tagChecks sync.Map
+
+// checkTags parses options of field tags of struct type tp, including
+// embedded structs, composite subfields and repeating groups. The first
+// unknown option is returned as FieldError matching ErrInvalidTag.
+func checkTags(tp reflect.Type) error {
+	if ret, ok := tagChecks.Load(tp); ok {
+		err, _ := ret.(error)
+		return err
+	}
+	err := checkTagsOf(tp, make(map[reflect.Type]bool))
+	tagChecks.Store(tp, err)
+	return err
+}
+
+// checkTagsOf checks tags of struct type tp, skipping types in seen
+func checkTagsOf(tp reflect.Type, seen map[reflect.Type]bool) error {
+	if seen[tp] {
+		return nil
+	}
+	seen[tp] = true
+	for i := 0; i < tp.NumField(); i++ {
+		sf := tp.Field(i)
+		et := sf.Type
+		if et.Kind() == reflect.Ptr {
+			et = et.Elem()
+		}
+		if sf.Tag.Get(TAG_FIELD) == "" {
+			if sf.Anonymous && et.Kind() == reflect.Struct {
+				if err := checkTagsOf(et, seen); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := checkFieldTags(sf); err != nil {
+			return err
+		}
+		if nested := nestedStruct(sf.Type); nested != nil {
+			if err := checkTagsOf(nested, seen); err != nil {
+				index, _, _ := parseFieldTag(sf.Tag.Get(TAG_FIELD))
+				sub := err.(*FieldError)
+				return &FieldError{index, fmt.Errorf("subfield %d: %w", sub.Field, sub.Err)}
+			}
+		}
+	}
+	return nil
+}
+
+// nestedStruct returns struct type of composite or repeating group field
+// of type tp, or nil
+func nestedStruct(tp reflect.Type) reflect.Type {
+	if tp.Implements(iso8583TypeType) || tp.Implements(fieldV2Type) {
+		return nil
+	}
+	switch tp.Kind() {
+	case reflect.Ptr:
+		if tp.Elem().Kind() == reflect.Struct {
+			return tp.Elem()
+		}
+	case reflect.Slice:
+		et := tp.Elem()
+		if et.Kind() == reflect.Ptr {
+			et = et.Elem()
+		}
+		if et.Kind() == reflect.Struct {
+			return et
+		}
+	}
+	return nil
+}
+
+// checkFieldTags parses tags of struct field sf
+func checkFieldTags(sf reflect.StructField) (err error) {
+	index, _, _ := parseFieldTag(sf.Tag.Get(TAG_FIELD))
+	defer func() {
+		if r := recover(); r != nil {
+			err = &FieldError{index, fmt.Errorf("%w: %s", ErrInvalidTag, r)}
+		}
+	}()
+
+	parsePadStr(sf.Tag.Get(TAG_PAD))
+	parseFillerStr(sf.Tag.Get(TAG_FILLER))
+	parseTruncateStr(sf.Tag.Get(TAG_TRUNCATE))
+	parseTransformStr(sf.Tag.Get(TAG_TRANSFORM))
+	parseCompressStr(sf.Tag.Get(TAG_COMPRESS))
+	if charset := sf.Tag.Get(TAG_CHARSET); charset != "" {
+		parseCharsetStr(charset)
+	}
+	isHexFormat(sf)
+	if format := sf.Tag.Get(TAG_TIME_FORMAT); format != "" {
+		if _, ok := timeLayouts[format]; !ok {
+			panic("unknown time format: " + format)
+		}
+	}
+
+	if nestedStruct(sf.Type) == nil {
+		return nil
+	}
+	_, bitmap := (&composite{kind: sf.Tag.Get(TAG_COMPOSITE)}).options()
+	if sf.Type.Kind() == reflect.Slice {
+		parseRepeatStr(sf.Tag.Get(TAG_REPEAT))
+		if bitmap > 0 {
+			panic("repeating groups can't be bitmapped")
+		}
+	}
+	return nil
+}
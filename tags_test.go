@@ -0,0 +1,51 @@
+package iso8583
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestInvalidTag(t *testing.T) {
+	type good struct {
+		F2 *Llvar `field:"2" length:"19"`
+	}
+	raw, err := NewMessage("0100", &good{NewLlvar([]byte("1234"))}).Bytes()
+	assert.Nil(t, err)
+
+	type group struct {
+		S1 *Alphanumeric `field:"1" length:"2" transform:"rot13"`
+	}
+	type nested struct {
+		Groups []group `field:"2" length:"99" composite:"llvar"`
+	}
+	type embedded struct {
+		F2 *Llvar `field:"2" length:"19" transform:"rot13"`
+	}
+	type withEmbedded struct {
+		embedded
+	}
+
+	for name, data := range map[string]interface{}{
+		"transform": &struct {
+			F2 *Llvar `field:"2" length:"19" transform:"rot13"`
+		}{},
+		"compress": &struct {
+			F2 *Llvar `field:"2" length:"19" compress:"lz4"`
+		}{},
+		"value_format": &struct {
+			F2 *Llvar `field:"2" length:"19" value_format:"base64"`
+		}{},
+		"embedded": &withEmbedded{},
+		"subfield": &nested{},
+	} {
+		_, err := NewMessage("0100", data).Bytes()
+		assert.ErrorIs(t, err, ErrInvalidTag, name)
+		var fe *FieldError
+		if assert.ErrorAs(t, err, &fe, name) {
+			assert.Equal(t, 2, fe.Field, name)
+		}
+
+		err = NewMessage("", data).Load(raw)
+		assert.ErrorIs(t, err, ErrInvalidTag, name)
+	}
+}
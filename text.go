@@ -0,0 +1,200 @@
+package iso8583
+
+// textField is implemented by fields which value is text encoded by ascii
+// or ebcdic encoder, ebcdic uses code page of Message
+type textField interface {
+	bytesText(encoder, lenEncoder, length int, cp *CodePage) ([]byte, error)
+	loadText(raw []byte, encoder, lenEncoder, length int, cp *CodePage) (int, error)
+}
+
+// encodeText encodes text by ascii or ebcdic encoder
+func encodeText(val string, encoder int, cp *CodePage) ([]byte, error) {
+	switch encoder {
+	case ASCII:
+		return []byte(val), nil
+	case EBCDIC:
+		return codePageOrDefault(cp).Encode([]byte(val)), nil
+	}
+	return nil, ErrInvalidEncoder
+}
+
+// decodeText decodes text by ascii or ebcdic encoder
+func decodeText(raw []byte, encoder int, cp *CodePage) (string, error) {
+	switch encoder {
+	case ASCII:
+		return string(raw), nil
+	case EBCDIC:
+		return string(codePageOrDefault(cp).Decode(raw)), nil
+	}
+	return "", ErrInvalidEncoder
+}
+
+// LlvarText contains text in non-fixed length field, first 2 symbols of
+// field contains length. Supported encoders are ascii and ebcdic, length
+// encoders are the same as Llvar ones.
+type LlvarText struct {
+	Value string
+}
+
+// NewLlvarText create new LlvarText field
+func NewLlvarText(val string) *LlvarText {
+	return &LlvarText{val}
+}
+
+// IsEmpty check LlvarText field for empty value
+func (l *LlvarText) IsEmpty() bool {
+	return len(l.Value) == 0
+}
+
+// Bytes encode LlvarText field to bytes, ebcdic uses code page 037
+func (l *LlvarText) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
+	return l.bytesText(encoder, lenEncoder, length, nil)
+}
+
+// Load decode LlvarText field from bytes, ebcdic uses code page 037
+func (l *LlvarText) Load(raw []byte, encoder, lenEncoder, length int) (int, error) {
+	return l.loadText(raw, encoder, lenEncoder, length, nil)
+}
+
+func (l *LlvarText) bytesText(encoder, lenEncoder, length int, cp *CodePage) ([]byte, error) {
+	val, err := encodeText(l.Value, encoder, cp)
+	if err != nil {
+		return nil, err
+	}
+	if length != -1 && len(val) > length {
+		return nil, valueTooLong("LlvarText", length, len(val))
+	}
+	return NewLlvar(val).Bytes(ASCII, lenEncoder, length)
+}
+
+func (l *LlvarText) loadText(raw []byte, encoder, lenEncoder, length int, cp *CodePage) (int, error) {
+	v := &Llvar{}
+	read, err := v.Load(raw, ASCII, lenEncoder, length)
+	if err != nil {
+		return 0, err
+	}
+	if l.Value, err = decodeText(v.Value, encoder, cp); err != nil {
+		return 0, err
+	}
+	return read, nil
+}
+
+// LllvarText contains text in non-fixed length field, first 3 symbols of
+// field contains length. Supported encoders are ascii and ebcdic, length
+// encoders are the same as Lllvar ones.
+type LllvarText struct {
+	Value string
+}
+
+// NewLllvarText create new LllvarText field
+func NewLllvarText(val string) *LllvarText {
+	return &LllvarText{val}
+}
+
+// IsEmpty check LllvarText field for empty value
+func (l *LllvarText) IsEmpty() bool {
+	return len(l.Value) == 0
+}
+
+// Bytes encode LllvarText field to bytes, ebcdic uses code page 037
+func (l *LllvarText) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
+	return l.bytesText(encoder, lenEncoder, length, nil)
+}
+
+// Load decode LllvarText field from bytes, ebcdic uses code page 037
+func (l *LllvarText) Load(raw []byte, encoder, lenEncoder, length int) (int, error) {
+	return l.loadText(raw, encoder, lenEncoder, length, nil)
+}
+
+func (l *LllvarText) bytesText(encoder, lenEncoder, length int, cp *CodePage) ([]byte, error) {
+	val, err := encodeText(l.Value, encoder, cp)
+	if err != nil {
+		return nil, err
+	}
+	if length != -1 && len(val) > length {
+		return nil, valueTooLong("LllvarText", length, len(val))
+	}
+	return NewLllvar(val).Bytes(ASCII, lenEncoder, length)
+}
+
+func (l *LllvarText) loadText(raw []byte, encoder, lenEncoder, length int, cp *CodePage) (int, error) {
+	v := &Lllvar{}
+	read, err := v.Load(raw, ASCII, lenEncoder, length)
+	if err != nil {
+		return 0, err
+	}
+	if l.Value, err = decodeText(v.Value, encoder, cp); err != nil {
+		return 0, err
+	}
+	return read, nil
+}
+
+// bytesText encodes Alphanumeric field, ebcdic encodes the padded value
+func (a *Alphanumeric) bytesText(encoder, lenEncoder, length int, cp *CodePage) ([]byte, error) {
+	if encoder != EBCDIC {
+		return a.Bytes(encoder, lenEncoder, length)
+	}
+	val, err := a.Bytes(ASCII, lenEncoder, length)
+	if err != nil {
+		return nil, err
+	}
+	return encodeText(string(val), encoder, cp)
+}
+
+// loadText decodes Alphanumeric field
+func (a *Alphanumeric) loadText(raw []byte, encoder, lenEncoder, length int, cp *CodePage) (int, error) {
+	read, err := a.Load(raw, ASCII, lenEncoder, length)
+	if err != nil || encoder != EBCDIC {
+		return read, err
+	}
+	a.Value, err = decodeText([]byte(a.Value), encoder, cp)
+	return read, err
+}
+
+func (l *Llnumeric) bytesText(encoder, lenEncoder, length int, cp *CodePage) ([]byte, error) {
+	if encoder != EBCDIC {
+		return l.Bytes(encoder, lenEncoder, length)
+	}
+	d, err := l.Bytes(ASCII, lenEncoder, length)
+	if err != nil {
+		return nil, err
+	}
+	hl := len(d) - len(l.Value)
+	return append(d[:hl:hl], codePageOrDefault(cp).Encode(d[hl:])...), nil
+}
+
+func (l *Llnumeric) loadText(raw []byte, encoder, lenEncoder, length int, cp *CodePage) (int, error) {
+	if encoder != EBCDIC {
+		return l.Load(raw, encoder, lenEncoder, length)
+	}
+	read, err := l.Load(raw, ASCII, lenEncoder, length)
+	if err != nil {
+		return 0, err
+	}
+	l.Value, err = decodeText([]byte(l.Value), encoder, cp)
+	return read, err
+}
+
+func (l *Lllnumeric) bytesText(encoder, lenEncoder, length int, cp *CodePage) ([]byte, error) {
+	if encoder != EBCDIC {
+		return l.Bytes(encoder, lenEncoder, length)
+	}
+	d, err := l.Bytes(ASCII, lenEncoder, length)
+	if err != nil {
+		return nil, err
+	}
+	hl := len(d) - len(l.Value)
+	return append(d[:hl:hl], codePageOrDefault(cp).Encode(d[hl:])...), nil
+}
+
+func (l *Lllnumeric) loadText(raw []byte, encoder, lenEncoder, length int, cp *CodePage) (int, error) {
+	if encoder != EBCDIC {
+		return l.Load(raw, encoder, lenEncoder, length)
+	}
+	read, err := l.Load(raw, ASCII, lenEncoder, length)
+	if err != nil {
+		return 0, err
+	}
+	l.Value, err = decodeText([]byte(l.Value), encoder, cp)
+	return read, err
+}
@@ -0,0 +1,39 @@
+package iso8583
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTextFields(t *testing.T) {
+	type test struct {
+		F43 *LlvarText  `field:"43" length:"40" encode:"ascii,ebcdic"`
+		F48 *LllvarText `field:"48" length:"999" encode:"bcd,ascii"`
+		F62 *LllvarText `field:"62" length:"999" encode:"ascii,ebcdic"`
+	}
+	data := &test{NewLlvarText("SHOP"), NewLllvarText("ab"), NewLllvarText("[x]")}
+	iso := NewMessage("0200", data)
+	res, err := iso.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "3034e2c8d6d7"+"00026162"+"303033baa7bb", fmt.Sprintf("%x", res[12:]))
+
+	loaded := &test{&LlvarText{}, &LllvarText{}, &LllvarText{}}
+	assert.Nil(t, NewMessage("", loaded).Load(res))
+	assert.Equal(t, data, loaded)
+
+	// message code page
+	iso.CodePage = CodePage1047
+	res, err = iso.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "ada7bd", fmt.Sprintf("%x", res[25:]))
+	loadedMsg := NewMessage("", loaded)
+	loadedMsg.CodePage = CodePage1047
+	assert.Nil(t, loadedMsg.Load(res))
+	assert.Equal(t, "[x]", loaded.F62.Value)
+
+	_, err = NewLlvarText("x").Bytes(BCD, ASCII, 10)
+	assert.Equal(t, ErrInvalidEncoder, err)
+	_, err = NewLlvarText("toolong").Bytes(EBCDIC, ASCII, 3)
+	assert.EqualError(t, err, "length of value is longer than definition; type=LlvarText, def_len=3, len=7")
+}
@@ -0,0 +1,112 @@
+package iso8583
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// SetTransmissionTime sets transmission date and time (field 7) to t in
+// UTC, and local transaction time (field 12) and date (field 13) to t in
+// loc, so they describe the same moment. Nil loc is location of t. Fields
+// which are not defined in Data are skipped.
+func (m *Message) SetTransmissionTime(t time.Time, loc *time.Location) error {
+	if loc == nil {
+		loc = t.Location()
+	}
+	for _, i := range []int{7, 12, 13} {
+		if !hasField(m.Data, i) {
+			continue
+		}
+		if err := m.SetTime(i, t.In(loc)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TransmissionTime returns transmission date and time (field 7, MMDDhhmmss
+// in UTC). Field has no year, so it is the year of ref or the one before
+// or after it, whichever is closest to ref (for ex. 1231235959 received on
+// January 1st is in previous year).
+func (m *Message) TransmissionTime(ref time.Time) (time.Time, error) {
+	v, err := m.GetString(7)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(v) != 10 || !isDigits(v) {
+		return time.Time{}, fmt.Errorf("field 7: bad date and time: %q", v)
+	}
+	t, ok := closestDate(v[:4], v[4:], ref, time.UTC)
+	if !ok {
+		return time.Time{}, fmt.Errorf("field 7: bad date and time: %q", v)
+	}
+	return t, nil
+}
+
+// LocalTime returns local transaction date (field 13, MMDD) and time
+// (field 12, hhmmss) in loc, or field 12 alone if it is YYMMDDhhmmss. Year
+// of MMDD is resolved as by TransmissionTime, nil loc is location of ref.
+func (m *Message) LocalTime(ref time.Time, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = ref.Location()
+	}
+	tm, err := m.GetString(12)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(tm) == 12 && isDigits(tm) {
+		t, err := time.ParseInLocation("060102150405", tm, loc)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("field 12: bad date and time: %q", tm)
+		}
+		return t, nil
+	}
+	if len(tm) != 6 || !isDigits(tm) {
+		return time.Time{}, fmt.Errorf("field 12: bad time: %q", tm)
+	}
+	date, err := m.GetString(13)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(date) != 4 || !isDigits(date) {
+		return time.Time{}, fmt.Errorf("field 13: bad date: %q", date)
+	}
+	t, ok := closestDate(date, tm, ref, loc)
+	if !ok {
+		return time.Time{}, fmt.Errorf("field 13: bad date: %q", date)
+	}
+	return t, nil
+}
+
+// closestDate returns time of date (MMDD) and clock (hhmmss) in loc in the
+// year closest to ref
+func closestDate(date, clock string, ref time.Time, loc *time.Location) (time.Time, bool) {
+	num := func(s string) int {
+		n, _ := strconv.Atoi(s)
+		return n
+	}
+	month, day := time.Month(num(date[:2])), num(date[2:])
+	hour, min, sec := num(clock[:2]), num(clock[2:4]), num(clock[4:])
+	if hour > 23 || min > 59 || sec > 59 {
+		return time.Time{}, false
+	}
+
+	ref = ref.In(loc)
+	var best time.Time
+	var bestDiff time.Duration
+	for y := ref.Year() - 1; y <= ref.Year()+1; y++ {
+		t := time.Date(y, month, day, hour, min, sec, 0, loc)
+		if t.Month() != month || t.Day() != day {
+			continue // for ex. February 29th in common year
+		}
+		diff := t.Sub(ref)
+		if diff < 0 {
+			diff = -diff
+		}
+		if best.IsZero() || diff < bestDiff {
+			best, bestDiff = t, diff
+		}
+	}
+	return best, !best.IsZero()
+}
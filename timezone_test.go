@@ -0,0 +1,54 @@
+package iso8583
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type timedMessage struct {
+	F7  *Numeric `field:"7" length:"10"`
+	F11 *Numeric `field:"11" length:"6"`
+	F12 *Numeric `field:"12" length:"6"`
+	F13 *Numeric `field:"13" length:"4"`
+}
+
+func TestTransmissionTime(t *testing.T) {
+	est := time.FixedZone("EST", -5*3600)
+	tm := time.Date(2026, 1, 1, 1, 30, 0, 0, time.UTC)
+
+	msg := NewMessage("0200", &timedMessage{})
+	assert.Nil(t, msg.SetTransmissionTime(tm, est))
+	data := msg.Data.(*timedMessage)
+	assert.Equal(t, "0101013000", data.F7.Value)
+	assert.Equal(t, "203000", data.F12.Value)
+	assert.Equal(t, "1231", data.F13.Value)
+	assert.Nil(t, data.F11)
+
+	ref := tm.Add(time.Minute)
+	sent, err := msg.TransmissionTime(ref)
+	assert.Nil(t, err)
+	assert.True(t, tm.Equal(sent))
+	assert.Equal(t, time.UTC, sent.Location())
+	local, err := msg.LocalTime(ref, est)
+	assert.Nil(t, err)
+	assert.True(t, tm.Equal(local))
+	assert.Equal(t, 2025, local.Year())
+
+	// year rollover and leap day
+	data.F7 = NewNumeric("1231235959")
+	sent, err = msg.TransmissionTime(tm)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2025, 12, 31, 23, 59, 59, 0, time.UTC), sent)
+	data.F13 = NewNumeric("0229")
+	local, err = msg.LocalTime(time.Date(2025, 3, 1, 0, 0, 0, 0, est), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, 2, 29, 20, 30, 0, 0, est), local)
+
+	data.F7 = NewNumeric("1301000000")
+	_, err = msg.TransmissionTime(tm)
+	assert.EqualError(t, err, `field 7: bad date and time: "1301000000"`)
+	data.F12 = nil
+	_, err = msg.LocalTime(tm, est)
+	assert.EqualError(t, err, `field 12: bad time: ""`)
+}
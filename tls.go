@@ -0,0 +1,75 @@
+package iso8583
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+)
+
+// tlsConfig returns copy of config with defaults: SNI server name is host
+// of addr and minimal version is TLS 1.2
+func tlsConfig(config *tls.Config, addr string) *tls.Config {
+	if config == nil {
+		config = &tls.Config{}
+	}
+	config = config.Clone()
+	if config.ServerName == "" && addr != "" {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			host = addr
+		}
+		config.ServerName = host
+	}
+	if config.MinVersion == 0 {
+		config.MinVersion = tls.VersionTLS12
+	}
+	return config
+}
+
+// DialTLS connects to addr and performs TLS handshake. Client certificate
+// for mutual TLS is taken from config.Certificates.
+func DialTLS(ctx context.Context, network, addr string, config *tls.Config) (*tls.Conn, error) {
+	d := &tls.Dialer{Config: tlsConfig(config, addr)}
+	conn, err := d.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+	return conn.(*tls.Conn), nil
+}
+
+// StartTLS upgrades plain connection to TLS as a client, for hosts which
+// switch to TLS after initial handshake message. greet exchanges
+// handshake messages over plain connection before upgrade, it may be nil.
+func StartTLS(ctx context.Context, conn net.Conn, config *tls.Config, greet func(rw io.ReadWriter) error) (*tls.Conn, error) {
+	if greet != nil {
+		if err := greet(conn); err != nil {
+			return nil, err
+		}
+	}
+	addr := ""
+	if conn.RemoteAddr() != nil {
+		addr = conn.RemoteAddr().String()
+	}
+	tc := tls.Client(conn, tlsConfig(config, addr))
+	if err := tc.HandshakeContext(ctx); err != nil {
+		return nil, err
+	}
+	return tc, nil
+}
+
+// StartTLSServer upgrades plain connection to TLS as a server, it is the
+// counterpart of StartTLS. Set config.ClientAuth to
+// tls.RequireAndVerifyClientCert for mutual TLS.
+func StartTLSServer(ctx context.Context, conn net.Conn, config *tls.Config, greet func(rw io.ReadWriter) error) (*tls.Conn, error) {
+	if greet != nil {
+		if err := greet(conn); err != nil {
+			return nil, err
+		}
+	}
+	tc := tls.Server(conn, tlsConfig(config, ""))
+	if err := tc.HandshakeContext(ctx); err != nil {
+		return nil, err
+	}
+	return tc, nil
+}
@@ -0,0 +1,91 @@
+package iso8583
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+)
+
+func testCertificate(t *testing.T, name string) (tls.Certificate, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.Nil(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.Nil(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, cert
+}
+
+func TestStartTLS(t *testing.T) {
+	serverCert, serverX509 := testCertificate(t, "host.example")
+	clientCert, clientX509 := testCertificate(t, "terminal")
+	serverCAs, clientCAs := x509.NewCertPool(), x509.NewCertPool()
+	serverCAs.AddCert(serverX509)
+	clientCAs.AddCert(clientX509)
+
+	greeting := []byte("0800 starttls")
+	clientGreet := func(rw io.ReadWriter) error {
+		_, err := rw.Write(greeting)
+		return err
+	}
+	serverGreet := func(rw io.ReadWriter) error {
+		buf := make([]byte, len(greeting))
+		_, err := io.ReadFull(rw, buf)
+		assert.Equal(t, greeting, buf)
+		return err
+	}
+
+	c, s := net.Pipe()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		tc, err := StartTLSServer(ctx, s, &tls.Config{
+			Certificates: []tls.Certificate{serverCert},
+			ClientCAs:    clientCAs,
+			ClientAuth:   tls.RequireAndVerifyClientCert,
+		}, serverGreet)
+		if err == nil {
+			assert.Equal(t, "terminal", tc.ConnectionState().PeerCertificates[0].Subject.CommonName)
+		}
+		done <- err
+	}()
+
+	tc, err := StartTLS(ctx, c, &tls.Config{
+		Certificates: []tls.Certificate{clientCert},
+		RootCAs:      serverCAs,
+		ServerName:   "host.example",
+	}, clientGreet)
+	assert.Nil(t, err)
+	assert.Nil(t, <-done)
+	state := tc.ConnectionState()
+	assert.Equal(t, "host.example", state.ServerName)
+	assert.True(t, state.Version >= tls.VersionTLS12)
+	c.Close()
+	s.Close()
+
+	config := tlsConfig(&tls.Config{MinVersion: tls.VersionTLS13}, "10.0.0.1:5000")
+	assert.Equal(t, "10.0.0.1", config.ServerName)
+	assert.Equal(t, uint16(tls.VersionTLS13), config.MinVersion)
+}
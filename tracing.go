@@ -0,0 +1,117 @@
+package iso8583
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Operations traced in addition to OpPack and OpUnpack
+const (
+	OpSend    string = "send"
+	OpReceive string = "receive"
+)
+
+// Span attributes set by tracing
+const (
+	AttrMti           string = "iso8583.mti"
+	AttrStan          string = "iso8583.stan"
+	AttrRrn           string = "iso8583.rrn"
+	AttrResponseCode  string = "iso8583.response_code"
+	AttrSize          string = "iso8583.size"
+	AttrCorrelationID string = "iso8583.correlation_id"
+)
+
+// Tracer starts spans of message operations: packing and unpacking by
+// BytesContext and LoadContext, sending by Balancer and receiving by
+// Router. Implementations must be safe for concurrent use. OpenTelemetry
+// implementation is built with otel tag, see NewOtelTracer.
+type Tracer interface {
+	// Start starts span of op, returned ctx carries the span
+	Start(ctx context.Context, op string) (context.Context, Span)
+}
+
+// Span is a traced operation
+type Span interface {
+	// SetAttribute sets attribute, value is string or int
+	SetAttribute(key string, value interface{})
+
+	// End finishes span, err is nil on success
+	End(err error)
+}
+
+type tracerHolder struct {
+	Tracer
+}
+
+var tracer atomic.Value
+
+func init() {
+	tracer.Store(tracerHolder{})
+}
+
+// SetTracer sets Tracer for all messages, nil disables tracing
+func SetTracer(t Tracer) {
+	tracer.Store(tracerHolder{t})
+}
+
+// startSpan starts span of op if tracing is enabled, returned span may be
+// nil
+func startSpan(ctx context.Context, op string) (context.Context, Span) {
+	t := tracer.Load().(tracerHolder).Tracer
+	if t == nil {
+		return ctx, nil
+	}
+	return t.Start(ctx, op)
+}
+
+// endSpan sets attributes of msg and ends span. size is omitted if it is
+// negative.
+func endSpan(span Span, msg *Message, size int, err error) {
+	if span == nil {
+		return
+	}
+	if msg != nil {
+		for key, value := range spanAttributes(msg) {
+			span.SetAttribute(key, value)
+		}
+	}
+	if size >= 0 {
+		span.SetAttribute(AttrSize, size)
+	}
+	span.End(err)
+}
+
+// endExchangeSpan ends span of request and response exchange, attributes
+// are taken from response if there is one
+func endExchangeSpan(span Span, req, resp *Message, err error) {
+	if resp != nil {
+		req = resp
+	}
+	endSpan(span, req, -1, err)
+}
+
+// spanAttributes returns MTI, correlation ID, STAN (field 11), RRN (field
+// 37) and response code (field 39) of msg, empty ones are omitted
+func spanAttributes(msg *Message) (attrs map[string]string) {
+	attrs = map[string]string{AttrMti: msg.Mti}
+	if msg.CorrelationID != "" {
+		attrs[AttrCorrelationID] = msg.CorrelationID
+	}
+	defer func() {
+		// attributes are best effort, bad data is reported by pack or unpack
+		recover()
+	}()
+
+	fields, err := parseFields(msg.Data)
+	if err != nil {
+		return attrs
+	}
+	for idx, key := range map[int]string{11: AttrStan, 37: AttrRrn, 39: AttrResponseCode} {
+		if info, ok := fields[idx]; ok {
+			if v := fieldValue(info.Field); v != "" {
+				attrs[key] = v
+			}
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,47 @@
+//go:build otel
+
+package iso8583
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// OtelTracer is Tracer creating OpenTelemetry spans named "iso8583.<op>"
+type OtelTracer struct {
+	Tracer trace.Tracer
+}
+
+// NewOtelTracer creates OtelTracer with tracer of provider tp
+func NewOtelTracer(tp trace.TracerProvider) *OtelTracer {
+	return &OtelTracer{tp.Tracer("github.com/ideazxy/iso8583")}
+}
+
+// Start starts OpenTelemetry span, child of span in ctx
+func (t *OtelTracer) Start(ctx context.Context, op string) (context.Context, Span) {
+	ctx, span := t.Tracer.Start(ctx, "iso8583."+op)
+	return ctx, otelSpan{span}
+}
+
+type otelSpan struct {
+	span trace.Span
+}
+
+func (s otelSpan) SetAttribute(key string, value interface{}) {
+	switch v := value.(type) {
+	case string:
+		s.span.SetAttributes(attribute.String(key, v))
+	case int:
+		s.span.SetAttributes(attribute.Int(key, v))
+	}
+}
+
+func (s otelSpan) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
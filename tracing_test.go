@@ -0,0 +1,112 @@
+package iso8583
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testSpan struct {
+	Op    string
+	Attrs map[string]interface{}
+	Err   error
+	Ended bool
+}
+
+func (s *testSpan) SetAttribute(key string, value interface{}) {
+	s.Attrs[key] = value
+}
+
+func (s *testSpan) End(err error) {
+	s.Err = err
+	s.Ended = true
+}
+
+type testTracer struct {
+	mu    sync.Mutex
+	spans []*testSpan
+}
+
+func (t *testTracer) Start(ctx context.Context, op string) (context.Context, Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := &testSpan{Op: op, Attrs: make(map[string]interface{})}
+	t.spans = append(t.spans, s)
+	return ctx, s
+}
+
+func TestTracing(t *testing.T) {
+	tracer := &testTracer{}
+	SetTracer(tracer)
+	defer SetTracer(nil)
+
+	ctx := context.Background()
+	msg := NewNetworkManagement(NMI_ECHO, "000123", time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	raw, err := msg.BytesContext(ctx)
+	assert.Nil(t, err)
+	assert.NotNil(t, NewMessage("0800", &NetworkManagement{}).LoadContext(ctx, raw[:10]))
+
+	fail := errors.New("connection reset")
+	bal := &Balancer{Endpoints: []*Endpoint{{Send: func(ctx context.Context, req *Message) (*Message, error) {
+		return nil, fail
+	}}}}
+	_, err = bal.Send(ctx, msg)
+	assert.Equal(t, fail, err)
+
+	assert.Equal(t, 3, len(tracer.spans))
+	assert.Equal(t, &testSpan{OpPack, map[string]interface{}{
+		AttrMti:  "0800",
+		AttrStan: "000123",
+		AttrSize: len(raw),
+	}, nil, true}, tracer.spans[0])
+	assert.Equal(t, OpUnpack, tracer.spans[1].Op)
+	assert.NotNil(t, tracer.spans[1].Err)
+	assert.Equal(t, 10, tracer.spans[1].Attrs[AttrSize])
+	assert.Equal(t, &testSpan{OpSend, map[string]interface{}{
+		AttrMti:  "0800",
+		AttrStan: "000123",
+	}, fail, true}, tracer.spans[2])
+}
+
+func TestCorrelationID(t *testing.T) {
+	tracer := &testTracer{}
+	SetTracer(tracer)
+	defer SetTracer(nil)
+	ins := &testInstrumentation{}
+	SetInstrumentation(ins)
+	defer SetInstrumentation(nil)
+
+	ctx := context.Background()
+	req := NewMessage("0200", &testTransaction{F11: NewNumeric("000042")})
+	req.CorrelationID = "api-7"
+
+	// response is tied to the request by Router and Correlator
+	r := &Router{}
+	r.Handle("0200", "", "", func(ctx context.Context, req *Message) (*Message, error) {
+		return NewMessage("0210", &testTransaction{F11: NewNumeric("000042")}), nil
+	})
+	resp, err := r.Serve(ctx, req)
+	assert.Nil(t, err)
+	assert.Equal(t, "api-7", resp.CorrelationID)
+	assert.Equal(t, "api-7", tracer.spans[0].Attrs[AttrCorrelationID])
+
+	c := &Correlator{}
+	resp, err = c.Send(ctx, req, func(ctx context.Context, req *Message) error {
+		c.Deliver(NewMessage("0210", &testTransaction{F11: NewNumeric("000042")}))
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "api-7", resp.CorrelationID)
+
+	// it is not packed, but reported
+	raw, err := req.Bytes()
+	assert.Nil(t, err)
+	parsed := NewMessage("", &testTransaction{F11: &Numeric{}})
+	assert.Nil(t, parsed.Load(raw))
+	assert.Equal(t, "", parsed.CorrelationID)
+	assert.Equal(t, "api-7", ins.events[0].CorrelationID)
+	assert.Equal(t, "", ins.events[1].CorrelationID)
+}
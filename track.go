@@ -0,0 +1,119 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	ERR_INVALID_TRACK1 string = "invalid track 1 data"
+)
+
+var ErrInvalidTrack1 = errors.New(ERR_INVALID_TRACK1)
+
+// track1MaxLen is maximum length of field 45
+const track1MaxLen = 76
+
+// Track1 contains track 1 data (field 45) in format
+// "B" PAN "^" NAME "^" YYMM SERVICE_CODE DISCRETIONARY_DATA. Start and end
+// sentinels are not included. It is packed like Llvar, supported encoder
+// is ascii.
+type Track1 struct {
+	FormatCode        string
+	PAN               string
+	Name              string
+	Expiry            string // YYMM
+	ServiceCode       string
+	DiscretionaryData string
+}
+
+// ParseTrack1 parses track 1 data. Start ('%') and end ('?') sentinels are
+// stripped if present.
+func ParseTrack1(s string) (*Track1, error) {
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "%"), "?")
+	parts := strings.SplitN(s, "^", 3)
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: missing field separator", ErrInvalidTrack1)
+	}
+	if len(parts[0]) < 1 || len(parts[2]) < 7 {
+		return nil, fmt.Errorf("%w: too short", ErrInvalidTrack1)
+	}
+	t := &Track1{
+		FormatCode:        parts[0][:1],
+		PAN:               parts[0][1:],
+		Name:              parts[1],
+		Expiry:            parts[2][:4],
+		ServiceCode:       parts[2][4:7],
+		DiscretionaryData: parts[2][7:],
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Validate checks track 1 data subfields
+func (t *Track1) Validate() error {
+	if len(t.FormatCode) != 1 || t.FormatCode[0] < 'A' || t.FormatCode[0] > 'Z' {
+		return fmt.Errorf("%w: bad format code", ErrInvalidTrack1)
+	}
+	if len(t.PAN) < 1 || len(t.PAN) > 19 || !isDigits(t.PAN) {
+		return fmt.Errorf("%w: bad PAN", ErrInvalidTrack1)
+	}
+	if len(t.Name) < 2 || len(t.Name) > 26 || strings.Contains(t.Name, "^") {
+		return fmt.Errorf("%w: bad name", ErrInvalidTrack1)
+	}
+	if len(t.Expiry) != 4 || !isDigits(t.Expiry) {
+		return fmt.Errorf("%w: bad expiry", ErrInvalidTrack1)
+	}
+	if len(t.ServiceCode) != 3 || !isDigits(t.ServiceCode) {
+		return fmt.Errorf("%w: bad service code", ErrInvalidTrack1)
+	}
+	if l := len(t.String()); l > track1MaxLen {
+		return valueTooLong("Track1", track1MaxLen, l)
+	}
+	return nil
+}
+
+// String returns track 1 data without sentinels
+func (t *Track1) String() string {
+	return t.FormatCode + t.PAN + "^" + t.Name + "^" + t.Expiry + t.ServiceCode + t.DiscretionaryData
+}
+
+// IsEmpty check Track1 field for empty value
+func (t *Track1) IsEmpty() bool {
+	return *t == Track1{}
+}
+
+// Bytes encode Track1 field to bytes
+func (t *Track1) Bytes(encoder, lenEncoder, length int) ([]byte, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return NewLlvar([]byte(t.String())).Bytes(encoder, lenEncoder, length)
+}
+
+// Load decode Track1 field from bytes
+func (t *Track1) Load(raw []byte, encoder, lenEncoder, length int) (int, error) {
+	l := &Llvar{}
+	read, err := l.Load(raw, encoder, lenEncoder, length)
+	if err != nil {
+		return 0, err
+	}
+	parsed, err := ParseTrack1(string(l.Value))
+	if err != nil {
+		return 0, err
+	}
+	*t = *parsed
+	return read, nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
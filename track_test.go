@@ -0,0 +1,51 @@
+package iso8583
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTrack1(t *testing.T) {
+	track, err := ParseTrack1("%B4276555555555555^DOE/JOHN^2512101123400000000?")
+	assert.Nil(t, err)
+	assert.Equal(t, &Track1{"B", "4276555555555555", "DOE/JOHN", "2512", "101", "123400000000"}, track)
+	assert.Equal(t, "B4276555555555555^DOE/JOHN^2512101123400000000", track.String())
+
+	type test struct {
+		F45 *Track1 `field:"45" length:"76"`
+	}
+
+	iso := NewMessage("0100", &test{track})
+	res, err := iso.Bytes()
+	assert.Nil(t, err)
+
+	iso2 := NewMessage("", &test{&Track1{}})
+	err = iso2.Load(res)
+	assert.Nil(t, err)
+	assert.Equal(t, track, iso2.Data.(*test).F45)
+
+	_, err = ParseTrack1("B4276555555555555DOE/JOHN2512101")
+	assert.EqualError(t, err, "invalid track 1 data: missing field separator")
+
+	_, err = ParseTrack1("B4276555555555555^DOE/JOHN^2512")
+	assert.EqualError(t, err, "invalid track 1 data: too short")
+
+	_, err = ParseTrack1("14276555555555555^DOE/JOHN^2512101")
+	assert.EqualError(t, err, "invalid track 1 data: bad format code")
+
+	_, err = ParseTrack1("B42765555A^DOE/JOHN^2512101")
+	assert.EqualError(t, err, "invalid track 1 data: bad PAN")
+
+	_, err = ParseTrack1("B4276555555555555^D^2512101")
+	assert.EqualError(t, err, "invalid track 1 data: bad name")
+
+	_, err = ParseTrack1("B4276555555555555^DOE/JOHN^25A2101")
+	assert.EqualError(t, err, "invalid track 1 data: bad expiry")
+
+	_, err = ParseTrack1("B4276555555555555^DOE/JOHN^25121A1")
+	assert.EqualError(t, err, "invalid track 1 data: bad service code")
+
+	track.DiscretionaryData = "12345678901234567890123456789012345678901234567890"
+	_, err = track.Bytes(ASCII, ASCII, 76)
+	assert.EqualError(t, err, "length of value is longer than definition; type=Track1, def_len=76, len=84")
+}
@@ -0,0 +1,81 @@
+package iso8583
+
+import "strings"
+
+// Transforms of field values applied on loading, set by transform tag as
+// comma separated list (for ex. `transform:"trim,upper"`) or
+// Message.Transforms. They normalize wire forms of Numeric, Alphanumeric,
+// Llnumeric, Lllnumeric, LlvarText and LllvarText values.
+const (
+	TRANSFORM_TRIM       string = "trim"       // leading and trailing spaces are removed
+	TRANSFORM_TRIM_LEFT  string = "trimleft"   // leading spaces are removed
+	TRANSFORM_TRIM_RIGHT string = "trimright"  // trailing spaces are removed
+	TRANSFORM_UPPER      string = "upper"      // letters are uppercased
+	TRANSFORM_LOWER      string = "lower"      // letters are lowercased
+	TRANSFORM_STRIP_ZERO string = "stripzeros" // leading zeros are removed, "000" becomes "0"
+)
+
+const TAG_TRANSFORM string = "transform"
+
+// transformFuncs are transforms by name
+var transformFuncs = map[string]func(string) string{
+	TRANSFORM_TRIM:       strings.TrimSpace,
+	TRANSFORM_TRIM_LEFT:  func(s string) string { return strings.TrimLeft(s, " ") },
+	TRANSFORM_TRIM_RIGHT: func(s string) string { return strings.TrimRight(s, " ") },
+	TRANSFORM_UPPER:      strings.ToUpper,
+	TRANSFORM_LOWER:      strings.ToLower,
+	TRANSFORM_STRIP_ZERO: stripZeros,
+}
+
+func stripZeros(s string) string {
+	t := strings.TrimLeft(s, "0")
+	if t == "" && s != "" {
+		return "0"
+	}
+	return t
+}
+
+// parseTransformStr returns transforms listed in str. It panics if any of
+// them is unknown.
+func parseTransformStr(str string) []func(string) string {
+	if str == "" {
+		return nil
+	}
+	var ret []func(string) string
+	for _, name := range strings.Split(str, ",") {
+		fn, ok := transformFuncs[strings.TrimSpace(name)]
+		if !ok {
+			panic("unknown transform: " + name)
+		}
+		ret = append(ret, fn)
+	}
+	return ret
+}
+
+// transform applies transforms of f to loaded value
+func (f *fieldInfo) transform() {
+	if f.Transform == "" {
+		return
+	}
+	fns := parseTransformStr(f.Transform)
+	apply := func(s string) string {
+		for _, fn := range fns {
+			s = fn(s)
+		}
+		return s
+	}
+	switch v := f.Field.(type) {
+	case *Numeric:
+		v.Value = apply(v.Value)
+	case *Alphanumeric:
+		v.Value = apply(v.Value)
+	case *Llnumeric:
+		v.Value = apply(v.Value)
+	case *Lllnumeric:
+		v.Value = apply(v.Value)
+	case *LlvarText:
+		v.Value = apply(v.Value)
+	case *LllvarText:
+		v.Value = apply(v.Value)
+	}
+}
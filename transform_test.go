@@ -0,0 +1,41 @@
+package iso8583
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTransform(t *testing.T) {
+	type test struct {
+		F4  *Numeric      `field:"4" length:"12" transform:"stripzeros"`
+		F37 *Alphanumeric `field:"37" length:"12"`
+		F43 *Alphanumeric `field:"43" length:"20" transform:"trim,upper"`
+	}
+	data := &test{
+		F4:  NewNumeric("150"),
+		F37: NewAlphanumeric("abc"),
+		F43: NewAlphanumeric("  Shop  "),
+	}
+	res, err := NewMessage("0100", data).Bytes()
+	assert.Nil(t, err)
+
+	p := &Parser{}
+	p.Register("0100", &test{})
+	msg, err := p.Parse(res)
+	assert.Nil(t, err)
+	loaded := msg.Data.(*test)
+	assert.Equal(t, "150", loaded.F4.Value)
+	assert.Equal(t, "         abc", loaded.F37.Value)
+	assert.Equal(t, "SHOP", loaded.F43.Value)
+
+	p.Transforms = map[int]string{37: TRANSFORM_TRIM_LEFT, 43: TRANSFORM_TRIM_RIGHT}
+	msg, err = p.Parse(res)
+	assert.Nil(t, err)
+	loaded = msg.Data.(*test)
+	assert.Equal(t, "abc", loaded.F37.Value)
+	assert.Equal(t, "              Shop", loaded.F43.Value)
+
+	assert.Equal(t, "0", stripZeros("000"))
+	assert.Equal(t, "", stripZeros(""))
+	assert.Panics(t, func() { parseTransformStr("trim,foo") })
+}
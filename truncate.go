@@ -0,0 +1,150 @@
+package iso8583
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Truncation policies of fields which value is longer than definition, set
+// by truncate tag (for ex. `truncate:"right"`) or Message.Truncation
+const (
+	TRUNCATE_ERROR string = "error" // packing fails with ValueTooLongError (default)
+	TRUNCATE_LEFT  string = "left"  // leftmost characters are dropped
+	TRUNCATE_RIGHT string = "right" // rightmost characters are dropped
+)
+
+// Options of left and right truncation of text fields (Alphanumeric, Llvar,
+// Lllvar, LlvarText and LllvarText), for ex. `truncate:"right,runes,ellipsis"`
+// for merchant descriptors
+const (
+	// TRUNCATE_RUNES keeps multi-byte characters of UTF-8 values whole, so
+	// value may be shorter than definition
+	TRUNCATE_RUNES string = "runes"
+	// TRUNCATE_ELLIPSIS marks truncated value with TruncationMarker at the
+	// cut end, within defined length
+	TRUNCATE_ELLIPSIS string = "ellipsis"
+)
+
+// TruncationMarker is added to values truncated with TRUNCATE_ELLIPSIS
+// option. It is ascii, so it is valid in every code page.
+const TruncationMarker string = "..."
+
+const TAG_TRUNCATE string = "truncate"
+
+const (
+	truncError = iota
+	truncLeft
+	truncRight
+
+	truncPolicy   = 0x3 // mask of policy, the rest are option flags
+	truncRunes    = 0x4
+	truncEllipsis = 0x8
+)
+
+func parseTruncateStr(str string) int {
+	opts := strings.Split(str, ",")
+	var ret int
+	switch opts[0] {
+	case "", TRUNCATE_ERROR:
+		ret = truncError
+	case TRUNCATE_LEFT:
+		ret = truncLeft
+	case TRUNCATE_RIGHT:
+		ret = truncRight
+	default:
+		panic("unknown truncation: " + str)
+	}
+	for _, opt := range opts[1:] {
+		switch {
+		case ret == truncError:
+			panic("truncation options require left or right policy: " + str)
+		case opt == TRUNCATE_RUNES:
+			ret |= truncRunes
+		case opt == TRUNCATE_ELLIPSIS:
+			ret |= truncEllipsis
+		default:
+			panic("unknown truncation option: " + opt)
+		}
+	}
+	return ret
+}
+
+func truncateString(s string, length, policy int) string {
+	if len(s) <= length {
+		return s
+	}
+	marker := ""
+	if policy&truncEllipsis != 0 {
+		if length <= len(TruncationMarker) {
+			return TruncationMarker[:length]
+		}
+		marker = TruncationMarker
+	}
+	runes := policy&truncRunes != 0 && utf8.ValidString(s)
+	n := length - len(marker)
+	if policy&truncPolicy == truncLeft {
+		start := len(s) - n
+		for runes && start < len(s) && !utf8.RuneStart(s[start]) {
+			start++
+		}
+		return marker + s[start:]
+	}
+	for runes && n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + marker
+}
+
+func truncateBytes(b []byte, length, policy int) []byte {
+	if len(b) <= length {
+		return b
+	}
+	if policy&^truncPolicy != 0 {
+		return []byte(truncateString(string(b), length, policy))
+	}
+	if policy == truncLeft {
+		return b[len(b)-length:]
+	}
+	return b[:length]
+}
+
+// truncated returns copy of f which value is truncated to defined length
+// according to truncation policy. Value of the original field is not
+// changed.
+func (f *fieldInfo) truncated() *fieldInfo {
+	length := f.length()
+	if f.Truncate == truncError || length < 0 {
+		return f
+	}
+	// options apply to text only
+	policy := f.Truncate & truncPolicy
+	var field Iso8583Type
+	switch v := f.Field.(type) {
+	case *Numeric:
+		field = &Numeric{truncateString(v.Value, length, policy)}
+	case *Alphanumeric:
+		field = &Alphanumeric{truncateString(v.Value, length, f.Truncate)}
+	case *Llnumeric:
+		field = &Llnumeric{truncateString(v.Value, length, policy)}
+	case *Lllnumeric:
+		field = &Lllnumeric{truncateString(v.Value, length, policy)}
+	case *Llvar:
+		field = &Llvar{truncateBytes(v.Value, length, f.Truncate)}
+	case *Lllvar:
+		field = &Lllvar{truncateBytes(v.Value, length, f.Truncate)}
+	case *LlvarText:
+		field = &LlvarText{truncateString(v.Value, length, f.Truncate)}
+	case *LllvarText:
+		field = &LllvarText{truncateString(v.Value, length, f.Truncate)}
+	case *Binary:
+		if v.FixLen != -1 {
+			length = v.FixLen
+		}
+		field = &Binary{truncateBytes(v.Value, length, policy), v.FixLen}
+	default:
+		return f
+	}
+	ret := *f
+	ret.Field = field
+	return &ret
+}
@@ -0,0 +1,80 @@
+package iso8583
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestTruncate(t *testing.T) {
+	type data struct {
+		F2  *Llnumeric    `field:"2" length:"19" truncate:"left"`
+		F4  *Numeric      `field:"4" length:"4"`
+		F43 *Alphanumeric `field:"43" length:"10" truncate:"right"`
+		F52 *Binary       `field:"52" length:"2" truncate:"left"`
+	}
+	d := &data{
+		F2:  NewLlnumeric("12345678901234567890"),
+		F4:  NewNumeric("123"),
+		F43: NewAlphanumeric("ACME SUPERMARKET"),
+		F52: NewBinary([]byte{1, 2, 3}),
+	}
+	msg := NewMessage("0200", d)
+	b, err := msg.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "0200"+"\x50\x00\x00\x00\x00\x20\x10\x00"+"192345678901234567890"+"0123"+"ACME SUPER"+"\x02\x03", string(b))
+	// values are not changed
+	assert.Equal(t, "ACME SUPERMARKET", d.F43.Value)
+
+	d.F4.Value = "12345"
+	_, err = msg.Bytes()
+	assert.True(t, errors.Is(err, ErrValueTooLong))
+	msg.Truncation = map[int]string{4: TRUNCATE_RIGHT, 43: TRUNCATE_ERROR}
+	_, err = msg.Bytes()
+	assert.Equal(t, &ValueTooLongError{"Alphanumeric", 10, 16}, err)
+	d.F43.Value = "ACME"
+	b, err = msg.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "1234", string(b[33:37]))
+
+	assert.Panics(t, func() { parseTruncateStr("middle") })
+}
+
+func TestTruncateRunes(t *testing.T) {
+	type data struct {
+		F4  *Numeric      `field:"4" length:"4" truncate:"right,ellipsis"`
+		F43 *Alphanumeric `field:"43" length:"10" truncate:"right,runes"`
+		F48 *LllvarText   `field:"48" length:"8" truncate:"left,runes,ellipsis"`
+		F62 *Llvar        `field:"62" length:"10" truncate:"right,ellipsis"`
+	}
+	// cuts at 10 and 12 bytes would split 2-byte characters
+	d := &data{
+		F4:  NewNumeric("12345"),
+		F43: NewAlphanumeric("CAFÉS CRÈME"),
+		F48: NewLllvarText("ÉCOLE DE MÜNCHE"),
+		F62: NewLlvar([]byte("ACME SUPERMARKET")),
+	}
+	msg := NewMessage("0200", d)
+	b, err := msg.Bytes()
+	assert.Nil(t, err)
+	// numeric values are not marked
+	assert.Equal(t, "1234"+" CAFÉS CR"+"007...NCHE"+"10ACME SU...", string(b[12:]))
+
+	// invalid UTF-8 is cut on bytes
+	d.F43.Value = strings.Repeat("\xe9", 11)
+	b, err = msg.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, strings.Repeat("\xe9", 10), string(b[16:26]))
+
+	msg.Truncation = map[int]string{43: "left,ellipsis"}
+	b, err = msg.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "..."+strings.Repeat("\xe9", 7), string(b[16:26]))
+
+	// marker is shortened for short fields
+	assert.Equal(t, "..", truncateString("abc", 2, truncRight|truncEllipsis))
+
+	assert.Panics(t, func() { parseTruncateStr("error,runes") })
+	assert.Panics(t, func() { parseTruncateStr("right,dots") })
+}
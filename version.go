@@ -0,0 +1,125 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NMI_SPEC_VERSION is network management information code (field 70) of
+// spec version negotiation. Request carries spec versions supported by
+// sender in field 48, comma separated in order of preference, approved
+// response carries the selected one.
+const NMI_SPEC_VERSION string = "801"
+
+// Response code of version negotiation without common spec version
+const RESPONSE_NO_COMMON_VERSION string = "30"
+
+const (
+	ERR_NO_COMMON_VERSION   string = "no common spec version"
+	ERR_NOT_VERSION_REQUEST string = "not a version negotiation request"
+)
+
+var (
+	ErrNoCommonVersion   = errors.New(ERR_NO_COMMON_VERSION)
+	ErrNotVersionRequest = errors.New(ERR_NOT_VERSION_REQUEST)
+)
+
+// NewVersionRequest creates new 0800 spec version negotiation message with
+// supported versions in order of preference
+func NewVersionRequest(stan string, t time.Time, versions []string) *Message {
+	msg := NewNetworkManagement(NMI_SPEC_VERSION, stan, t)
+	msg.Data.(*NetworkManagement).F48 = NewLllvar([]byte(strings.Join(versions, ",")))
+	return msg
+}
+
+// VersionRegistry holds Parsers of spec versions supported by endpoint, so
+// Parser of version negotiated with the other side is selected for the
+// session. It is safe for concurrent use.
+type VersionRegistry struct {
+	mu       sync.RWMutex
+	versions []string // in order of preference
+	parsers  map[string]*Parser
+}
+
+// Register adds parser of spec version. Versions registered earlier are
+// preferred.
+func (r *VersionRegistry) Register(version string, p *Parser) error {
+	if version == "" || strings.Contains(version, ",") {
+		return fmt.Errorf("bad spec version: %q", version)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.parsers == nil {
+		r.parsers = make(map[string]*Parser)
+	}
+	if _, ok := r.parsers[version]; !ok {
+		r.versions = append(r.versions, version)
+	}
+	r.parsers[version] = p
+	return nil
+}
+
+// Versions returns registered versions in order of preference
+func (r *VersionRegistry) Versions() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]string(nil), r.versions...)
+}
+
+// Parser returns parser of version, or nil if it is not registered
+func (r *VersionRegistry) Parser(version string) *Parser {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.parsers[version]
+}
+
+// Select returns the first of versions offered by the other side which is
+// registered. It returns ErrNoCommonVersion if there is none.
+func (r *VersionRegistry) Select(versions []string) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, v := range versions {
+		if _, ok := r.parsers[v]; ok {
+			return v, nil
+		}
+	}
+	return "", ErrNoCommonVersion
+}
+
+// Respond creates 0810 response to version negotiation request created by
+// NewVersionRequest. It is approved with the selected version in field 48,
+// or declined with RESPONSE_NO_COMMON_VERSION if there is none. Server
+// passes the response to Session.Observe too. Other messages (including
+// ones which Data is not *NetworkManagement) are rejected with error
+// matching ErrNotVersionRequest.
+func (r *VersionRegistry) Respond(req *Message) (*Message, error) {
+	data, ok := req.Data.(*NetworkManagement)
+	if !ok || data == nil || data.F70 == nil || data.F70.Value != NMI_SPEC_VERSION {
+		return nil, fmt.Errorf("%w: %s", ErrNotVersionRequest, req.Mti)
+	}
+	var offered []string
+	if data.F48 != nil {
+		offered = strings.Split(string(data.F48.Value), ",")
+	}
+	version, err := r.Select(offered)
+	code := "00"
+	if err != nil {
+		code = RESPONSE_NO_COMMON_VERSION
+	}
+	resp, err := NewNetworkManagementResponse(req, code)
+	if err != nil {
+		return nil, err
+	}
+	respData, ok := resp.Data.(*NetworkManagement)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotVersionRequest, req.Mti)
+	}
+	respData.F48 = nil
+	if version != "" {
+		respData.F48 = NewLllvar([]byte(version))
+	}
+	return resp, nil
+}
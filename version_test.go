@@ -0,0 +1,67 @@
+package iso8583
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestVersionNegotiation(t *testing.T) {
+	v1, v2 := &Parser{}, &Parser{MtiEncode: BCD}
+	client := &VersionRegistry{}
+	assert.Nil(t, client.Register("2024.1", v2))
+	assert.Nil(t, client.Register("2023.2", v1))
+	assert.Equal(t, []string{"2024.1", "2023.2"}, client.Versions())
+	assert.EqualError(t, client.Register("a,b", v1), `bad spec version: "a,b"`)
+
+	server := &VersionRegistry{}
+	assert.Nil(t, server.Register("2023.2", &Parser{}))
+	assert.Nil(t, server.Register("2022.1", &Parser{}))
+	serverSession := &Session{Versions: server}
+
+	send := func(ctx context.Context, req *Message) (*Message, error) {
+		var resp *Message
+		var err error
+		if req.Data.(*NetworkManagement).F70.Value == NMI_SPEC_VERSION {
+			resp, err = server.Respond(req)
+		} else {
+			resp, err = NewNetworkManagementResponse(req, "00")
+		}
+		if err == nil {
+			serverSession.Observe(resp)
+		}
+		return resp, err
+	}
+
+	s := &Session{Versions: client}
+	assert.Nil(t, s.Parser())
+	assert.Nil(t, s.Connect(func(ctx context.Context) error { return nil }, send)(context.Background()))
+	assert.Equal(t, "2023.2", s.Version())
+	assert.Equal(t, v1, s.Parser())
+	assert.Equal(t, "2023.2", serverSession.Version())
+
+	s.Reset()
+	assert.Equal(t, "", s.Version())
+
+	// no common version
+	s.Versions = &VersionRegistry{}
+	assert.Nil(t, s.Versions.Register("2025.1", v2))
+	err := s.Connect(func(ctx context.Context) error { return nil }, send)(context.Background())
+	assert.True(t, errors.Is(err, ErrNoCommonVersion))
+	assert.True(t, s.SignedOn())
+	assert.Equal(t, "", s.Version())
+
+	resp, err := server.Respond(NewVersionRequest("000001", time.Now(), []string{"2025.1"}))
+	assert.Nil(t, err)
+	assert.Equal(t, RESPONSE_NO_COMMON_VERSION, resp.Data.(*NetworkManagement).F39.Value)
+	assert.Nil(t, resp.Data.(*NetworkManagement).F48)
+
+	_, err = server.Respond(NewEcho("000001", time.Now()))
+	assert.EqualError(t, err, "not a version negotiation request: 0800")
+	for _, data := range []interface{}{&testTransaction{}, (*NetworkManagement)(nil), nil} {
+		_, err = server.Respond(NewMessage("0800", data))
+		assert.ErrorIs(t, err, ErrNotVersionRequest)
+	}
+}
@@ -0,0 +1,65 @@
+package iso8583
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Zones of the last digit of zoned decimal
+const (
+	zonePositive = 0xc
+	zoneNegative = 0xd
+	zoneUnsigned = 0xf
+)
+
+// zoned encodes numeric value with optional sign ("-" or "+") into zoned
+// decimal of length digits. Unsigned value gets zone F, negative one D and
+// value with "+" sign C.
+func zoned(val string, length int) ([]byte, error) {
+	sign := byte(zoneUnsigned)
+	switch {
+	case strings.HasPrefix(val, "-"):
+		sign = zoneNegative
+		val = val[1:]
+	case strings.HasPrefix(val, "+"):
+		sign = zonePositive
+		val = val[1:]
+	}
+	if !isDigits(val) {
+		return nil, fmt.Errorf("%w: %s", ErrNotNumeric, val)
+	}
+	if len(val) > length {
+		return nil, valueTooLong("Numeric", length, len(val))
+	}
+	val = strings.Repeat("0", length-len(val)) + val
+	ret := make([]byte, length)
+	for i := range val {
+		ret[i] = 0xf0 | (val[i] - '0')
+	}
+	if length > 0 {
+		ret[length-1] = sign<<4 | ret[length-1]&0x0f
+	}
+	return ret, nil
+}
+
+// unzoned decodes zoned decimal, negative value gets "-" sign
+func unzoned(raw []byte) (string, error) {
+	val := make([]byte, len(raw))
+	sign := ""
+	for i, b := range raw {
+		zone := b >> 4
+		if b&0x0f > 9 {
+			return "", ErrBadRaw
+		}
+		switch {
+		case zone == zoneUnsigned:
+		case i == len(raw)-1 && (zone == zonePositive || zone == 0xa || zone == 0xe):
+		case i == len(raw)-1 && (zone == zoneNegative || zone == 0xb):
+			sign = "-"
+		default:
+			return "", ErrBadRaw
+		}
+		val[i] = '0' + b&0x0f
+	}
+	return sign + string(val), nil
+}
@@ -0,0 +1,39 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestZoned(t *testing.T) {
+	type test struct {
+		F4 *Numeric `field:"4" length:"6" encode:"zoned"`
+		F5 *Numeric `field:"5" length:"4" encode:"zoned"`
+		F6 *Numeric `field:"6" length:"3" encode:"zoned"`
+	}
+	data := &test{NewNumeric("-1250"), NewNumeric("+17"), NewNumeric("3")}
+	res, err := NewMessage("0200", data).Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "f0f0f1f2f5d0"+"f0f0f1c7"+"f0f0f3", fmt.Sprintf("%x", res[12:]))
+
+	loaded := &test{&Numeric{}, &Numeric{}, &Numeric{}}
+	assert.Nil(t, NewMessage("", loaded).Load(res))
+	assert.Equal(t, "-001250", loaded.F4.Value)
+	assert.Equal(t, "0017", loaded.F5.Value)
+	assert.Equal(t, "003", loaded.F6.Value)
+
+	val, err := unzoned([]byte{0xf1, 0xb2})
+	assert.Nil(t, err)
+	assert.Equal(t, "-12", val)
+	_, err = unzoned([]byte{0xd1, 0xf2})
+	assert.Equal(t, ErrBadRaw, err)
+	_, err = unzoned([]byte{0xfa})
+	assert.Equal(t, ErrBadRaw, err)
+
+	_, err = zoned("12a", 4)
+	assert.True(t, errors.Is(err, ErrNotNumeric))
+	_, err = zoned("-12345", 4)
+	assert.True(t, errors.Is(err, ErrValueTooLong))
+}